@@ -25,6 +25,13 @@ func main() {
 		os.Exit(2)
 	}
 
+	err = config.Validate()
+	if err != nil {
+		config.OutputUsage()
+		log.Errorf("Invalid processor config: err: %v\n", err)
+		os.Exit(2)
+	}
+
 	persisters, err := processormain.InitPersisters(config)
 	if err != nil {
 		log.Errorf("Error initializing persister: err: %v", err)
@@ -32,6 +39,7 @@ func main() {
 	}
 
 	processormain.SetupKillNotify(persisters)
+	processormain.StartHealthServer(config, persisters)
 
 	if config.CronConfig != "" {
 		processormain.ProcessorCronMain(config, persisters)
@@ -0,0 +1,110 @@
+// Package main contains logic to set the processor to replay events from an
+// earlier timestamp
+package main
+
+import (
+	"flag"
+	"os"
+
+	log "github.com/golang/glog"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence"
+
+	cconfig "github.com/joincivil/go-common/pkg/config"
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+)
+
+const (
+	envVarPrefix = "replayfrom"
+)
+
+var (
+	timestamp = flag.Int64("ts", 0, "Timestamp in secs to replay events from")
+	confirm   = flag.Bool("confirm", false, "Must be set to true to update the cron table")
+)
+
+// Config configures this command
+type Config struct {
+	PersisterPostgresAddress string `split_words:"true" required:"true" desc:"Sets the Postgresql address"`
+	PersisterPostgresPort    int    `split_words:"true" required:"true" desc:"Sets the Postgresql port"`
+	PersisterPostgresDbname  string `split_words:"true" required:"true" desc:"Sets the Postgresql database name"`
+	PersisterPostgresUser    string `split_words:"true" desc:"Sets the Postgresql database user"`
+	PersisterPostgresPw      string `split_words:"true" desc:"Sets the Postgresql database password"`
+	PersisterPostgresSslMode string `split_words:"true" default:"disable" desc:"Sets the Postgresql SSL mode"`
+}
+
+// PopulateFromEnv processes the environment vars, populates Config
+func (c *Config) PopulateFromEnv() error {
+	return envconfig.Process(envVarPrefix, c)
+}
+
+// OutputUsage prints the usage string to os.Stdout
+func (c *Config) OutputUsage() {
+	cconfig.OutputUsage(c, envVarPrefix, envVarPrefix)
+}
+
+func main() {
+	config := &Config{}
+	flag.Usage = func() {
+		flag.PrintDefaults()
+		config.OutputUsage()
+		os.Exit(0)
+	}
+	flag.Parse()
+
+	err := config.PopulateFromEnv()
+	if err != nil {
+		config.OutputUsage()
+		log.Errorf("Invalid replayfrom config: err: %v\n", err)
+		os.Exit(2)
+	}
+	if *timestamp <= 0 {
+		log.Errorf("A timestamp to replay from is required, set with -ts")
+		os.Exit(2)
+	}
+
+	persister, err := persistence.NewPostgresPersister(
+		config.PersisterPostgresAddress,
+		config.PersisterPostgresPort,
+		config.PersisterPostgresUser,
+		config.PersisterPostgresPw,
+		config.PersisterPostgresDbname,
+		nil,
+		nil,
+		nil,
+		&persistence.SSLConfig{Mode: config.PersisterPostgresSslMode},
+	)
+	if err != nil {
+		log.Errorf("Error connecting to Postgresql, stopping...; err: %v", err)
+		os.Exit(1)
+	}
+	defer persister.Close() // nolint: errcheck
+
+	// Use the current version of the tables without updating the version
+	_, err = persister.PersisterVersion()
+	if err != nil && err != cpersist.ErrPersisterNoResults {
+		log.Errorf("Error getting version, stopping...; err: %v", err)
+		os.Exit(1)
+	}
+
+	lastTs, err := persister.TimestampOfLastEventForCron()
+	if err != nil {
+		log.Errorf("Error getting last cron timestamp, stopping...; err: %v", err)
+		os.Exit(1)
+	}
+
+	if !*confirm {
+		log.Infof("Would set cron timestamp from %v to %v, set -confirm to update", lastTs, *timestamp)
+		log.Flush()
+		return
+	}
+
+	err = persister.SetCronTimestamp(*timestamp)
+	if err != nil {
+		log.Errorf("Error setting cron timestamp, stopping...; err: %v", err)
+		os.Exit(1)
+	}
+	log.Infof("Set cron timestamp from %v to %v, next run will replay from %v", lastTs, *timestamp, *timestamp)
+	log.Flush()
+}
@@ -0,0 +1,120 @@
+// Package main contains logic to reprocess a single crawler event by hash,
+// for reproducing the processing of an event when debugging
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/golang/glog"
+
+	"github.com/joincivil/civil-events-processor/pkg/helpers"
+	"github.com/joincivil/civil-events-processor/pkg/persistence"
+	"github.com/joincivil/civil-events-processor/pkg/processor"
+	"github.com/joincivil/civil-events-processor/pkg/processormain"
+	"github.com/joincivil/civil-events-processor/pkg/utils"
+)
+
+var (
+	hash           = flag.String("hash", "", "Hash of the crawler event to reprocess")
+	scratchVersion = flag.String("scratch_version", "", "If set, processes into the tables of this version instead of the current version. The tables are created if they do not exist and the version is not saved.")
+)
+
+// scratchPersister returns a persister for the tables of the given version,
+// creating them if needed
+func scratchPersister(persister *persistence.PostgresPersister,
+	version string) (*persistence.PostgresPersister, error) {
+	scratch := persister.WithVersion(version)
+	err := scratch.CreateTables()
+	if err != nil {
+		return nil, err
+	}
+	err = scratch.CreateIndices()
+	if err != nil {
+		return nil, err
+	}
+	err = scratch.RunMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return scratch, nil
+}
+
+func main() {
+	config := &utils.ProcessorConfig{}
+	flag.Usage = func() {
+		flag.PrintDefaults()
+		config.OutputUsage()
+		os.Exit(0)
+	}
+	flag.Parse()
+
+	err := config.PopulateFromEnv()
+	if err != nil {
+		config.OutputUsage()
+		log.Errorf("Invalid processor config: err: %v\n", err)
+		os.Exit(2)
+	}
+	if *hash == "" {
+		log.Errorf("An event hash to reprocess is required, set with -hash")
+		os.Exit(2)
+	}
+
+	persisters, err := processormain.InitPersisters(config)
+	if err != nil {
+		log.Errorf("Error initializing persister: err: %v", err)
+		os.Exit(2)
+	}
+	defer processormain.ClosePersisters(persisters)
+
+	persister := persisters.Persister
+	if *scratchVersion != "" {
+		persister, err = scratchPersister(persister, *scratchVersion)
+		if err != nil {
+			log.Errorf("Error creating scratch version tables: err: %v", err)
+			os.Exit(1)
+		}
+		log.Infof("Processing into scratch version %v", *scratchVersion)
+	}
+
+	client, err := ethclient.Dial(config.EthAPIURL)
+	if err != nil {
+		log.Errorf("Error connecting to eth API: err: %v", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	// Events are not published to pubsub, as this is a rerun of the event
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		Client:                               client,
+		ListingPersister:                     persister,
+		RevisionPersister:                    persister,
+		GovEventPersister:                    persister,
+		ChallengePersister:                   persister,
+		PollPersister:                        persister,
+		AppealPersister:                      persister,
+		TokenTransferPersister:               persister,
+		ParameterProposalPersister:           persister,
+		ParameterPersister:                   persister,
+		UserChallengeDataPersister:           persister,
+		MultiSigPersister:                    persister,
+		MultiSigOwnerPersister:               persister,
+		GovernmentParameterProposalPersister: persister,
+		GovernmentParameterPersister:         persister,
+		CharterScraper:                       helpers.CharterScraper(config),
+		ContentScraper:                       helpers.ContentScraper(config),
+		CivilMetadataScraper:                 helpers.CivilMetadataScraper(config),
+		TCRContractAddresses:                 config.TCRAddresses(),
+		EventSource:                          persisters.Event,
+	})
+
+	err = proc.ProcessEventByHash(*hash)
+	if err != nil {
+		log.Errorf("Error reprocessing event %v: err: %v", *hash, err)
+		log.Flush()
+		os.Exit(1)
+	}
+	log.Infof("Reprocessed event %v", *hash)
+	log.Flush()
+}
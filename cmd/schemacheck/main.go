@@ -0,0 +1,112 @@
+package main
+
+// This script checks the columns of the processor tables in the database
+// against the db tags of the postgres models. Drift between them causes scan
+// errors at runtime, so this can be run before deploying a new version.
+// Exits with a non-zero status if any table does not match.
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence"
+
+	cconfig "github.com/joincivil/go-common/pkg/config"
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+)
+
+// Config configures this script
+type Config struct {
+	PersisterPostgresAddress string `split_words:"true" desc:"If persister type is Postgresql, sets the address"`
+	PersisterPostgresPort    int    `split_words:"true" desc:"If persister type is Postgresql, sets the port"`
+	PersisterPostgresDbname  string `split_words:"true" desc:"If persister type is Postgresql, sets the database name"`
+	PersisterPostgresUser    string `split_words:"true" desc:"If persister type is Postgresql, sets the database user"`
+	PersisterPostgresPw      string `split_words:"true" desc:"If persister type is Postgresql, sets the database password"`
+}
+
+// PopulateFromEnv processes the environment vars, populates Config
+func (c *Config) PopulateFromEnv() error {
+	return envconfig.Process("schemacheck", c)
+}
+
+// OutputUsage prints the usage string to os.Stdout
+func (c *Config) OutputUsage() {
+	cconfig.OutputUsage(c, "schemacheck", "schemacheck")
+}
+
+func schemaPersister(config *Config) (*persistence.PostgresPersister, error) {
+	persister, err := persistence.NewPostgresPersister(
+		config.PersisterPostgresAddress,
+		config.PersisterPostgresPort,
+		config.PersisterPostgresUser,
+		config.PersisterPostgresPw,
+		config.PersisterPostgresDbname,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Check the current version of the tables without updating the version
+	_, err = persister.PersisterVersion()
+	if err != nil && err != cpersist.ErrPersisterNoResults {
+		return nil, err
+	}
+	return persister, nil
+}
+
+func run(config *Config) {
+	persister, err := schemaPersister(config)
+	if err != nil {
+		fmt.Printf("error with persister: err: %v\n", err)
+		os.Exit(2)
+	}
+
+	mismatches, err := persister.VerifySchema()
+	if err != nil {
+		fmt.Printf("error verifying schema: err: %v\n", err)
+		os.Exit(2)
+	}
+
+	for _, mismatch := range mismatches {
+		fmt.Printf("Mismatched table: %v, missing columns: [%v], extra columns: [%v]\n",
+			mismatch.TableName,
+			strings.Join(mismatch.MissingColumns, ", "),
+			strings.Join(mismatch.ExtraColumns, ", "),
+		)
+	}
+	if len(mismatches) > 0 {
+		fmt.Printf("Found %v mismatched tables.\n", len(mismatches))
+		os.Exit(1)
+	}
+	fmt.Printf("Done.\n")
+}
+
+func main() {
+	config := &Config{}
+	flag.Usage = func() {
+		config.OutputUsage()
+		os.Exit(0)
+	}
+	flag.Parse()
+
+	err := cconfig.PopulateFromDotEnv("SCHEMACHECK_ENV")
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(2)
+	}
+
+	err = config.PopulateFromEnv()
+	if err != nil {
+		config.OutputUsage()
+		os.Exit(2)
+	}
+
+	run(config)
+}
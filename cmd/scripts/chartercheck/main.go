@@ -14,6 +14,7 @@ import (
 
 	"github.com/joincivil/civil-events-processor/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/persistence"
+	"github.com/joincivil/civil-events-processor/pkg/processor"
 
 	"github.com/joincivil/go-common/pkg/generated/contract"
 
@@ -129,6 +130,30 @@ func ensureListingLatestCharter(newsroomAddr common.Address, newsroom *contract.
 	return nil
 }
 
+// flags listings with a charter signature that does not match the charter author
+func checkCharterSignature(listing *model.Listing) {
+	charter := listing.Charter()
+	if charter == nil {
+		return
+	}
+	valid, err := charter.VerifySignature(listing.ContractAddress())
+	if err != nil {
+		fmt.Printf(
+			"invalid charter signature: listing addr: %v, err: %v\n",
+			listing.ContractAddress().Hex(),
+			err,
+		)
+		return
+	}
+	if !valid {
+		fmt.Printf(
+			"charter signature does not match author: listing addr: %v, author: %v\n",
+			listing.ContractAddress().Hex(),
+			charter.Author().Hex(),
+		)
+	}
+}
+
 // ensures we have all our charter revisions in the content_revision table
 func ensureCharterContentRevisions(newsroomAddr common.Address, newsroom *contract.NewsroomContract,
 	persister *persistence.PostgresPersister, wetRun bool) error {
@@ -217,6 +242,7 @@ func main() {
 		nil,
 		nil,
 		nil,
+		nil,
 	)
 	if err != nil {
 		fmt.Printf("err db: %v", err)
@@ -238,28 +264,38 @@ func main() {
 	}
 
 	for _, listing := range listings {
-		newsroom, err := contract.NewNewsroomContract(listing.ContractAddress(), client)
+		checkCharterSignature(listing)
+	}
+
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		Client:            client,
+		ListingPersister:  persister,
+		RevisionPersister: persister,
+		GovEventPersister: persister,
+	})
+	outdated, err := proc.ListingsWithOutdatedCharter(client)
+	if err != nil {
+		fmt.Printf("err outdated charters: %v", err)
+		return
+	}
+
+	for _, listingAddress := range outdated {
+		newsroom, err := contract.NewNewsroomContract(listingAddress, client)
 		if err != nil {
 			fmt.Printf("err b: %v", err)
-			return
+			continue
 		}
 
-		// err = checkRevisionFromContract(listing.ContractAddress(), newsroom, persister)
-		// if err != nil {
-		// 	fmt.Printf("err check content rev: %v", err)
-		// 	return
-		// }
-
-		err = ensureCharterContentRevisions(listing.ContractAddress(), newsroom, persister, config.WetRun)
+		err = ensureCharterContentRevisions(listingAddress, newsroom, persister, config.WetRun)
 		if err != nil {
 			fmt.Printf("err charter content rev: %v", err)
-			return
+			continue
 		}
 
-		err = ensureListingLatestCharter(listing.ContractAddress(), newsroom, persister, config.WetRun)
+		err = ensureListingLatestCharter(listingAddress, newsroom, persister, config.WetRun)
 		if err != nil {
 			fmt.Printf("err listing latest charter: %v", err)
-			return
+			continue
 		}
 		fmt.Printf("\n\n\n")
 	}
@@ -15,22 +15,19 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
 
 	"cloud.google.com/go/datastore"
 	"github.com/kelseyhightower/envconfig"
-	"google.golang.org/api/iterator"
 
-	crawlmodel "github.com/joincivil/civil-events-crawler/pkg/model"
-	crawlpersist "github.com/joincivil/civil-events-crawler/pkg/persistence"
+	"github.com/joincivil/civil-events-processor/pkg/dscheck"
+	"github.com/joincivil/civil-events-processor/pkg/persistence"
 
 	cconfig "github.com/joincivil/go-common/pkg/config"
-	ceth "github.com/joincivil/go-common/pkg/eth"
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
 )
 
 const (
 	googleCredsEnvVarName = "GOOGLE_APPLICATION_CREDENTIALS"
-	dsKind                = "GovernanceEvent"
 )
 
 // Config configures this script
@@ -41,7 +38,7 @@ type Config struct {
 	PersisterPostgresDbname  string `split_words:"true" desc:"If persister type is Postgresql, sets the database name"`
 	PersisterPostgresUser    string `split_words:"true" desc:"If persister type is Postgresql, sets the database user"`
 	PersisterPostgresPw      string `split_words:"true" desc:"If persister type is Postgresql, sets the database password"`
-	TcrContractAddress       string `split_words:"true" desc:"Sets up the contract address of the TCR"`
+	PersisterPostgresReplica string `split_words:"true" desc:"If persister type is Postgresql, sets the connection URL of a read replica for the governance event queries"`
 	DsNamespace              string `split_words:"true" desc:"Sets up the datastore namespace to use"`
 }
 
@@ -72,8 +69,10 @@ func datastoreClient() (*datastore.Client, error) {
 	return client, nil
 }
 
-func eventsPersister(config *Config) (*crawlpersist.PostgresPersister, error) {
-	return crawlpersist.NewPostgresPersister(
+// govEventsPersister returns the governance event store checked against the
+// datastore, backed by the Postgres persister
+func govEventsPersister(config *Config) (dscheck.GovernanceEventRetriever, error) {
+	persister, err := persistence.NewPostgresPersister(
 		config.PersisterPostgresAddress,
 		config.PersisterPostgresPort,
 		config.PersisterPostgresUser,
@@ -82,7 +81,24 @@ func eventsPersister(config *Config) (*crawlpersist.PostgresPersister, error) {
 		nil,
 		nil,
 		nil,
+		nil,
 	)
+	if err != nil {
+		return nil, err
+	}
+	// The script only reads, so can read from a replica
+	if config.PersisterPostgresReplica != "" {
+		err = persister.ConnectReadReplica(config.PersisterPostgresReplica)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Read from the current version of the tables without updating the version
+	_, err = persister.PersisterVersion()
+	if err != nil && err != cpersist.ErrPersisterNoResults {
+		return nil, err
+	}
+	return persister, nil
 }
 
 func run(config *Config) {
@@ -92,73 +108,30 @@ func run(config *Config) {
 		os.Exit(2)
 	}
 
-	eventsPersister, err := eventsPersister(config)
+	govEventsPersister, err := govEventsPersister(config)
 	if err != nil {
 		fmt.Printf("error with persister: err: %v\n", err)
 		os.Exit(2)
 	}
 
-	offset := 0
-	count := 500
-	ctx := context.Background()
+	missing, err := dscheck.GovernanceEventsMissingFromDatastore(
+		context.Background(),
+		dscheck.NewQuerier(dsClient),
+		config.DsNamespace,
+		govEventsPersister,
+	)
+	if err != nil {
+		fmt.Printf("error checking datastore: err: %v\n", err)
+		os.Exit(2)
+	}
 
-	// normalize addr
-	config.TcrContractAddress = ceth.NormalizeEthAddress(config.TcrContractAddress)
-
-Loop:
-	for {
-		events, err := eventsPersister.RetrieveEvents(
-			&crawlmodel.RetrieveEventsCriteria{
-				ContractAddress: config.TcrContractAddress,
-				Offset:          offset,
-				Count:           count,
-			},
+	for _, event := range missing {
+		blockData := event.BlockData()
+		fmt.Printf("Missing event: %v, metadata: %v, txHash: %v\n",
+			event.GovernanceEventType(),
+			event.Metadata(),
+			blockData.TxHash(),
 		)
-		if err != nil {
-			fmt.Printf("error retrieving events: err: %v\n", err)
-			os.Exit(2)
-		}
-
-		for _, event := range events {
-			txHash := strings.ToLower(event.TxHash().Hex())
-			query := datastore.NewQuery(dsKind).
-				Filter("txHash =", txHash).
-				Namespace(config.DsNamespace).
-				KeysOnly()
-
-			it := dsClient.Run(ctx, query)
-
-			resultFound := false
-			for {
-				_, err := it.Next(nil)
-				if err == iterator.Done {
-					break
-				}
-				if err != nil {
-					fmt.Printf(
-						"error retrieving from ds: eventType: %v, err: %v\n",
-						event.EventType(),
-						err,
-					)
-					continue
-				}
-				resultFound = true
-			}
-
-			if !resultFound {
-				fmt.Printf("Missing event: %v, payload: %v, txHash: %v\n",
-					event.EventType(),
-					event.EventPayload(),
-					event.TxHash().Hex(),
-				)
-			}
-		}
-
-		if len(events) < count {
-			break Loop
-		}
-
-		offset += count
 	}
 	fmt.Printf("Done.\n")
 }
@@ -8,6 +8,7 @@ require (
 	github.com/ethereum/go-ethereum v1.9.6
 	github.com/fatih/structs v1.1.0 // indirect
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
+	github.com/hashicorp/golang-lru v0.5.3
 	github.com/jmoiron/sqlx v0.0.0-20180614180643-0dae4fefe7c0
 	github.com/joincivil/civil-events-crawler v0.0.0-20200107003832-d536ba1f7b6f
 	github.com/joincivil/go-common v0.0.0-20200107002045-7da72c934006
@@ -0,0 +1,103 @@
+// Package dscheck contains helpers to check processor data against the Google
+// Cloud datastore used by the notification functions.
+package dscheck // import "github.com/joincivil/civil-events-processor/pkg/dscheck"
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+const (
+	// GovernanceEventKind is the datastore kind for governance events
+	GovernanceEventKind = "GovernanceEvent"
+
+	defaultPageSize = 500
+)
+
+// Querier checks for the existence of entities in the datastore
+type Querier interface {
+	// TxHashExists returns true if an entity of the given kind with the given
+	// tx hash exists in the namespace
+	TxHashExists(ctx context.Context, kind string, namespace string, txHash string) (bool, error)
+}
+
+// NewQuerier returns a Querier backed by the given datastore client
+func NewQuerier(client *datastore.Client) Querier {
+	return &clientQuerier{client: client}
+}
+
+type clientQuerier struct {
+	client *datastore.Client
+}
+
+func (c *clientQuerier) TxHashExists(ctx context.Context, kind string, namespace string,
+	txHash string) (bool, error) {
+	query := datastore.NewQuery(kind).
+		Filter("txHash =", txHash).
+		Namespace(namespace).
+		KeysOnly().
+		Limit(1)
+
+	keys, err := c.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(keys) > 0, nil
+}
+
+// GovernanceEventRetriever retrieves governance events by criteria.
+// Implemented by model.GovernanceEventPersister.
+type GovernanceEventRetriever interface {
+	GovernanceEventsByCriteria(criteria *model.GovernanceEventCriteria) ([]*model.GovernanceEvent, error)
+}
+
+// GovernanceEventsMissingFromDatastore pages through all the governance events
+// and returns those whose tx hash is not found in the datastore namespace.
+func GovernanceEventsMissingFromDatastore(ctx context.Context, querier Querier,
+	namespace string, retriever GovernanceEventRetriever) ([]*model.GovernanceEvent, error) {
+	return governanceEventsMissingFromDatastore(ctx, querier, namespace, retriever,
+		defaultPageSize)
+}
+
+func governanceEventsMissingFromDatastore(ctx context.Context, querier Querier,
+	namespace string, retriever GovernanceEventRetriever,
+	pageSize int) ([]*model.GovernanceEvent, error) {
+	missing := []*model.GovernanceEvent{}
+	offset := 0
+
+	for {
+		events, err := retriever.GovernanceEventsByCriteria(
+			&model.GovernanceEventCriteria{
+				Offset: offset,
+				Count:  pageSize,
+			},
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "error retrieving governance events")
+		}
+
+		for _, event := range events {
+			blockData := event.BlockData()
+			txHash := strings.ToLower(blockData.TxHash())
+			exists, err := querier.TxHashExists(ctx, GovernanceEventKind, namespace, txHash)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error querying datastore for tx hash %v", txHash)
+			}
+			if !exists {
+				missing = append(missing, event)
+			}
+		}
+
+		if len(events) < pageSize {
+			break
+		}
+		offset += pageSize
+	}
+
+	return missing, nil
+}
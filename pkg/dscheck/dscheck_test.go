@@ -0,0 +1,140 @@
+package dscheck
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+type fakeQuerier struct {
+	txHashes map[string]bool
+	err      error
+	queries  int
+}
+
+func (f *fakeQuerier) TxHashExists(ctx context.Context, kind string, namespace string,
+	txHash string) (bool, error) {
+	f.queries++
+	if f.err != nil {
+		return false, f.err
+	}
+	if kind != GovernanceEventKind || namespace != "testns" {
+		return false, nil
+	}
+	return f.txHashes[txHash], nil
+}
+
+type fakeRetriever struct {
+	events  []*model.GovernanceEvent
+	offsets []int
+}
+
+func (f *fakeRetriever) GovernanceEventsByCriteria(criteria *model.GovernanceEventCriteria) (
+	[]*model.GovernanceEvent, error) {
+	f.offsets = append(f.offsets, criteria.Offset)
+	if criteria.Offset >= len(f.events) {
+		return []*model.GovernanceEvent{}, nil
+	}
+	end := criteria.Offset + criteria.Count
+	if end > len(f.events) {
+		end = len(f.events)
+	}
+	return f.events[criteria.Offset:end], nil
+}
+
+func testGovEvent(txHash common.Hash) *model.GovernanceEvent {
+	return model.NewGovernanceEvent(
+		common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"),
+		model.Metadata{},
+		"Application",
+		1000,
+		1000,
+		txHash.Hex(),
+		100,
+		txHash,
+		1,
+		common.Hash{},
+		1,
+	)
+}
+
+func TestGovernanceEventsMissingFromDatastore(t *testing.T) {
+	querier := &fakeQuerier{txHashes: map[string]bool{}}
+	retriever := &fakeRetriever{}
+	for i := 0; i < 7; i++ {
+		event := testGovEvent(common.BigToHash(big.NewInt(int64(i + 1))))
+		retriever.events = append(retriever.events, event)
+		// Every other event is in the datastore
+		if i%2 == 0 {
+			blockData := event.BlockData()
+			querier.txHashes[strings.ToLower(blockData.TxHash())] = true
+		}
+	}
+
+	missing, err := governanceEventsMissingFromDatastore(context.Background(), querier,
+		"testns", retriever, 3)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error: %v", err)
+	}
+	if len(missing) != 3 {
+		t.Fatalf("Should have 3 missing events, have %v", len(missing))
+	}
+	for i, event := range missing {
+		if event != retriever.events[i*2+1] {
+			t.Errorf("Wrong missing event at %v", i)
+		}
+	}
+	if querier.queries != 7 {
+		t.Errorf("Should have queried the datastore 7 times, queried %v", querier.queries)
+	}
+	expectedOffsets := []int{0, 3, 6}
+	if len(retriever.offsets) != len(expectedOffsets) {
+		t.Fatalf("Should have retrieved %v pages, retrieved %v", len(expectedOffsets),
+			len(retriever.offsets))
+	}
+	for i, offset := range expectedOffsets {
+		if retriever.offsets[i] != offset {
+			t.Errorf("Wrong offset for page %v: %v", i, retriever.offsets[i])
+		}
+	}
+}
+
+func TestGovernanceEventsMissingFromDatastoreFullPages(t *testing.T) {
+	querier := &fakeQuerier{txHashes: map[string]bool{}}
+	retriever := &fakeRetriever{}
+	for i := 0; i < 4; i++ {
+		retriever.events = append(retriever.events, testGovEvent(common.BigToHash(common.Big1)))
+	}
+
+	missing, err := governanceEventsMissingFromDatastore(context.Background(), querier,
+		"testns", retriever, 2)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error: %v", err)
+	}
+	if len(missing) != 4 {
+		t.Errorf("Should have 4 missing events, have %v", len(missing))
+	}
+	// Last page is empty when events fill the pages exactly
+	if len(retriever.offsets) != 3 {
+		t.Errorf("Should have retrieved 3 pages, retrieved %v", len(retriever.offsets))
+	}
+}
+
+func TestGovernanceEventsMissingFromDatastoreError(t *testing.T) {
+	querier := &fakeQuerier{err: errors.New("datastore error")}
+	retriever := &fakeRetriever{
+		events: []*model.GovernanceEvent{testGovEvent(common.BigToHash(common.Big1))},
+	}
+
+	_, err := GovernanceEventsMissingFromDatastore(context.Background(), querier,
+		"testns", retriever)
+	if err == nil {
+		t.Errorf("Should have gotten an error from the datastore")
+	}
+}
@@ -3,7 +3,9 @@
 package helpers
 
 import (
-	// log "github.com/golang/glog"
+	"time"
+
+	log "github.com/golang/glog"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/joincivil/civil-events-processor/pkg/model"
@@ -299,7 +301,39 @@ func UserChallengeDataPersisterFromSqlx(db *sqlx.DB, versionNumber string) (
 	return p.(model.UserChallengeDataPersister), nil
 }
 
+// postgresSSLConfig is implemented by persister configs that set the SSL
+// options for the Postgres connection
+type postgresSSLConfig interface {
+	SSLMode() string
+	SSLRootCert() string
+	SSLCert() string
+	SSLKey() string
+}
+
+// postgresSlowQueryConfig is implemented by persister configs that set the
+// slow query logging threshold
+type postgresSlowQueryConfig interface {
+	SlowQueryThreshold() time.Duration
+}
+
+// postgresReadReplicaConfig is implemented by read only persister configs, such
+// as for an API, that set the read replica for queries. The processor config
+// does not implement it, since processing reads rows to update them and replica
+// reads may lag behind the primary.
+type postgresReadReplicaConfig interface {
+	ReadReplicaURL() string
+}
+
 func postgresPersister(config cconfig.PersisterConfig, versionNumber string) (*persistence.PostgresPersister, error) {
+	var ssl *persistence.SSLConfig
+	if sslConfig, ok := config.(postgresSSLConfig); ok {
+		ssl = &persistence.SSLConfig{
+			Mode:     sslConfig.SSLMode(),
+			RootCert: sslConfig.SSLRootCert(),
+			Cert:     sslConfig.SSLCert(),
+			Key:      sslConfig.SSLKey(),
+		}
+	}
 	persister, err := persistence.NewPostgresPersister(
 		config.Address(),
 		config.Port(),
@@ -309,10 +343,20 @@ func postgresPersister(config cconfig.PersisterConfig, versionNumber string) (*p
 		config.PoolMaxConns(),
 		config.PoolMaxIdleConns(),
 		config.PoolConnLifetimeSecs(),
+		ssl,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if replicaConfig, ok := config.(postgresReadReplicaConfig); ok && replicaConfig.ReadReplicaURL() != "" {
+		err = persister.ConnectReadReplica(replicaConfig.ReadReplicaURL(), postgresPoolOptions(config)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if slowQueryConfig, ok := config.(postgresSlowQueryConfig); ok {
+		persister.SetSlowQueryThreshold(slowQueryConfig.SlowQueryThreshold())
+	}
 	err = initTablesAndData(persister, versionNumber)
 	if err != nil {
 		return nil, err
@@ -321,6 +365,21 @@ func postgresPersister(config cconfig.PersisterConfig, versionNumber string) (*p
 	return persister, nil
 }
 
+// postgresPoolOptions returns the pool options set in the persister config
+func postgresPoolOptions(config cconfig.PersisterConfig) []persistence.PoolOption {
+	opts := []persistence.PoolOption{}
+	if config.PoolMaxConns() != nil {
+		opts = append(opts, persistence.WithMaxConns(*config.PoolMaxConns()))
+	}
+	if config.PoolMaxIdleConns() != nil {
+		opts = append(opts, persistence.WithMaxIdleConns(*config.PoolMaxIdleConns()))
+	}
+	if config.PoolConnLifetimeSecs() != nil {
+		opts = append(opts, persistence.WithConnLifetimeSecs(*config.PoolConnLifetimeSecs()))
+	}
+	return opts
+}
+
 func initTablesAndData(persister *persistence.PostgresPersister, versionNumber string) error {
 	// Version table created by eventPersister, so just store version
 	err := persister.InitProcessorVersion(&versionNumber)
@@ -342,5 +401,10 @@ func initTablesAndData(persister *persistence.PostgresPersister, versionNumber s
 	if err != nil {
 		return err
 	}
+	// Warns on any listing sort columns without an index
+	err = persister.CheckListingSortIndices()
+	if err != nil {
+		log.Errorf("Error checking listing sort indices: err: %v", err)
+	}
 	return nil
 }
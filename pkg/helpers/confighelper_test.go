@@ -0,0 +1,15 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/utils"
+)
+
+func TestProcessorConfigNoReadReplica(t *testing.T) {
+	// The processor reads rows to update them, so must read from the primary
+	var config interface{} = &utils.ProcessorConfig{}
+	if _, ok := config.(postgresReadReplicaConfig); ok {
+		t.Errorf("Processor config should not be able to set a read replica")
+	}
+}
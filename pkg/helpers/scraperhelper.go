@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/scraper"
+	"github.com/joincivil/civil-events-processor/pkg/utils"
+)
+
+// CharterScraper is a helper function to return a charter scraper with the
+// timeout, retries, IPFS gateway and host filter from the config
+func CharterScraper(config *utils.ProcessorConfig) model.ContentScraper {
+	return scraper.NewCharterIPFSScraper(config.ScraperCharterTimeout(), config.ScraperCharterRetries,
+		config.ScraperIpfsGatewayURL, ScraperHostFilter(config))
+}
+
+// ContentScraper is a helper function to return a newsroom content scraper
+// with the IPFS gateway from the config. Uses the charter timeout and retries.
+func ContentScraper(config *utils.ProcessorConfig) model.ContentScraper {
+	return scraper.NewContentScraper(config.ScraperCharterTimeout(), config.ScraperCharterRetries,
+		config.ScraperIpfsGatewayURL)
+}
+
+// CivilMetadataScraper is a helper function to return a Civil metadata scraper
+// with the timeout, retries and host filter from the config
+func CivilMetadataScraper(config *utils.ProcessorConfig) model.CivilMetadataScraper {
+	return scraper.NewCivilMetadataScraper(config.ScraperMetadataTimeout(), config.ScraperMetadataRetries,
+		ScraperHostFilter(config))
+}
+
+// ScraperHostFilter is a helper function to return the scraper host filter
+// with the allowed and blocked hosts from the config
+func ScraperHostFilter(config *utils.ProcessorConfig) *scraper.HostFilter {
+	return scraper.NewHostFilter(config.ScraperAllowedHosts, config.ScraperBlockedHosts)
+}
@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+// TokenTransferCSVHeader is the header row of the token transfer CSV export
+var TokenTransferCSVHeader = []string{
+	"from_address",
+	"to_address",
+	"amount",
+	"transfer_date",
+	"tx_hash",
+	"block_number",
+}
+
+// NewTokenTransferExporter returns a new TokenTransferExporter that reads
+// token transfers from the given persister
+func NewTokenTransferExporter(persister model.TokenTransferPersister) *TokenTransferExporter {
+	return &TokenTransferExporter{persister: persister}
+}
+
+// TokenTransferExporter exports token transfers to other formats
+type TokenTransferExporter struct {
+	persister model.TokenTransferPersister
+}
+
+// ExportTokenTransfersCSV writes the token transfers matching the criteria to
+// w as CSV. Rows are read from the persister one at a time so the full result
+// set is never held in memory. Amounts are in gwei and dates are RFC3339 in UTC.
+func (e *TokenTransferExporter) ExportTokenTransfersCSV(w io.Writer,
+	criteria *model.TokenTransferCriteria) (err error) {
+	iter, err := e.persister.TokenTransfersByCriteriaIter(criteria)
+	if err != nil {
+		return errors.WithMessage(err, "error retrieving token transfers")
+	}
+	defer func() {
+		closeErr := iter.Close()
+		if err == nil && closeErr != nil {
+			err = errors.WithMessage(closeErr, "error closing token transfer iterator")
+		}
+	}()
+
+	csvWriter := csv.NewWriter(w)
+	err = csvWriter.Write(TokenTransferCSVHeader)
+	if err != nil {
+		return errors.Wrap(err, "error writing token transfer csv header")
+	}
+	for iter.Next() {
+		err = csvWriter.Write(tokenTransferCSVRow(iter.TokenTransfer()))
+		if err != nil {
+			return errors.Wrap(err, "error writing token transfer csv row")
+		}
+	}
+	err = iter.Err()
+	if err != nil {
+		return errors.WithMessage(err, "error iterating token transfers")
+	}
+	csvWriter.Flush()
+	return errors.Wrap(csvWriter.Error(), "error flushing token transfer csv")
+}
+
+func tokenTransferCSVRow(transfer *model.TokenTransfer) []string {
+	blockData := transfer.BlockData()
+	amount := ""
+	if transfer.Amount() != nil {
+		amount = transfer.Amount().String()
+	}
+	return []string{
+		transfer.FromAddress().Hex(),
+		transfer.ToAddress().Hex(),
+		amount,
+		time.Unix(transfer.TransferDate(), 0).UTC().Format(time.RFC3339),
+		blockData.TxHash(),
+		strconv.FormatUint(blockData.BlockNumber(), 10),
+	}
+}
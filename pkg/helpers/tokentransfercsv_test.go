@@ -0,0 +1,99 @@
+package helpers_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/helpers"
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/testutils"
+)
+
+const (
+	testFromAddress = "0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"
+	testToAddress   = "0x22e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d331d"
+)
+
+func createTestTokenTransfer(t *testing.T, persister *testutils.TestPersister,
+	amount string, transferDate int64, blockNumber uint64, txHash string) {
+	amountInt, _ := new(big.Int).SetString(amount, 10)
+	transfer := model.NewTokenTransfer(&model.TokenTransferParams{
+		ToAddress:    common.HexToAddress(testToAddress),
+		FromAddress:  common.HexToAddress(testFromAddress),
+		Amount:       amountInt,
+		TransferDate: transferDate,
+		BlockNumber:  blockNumber,
+		TxHash:       common.HexToHash(txHash),
+		BlockHash:    common.HexToHash("0x1"),
+	})
+	err := persister.CreateTokenTransfer(transfer)
+	if err != nil {
+		t.Fatalf("Should not have failed to create token transfer: err: %v", err)
+	}
+}
+
+func TestExportTokenTransfersCSV(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	createTestTokenTransfer(t, persister, "2000000000000000000000", 1546300800, 20, "0x2")
+	createTestTokenTransfer(t, persister, "1000000000000000000", 1546214400, 10, "0x1")
+	createTestTokenTransfer(t, persister, "5", 1546387200, 30, "0x3")
+
+	buf := &bytes.Buffer{}
+	exporter := helpers.NewTokenTransferExporter(persister)
+	err := exporter.ExportTokenTransfersCSV(buf, &model.TokenTransferCriteria{
+		BeforeTs: 1546387200,
+	})
+	if err != nil {
+		t.Fatalf("Should not have failed to export token transfers: err: %v", err)
+	}
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Should have written valid csv: err: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Should have written a header and 2 rows, got %v", len(rows))
+	}
+	if strings.Join(rows[0], ",") !=
+		"from_address,to_address,amount,transfer_date,tx_hash,block_number" {
+		t.Errorf("Should have written the header row, got %v", rows[0])
+	}
+	expected := []string{
+		common.HexToAddress(testFromAddress).Hex(),
+		common.HexToAddress(testToAddress).Hex(),
+		"1000000000000000000",
+		"2018-12-31T00:00:00Z",
+		common.HexToHash("0x1").Hex(),
+		"10",
+	}
+	if strings.Join(rows[1], ",") != strings.Join(expected, ",") {
+		t.Errorf("Should have written the earliest transfer first, got %v", rows[1])
+	}
+	if rows[2][2] != "2000000000000000000000" {
+		t.Errorf("Should have written the full amount, got %v", rows[2][2])
+	}
+	if rows[2][3] != "2019-01-01T00:00:00Z" {
+		t.Errorf("Should have written the transfer date in UTC, got %v", rows[2][3])
+	}
+	if rows[2][5] != "20" {
+		t.Errorf("Should have written the block number, got %v", rows[2][5])
+	}
+}
+
+func TestExportTokenTransfersCSVNoResults(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	buf := &bytes.Buffer{}
+	exporter := helpers.NewTokenTransferExporter(persister)
+	err := exporter.ExportTokenTransfersCSV(buf, &model.TokenTransferCriteria{})
+	if err != nil {
+		t.Fatalf("Should not have failed to export token transfers: err: %v", err)
+	}
+	if buf.String() != strings.Join(helpers.TokenTransferCSVHeader, ",")+"\n" {
+		t.Errorf("Should have only written the header, got %v", buf.String())
+	}
+}
@@ -2,6 +2,7 @@
 package model // import "github.com/joincivil/civil-events-processor/pkg/model"
 
 import (
+	"encoding/json"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -125,3 +126,60 @@ func (a *Appeal) AppealGrantedStatementURI() string {
 func (a *Appeal) SetAppealGrantedStatementURI(uri string) {
 	a.appealGrantedStatementURI = uri
 }
+
+type appealJSON struct {
+	OriginalChallengeID         *string `json:"originalChallengeID"`
+	Requester                   string  `json:"requester"`
+	AppealFeePaid               *string `json:"appealFeePaid"`
+	AppealPhaseExpiry           *string `json:"appealPhaseExpiry"`
+	AppealGranted               bool    `json:"appealGranted"`
+	AppealOpenToChallengeExpiry *string `json:"appealOpenToChallengeExpiry"`
+	Statement                   string  `json:"statement"`
+	AppealGrantedStatementURI   string  `json:"appealGrantedStatementURI"`
+	AppealChallengeID           *string `json:"appealChallengeID"`
+	LastUpdatedDateTs           int64   `json:"lastUpdatedDateTs"`
+}
+
+// MarshalJSON converts this struct into a []byte. Big ints are encoded as
+// decimal strings and addresses as checksummed hex.
+func (a *Appeal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&appealJSON{
+		OriginalChallengeID:         bigIntToJSON(a.originalChallengeID),
+		Requester:                   addressToJSON(a.requester),
+		AppealFeePaid:               bigIntToJSON(a.appealFeePaid),
+		AppealPhaseExpiry:           bigIntToJSON(a.appealPhaseExpiry),
+		AppealGranted:               a.appealGranted,
+		AppealOpenToChallengeExpiry: bigIntToJSON(a.appealOpenToChallengeExpiry),
+		Statement:                   a.statement,
+		AppealGrantedStatementURI:   a.appealGrantedStatementURI,
+		AppealChallengeID:           bigIntToJSON(a.appealChallengeID),
+		LastUpdatedDateTs:           a.lastUpdatedDateTs,
+	})
+}
+
+// UnmarshalJSON populates the struct with given []byte.
+func (a *Appeal) UnmarshalJSON(data []byte) error {
+	aj := &appealJSON{}
+	err := json.Unmarshal(data, aj)
+	if err != nil {
+		return err
+	}
+	d := &jsonFieldDecoder{}
+	appeal := &Appeal{
+		originalChallengeID:         d.bigInt("originalChallengeID", aj.OriginalChallengeID),
+		requester:                   d.address("requester", aj.Requester),
+		appealFeePaid:               d.bigInt("appealFeePaid", aj.AppealFeePaid),
+		appealPhaseExpiry:           d.bigInt("appealPhaseExpiry", aj.AppealPhaseExpiry),
+		appealGranted:               aj.AppealGranted,
+		appealOpenToChallengeExpiry: d.bigInt("appealOpenToChallengeExpiry", aj.AppealOpenToChallengeExpiry),
+		statement:                   aj.Statement,
+		appealGrantedStatementURI:   aj.AppealGrantedStatementURI,
+		appealChallengeID:           d.bigInt("appealChallengeID", aj.AppealChallengeID),
+		lastUpdatedDateTs:           aj.LastUpdatedDateTs,
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*a = *appeal
+	return nil
+}
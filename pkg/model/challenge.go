@@ -2,8 +2,11 @@
 package model // import "github.com/joincivil/civil-events-processor/pkg/model"
 
 import (
-	"github.com/ethereum/go-ethereum/common"
+	"encoding/json"
 	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -140,3 +143,85 @@ func (c *Challenge) LastUpdatedDateTs() int64 {
 func (c *Challenge) SetLastUpdateDateTs(ts int64) {
 	c.lastUpdatedDateTs = ts
 }
+
+// Validate returns an error if any of the required fields of the challenge are
+// missing. Total tokens and request appeal expiry are optional since they are
+// not set for all challenge types.
+func (c *Challenge) Validate() error {
+	if c.challengeID == nil {
+		return errors.New("challenge ID is nil")
+	}
+	if c.listingAddress == (common.Address{}) {
+		return errors.Errorf("challenge %v listing address is empty", c.challengeID)
+	}
+	if c.challenger == (common.Address{}) {
+		return errors.Errorf("challenge %v challenger address is empty", c.challengeID)
+	}
+	if c.rewardPool == nil {
+		return errors.Errorf("challenge %v reward pool is nil", c.challengeID)
+	}
+	if c.stake == nil {
+		return errors.Errorf("challenge %v stake is nil", c.challengeID)
+	}
+	return nil
+}
+
+type challengeJSON struct {
+	ChallengeID         *string `json:"challengeID"`
+	ListingAddress      string  `json:"listingAddress"`
+	Statement           string  `json:"statement"`
+	RewardPool          *string `json:"rewardPool"`
+	Challenger          string  `json:"challenger"`
+	Resolved            bool    `json:"resolved"`
+	Stake               *string `json:"stake"`
+	TotalTokens         *string `json:"totalTokens"`
+	RequestAppealExpiry *string `json:"requestAppealExpiry"`
+	ChallengeType       string  `json:"challengeType"`
+	LastUpdatedDateTs   int64   `json:"lastUpdatedDateTs"`
+}
+
+// MarshalJSON converts this struct into a []byte. Big ints are encoded as
+// decimal strings and addresses as checksummed hex.
+func (c *Challenge) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&challengeJSON{
+		ChallengeID:         bigIntToJSON(c.challengeID),
+		ListingAddress:      addressToJSON(c.listingAddress),
+		Statement:           c.statement,
+		RewardPool:          bigIntToJSON(c.rewardPool),
+		Challenger:          addressToJSON(c.challenger),
+		Resolved:            c.resolved,
+		Stake:               bigIntToJSON(c.stake),
+		TotalTokens:         bigIntToJSON(c.totalTokens),
+		RequestAppealExpiry: bigIntToJSON(c.requestAppealExpiry),
+		ChallengeType:       c.challengeType,
+		LastUpdatedDateTs:   c.lastUpdatedDateTs,
+	})
+}
+
+// UnmarshalJSON populates the struct with given []byte.
+func (c *Challenge) UnmarshalJSON(data []byte) error {
+	cj := &challengeJSON{}
+	err := json.Unmarshal(data, cj)
+	if err != nil {
+		return err
+	}
+	d := &jsonFieldDecoder{}
+	challenge := &Challenge{
+		challengeID:         d.bigInt("challengeID", cj.ChallengeID),
+		listingAddress:      d.address("listingAddress", cj.ListingAddress),
+		statement:           cj.Statement,
+		rewardPool:          d.bigInt("rewardPool", cj.RewardPool),
+		challenger:          d.address("challenger", cj.Challenger),
+		resolved:            cj.Resolved,
+		stake:               d.bigInt("stake", cj.Stake),
+		totalTokens:         d.bigInt("totalTokens", cj.TotalTokens),
+		requestAppealExpiry: d.bigInt("requestAppealExpiry", cj.RequestAppealExpiry),
+		challengeType:       cj.ChallengeType,
+		lastUpdatedDateTs:   cj.LastUpdatedDateTs,
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*c = *challenge
+	return nil
+}
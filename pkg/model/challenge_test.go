@@ -0,0 +1,78 @@
+package model_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+type testChallengeParams struct {
+	challengeID    *big.Int
+	listingAddress common.Address
+	challenger     common.Address
+	rewardPool     *big.Int
+	stake          *big.Int
+}
+
+func validTestChallengeParams() *testChallengeParams {
+	return &testChallengeParams{
+		challengeID:    big.NewInt(10),
+		listingAddress: common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"),
+		challenger:     common.HexToAddress("0x22e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d331d"),
+		rewardPool:     big.NewInt(1000),
+		stake:          big.NewInt(2000),
+	}
+}
+
+func newTestChallenge(params *testChallengeParams) *model.Challenge {
+	return model.NewChallenge(params.challengeID, params.listingAddress, "statement",
+		params.rewardPool, params.challenger, false, params.stake, nil, nil,
+		model.ChallengePollType, 1000)
+}
+
+func TestChallengeValidate(t *testing.T) {
+	// Total tokens and request appeal expiry are optional
+	challenge := newTestChallenge(validTestChallengeParams())
+	err := challenge.Validate()
+	if err != nil {
+		t.Errorf("Should have been a valid challenge: err: %v", err)
+	}
+
+	params := validTestChallengeParams()
+	params.challengeID = nil
+	err = newTestChallenge(params).Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for nil challenge ID")
+	}
+
+	params = validTestChallengeParams()
+	params.listingAddress = common.Address{}
+	err = newTestChallenge(params).Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for empty listing address")
+	}
+
+	params = validTestChallengeParams()
+	params.challenger = common.Address{}
+	err = newTestChallenge(params).Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for empty challenger address")
+	}
+
+	params = validTestChallengeParams()
+	params.rewardPool = nil
+	err = newTestChallenge(params).Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for nil reward pool")
+	}
+
+	params = validTestChallengeParams()
+	params.stake = nil
+	err = newTestChallenge(params).Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for nil stake")
+	}
+}
@@ -2,12 +2,56 @@
 package model // import "github.com/joincivil/civil-events-processor/pkg/model"
 
 import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 )
 
 // Metadata represents any metadata associated with a governance event
 type Metadata map[string]interface{}
 
+// metadataRequiredKeys maps governance event types to the metadata keys
+// required for that type. Keys are the TCR contract event fields.
+var metadataRequiredKeys = map[string][]string{
+	"Application":                   {"ListingAddress", "Deposit", "AppEndDate", "Applicant"},
+	"ApplicationWhitelisted":        {"ListingAddress"},
+	"ApplicationRemoved":            {"ListingAddress"},
+	"Deposit":                       {"ListingAddress", "Added", "NewTotal", "Owner"},
+	"Withdrawal":                    {"ListingAddress", "Withdrew", "NewTotal", "Owner"},
+	"ListingRemoved":                {"ListingAddress"},
+	"ListingWithdrawn":              {"ListingAddress"},
+	"TouchAndRemoved":               {"ListingAddress"},
+	"Challenge":                     {"ListingAddress", "ChallengeID", "CommitEndDate", "RevealEndDate", "Challenger"},
+	"ChallengeFailed":               {"ListingAddress", "ChallengeID", "RewardPool", "TotalTokens"},
+	"ChallengeSucceeded":            {"ListingAddress", "ChallengeID", "RewardPool", "TotalTokens"},
+	"FailedChallengeOverturned":     {"ListingAddress", "ChallengeID", "RewardPool", "TotalTokens"},
+	"SuccessfulChallengeOverturned": {"ListingAddress", "ChallengeID", "RewardPool", "TotalTokens"},
+	"AppealRequested":               {"ListingAddress", "ChallengeID", "AppealFeePaid", "Requester"},
+	"AppealGranted":                 {"ListingAddress", "ChallengeID"},
+	"GrantedAppealChallenged":       {"ListingAddress", "ChallengeID", "AppealChallengeID"},
+	"GrantedAppealConfirmed":        {"ListingAddress", "ChallengeID", "AppealChallengeID"},
+	"GrantedAppealOverturned":       {"ListingAddress", "ChallengeID", "AppealChallengeID"},
+	"RewardClaimed":                 {"ChallengeID", "Reward", "Voter"},
+}
+
+// ValidateMetadata returns an error if the metadata is missing any of the keys
+// required for the event type. Event types without a schema are not validated.
+func ValidateMetadata(eventType string, md Metadata) error {
+	requiredKeys, ok := metadataRequiredKeys[eventType]
+	if !ok {
+		return nil
+	}
+	for _, key := range requiredKeys {
+		if val, ok := md[key]; !ok || val == nil {
+			return errors.Errorf("%v metadata missing required key %v", eventType, key)
+		}
+	}
+	return nil
+}
+
 // BlockData is block data from the block. NOTE: filled in by node, not secured by consensus
 // TODO(IS): Instead of intializing this in NewGovernanceEvent, create constructor for this.
 type BlockData struct {
@@ -82,6 +126,8 @@ type GovernanceEvent struct {
 	eventHash string
 
 	blockData BlockData
+
+	contractAddress common.Address
 }
 
 // ListingAddress returns the listing address associated with this event
@@ -120,8 +166,49 @@ func (g *GovernanceEvent) EventHash() string {
 	return g.eventHash
 }
 
+// ContractAddress returns the address of the contract that emitted the event
+func (g *GovernanceEvent) ContractAddress() common.Address {
+	return g.contractAddress
+}
+
+// SetContractAddress sets the address of the contract that emitted the event
+func (g *GovernanceEvent) SetContractAddress(address common.Address) {
+	g.contractAddress = address
+}
+
 // BlockData has all the block data from the block associated with this event.
 // NOTE: This is not secured by consensus.
 func (g *GovernanceEvent) BlockData() BlockData {
 	return g.blockData
 }
+
+// Cursor returns the pagination cursor for this event. Set it as the
+// GovernanceEventCriteria AfterCursor to retrieve the events after this one.
+func (g *GovernanceEvent) Cursor() string {
+	return EncodeGovernanceEventCursor(g.creationDateTs, g.eventHash)
+}
+
+// EncodeGovernanceEventCursor returns an opaque cursor for the given event
+// creation date and event hash
+func EncodeGovernanceEventCursor(creationDateTs int64, eventHash string) string {
+	cursor := strconv.FormatInt(creationDateTs, 10) + ":" + eventHash
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+// DecodeGovernanceEventCursor returns the event creation date and event hash
+// in the given cursor
+func DecodeGovernanceEventCursor(cursor string) (int64, string, error) {
+	bys, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", errors.Wrap(err, "invalid governance event cursor")
+	}
+	parts := strings.SplitN(string(bys), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, "", errors.Errorf("invalid governance event cursor: %v", cursor)
+	}
+	creationDateTs, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", errors.Wrap(err, "invalid governance event cursor creation date")
+	}
+	return creationDateTs, parts[1], nil
+}
@@ -0,0 +1,136 @@
+package model_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+var (
+	testMetadataListingAddress = common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	testMetadataUserAddress    = common.HexToAddress("0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A")
+)
+
+func validTestMetadata() map[string]model.Metadata {
+	return map[string]model.Metadata{
+		"Application": {
+			"ListingAddress": testMetadataListingAddress,
+			"Deposit":        big.NewInt(1000),
+			"AppEndDate":     big.NewInt(1527266803),
+			"Data":           "",
+			"Applicant":      testMetadataUserAddress,
+		},
+		"Challenge": {
+			"ListingAddress": testMetadataListingAddress,
+			"ChallengeID":    big.NewInt(10),
+			"Data":           "ipfs://QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH",
+			"CommitEndDate":  big.NewInt(1527266803),
+			"RevealEndDate":  big.NewInt(1527268603),
+			"Challenger":     testMetadataUserAddress,
+		},
+		"ChallengeFailed": {
+			"ListingAddress": testMetadataListingAddress,
+			"ChallengeID":    big.NewInt(10),
+			"RewardPool":     big.NewInt(500),
+			"TotalTokens":    big.NewInt(2000),
+		},
+		"AppealRequested": {
+			"ListingAddress": testMetadataListingAddress,
+			"ChallengeID":    big.NewInt(10),
+			"AppealFeePaid":  big.NewInt(100),
+			"Requester":      testMetadataUserAddress,
+			"Data":           "",
+		},
+		"GrantedAppealChallenged": {
+			"ListingAddress":    testMetadataListingAddress,
+			"ChallengeID":       big.NewInt(10),
+			"AppealChallengeID": big.NewInt(11),
+			"Data":              "",
+		},
+		"RewardClaimed": {
+			"ChallengeID": big.NewInt(10),
+			"Reward":      big.NewInt(50),
+			"Voter":       testMetadataUserAddress,
+		},
+		"ApplicationWhitelisted": {
+			"ListingAddress": testMetadataListingAddress,
+		},
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	for eventType, md := range validTestMetadata() {
+		err := model.ValidateMetadata(eventType, md)
+		if err != nil {
+			t.Errorf("Should not have gotten an error for valid %v metadata: err: %v", eventType, err)
+		}
+	}
+}
+
+func TestValidateMetadataMissingKey(t *testing.T) {
+	for eventType, md := range validTestMetadata() {
+		for key := range md {
+			// Data is optional for all types
+			if key == "Data" {
+				continue
+			}
+			invalid := model.Metadata{}
+			for k, v := range md {
+				if k != key {
+					invalid[k] = v
+				}
+			}
+			err := model.ValidateMetadata(eventType, invalid)
+			if err == nil {
+				t.Errorf("Should have gotten an error for %v metadata missing %v", eventType, key)
+			}
+		}
+	}
+}
+
+func TestValidateMetadataNilValue(t *testing.T) {
+	md := validTestMetadata()["Challenge"]
+	md["ChallengeID"] = nil
+	err := model.ValidateMetadata("Challenge", md)
+	if err == nil {
+		t.Errorf("Should have gotten an error for Challenge metadata with nil ChallengeID")
+	}
+}
+
+func TestValidateMetadataUnknownType(t *testing.T) {
+	err := model.ValidateMetadata("governanceeventtypehere", model.Metadata{})
+	if err != nil {
+		t.Errorf("Should not have gotten an error for event type with no schema: err: %v", err)
+	}
+}
+
+func TestGovernanceEventCursor(t *testing.T) {
+	event := model.NewGovernanceEvent(testMetadataListingAddress, model.Metadata{}, "Application",
+		1527266803, 1527266803, "0xeventhash", 1, common.Hash{}, 0, common.Hash{}, 0)
+	creationDateTs, eventHash, err := model.DecodeGovernanceEventCursor(event.Cursor())
+	if err != nil {
+		t.Fatalf("Should not have gotten an error decoding cursor: err: %v", err)
+	}
+	if creationDateTs != 1527266803 {
+		t.Errorf("Should have decoded the creation date: %v", creationDateTs)
+	}
+	if eventHash != "0xeventhash" {
+		t.Errorf("Should have decoded the event hash: %v", eventHash)
+	}
+
+	invalidCursors := []string{
+		"not base64!",
+		model.EncodeGovernanceEventCursor(1527266803, ""),
+		"MTUyNzI2NjgwMw",   // no event hash
+		"bm90YW51bWJlcjox", // creation date not a number
+	}
+	for _, cursor := range invalidCursors {
+		_, _, err = model.DecodeGovernanceEventCursor(cursor)
+		if err == nil {
+			t.Errorf("Should have gotten an error decoding invalid cursor %v", cursor)
+		}
+	}
+}
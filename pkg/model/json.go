@@ -0,0 +1,108 @@
+// Package model contains the general data models and interfaces for the Civil processor.
+package model // import "github.com/joincivil/civil-events-processor/pkg/model"
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// bigIntToJSON converts a *big.Int to a decimal string to avoid any loss of
+// precision in JSON number parsers. Returns nil if the value is nil.
+func bigIntToJSON(val *big.Int) *string {
+	if val == nil {
+		return nil
+	}
+	str := val.String()
+	return &str
+}
+
+// bigIntFromJSON converts a decimal string to a *big.Int. Returns nil if
+// the string is nil.
+func bigIntFromJSON(val *string) (*big.Int, error) {
+	if val == nil {
+		return nil, nil
+	}
+	bigVal, ok := new(big.Int).SetString(*val, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid decimal value: %v", *val)
+	}
+	return bigVal, nil
+}
+
+// addressToJSON converts an address to a checksummed hex string
+func addressToJSON(addr common.Address) string {
+	return addr.Hex()
+}
+
+// addressFromJSON converts a hex string to an address. An empty string
+// returns an empty address.
+func addressFromJSON(val string) (common.Address, error) {
+	if val == "" {
+		return common.Address{}, nil
+	}
+	if !common.IsHexAddress(val) {
+		return common.Address{}, errors.Errorf("invalid address: %v", val)
+	}
+	return common.HexToAddress(val), nil
+}
+
+// addressesToJSON converts a slice of addresses to checksummed hex strings
+func addressesToJSON(addrs []common.Address) []string {
+	if addrs == nil {
+		return nil
+	}
+	strs := make([]string, len(addrs))
+	for index, addr := range addrs {
+		strs[index] = addressToJSON(addr)
+	}
+	return strs
+}
+
+// addressesFromJSON converts a slice of hex strings to addresses
+func addressesFromJSON(vals []string) ([]common.Address, error) {
+	if vals == nil {
+		return nil, nil
+	}
+	addrs := make([]common.Address, len(vals))
+	for index, val := range vals {
+		addr, err := addressFromJSON(val)
+		if err != nil {
+			return nil, err
+		}
+		addrs[index] = addr
+	}
+	return addrs, nil
+}
+
+// jsonFieldDecoder converts JSON field values back to model values, keeping
+// the first error encountered so a struct can be decoded without checking
+// each field.
+type jsonFieldDecoder struct {
+	err error
+}
+
+func (d *jsonFieldDecoder) bigInt(field string, val *string) *big.Int {
+	bigVal, err := bigIntFromJSON(val)
+	if err != nil && d.err == nil {
+		d.err = errors.WithMessage(err, field)
+	}
+	return bigVal
+}
+
+func (d *jsonFieldDecoder) address(field string, val string) common.Address {
+	addr, err := addressFromJSON(val)
+	if err != nil && d.err == nil {
+		d.err = errors.WithMessage(err, field)
+	}
+	return addr
+}
+
+func (d *jsonFieldDecoder) addresses(field string, vals []string) []common.Address {
+	addrs, err := addressesFromJSON(vals)
+	if err != nil && d.err == nil {
+		d.err = errors.WithMessage(err, field)
+	}
+	return addrs
+}
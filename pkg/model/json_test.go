@@ -0,0 +1,251 @@
+package model_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+const (
+	// Larger than an int64 and a float64 can represent exactly
+	testLargeIntStr = "123456789012345678901234567890"
+)
+
+func testLargeInt() *big.Int {
+	val, _ := new(big.Int).SetString(testLargeIntStr, 10)
+	return val
+}
+
+func checkBigIntEqual(t *testing.T, field string, expected *big.Int, actual *big.Int) {
+	if expected == nil || actual == nil {
+		if expected != actual {
+			t.Errorf("%v not equal: expected %v, got %v", field, expected, actual)
+		}
+		return
+	}
+	if expected.Cmp(actual) != 0 {
+		t.Errorf("%v not equal: expected %v, got %v", field, expected, actual)
+	}
+}
+
+func TestListingJSONRoundTrip(t *testing.T) {
+	listing, _ := setupSampleListing()
+	listing.SetUnstakedDeposit(testLargeInt())
+	listing.SetAppExpiry(nil)
+
+	data, err := json.Marshal(listing)
+	if err != nil {
+		t.Fatalf("Should not have failed to marshal listing: err: %v", err)
+	}
+	if !strings.Contains(string(data), `"unstakedDeposit":"`+testLargeIntStr+`"`) {
+		t.Errorf("Should have encoded unstaked deposit as a decimal string: %v", string(data))
+	}
+	if !strings.Contains(string(data), listing.ContractAddress().Hex()) {
+		t.Errorf("Should have encoded contract address as checksummed hex: %v", string(data))
+	}
+
+	decoded := &model.Listing{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("Should not have failed to unmarshal listing: err: %v", err)
+	}
+	if decoded.Name() != listing.Name() {
+		t.Errorf("Name not equal")
+	}
+	if decoded.ContractAddress() != listing.ContractAddress() {
+		t.Errorf("Contract address not equal")
+	}
+	if decoded.Whitelisted() != listing.Whitelisted() {
+		t.Errorf("Whitelisted not equal")
+	}
+	if decoded.LastGovernanceState() != listing.LastGovernanceState() {
+		t.Errorf("Last governance state not equal")
+	}
+	if decoded.URL() != listing.URL() || decoded.CleanedURL() != listing.CleanedURL() {
+		t.Errorf("URLs not equal")
+	}
+	if decoded.Owner() != listing.Owner() {
+		t.Errorf("Owner not equal")
+	}
+	if len(decoded.OwnerAddresses()) != len(listing.OwnerAddresses()) {
+		t.Fatalf("Owner addresses length not equal")
+	}
+	for index, addr := range listing.OwnerAddresses() {
+		if decoded.OwnerAddresses()[index] != addr {
+			t.Errorf("Owner address %v not equal", index)
+		}
+	}
+	if len(decoded.ContributorAddresses()) != len(listing.ContributorAddresses()) {
+		t.Fatalf("Contributor addresses length not equal")
+	}
+	if decoded.CreatedDateTs() != listing.CreatedDateTs() ||
+		decoded.ApplicationDateTs() != listing.ApplicationDateTs() ||
+		decoded.ApprovalDateTs() != listing.ApprovalDateTs() ||
+		decoded.LastUpdatedDateTs() != listing.LastUpdatedDateTs() {
+		t.Errorf("Timestamps not equal")
+	}
+	checkBigIntEqual(t, "appExpiry", listing.AppExpiry(), decoded.AppExpiry())
+	checkBigIntEqual(t, "unstakedDeposit", listing.UnstakedDeposit(), decoded.UnstakedDeposit())
+	checkBigIntEqual(t, "challengeID", listing.ChallengeID(), decoded.ChallengeID())
+
+	charter := listing.Charter()
+	decodedCharter := decoded.Charter()
+	if decodedCharter == nil {
+		t.Fatalf("Should have decoded the charter")
+	}
+	if decodedCharter.URI() != charter.URI() {
+		t.Errorf("Charter URI not equal")
+	}
+	checkBigIntEqual(t, "contentID", charter.ContentID(), decodedCharter.ContentID())
+	checkBigIntEqual(t, "revisionID", charter.RevisionID(), decodedCharter.RevisionID())
+	checkBigIntEqual(t, "timestamp", charter.Timestamp(), decodedCharter.Timestamp())
+	if !bytes.Equal(decodedCharter.Signature(), charter.Signature()) {
+		t.Errorf("Charter signature not equal")
+	}
+	if decodedCharter.Author() != charter.Author() {
+		t.Errorf("Charter author not equal")
+	}
+	if decodedCharter.ContentHash() != charter.ContentHash() {
+		t.Errorf("Charter content hash not equal")
+	}
+}
+
+func TestListingJSONInvalidValues(t *testing.T) {
+	decoded := &model.Listing{}
+	err := json.Unmarshal([]byte(`{"contractAddress":"notanaddress"}`), decoded)
+	if err == nil {
+		t.Errorf("Should have failed to unmarshal an invalid address")
+	}
+	err = json.Unmarshal([]byte(`{"challengeID":"1.5"}`), decoded)
+	if err == nil {
+		t.Errorf("Should have failed to unmarshal an invalid big int")
+	}
+}
+
+func TestChallengeJSONRoundTrip(t *testing.T) {
+	params := validTestChallengeParams()
+	params.rewardPool = testLargeInt()
+	challenge := model.NewChallenge(params.challengeID, params.listingAddress, "statement",
+		params.rewardPool, params.challenger, true, params.stake, testLargeInt(), nil,
+		model.ChallengePollType, 1000)
+
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		t.Fatalf("Should not have failed to marshal challenge: err: %v", err)
+	}
+	if !strings.Contains(string(data), `"challenger":"`+params.challenger.Hex()+`"`) {
+		t.Errorf("Should have encoded challenger as checksummed hex: %v", string(data))
+	}
+
+	decoded := &model.Challenge{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("Should not have failed to unmarshal challenge: err: %v", err)
+	}
+	checkBigIntEqual(t, "challengeID", challenge.ChallengeID(), decoded.ChallengeID())
+	checkBigIntEqual(t, "rewardPool", challenge.RewardPool(), decoded.RewardPool())
+	checkBigIntEqual(t, "stake", challenge.Stake(), decoded.Stake())
+	checkBigIntEqual(t, "totalTokens", challenge.TotalTokens(), decoded.TotalTokens())
+	checkBigIntEqual(t, "requestAppealExpiry", challenge.RequestAppealExpiry(),
+		decoded.RequestAppealExpiry())
+	if decoded.ListingAddress() != challenge.ListingAddress() {
+		t.Errorf("Listing address not equal")
+	}
+	if decoded.Challenger() != challenge.Challenger() {
+		t.Errorf("Challenger not equal")
+	}
+	if decoded.Statement() != challenge.Statement() {
+		t.Errorf("Statement not equal")
+	}
+	if decoded.Resolved() != challenge.Resolved() {
+		t.Errorf("Resolved not equal")
+	}
+	if decoded.ChallengeType() != challenge.ChallengeType() {
+		t.Errorf("Challenge type not equal")
+	}
+	if decoded.LastUpdatedDateTs() != challenge.LastUpdatedDateTs() {
+		t.Errorf("Last updated ts not equal")
+	}
+}
+
+func TestAppealJSONRoundTrip(t *testing.T) {
+	appeal := model.NewAppeal(big.NewInt(10),
+		common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"), testLargeInt(),
+		big.NewInt(1600000000), true, "statement", 1000, "/appeal/uri")
+	appeal.SetAppealChallengeID(big.NewInt(12))
+	appeal.SetAppealOpenToChallengeExpiry(big.NewInt(1600000100))
+
+	data, err := json.Marshal(appeal)
+	if err != nil {
+		t.Fatalf("Should not have failed to marshal appeal: err: %v", err)
+	}
+
+	decoded := &model.Appeal{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("Should not have failed to unmarshal appeal: err: %v", err)
+	}
+	checkBigIntEqual(t, "originalChallengeID", appeal.OriginalChallengeID(),
+		decoded.OriginalChallengeID())
+	checkBigIntEqual(t, "appealFeePaid", appeal.AppealFeePaid(), decoded.AppealFeePaid())
+	checkBigIntEqual(t, "appealPhaseExpiry", appeal.AppealPhaseExpiry(),
+		decoded.AppealPhaseExpiry())
+	checkBigIntEqual(t, "appealOpenToChallengeExpiry", appeal.AppealOpenToChallengeExpiry(),
+		decoded.AppealOpenToChallengeExpiry())
+	checkBigIntEqual(t, "appealChallengeID", appeal.AppealChallengeID(),
+		decoded.AppealChallengeID())
+	if decoded.Requester() != appeal.Requester() {
+		t.Errorf("Requester not equal")
+	}
+	if decoded.AppealGranted() != appeal.AppealGranted() {
+		t.Errorf("Appeal granted not equal")
+	}
+	if decoded.Statement() != appeal.Statement() {
+		t.Errorf("Statement not equal")
+	}
+	if decoded.AppealGrantedStatementURI() != appeal.AppealGrantedStatementURI() {
+		t.Errorf("Appeal granted statement URI not equal")
+	}
+	if decoded.LastUpdatedDateTs() != appeal.LastUpdatedDateTs() {
+		t.Errorf("Last updated ts not equal")
+	}
+}
+
+func TestPollJSONRoundTrip(t *testing.T) {
+	poll := model.NewPoll(big.NewInt(10), big.NewInt(1600000000), big.NewInt(1600000100),
+		big.NewInt(50), testLargeInt(), big.NewInt(0), 1000)
+	poll.SetPollType(model.ChallengePollType)
+	poll.SetIsPassed(true)
+
+	data, err := json.Marshal(poll)
+	if err != nil {
+		t.Fatalf("Should not have failed to marshal poll: err: %v", err)
+	}
+
+	decoded := &model.Poll{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("Should not have failed to unmarshal poll: err: %v", err)
+	}
+	checkBigIntEqual(t, "pollID", poll.PollID(), decoded.PollID())
+	checkBigIntEqual(t, "commitEndDate", poll.CommitEndDate(), decoded.CommitEndDate())
+	checkBigIntEqual(t, "revealEndDate", poll.RevealEndDate(), decoded.RevealEndDate())
+	checkBigIntEqual(t, "voteQuorum", poll.VoteQuorum(), decoded.VoteQuorum())
+	checkBigIntEqual(t, "votesFor", poll.VotesFor(), decoded.VotesFor())
+	checkBigIntEqual(t, "votesAgainst", poll.VotesAgainst(), decoded.VotesAgainst())
+	if decoded.PollType() != poll.PollType() {
+		t.Errorf("Poll type not equal")
+	}
+	if decoded.IsPassed() != poll.IsPassed() {
+		t.Errorf("Is passed not equal")
+	}
+	if decoded.LastUpdatedDateTs() != poll.LastUpdatedDateTs() {
+		t.Errorf("Last updated ts not equal")
+	}
+}
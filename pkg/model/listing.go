@@ -2,9 +2,14 @@
 package model // import "github.com/joincivil/civil-events-processor/pkg/model"
 
 import (
+	"encoding/json"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 
 	cbytes "github.com/joincivil/go-common/pkg/bytes"
 )
@@ -131,6 +136,31 @@ func (c *Charter) Timestamp() *big.Int {
 	return c.timestamp
 }
 
+// VerifySignature returns true if the charter signature was signed by the
+// charter author for the given newsroom. As with the Newsroom contract, the
+// author signs keccak256(newsroomAddress, contentHash) as an Ethereum signed
+// message, as with eth_sign. Returns an error if the signature is malformed.
+func (c *Charter) VerifySignature(newsroomAddress common.Address) (bool, error) {
+	if len(c.signature) != crypto.SignatureLength {
+		return false, errors.Errorf("invalid signature length: %v", len(c.signature))
+	}
+	// Copy to avoid modifying the charter signature when normalizing the
+	// recovery ID. Signatures from web3 use 27 or 28 rather than 0 or 1.
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, c.signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(
+		accounts.TextHash(crypto.Keccak256(newsroomAddress.Bytes(), c.contentHash[:])),
+		sig,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "error recovering charter signer")
+	}
+	return crypto.PubkeyToAddress(*pubKey) == c.author, nil
+}
+
 // AsMap returns the charter data as a map[string]interface{}
 func (c *Charter) AsMap() map[string]interface{} {
 	newMap := map[string]interface{}{}
@@ -204,6 +234,59 @@ func (c *Charter) FromMap(charterMap map[string]interface{}) error {
 	return nil
 }
 
+type charterJSON struct {
+	URI         string        `json:"uri"`
+	ContentID   *string       `json:"contentID"`
+	RevisionID  *string       `json:"revisionID"`
+	Signature   hexutil.Bytes `json:"signature"`
+	Author      string        `json:"author"`
+	ContentHash string        `json:"contentHash"`
+	Timestamp   *string       `json:"timestamp"`
+}
+
+// MarshalJSON converts this struct into a []byte. Big ints are encoded as
+// decimal strings and addresses as checksummed hex.
+func (c *Charter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&charterJSON{
+		URI:         c.uri,
+		ContentID:   bigIntToJSON(c.contentID),
+		RevisionID:  bigIntToJSON(c.revisionID),
+		Signature:   c.signature,
+		Author:      addressToJSON(c.author),
+		ContentHash: cbytes.Byte32ToHexString(c.contentHash),
+		Timestamp:   bigIntToJSON(c.timestamp),
+	})
+}
+
+// UnmarshalJSON populates the struct with given []byte.
+func (c *Charter) UnmarshalJSON(data []byte) error {
+	cj := &charterJSON{}
+	err := json.Unmarshal(data, cj)
+	if err != nil {
+		return err
+	}
+	d := &jsonFieldDecoder{}
+	charter := &Charter{
+		uri:        cj.URI,
+		contentID:  d.bigInt("contentID", cj.ContentID),
+		revisionID: d.bigInt("revisionID", cj.RevisionID),
+		signature:  cj.Signature,
+		author:     d.address("author", cj.Author),
+		timestamp:  d.bigInt("timestamp", cj.Timestamp),
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if cj.ContentHash != "" {
+		charter.contentHash, err = cbytes.HexStringToByte32(cj.ContentHash)
+		if err != nil {
+			return errors.WithMessage(err, "contentHash")
+		}
+	}
+	*c = *charter
+	return nil
+}
+
 // NewListingParams represents all the necessary data to create a new listing
 // using NewListing
 type NewListingParams struct {
@@ -224,6 +307,7 @@ type NewListingParams struct {
 	UnstakedDeposit      *big.Int
 	ChallengeID          *big.Int
 	CleanedURL           string
+	Removed              bool
 }
 
 // NewListing is a convenience function to initialize a new Listing struct
@@ -246,6 +330,7 @@ func NewListing(params *NewListingParams) *Listing {
 		unstakedDeposit:      params.UnstakedDeposit,
 		challengeID:          params.ChallengeID,
 		cleanedURL:           params.CleanedURL,
+		removed:              params.Removed,
 	}
 }
 
@@ -284,6 +369,12 @@ type Listing struct {
 	challengeID *big.Int
 
 	cleanedURL string
+
+	removed bool
+
+	// challengeCount is not persisted, only retrieved with
+	// ListingCriteria.WithChallengeCount
+	challengeCount int
 }
 
 // Name returns the newsroom name
@@ -489,3 +580,119 @@ func (l *Listing) SetChallengeID(id *big.Int) {
 func (l *Listing) ChallengeID() *big.Int {
 	return l.challengeID
 }
+
+// Removed returns true if the listing was removed from the registry. Removed
+// listings are kept, but excluded from ListingsByCriteria by default.
+func (l *Listing) Removed() bool {
+	return l.removed
+}
+
+// SetRemoved sets whether the listing was removed from the registry
+func (l *Listing) SetRemoved(removed bool) {
+	l.removed = removed
+}
+
+// ChallengeCount returns the number of challenges of the listing. Only set if
+// the listing was retrieved with ListingCriteria.WithChallengeCount.
+func (l *Listing) ChallengeCount() int {
+	return l.challengeCount
+}
+
+// SetChallengeCount sets the number of challenges of the listing
+func (l *Listing) SetChallengeCount(count int) {
+	l.challengeCount = count
+}
+
+// Validate returns an error if any of the required fields of the listing are
+// missing. App expiry, unstaked deposit and challenge ID are optional since
+// listings created from newsroom events do not have TCR data.
+func (l *Listing) Validate() error {
+	if l.contractAddress == (common.Address{}) {
+		return errors.New("listing contract address is empty")
+	}
+	if l.owner == (common.Address{}) {
+		return errors.Errorf("listing %v owner address is empty", l.contractAddress.Hex())
+	}
+	return nil
+}
+
+type listingJSON struct {
+	Name                 string          `json:"name"`
+	ContractAddress      string          `json:"contractAddress"`
+	Whitelisted          bool            `json:"whitelisted"`
+	LastGovernanceState  GovernanceState `json:"lastGovernanceState"`
+	URL                  string          `json:"url"`
+	Charter              *Charter        `json:"charter"`
+	Owner                string          `json:"owner"`
+	OwnerAddresses       []string        `json:"ownerAddresses"`
+	ContributorAddresses []string        `json:"contributorAddresses"`
+	CreatedDateTs        int64           `json:"createdDateTs"`
+	ApplicationDateTs    int64           `json:"applicationDateTs"`
+	ApprovalDateTs       int64           `json:"approvalDateTs"`
+	LastUpdatedDateTs    int64           `json:"lastUpdatedDateTs"`
+	AppExpiry            *string         `json:"appExpiry"`
+	UnstakedDeposit      *string         `json:"unstakedDeposit"`
+	ChallengeID          *string         `json:"challengeID"`
+	CleanedURL           string          `json:"cleanedURL"`
+	Removed              bool            `json:"removed"`
+}
+
+// MarshalJSON converts this struct into a []byte. Big ints are encoded as
+// decimal strings and addresses as checksummed hex.
+func (l *Listing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&listingJSON{
+		Name:                 l.name,
+		ContractAddress:      addressToJSON(l.contractAddress),
+		Whitelisted:          l.whitelisted,
+		LastGovernanceState:  l.lastGovernanceState,
+		URL:                  l.url,
+		Charter:              l.charter,
+		Owner:                addressToJSON(l.owner),
+		OwnerAddresses:       addressesToJSON(l.ownerAddresses),
+		ContributorAddresses: addressesToJSON(l.contributorAddresses),
+		CreatedDateTs:        l.createdDateTs,
+		ApplicationDateTs:    l.applicationDateTs,
+		ApprovalDateTs:       l.approvalDateTs,
+		LastUpdatedDateTs:    l.lastUpdatedDateTs,
+		AppExpiry:            bigIntToJSON(l.appExpiry),
+		UnstakedDeposit:      bigIntToJSON(l.unstakedDeposit),
+		ChallengeID:          bigIntToJSON(l.challengeID),
+		CleanedURL:           l.cleanedURL,
+		Removed:              l.removed,
+	})
+}
+
+// UnmarshalJSON populates the struct with given []byte.
+func (l *Listing) UnmarshalJSON(data []byte) error {
+	lj := &listingJSON{}
+	err := json.Unmarshal(data, lj)
+	if err != nil {
+		return err
+	}
+	d := &jsonFieldDecoder{}
+	listing := &Listing{
+		name:                 lj.Name,
+		contractAddress:      d.address("contractAddress", lj.ContractAddress),
+		whitelisted:          lj.Whitelisted,
+		lastGovernanceState:  lj.LastGovernanceState,
+		url:                  lj.URL,
+		charter:              lj.Charter,
+		owner:                d.address("owner", lj.Owner),
+		ownerAddresses:       d.addresses("ownerAddresses", lj.OwnerAddresses),
+		contributorAddresses: d.addresses("contributorAddresses", lj.ContributorAddresses),
+		createdDateTs:        lj.CreatedDateTs,
+		applicationDateTs:    lj.ApplicationDateTs,
+		approvalDateTs:       lj.ApprovalDateTs,
+		lastUpdatedDateTs:    lj.LastUpdatedDateTs,
+		appExpiry:            d.bigInt("appExpiry", lj.AppExpiry),
+		unstakedDeposit:      d.bigInt("unstakedDeposit", lj.UnstakedDeposit),
+		challengeID:          d.bigInt("challengeID", lj.ChallengeID),
+		cleanedURL:           lj.CleanedURL,
+		removed:              lj.Removed,
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*l = *listing
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package model contains the general data models and interfaces for the Civil processor.
+package model // import "github.com/joincivil/civil-events-processor/pkg/model"
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ListingStateChange represents a change to the whitelisted or last governance
+// state of a listing made while processing an event
+type ListingStateChange struct {
+	listingAddress common.Address
+	oldWhitelisted bool
+	newWhitelisted bool
+	oldState       GovernanceState
+	newState       GovernanceState
+	eventHash      string
+	timestamp      int64
+}
+
+// NewListingStateChangeParams are the params to create a new ListingStateChange
+type NewListingStateChangeParams struct {
+	ListingAddress common.Address
+	OldWhitelisted bool
+	NewWhitelisted bool
+	OldState       GovernanceState
+	NewState       GovernanceState
+	EventHash      string
+	Timestamp      int64
+}
+
+// NewListingStateChange creates a new listing state change object
+func NewListingStateChange(params *NewListingStateChangeParams) *ListingStateChange {
+	return &ListingStateChange{
+		listingAddress: params.ListingAddress,
+		oldWhitelisted: params.OldWhitelisted,
+		newWhitelisted: params.NewWhitelisted,
+		oldState:       params.OldState,
+		newState:       params.NewState,
+		eventHash:      params.EventHash,
+		timestamp:      params.Timestamp,
+	}
+}
+
+// ListingAddress returns the address of the listing
+func (l *ListingStateChange) ListingAddress() common.Address {
+	return l.listingAddress
+}
+
+// OldWhitelisted returns whether the listing was whitelisted before the change
+func (l *ListingStateChange) OldWhitelisted() bool {
+	return l.oldWhitelisted
+}
+
+// NewWhitelisted returns whether the listing is whitelisted after the change
+func (l *ListingStateChange) NewWhitelisted() bool {
+	return l.newWhitelisted
+}
+
+// OldState returns the last governance state before the change
+func (l *ListingStateChange) OldState() GovernanceState {
+	return l.oldState
+}
+
+// NewState returns the last governance state after the change
+func (l *ListingStateChange) NewState() GovernanceState {
+	return l.newState
+}
+
+// EventHash returns the hash of the event that triggered the change
+func (l *ListingStateChange) EventHash() string {
+	return l.eventHash
+}
+
+// Timestamp returns the timestamp of the triggering event
+func (l *ListingStateChange) Timestamp() int64 {
+	return l.timestamp
+}
@@ -2,13 +2,22 @@ package model_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/ecdsa"
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/joincivil/civil-events-crawler/pkg/contractutils"
 
 	"github.com/joincivil/civil-events-processor/pkg/model"
 
+	"github.com/joincivil/go-common/pkg/generated/contract"
 	cstrings "github.com/joincivil/go-common/pkg/strings"
 )
 
@@ -114,6 +123,217 @@ func TestCharterAsMapFromMap(t *testing.T) {
 	}
 }
 
+const (
+	testCharterAuthorKey       = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+	testCharterNewsroomAddress = "0x39eeb9d8c8f6fd2a7a4e40e8e3e4f2bbf39f4ea7"
+)
+
+// signCharterContent signs the content hash as the Newsroom contract expects,
+// keccak256(newsroomAddress, contentHash) as an Ethereum signed message
+func signCharterContent(t *testing.T, newsroomAddress common.Address,
+	contentHash common.Hash, key *ecdsa.PrivateKey) []byte {
+	signature, err := crypto.Sign(
+		accounts.TextHash(crypto.Keccak256(newsroomAddress.Bytes(), contentHash.Bytes())),
+		key,
+	)
+	if err != nil {
+		t.Fatalf("Should have not returned error signing hash: err: %v", err)
+	}
+	return signature
+}
+
+func setupSignedCharter(t *testing.T) (*model.Charter, []byte) {
+	key, err := crypto.HexToECDSA(testCharterAuthorKey)
+	if err != nil {
+		t.Fatalf("Should have not returned error loading key: err: %v", err)
+	}
+	contentHash := crypto.Keccak256Hash([]byte("charter content"))
+	signature := signCharterContent(t, common.HexToAddress(testCharterNewsroomAddress),
+		contentHash, key)
+	charter := model.NewCharter(&model.CharterParams{
+		URI:         "/charter/uri",
+		ContentID:   big.NewInt(0),
+		RevisionID:  big.NewInt(1),
+		Signature:   signature,
+		Author:      crypto.PubkeyToAddress(key.PublicKey),
+		ContentHash: contentHash,
+		Timestamp:   big.NewInt(12345678),
+	})
+	return charter, signature
+}
+
+func TestCharterVerifySignature(t *testing.T) {
+	charter, signature := setupSignedCharter(t)
+	newsroomAddress := common.HexToAddress(testCharterNewsroomAddress)
+	valid, err := charter.VerifySignature(newsroomAddress)
+	if err != nil {
+		t.Errorf("Should have not returned error verifying signature: err: %v", err)
+	}
+	if !valid {
+		t.Errorf("Should have verified the author signature")
+	}
+
+	// web3 style recovery IDs of 27 or 28
+	web3Sig := make([]byte, len(signature))
+	copy(web3Sig, signature)
+	web3Sig[crypto.RecoveryIDOffset] += 27
+	charter = model.NewCharter(&model.CharterParams{
+		Signature:   web3Sig,
+		Author:      charter.Author(),
+		ContentHash: charter.ContentHash(),
+	})
+	valid, err = charter.VerifySignature(newsroomAddress)
+	if err != nil {
+		t.Errorf("Should have not returned error verifying signature: err: %v", err)
+	}
+	if !valid {
+		t.Errorf("Should have verified the author signature with a web3 recovery ID")
+	}
+	if !bytes.Equal(charter.Signature(), web3Sig) {
+		t.Errorf("Should have not modified the charter signature")
+	}
+}
+
+func TestCharterVerifySignatureTampered(t *testing.T) {
+	charter, signature := setupSignedCharter(t)
+	newsroomAddress := common.HexToAddress(testCharterNewsroomAddress)
+
+	// Tampered content hash
+	tamperedHash := charter.ContentHash()
+	tamperedHash[0] ^= 0xff
+	tampered := model.NewCharter(&model.CharterParams{
+		Signature:   signature,
+		Author:      charter.Author(),
+		ContentHash: tamperedHash,
+	})
+	valid, err := tampered.VerifySignature(newsroomAddress)
+	if err == nil && valid {
+		t.Errorf("Should have not verified signature with a tampered hash")
+	}
+
+	// Different author
+	tampered = model.NewCharter(&model.CharterParams{
+		Signature:   signature,
+		Author:      common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"),
+		ContentHash: charter.ContentHash(),
+	})
+	valid, err = tampered.VerifySignature(newsroomAddress)
+	if err != nil {
+		t.Errorf("Should have not returned error verifying signature: err: %v", err)
+	}
+	if valid {
+		t.Errorf("Should have not verified signature for a different author")
+	}
+
+	// Different newsroom
+	valid, err = charter.VerifySignature(
+		common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"),
+	)
+	if err == nil && valid {
+		t.Errorf("Should have not verified signature for a different newsroom")
+	}
+
+	// Malformed signature
+	tampered = model.NewCharter(&model.CharterParams{
+		Signature:   signature[:40],
+		Author:      charter.Author(),
+		ContentHash: charter.ContentHash(),
+	})
+	valid, err = tampered.VerifySignature(newsroomAddress)
+	if err == nil {
+		t.Errorf("Should have returned error for a malformed signature")
+	}
+	if valid {
+		t.Errorf("Should have not verified a malformed signature")
+	}
+}
+
+// Verifies against a charter revision signed by an editor and accepted by a
+// deployed Newsroom contract
+func TestCharterVerifySignatureNewsroomRevision(t *testing.T) {
+	client, auth := contractutils.SetupSimulatedClient(uint64(8000000))
+	newsroomAddress, _, newsroom, err := contract.DeployNewsroomContract(
+		auth,
+		client,
+		"newsroom",
+		"newsroom.com/charter",
+		[32]byte{},
+	)
+	if err != nil {
+		t.Fatalf("Should have not returned error deploying newsroom: err: %v", err)
+	}
+	client.Commit()
+
+	key, err := crypto.HexToECDSA(testCharterAuthorKey)
+	if err != nil {
+		t.Fatalf("Should have not returned error loading key: err: %v", err)
+	}
+	editor := crypto.PubkeyToAddress(key.PublicKey)
+	_, err = newsroom.AddEditor(auth, editor)
+	if err != nil {
+		t.Fatalf("Should have not returned error adding editor: err: %v", err)
+	}
+	client.Commit()
+
+	// Fund the editor to publish
+	nonce, err := client.PendingNonceAt(context.Background(), auth.From)
+	if err != nil {
+		t.Fatalf("Should have not returned error getting nonce: err: %v", err)
+	}
+	fundTx := types.NewTransaction(nonce, editor, big.NewInt(1000000000000000000), 21000,
+		big.NewInt(1), nil)
+	fundTx, err = auth.Signer(types.HomesteadSigner{}, auth.From, fundTx)
+	if err != nil {
+		t.Fatalf("Should have not returned error signing transfer: err: %v", err)
+	}
+	err = client.SendTransaction(context.Background(), fundTx)
+	if err != nil {
+		t.Fatalf("Should have not returned error funding editor: err: %v", err)
+	}
+	client.Commit()
+
+	contentHash := crypto.Keccak256Hash([]byte("charter content"))
+	signature := signCharterContent(t, newsroomAddress, contentHash, key)
+	signature[crypto.RecoveryIDOffset] += 27
+	editorAuth := bind.NewKeyedTransactor(key)
+	editorAuth.GasLimit = 3000000
+	tx, err := newsroom.PublishContent(editorAuth, "newsroom.com/content", contentHash,
+		editor, signature)
+	if err != nil {
+		t.Fatalf("Should have not returned error publishing content: err: %v", err)
+	}
+	client.Commit()
+	receipt, err := client.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("Should have not returned error getting receipt: err: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("Should have had the signed content accepted by the newsroom")
+	}
+
+	// The charter is content 0, the published content is content 1
+	revision, err := newsroom.GetRevision(&bind.CallOpts{}, big.NewInt(1), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Should have not returned error getting revision: err: %v", err)
+	}
+	charter := model.NewCharter(&model.CharterParams{
+		URI:         revision.Uri,
+		ContentID:   big.NewInt(1),
+		RevisionID:  big.NewInt(0),
+		Signature:   revision.Signature,
+		Author:      revision.Author,
+		ContentHash: revision.ContentHash,
+		Timestamp:   revision.Timestamp,
+	})
+	valid, err := charter.VerifySignature(newsroomAddress)
+	if err != nil {
+		t.Errorf("Should have not returned error verifying signature: err: %v", err)
+	}
+	if !valid {
+		t.Errorf("Should have verified the signature accepted by the newsroom")
+	}
+}
+
 func TestListingCharterAsMapFromMap(t *testing.T) {
 	listing, _ := setupSampleListing()
 	charter := listing.Charter()
@@ -148,3 +368,36 @@ func TestListingCharterAsMapFromMap(t *testing.T) {
 		t.Errorf("Should have had same timestamp")
 	}
 }
+
+func TestListingValidate(t *testing.T) {
+	listing, _ := setupSampleListing()
+	err := listing.Validate()
+	if err != nil {
+		t.Errorf("Should have been a valid listing: err: %v", err)
+	}
+
+	// Optional fields may be nil
+	listing.SetAppExpiry(nil)
+	listing.SetUnstakedDeposit(nil)
+	listing.SetChallengeID(nil)
+	err = listing.Validate()
+	if err != nil {
+		t.Errorf("Should have been a valid listing without optional fields: err: %v", err)
+	}
+
+	listing = model.NewListing(&model.NewListingParams{
+		Owner: common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"),
+	})
+	err = listing.Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for empty contract address")
+	}
+
+	listing = model.NewListing(&model.NewListingParams{
+		ContractAddress: common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"),
+	})
+	err = listing.Validate()
+	if err == nil {
+		t.Errorf("Should have returned error for empty owner address")
+	}
+}
@@ -0,0 +1,49 @@
+// Package model contains the general data models and interfaces for the Civil processor.
+package model // import "github.com/joincivil/civil-events-processor/pkg/model"
+
+// ListingIterator iterates over a set of listings one at a time, so large
+// result sets do not need to be held in memory.
+type ListingIterator interface {
+	// Next advances to the next listing. Returns false when there are no more
+	// listings or an error occurred.
+	Next() bool
+	// Listing returns the current listing
+	Listing() *Listing
+	// Err returns the error that stopped the iteration, if any
+	Err() error
+	// Close releases the resources held by the iterator
+	Close() error
+}
+
+// NewListingSliceIterator returns a ListingIterator over a slice of listings
+func NewListingSliceIterator(listings []*Listing) ListingIterator {
+	return &listingSliceIterator{listings: listings, index: -1}
+}
+
+type listingSliceIterator struct {
+	listings []*Listing
+	index    int
+}
+
+func (l *listingSliceIterator) Next() bool {
+	if l.index+1 >= len(l.listings) {
+		return false
+	}
+	l.index++
+	return true
+}
+
+func (l *listingSliceIterator) Listing() *Listing {
+	if l.index < 0 || l.index >= len(l.listings) {
+		return nil
+	}
+	return l.listings[l.index]
+}
+
+func (l *listingSliceIterator) Err() error {
+	return nil
+}
+
+func (l *listingSliceIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package model contains the general data models and interfaces for the Civil processor.
+package model // import "github.com/joincivil/civil-events-processor/pkg/model"
+
+import (
+	"math/big"
+)
+
+// ParameterChange represents a change to a parameter value from an accepted
+// parameter proposal
+type ParameterChange struct {
+	paramName string
+	oldValue  *big.Int
+	newValue  *big.Int
+	propID    [32]byte
+	timestamp int64
+}
+
+// NewParameterChange creates a new parameter change object
+func NewParameterChange(paramName string, oldValue *big.Int, newValue *big.Int,
+	propID [32]byte, timestamp int64) *ParameterChange {
+	return &ParameterChange{
+		paramName: paramName,
+		oldValue:  oldValue,
+		newValue:  newValue,
+		propID:    propID,
+		timestamp: timestamp,
+	}
+}
+
+// ParamName returns the name of the changed parameter
+func (p *ParameterChange) ParamName() string {
+	return p.paramName
+}
+
+// OldValue returns the value of the parameter before the change
+func (p *ParameterChange) OldValue() *big.Int {
+	return p.oldValue
+}
+
+// NewValue returns the value of the parameter after the change
+func (p *ParameterChange) NewValue() *big.Int {
+	return p.newValue
+}
+
+// PropID returns the ID of the proposal that changed the parameter
+func (p *ParameterChange) PropID() [32]byte {
+	return p.propID
+}
+
+// Timestamp returns the timestamp of the change
+func (p *ParameterChange) Timestamp() int64 {
+	return p.timestamp
+}
@@ -25,12 +25,26 @@ const (
 	SortByApplied = "APPLIED"
 	// SortByWhitelisted sorts by the newsroom's whitelisted date
 	SortByWhitelisted = "WHITELISTED"
+	// SortByLastGovernanceActivity sorts by the creation date of the newsroom's
+	// latest governance event
+	SortByLastGovernanceActivity = "LAST_GOVERNANCE_ACTIVITY"
 )
 
+// AllSortByType is all the valid SortByType values
+var AllSortByType = []SortByType{
+	SortByUndefined,
+	SortByName,
+	SortByCreated,
+	SortByApplied,
+	SortByWhitelisted,
+	SortByLastGovernanceActivity,
+}
+
 // IsValid returns if the enum is a valid one
 func (e SortByType) IsValid() bool {
 	switch e {
-	case SortByUndefined, SortByName, SortByCreated, SortByApplied, SortByWhitelisted:
+	case SortByUndefined, SortByName, SortByCreated, SortByApplied, SortByWhitelisted,
+		SortByLastGovernanceActivity:
 		return true
 	}
 	return false
@@ -81,6 +95,16 @@ type ListingCriteria struct {
 	CurrentApplication bool  `db:"current_application"`
 	CreatedFromTs      int64 `db:"created_fromts"`
 	CreatedBeforeTs    int64 `db:"created_beforets"`
+	// Listings that have been updated after the given timestamp
+	UpdatedAfterTs int64 `db:"updated_afterts"`
+	// Listings that have at least one content revision
+	HasContent bool `db:"has_content"`
+	// Listings whose contract address is not in the given list of addresses
+	ExcludeAddresses []string `db:"exclude_addresses"`
+	// Retrieves the number of challenges for each listing with the listings
+	WithChallengeCount bool `db:"with_challenge_count"`
+	// Includes listings marked as removed, which are excluded by default
+	IncludeRemoved bool `db:"include_removed"`
 
 	// SortBy is the sort type to use when returning results
 	SortBy SortByType `db:"sort_by"`
@@ -88,16 +112,50 @@ type ListingCriteria struct {
 	SortDesc bool `db:"sort_desc"`
 }
 
+// ListingStatsResult contains the number of listings that reached each state
+// within a time window
+type ListingStatsResult struct {
+	// Applications is the number of listings that applied in the window
+	Applications int
+	// Whitelisted is the number of listings approved in the window
+	Whitelisted int
+	// Rejected is the number of rejected listings last updated in the window
+	Rejected int
+	// Withdrawn is the number of withdrawn listings last updated in the window
+	Withdrawn int
+}
+
+// ListingGovernance contains a listing and the governance data for its active
+// challenge. Challenge, Poll and Appeal are nil if they do not exist.
+type ListingGovernance struct {
+	Listing   *Listing
+	Challenge *Challenge
+	Poll      *Poll
+	Appeal    *Appeal
+}
+
+// AppealWithChallenge contains an appeal and the challenge it appeals
+type AppealWithChallenge struct {
+	Appeal    *Appeal
+	Challenge *Challenge
+}
+
 // ListingPersister is the interface to store the listings data related to the processor
 // and the aggregated data from the events.  Potentially to be used to service
 // the APIs to pull data.
 type ListingPersister interface {
 	// Listings returns all listings by ListingCriteria sorted by creation ts
 	ListingsByCriteria(criteria *ListingCriteria) ([]*Listing, error)
+	// ListingsByCriteriaIter returns an iterator over listings by ListingCriteria.
+	// The iterator must be closed when done.
+	ListingsByCriteriaIter(criteria *ListingCriteria) (ListingIterator, error)
 	// ListingsByAddress returns a slice of Listings in order based on addresses
 	ListingsByAddresses(addresses []common.Address) ([]*Listing, error)
 	// ListingByAddress retrieves listings based on addresses
 	ListingByAddress(address common.Address) (*Listing, error)
+	// ListingsWithRecentGovernanceEvent returns the listings with a governance
+	// event of the given type created at or after the given timestamp
+	ListingsWithRecentGovernanceEvent(eventType string, sinceTs int64) ([]*Listing, error)
 	// ListingsByOwnerAddress retrieves listings based on owner address
 	ListingsByOwnerAddress(address common.Address) ([]*Listing, error)
 	// CreateListing creates a new listing
@@ -106,10 +164,23 @@ type ListingPersister interface {
 	UpdateListing(listing *Listing, updatedFields []string) error
 	// DeleteListing removes a listing
 	DeleteListing(listing *Listing) error
+	// MarkListingRemoved marks a listing as removed from the registry without
+	// deleting it
+	MarkListingRemoved(addr common.Address) error
 	// ListingByCleanedNewsroomURL retrieves a listing that matches the given url
 	ListingByCleanedNewsroomURL(cleanedURL string) (*Listing, error)
 	// AllListingAddresses returns all addresses for listings in persistence
 	AllListingAddresses() ([]string, error)
+	// CreateListingStateChange records a change to the state of a listing
+	CreateListingStateChange(change *ListingStateChange) error
+	// ListingStateHistory gets the state changes for a listing ordered by timestamp
+	ListingStateHistory(address common.Address) ([]*ListingStateChange, error)
+	// ListingStats returns the number of listings that applied, were whitelisted,
+	// rejected or withdrawn between the given timestamps
+	ListingStats(fromTs int64, beforeTs int64) (*ListingStatsResult, error)
+	// ListingGovernanceState returns the listing with its active challenge,
+	// the challenge poll and any appeal
+	ListingGovernanceState(address common.Address) (*ListingGovernance, error)
 	// Close shuts down the persister
 	Close() error
 }
@@ -151,6 +222,14 @@ type ContentRevisionCriteria struct {
 	LatestOnly     bool   `db:"latest_only"`
 	FromTs         int64  `db:"fromts"`
 	BeforeTs       int64  `db:"beforets"`
+	// TitleContains matches revisions with payload titles containing all
+	// the words in the string, using full-text search
+	TitleContains string `db:"title_contains"`
+	// EditorAddress matches revisions by the editor address, case-insensitive
+	EditorAddress string `db:"editor_address"`
+	// ContentIDs matches revisions of any of the content IDs. Applies with or
+	// without LatestOnly.
+	ContentIDs []int64 `db:"content_ids"`
 }
 
 // ContentRevisionPersister is the interface to store the content data related to the processor
@@ -159,14 +238,43 @@ type ContentRevisionCriteria struct {
 type ContentRevisionPersister interface {
 	// ContentRevisionsByCriteria returns all content revisions by ContentRevisionCriteria
 	ContentRevisionsByCriteria(criteria *ContentRevisionCriteria) ([]*ContentRevision, error)
-	// ContentRevisions retrieves the revisions for content on a listing
+	// ContentRevisions retrieves the revisions for content on a listing.
+	// Returns cpersist.ErrPersisterNoResults if there are none.
 	ContentRevisions(address common.Address, contentID *big.Int) ([]*ContentRevision, error)
+	// ContentRevisionCount returns the number of distinct content items on a
+	// listing, not the number of revisions
+	ContentRevisionCount(address common.Address) (int, error)
+	// LatestRevisionsByListing returns the newest revision of each content item
+	// on a listing
+	LatestRevisionsByListing(address common.Address) ([]*ContentRevision, error)
+	// ContentRevisionsModifiedAfter returns up to limit revisions across all
+	// listings with a revision timestamp after ts, ordered by revision timestamp.
+	// More than limit revisions are returned if the last revisions share a
+	// timestamp, so polling from the last timestamp does not skip any.
+	ContentRevisionsModifiedAfter(ts int64, limit int) ([]*ContentRevision, error)
+	// MostRecentContentRevision returns the revision with the newest revision
+	// timestamp across all listings.
+	// Returns cpersist.ErrPersisterNoResults if there are none.
+	MostRecentContentRevision() (*ContentRevision, error)
 	// ContentRevision retrieves a specific content revision for newsroom content
 	ContentRevision(address common.Address, contentID *big.Int, revisionID *big.Int) (*ContentRevision, error)
+	// ContentRevisionByHash retrieves the earliest content revision with the
+	// given article payload hash
+	ContentRevisionByHash(hash string) (*ContentRevision, error)
+	// ContentRevisionsByHashes retrieves the earliest content revision for each
+	// of the given article payload hashes, in the same order as the hashes.
+	// If a hash is not found, nil is returned in its place.
+	ContentRevisionsByHashes(hashes []string) ([]*ContentRevision, error)
 	// CreateContentRevision creates a new content revision
 	CreateContentRevision(revision *ContentRevision) error
+	// ContentRevisionExists returns true if there is a revision for the listing
+	// content ID and revision ID
+	ContentRevisionExists(address common.Address, contentID *big.Int, revisionID *big.Int) (bool, error)
 	// UpdateContentRevision updates fields on an existing content revision
 	UpdateContentRevision(revision *ContentRevision, updatedFields []string) error
+	// UpsertContentRevision creates a new content revision or updates the
+	// existing revision for the same listing content ID and revision ID
+	UpsertContentRevision(revision *ContentRevision) error
 	// DeleteContentRevision removes a content revision
 	DeleteContentRevision(revision *ContentRevision) error
 	// Close shuts down the persister
@@ -181,20 +289,47 @@ type GovernanceEventCriteria struct {
 	Count           int    `db:"count"`
 	CreatedFromTs   int64  `db:"created_fromts"`
 	CreatedBeforeTs int64  `db:"created_beforets"`
+	ContractAddress string `db:"contract_address"`
+	// ChallengeID filters on the ChallengeID in the event metadata if not nil
+	ChallengeID *int `db:"challenge_id"`
+	// LatestPerListing only retrieves the most recent event for each listing
+	// among the events matching the other criteria
+	LatestPerListing bool `db:"latest_per_listing"`
+	// AfterCursor retrieves the events after the event with this cursor, as
+	// returned by GovernanceEventsByCursor. Offset is ignored if set.
+	AfterCursor string `db:"after_cursor"`
 }
 
 // GovernanceEventPersister is the interface to store the governance event data related to the processor
 // and the aggregated data from the events.  Potentially to be used to service
 // the APIs to pull data.
 type GovernanceEventPersister interface {
-	//GovernanceEventsByTxHash gets governance events based on txhash
+	// GovernanceEventsByTxHash gets governance events based on txhash.
+	// Returns cpersist.ErrPersisterNoResults if there are none.
 	GovernanceEventsByTxHash(txHash common.Hash) ([]*GovernanceEvent, error)
+	// GovernanceEventBySourceEvent gets the governance event processed from the
+	// crawler event with the given tx hash and log index
+	GovernanceEventBySourceEvent(txHash common.Hash, logIndex uint) (*GovernanceEvent, error)
 	// GovernanceEventsByCriteria retrieves governance events based on criteria
 	GovernanceEventsByCriteria(criteria *GovernanceEventCriteria) ([]*GovernanceEvent, error)
-	// GovernanceEventsByListingAddress retrieves governance events based on listing address
+	// GovernanceEventsByCursor retrieves governance events based on criteria
+	// ordered by creation date and event hash, and returns the cursor to
+	// retrieve the next page with. The cursor is empty on the last page.
+	GovernanceEventsByCursor(criteria *GovernanceEventCriteria) ([]*GovernanceEvent, string, error)
+	// CountGovernanceEventsByCriteria returns the number of governance events matching
+	// the criteria. Offset, Count and AfterCursor are ignored.
+	CountGovernanceEventsByCriteria(criteria *GovernanceEventCriteria) (int, error)
+	// GovernanceEventsByListingAddress retrieves governance events based on listing address.
+	// Returns cpersist.ErrPersisterNoResults if there are none.
 	GovernanceEventsByListingAddress(address common.Address) ([]*GovernanceEvent, error)
 	// CreateGovernanceEvent creates a new governance event
 	CreateGovernanceEvent(govEvent *GovernanceEvent) error
+	// CreateGovernanceEvents creates the governance events together. If any
+	// fail, none are created.
+	CreateGovernanceEvents(govEvents []*GovernanceEvent) error
+	// UpsertGovernanceEvent creates a new governance event or updates the last
+	// updated timestamp if it already exists
+	UpsertGovernanceEvent(govEvent *GovernanceEvent) error
 	// UpdateGovernanceEvent updates fields on an existing governance event
 	UpdateGovernanceEvent(govEvent *GovernanceEvent, updatedFields []string) error
 	// DeleteGovernanceEvent removes a governance event
@@ -211,8 +346,12 @@ type CronPersister interface {
 	UpdateTimestampForCron(timestamp int64) error
 	// EventHashesOfLastTimestampForCron returns the event hashes processed for the last timestamp from cron
 	EventHashesOfLastTimestampForCron() ([]string, error)
-	// UpdateEventHashesForCron updates the eventHashes saved in cron table
+	// UpdateEventHashesForCron updates the eventHashes saved in cron table, skipping
+	// empty and duplicate hashes
 	UpdateEventHashesForCron(eventHashes []string) error
+	// SetCronTimestamp sets the timestamp and clears the event hashes so the next
+	// run reprocesses events from the timestamp
+	SetCronTimestamp(timestamp int64) error
 	// Close shuts down the persister
 	Close() error
 }
@@ -221,6 +360,9 @@ type CronPersister interface {
 type ChallengePersister interface {
 	// ChallengeByChallengeID gets a challenge by challengeID
 	ChallengeByChallengeID(challengeID int) (*Challenge, error)
+	// ChallengeByPollID gets the challenge associated with the given pollID.
+	// Challenge IDs and their poll IDs are the same value in the TCR.
+	ChallengeByPollID(pollID int) (*Challenge, error)
 	// ChallengesByChallengeIDs returns a slice of challenges in order based on challenge IDs
 	ChallengesByChallengeIDs(challengeIDs []int) ([]*Challenge, error)
 	// ChallengesByListingAddress gets list of challenges for a listing sorted by
@@ -231,6 +373,12 @@ type ChallengePersister interface {
 	ChallengesByListingAddresses(addr []common.Address) ([][]*Challenge, error)
 	// ChallengesByChallengerAddress returns a slice of challenges started by given user
 	ChallengesByChallengerAddress(addr common.Address) ([]*Challenge, error)
+	// ResolvedChallengesByTimeRange returns the resolved challenges last updated
+	// between fromTs and beforeTs inclusive, sorted by timestamp
+	ResolvedChallengesByTimeRange(fromTs int64, beforeTs int64) ([]*Challenge, error)
+	// OrphanedChallenges returns the challenges for listing addresses that have
+	// no listing, sorted by challenge id
+	OrphanedChallenges() ([]*Challenge, error)
 	// CreateChallenge creates a new challenge
 	CreateChallenge(challenge *Challenge) error
 	// UpdateChallenge updates a challenge
@@ -243,12 +391,19 @@ type ChallengePersister interface {
 type PollPersister interface {
 	// PollByPollID gets a poll by pollID
 	PollByPollID(pollID int) (*Poll, error)
+	// PollByChallengeID gets the poll associated with the given challengeID
+	PollByChallengeID(challengeID int) (*Poll, error)
 	// PollsByPollIDs returns a slice of polls in order based on poll IDs
 	PollsByPollIDs(pollIDs []int) ([]*Poll, error)
+	// PollsByPollIDsMap returns a map of found polls keyed by poll ID
+	PollsByPollIDsMap(pollIDs []int) (map[int]*Poll, error)
 	// CreatePoll creates a new poll
 	CreatePoll(poll *Poll) error
 	// UpdatePoll updates a poll
 	UpdatePoll(poll *Poll, updatedFields []string) error
+	// UpsertPoll creates a new poll or updates the vote totals and last updated
+	// timestamp of an existing poll
+	UpsertPoll(poll *Poll) error
 	// Close shuts down the persister
 	Close() error
 }
@@ -259,8 +414,12 @@ type AppealPersister interface {
 	AppealByChallengeID(challengeID int) (*Appeal, error)
 	// AppealsByChallengeIDs returns a slice of appeals in order based on challenge IDs
 	AppealsByChallengeIDs(challengeIDs []int) ([]*Appeal, error)
+	// AppealWithChallenge gets an appeal by challengeID with its challenge
+	AppealWithChallenge(challengeID int) (*AppealWithChallenge, error)
 	// AppealByAppealChallengeID gets an appeal by appealchallengeID
 	AppealByAppealChallengeID(challengeID int) (*Appeal, error)
+	// AppealsByAppealChallengeIDs returns a slice of appeals in order based on appeal challenge IDs
+	AppealsByAppealChallengeIDs(appealChallengeIDs []int) ([]*Appeal, error)
 	// CreateAppeal creates a new appeal
 	CreateAppeal(appeal *Appeal) error
 	// UpdateAppeal updates an appeal
@@ -275,20 +434,50 @@ type ParameterPersister interface {
 	ParameterByName(paramName string) (*Parameter, error)
 	// ParametersByName gets a slice of parameter by name
 	ParametersByName(paramName []string) ([]*Parameter, error)
+	// ParametersByNameMap gets a map of parameters keyed by name
+	ParametersByNameMap(paramName []string) (map[string]*Parameter, error)
+	// AllParameters gets all parameters ordered by name
+	AllParameters() ([]*Parameter, error)
 	// UpdateParameter updates a parameter value
 	UpdateParameter(parameter *Parameter, updatedFields []string) error
+	// CreateParameterChange records a change to a parameter value
+	CreateParameterChange(change *ParameterChange) error
+	// ParameterHistory gets the changes to a parameter ordered by timestamp
+	ParameterHistory(paramName string) ([]*ParameterChange, error)
 	// CreateDefaultValues creates Parameter default values
 	CreateDefaultValues(config *utils.ProcessorConfig) error
+	// UpsertParameterDefaults adds default values for parameters that do not
+	// exist yet without overwriting existing values
+	UpsertParameterDefaults(defaults map[string]string) error
 	// Close shuts down the persister
 	Close() error
 }
 
+// TokenTransferCriteria contains the retrieval criteria for token transfer
+// queries
+type TokenTransferCriteria struct {
+	FromAddress string `db:"from_address"`
+	ToAddress   string `db:"to_address"`
+	FromTs      int64  `db:"fromts"`
+	BeforeTs    int64  `db:"beforets"`
+	Offset      int    `db:"offset"`
+	Count       int    `db:"count"`
+}
+
 // TokenTransferPersister is the persister interface to store TokenTransfer
 type TokenTransferPersister interface {
 	// TokenTransfersByTxHash gets a list of token transfers by txhash
 	TokenTransfersByTxHash(txHash common.Hash) ([]*TokenTransfer, error)
 	// TokenTransfersByToAddress gets a list of token transfers by purchaser address
 	TokenTransfersByToAddress(addr common.Address) ([]*TokenTransfer, error)
+	// TokenTransfersByBlockRange gets a list of token transfers between the given blocks
+	TokenTransfersByBlockRange(fromBlock uint64, toBlock uint64) ([]*TokenTransfer, error)
+	// TokenTransfersByCriteriaIter returns an iterator over token transfers
+	// by TokenTransferCriteria sorted by transfer date
+	TokenTransfersByCriteriaIter(criteria *TokenTransferCriteria) (TokenTransferIterator, error)
+	// DailyTokenTransferTotals gets the token transfer totals per day between
+	// the given timestamps
+	DailyTokenTransferTotals(fromTs int64, beforeTs int64) ([]*DayTotal, error)
 	// CreateTokenTransfer creates a new token transfer
 	CreateTokenTransfer(purchase *TokenTransfer) error
 	// Close shuts down the persister
@@ -315,10 +504,17 @@ type GovernmentParameterPersister interface {
 	GovernmentParameterByName(paramName string) (*GovernmentParameter, error)
 	// GovernmentParametersByName gets a slice of parameter by name
 	GovernmentParametersByName(paramName []string) ([]*GovernmentParameter, error)
+	// GovernmentParametersByNameMap gets a map of parameters keyed by name
+	GovernmentParametersByNameMap(paramName []string) (map[string]*GovernmentParameter, error)
+	// AllGovernmentParameters gets all government parameters ordered by name
+	AllGovernmentParameters() ([]*GovernmentParameter, error)
 	// UpdateGovernmentParameter updates a parameter value
 	UpdateGovernmentParameter(parameter *GovernmentParameter, updatedFields []string) error
 	// CreateDefaultValues creates Government Parameter default values
 	CreateDefaultValues(config *utils.ProcessorConfig) error
+	// UpsertGovernmentParameterDefaults adds default values for government
+	// parameters that do not exist yet without overwriting existing values
+	UpsertGovernmentParameterDefaults(defaults map[string]string) error
 	// Close shuts down the persister
 	Close() error
 }
@@ -340,13 +536,14 @@ type GovernmentParamProposalPersister interface {
 // UserChallengeDataCriteria contains the retrieval criteria for the UserChallengeDataByCriteria
 // query
 type UserChallengeDataCriteria struct {
-	UserAddress    string `db:"user_address"`
-	PollID         uint64 `db:"poll_id"`
-	CanUserCollect bool   `db:"can_user_collect"`
-	CanUserReveal  bool   `db:"can_user_reveal"`
-	CanUserRescue  bool   `db:"can_user_rescue"`
-	Offset         int    `db:"offset"`
-	Count          int    `db:"count"`
+	UserAddress     string `db:"user_address"`
+	PollID          uint64 `db:"poll_id"`
+	CanUserCollect  bool   `db:"can_user_collect"`
+	CanUserReveal   bool   `db:"can_user_reveal"`
+	CanUserRescue   bool   `db:"can_user_rescue"`
+	IncludeAllVotes bool   `db:"include_all_votes"`
+	Offset          int    `db:"offset"`
+	Count           int    `db:"count"`
 }
 
 // UserChallengeDataPersister is the persister interface to store UserChallengeData
@@ -355,6 +552,9 @@ type UserChallengeDataPersister interface {
 	CreateUserChallengeData(userChallengeData *UserChallengeData) error
 	// UserChallengeDataByCriteria retrieves UserChallengeData based on criteria
 	UserChallengeDataByCriteria(criteria *UserChallengeDataCriteria) ([]*UserChallengeData, error)
+	// CountUserChallengeDataByCriteria returns the number of UserChallengeData matching
+	// the criteria. Offset and Count are ignored.
+	CountUserChallengeDataByCriteria(criteria *UserChallengeDataCriteria) (int, error)
 	// UpdateUserChallengeData updates UserChallengeData in table.
 	// user=true updates for user + pollID, user=false updates for pollID
 	// Since we save on all voteCommitted events, latestVote=True only updates the latest vote
@@ -2,6 +2,7 @@
 package model // import "github.com/joincivil/civil-events-processor/pkg/model"
 
 import (
+	"encoding/json"
 	"math/big"
 )
 
@@ -110,3 +111,57 @@ func (p *Poll) LastUpdatedDateTs() int64 {
 func (p *Poll) SetLastUpdatedDateTs(lastUpdatedTs int64) {
 	p.lastUpdatedDateTs = lastUpdatedTs
 }
+
+type pollJSON struct {
+	PollID            *string `json:"pollID"`
+	PollType          string  `json:"pollType"`
+	CommitEndDate     *string `json:"commitEndDate"`
+	RevealEndDate     *string `json:"revealEndDate"`
+	IsPassed          bool    `json:"isPassed"`
+	VoteQuorum        *string `json:"voteQuorum"`
+	VotesFor          *string `json:"votesFor"`
+	VotesAgainst      *string `json:"votesAgainst"`
+	LastUpdatedDateTs int64   `json:"lastUpdatedDateTs"`
+}
+
+// MarshalJSON converts this struct into a []byte. Big ints are encoded as
+// decimal strings.
+func (p *Poll) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&pollJSON{
+		PollID:            bigIntToJSON(p.pollID),
+		PollType:          p.pollType,
+		CommitEndDate:     bigIntToJSON(p.commitEndDate),
+		RevealEndDate:     bigIntToJSON(p.revealEndDate),
+		IsPassed:          p.isPassed,
+		VoteQuorum:        bigIntToJSON(p.voteQuorum),
+		VotesFor:          bigIntToJSON(p.votesFor),
+		VotesAgainst:      bigIntToJSON(p.votesAgainst),
+		LastUpdatedDateTs: p.lastUpdatedDateTs,
+	})
+}
+
+// UnmarshalJSON populates the struct with given []byte.
+func (p *Poll) UnmarshalJSON(data []byte) error {
+	pj := &pollJSON{}
+	err := json.Unmarshal(data, pj)
+	if err != nil {
+		return err
+	}
+	d := &jsonFieldDecoder{}
+	poll := &Poll{
+		pollID:            d.bigInt("pollID", pj.PollID),
+		pollType:          pj.PollType,
+		commitEndDate:     d.bigInt("commitEndDate", pj.CommitEndDate),
+		revealEndDate:     d.bigInt("revealEndDate", pj.RevealEndDate),
+		isPassed:          pj.IsPassed,
+		voteQuorum:        d.bigInt("voteQuorum", pj.VoteQuorum),
+		votesFor:          d.bigInt("votesFor", pj.VotesFor),
+		votesAgainst:      d.bigInt("votesAgainst", pj.VotesAgainst),
+		lastUpdatedDateTs: pj.LastUpdatedDateTs,
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*p = *poll
+	return nil
+}
@@ -2,10 +2,20 @@ package model
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// DayTotal is the total amount and number of token transfers for a day
+type DayTotal struct {
+	// Day is the start of the day in UTC
+	Day time.Time
+	// Total is the sum of the transfer amounts in gwei
+	Total *big.Int
+	Count int
+}
+
 // TokenTransferParams are the params to initialize a new TokenTransfer
 type TokenTransferParams struct {
 	ToAddress    common.Address
@@ -0,0 +1,50 @@
+// Package model contains the general data models and interfaces for the Civil processor.
+package model // import "github.com/joincivil/civil-events-processor/pkg/model"
+
+// TokenTransferIterator iterates over a set of token transfers one at a time,
+// so large result sets do not need to be held in memory.
+type TokenTransferIterator interface {
+	// Next advances to the next token transfer. Returns false when there are
+	// no more token transfers or an error occurred.
+	Next() bool
+	// TokenTransfer returns the current token transfer
+	TokenTransfer() *TokenTransfer
+	// Err returns the error that stopped the iteration, if any
+	Err() error
+	// Close releases the resources held by the iterator
+	Close() error
+}
+
+// NewTokenTransferSliceIterator returns a TokenTransferIterator over a slice
+// of token transfers
+func NewTokenTransferSliceIterator(transfers []*TokenTransfer) TokenTransferIterator {
+	return &tokenTransferSliceIterator{transfers: transfers, index: -1}
+}
+
+type tokenTransferSliceIterator struct {
+	transfers []*TokenTransfer
+	index     int
+}
+
+func (t *tokenTransferSliceIterator) Next() bool {
+	if t.index+1 >= len(t.transfers) {
+		return false
+	}
+	t.index++
+	return true
+}
+
+func (t *tokenTransferSliceIterator) TokenTransfer() *TokenTransfer {
+	if t.index < 0 || t.index >= len(t.transfers) {
+		return nil
+	}
+	return t.transfers[t.index]
+}
+
+func (t *tokenTransferSliceIterator) Err() error {
+	return nil
+}
+
+func (t *tokenTransferSliceIterator) Close() error {
+	return nil
+}
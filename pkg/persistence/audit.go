@@ -0,0 +1,32 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	log "github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+)
+
+// LogOrphanedChallenges logs the challenges for listing addresses that have no
+// listing and returns them. Nothing is modified, the log is meant for data
+// integrity audits and to find listings to re-ingest.
+func LogOrphanedChallenges(persister model.ChallengePersister) ([]*model.Challenge, error) {
+	challenges, err := persister.OrphanedChallenges()
+	if err != nil && err != cpersist.ErrPersisterNoResults {
+		return nil, errors.WithMessage(err, "error retrieving orphaned challenges")
+	}
+	for _, challenge := range challenges {
+		log.Warningf(
+			"Orphaned challenge: challenge id: %v, listing: %v, resolved: %v",
+			challenge.ChallengeID(),
+			challenge.ListingAddress().Hex(),
+			challenge.Resolved(),
+		)
+	}
+	if len(challenges) == 0 {
+		return []*model.Challenge{}, nil
+	}
+	return challenges, nil
+}
@@ -0,0 +1,55 @@
+package persistence_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/persistence"
+	"github.com/joincivil/civil-events-processor/pkg/testutils"
+)
+
+func testChallenge(challengeID int64, listingAddress common.Address) *model.Challenge {
+	return model.NewChallenge(big.NewInt(challengeID), listingAddress, "", big.NewInt(50),
+		common.Address{}, false, big.NewInt(100), big.NewInt(1000), big.NewInt(0),
+		model.ChallengePollType, 1000)
+}
+
+func TestLogOrphanedChallenges(t *testing.T) {
+	listingAddress := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	orphanAddress := common.HexToAddress("0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A")
+	persister := &testutils.TestPersister{}
+
+	orphans, err := persistence.LogOrphanedChallenges(persister)
+	if err != nil {
+		t.Errorf("Should not have gotten error with no challenges: err: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Should have no orphans with no challenges, have %v", len(orphans))
+	}
+
+	listing := model.NewListing(&model.NewListingParams{ContractAddress: listingAddress})
+	_ = persister.CreateListing(listing)
+	_ = persister.CreateChallenge(testChallenge(3, orphanAddress))
+	_ = persister.CreateChallenge(testChallenge(1, listingAddress))
+	_ = persister.CreateChallenge(testChallenge(2, orphanAddress))
+
+	orphans, err = persistence.LogOrphanedChallenges(persister)
+	if err != nil {
+		t.Errorf("Should not have gotten error logging orphans: err: %v", err)
+	}
+	if len(orphans) != 2 {
+		t.Fatalf("Should have 2 orphans, have %v", len(orphans))
+	}
+	for i, challengeID := range []int64{2, 3} {
+		if orphans[i].ChallengeID().Int64() != challengeID {
+			t.Errorf("Should have orphan %v at %v, have %v", challengeID, i,
+				orphans[i].ChallengeID())
+		}
+		if orphans[i].ListingAddress() != orphanAddress {
+			t.Errorf("Should have only returned challenges without listings")
+		}
+	}
+}
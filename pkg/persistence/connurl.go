@@ -0,0 +1,33 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// postgresConnInfoFromURL converts a postgres:// or postgresql:// connection
+// URL to a connection string. The URL must have a host and database name.
+// Adds DefaultSSLMode if the URL has no sslmode, since pq defaults to require.
+func postgresConnInfoFromURL(connURL string) (string, error) {
+	u, err := url.Parse(connURL)
+	if err != nil {
+		return "", errors.Wrap(err, "error parsing postgres url")
+	}
+	if u.Host == "" {
+		return "", errors.New("postgres url has no host")
+	}
+	if u.Path == "" || u.Path == "/" {
+		return "", errors.New("postgres url has no db name")
+	}
+	connInfo, err := pq.ParseURL(connURL)
+	if err != nil {
+		return "", errors.Wrap(err, "error parsing postgres url")
+	}
+	if u.Query().Get("sslmode") == "" {
+		connInfo = fmt.Sprintf("%v sslmode=%v", connInfo, DefaultSSLMode)
+	}
+	return connInfo, nil
+}
@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostgresConnInfoFromURL(t *testing.T) {
+	connInfo, err := postgresConnInfoFromURL(
+		"postgres://user:pw@db.example.com:5433/civil?sslmode=verify-full",
+	)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error parsing url: %v", err)
+	}
+	expected := []string{
+		"user=user",
+		"password=pw",
+		"host=db.example.com",
+		"port=5433",
+		"dbname=civil",
+		"sslmode=verify-full",
+	}
+	for _, field := range expected {
+		if !strings.Contains(connInfo, field) {
+			t.Errorf("Should have gotten %v in conn info: %v", field, connInfo)
+		}
+	}
+	if strings.Count(connInfo, "sslmode=") != 1 {
+		t.Errorf("Should have gotten a single sslmode in conn info: %v", connInfo)
+	}
+}
+
+func TestPostgresConnInfoFromURLDefaultSSLMode(t *testing.T) {
+	connInfo, err := postgresConnInfoFromURL("postgresql://user:pw@localhost:5432/civil")
+	if err != nil {
+		t.Fatalf("Should not have gotten an error parsing url: %v", err)
+	}
+	if !strings.Contains(connInfo, "sslmode=disable") {
+		t.Errorf("Should have defaulted to sslmode=disable: %v", connInfo)
+	}
+}
+
+func TestPostgresConnInfoFromURLMalformed(t *testing.T) {
+	urls := []string{
+		"mysql://user:pw@localhost:5432/civil",
+		"postgres://user:pw@localhost:5432",
+		"postgres:///civil",
+		"postgres://user:pw@localhost:port/civil",
+		"not a url",
+	}
+	for _, connURL := range urls {
+		_, err := postgresConnInfoFromURL(connURL)
+		if err == nil {
+			t.Errorf("Should have gotten an error parsing url: %v", connURL)
+		}
+	}
+}
+
+func TestNewPostgresPersisterFromURLMalformed(t *testing.T) {
+	_, err := NewPostgresPersisterFromURL("postgres://localhost:5432", WithMaxConns(5))
+	if err == nil {
+		t.Errorf("Should have gotten an error for url with no db name")
+	}
+}
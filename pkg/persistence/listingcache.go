@@ -0,0 +1,104 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+// WithListingCache caches up to size listings retrieved by ListingByAddress
+// and ListingsByAddresses for the given TTL. A cached listing is invalidated
+// when it is created, updated, deleted or marked removed through the persister,
+// but changes made by other processes are not seen until the TTL expires.
+// Listings are cached as DB rows, so each caller gets its own listing to modify.
+// Listings are not cached by default.
+func WithListingCache(size int, ttl time.Duration) PoolOption {
+	return func(config *poolConfig) {
+		config.listingCacheSize = size
+		config.listingCacheTTL = ttl
+	}
+}
+
+type listingCacheKey struct {
+	tableName string
+	address   common.Address
+}
+
+type listingCacheEntry struct {
+	listing *postgres.Listing
+	expires time.Time
+}
+
+// listingCache is an LRU cache of listings keyed by table name and address.
+// A nil listingCache caches nothing.
+type listingCache struct {
+	lru *lru.Cache
+	ttl time.Duration
+	// now returns the current time, set in tests
+	now func() time.Time
+}
+
+func newListingCache(size int, ttl time.Duration) (*listingCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating listing cache")
+	}
+	return &listingCache{lru: cache, ttl: ttl, now: time.Now}, nil
+}
+
+// get adds the unexpired cached listings for the given addresses to
+// listingsMap and returns the addresses that were not cached
+func (c *listingCache) get(tableName string, addresses []common.Address,
+	listingsMap map[common.Address]*model.Listing) []common.Address {
+	if c == nil {
+		return addresses
+	}
+	now := c.now()
+	missed := []common.Address{}
+	for _, address := range addresses {
+		key := listingCacheKey{tableName: tableName, address: address}
+		value, ok := c.lru.Get(key)
+		if !ok {
+			missed = append(missed, address)
+			continue
+		}
+		entry := value.(*listingCacheEntry)
+		if now.After(entry.expires) {
+			c.lru.Remove(key)
+			missed = append(missed, address)
+			continue
+		}
+		listingsMap[address] = entry.listing.DbToListingData()
+	}
+	return missed
+}
+
+// add caches the listings in listingsMap for the given addresses
+func (c *listingCache) add(tableName string, addresses []common.Address,
+	listingsMap map[common.Address]*model.Listing) {
+	if c == nil {
+		return
+	}
+	expires := c.now().Add(c.ttl)
+	for _, address := range addresses {
+		listing, ok := listingsMap[address]
+		if !ok || listing == nil {
+			continue
+		}
+		key := listingCacheKey{tableName: tableName, address: address}
+		c.lru.Add(key, &listingCacheEntry{listing: postgres.NewListing(listing), expires: expires})
+	}
+}
+
+// remove invalidates the cached listing for the given address
+func (c *listingCache) remove(tableName string, address common.Address) {
+	if c == nil {
+		return
+	}
+	c.lru.Remove(listingCacheKey{tableName: tableName, address: address})
+}
@@ -0,0 +1,168 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+func newCachedTestListing(address common.Address) *model.Listing {
+	return model.NewListing(&model.NewListingParams{
+		Name:            "test newsroom",
+		ContractAddress: address,
+	})
+}
+
+func TestListingCacheTTL(t *testing.T) {
+	cache, err := newListingCache(10, time.Minute)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the cache: %v", err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	address1 := common.HexToAddress("0x1")
+	address2 := common.HexToAddress("0x2")
+	cache.add("listing", []common.Address{address1, address2},
+		map[common.Address]*model.Listing{address1: newCachedTestListing(address1)})
+
+	listingsMap := map[common.Address]*model.Listing{}
+	missed := cache.get("listing", []common.Address{address1, address2}, listingsMap)
+	if len(missed) != 1 || missed[0] != address2 {
+		t.Errorf("Should have missed only the listing not found: %v", missed)
+	}
+	if listingsMap[address1] == nil {
+		t.Errorf("Should have gotten the cached listing")
+	}
+
+	missed = cache.get("listing_123", []common.Address{address1}, listingsMap)
+	if len(missed) != 1 {
+		t.Errorf("Should not have gotten a listing cached for another table")
+	}
+
+	now = now.Add(2 * time.Minute)
+	listingsMap = map[common.Address]*model.Listing{}
+	missed = cache.get("listing", []common.Address{address1}, listingsMap)
+	if len(missed) != 1 || len(listingsMap) != 0 {
+		t.Errorf("Should have missed the expired listing: %v", missed)
+	}
+	if cache.lru.Len() != 0 {
+		t.Errorf("Should have removed the expired listing: %v", cache.lru.Len())
+	}
+}
+
+func TestListingCacheSize(t *testing.T) {
+	cache, err := newListingCache(1, time.Minute)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the cache: %v", err)
+	}
+	address1 := common.HexToAddress("0x1")
+	address2 := common.HexToAddress("0x2")
+	cache.add("listing", []common.Address{address1, address2}, map[common.Address]*model.Listing{
+		address1: newCachedTestListing(address1),
+		address2: newCachedTestListing(address2),
+	})
+	missed := cache.get("listing", []common.Address{address1, address2},
+		map[common.Address]*model.Listing{})
+	if len(missed) != 1 || missed[0] != address1 {
+		t.Errorf("Should have evicted the least recently used listing: %v", missed)
+	}
+}
+
+func TestListingCacheReturnsCopies(t *testing.T) {
+	cache, err := newListingCache(10, time.Minute)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the cache: %v", err)
+	}
+	address := common.HexToAddress("0x1")
+	listing := newCachedTestListing(address)
+	cache.add("listing", []common.Address{address}, map[common.Address]*model.Listing{
+		address: listing,
+	})
+	listing.SetName("modified after caching")
+
+	listingsMap := map[common.Address]*model.Listing{}
+	cache.get("listing", []common.Address{address}, listingsMap)
+	if listingsMap[address].Name() != "test newsroom" {
+		t.Errorf("Should not have seen changes to the added listing: %v", listingsMap[address].Name())
+	}
+	listingsMap[address].SetName("modified after get")
+
+	listingsMap = map[common.Address]*model.Listing{}
+	cache.get("listing", []common.Address{address}, listingsMap)
+	if listingsMap[address].Name() != "test newsroom" {
+		t.Errorf("Should not have seen changes to a retrieved listing: %v", listingsMap[address].Name())
+	}
+}
+
+func TestNilListingCache(t *testing.T) {
+	var cache *listingCache
+	address := common.HexToAddress("0x1")
+	listingsMap := map[common.Address]*model.Listing{address: newCachedTestListing(address)}
+	cache.add("listing", []common.Address{address}, listingsMap)
+	cache.remove("listing", address)
+	missed := cache.get("listing", []common.Address{address}, map[common.Address]*model.Listing{})
+	if len(missed) != 1 {
+		t.Errorf("Should have missed all listings with no cache: %v", missed)
+	}
+}
+
+func TestPersisterListingCache(t *testing.T) {
+	db, recorder := openRecordingDB(t, "listing_cache_test")
+	persister, err := NewPostgresPersisterFromSqlx(db, WithListingCache(10, time.Minute))
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the persister: %v", err)
+	}
+	defer persister.Close() // nolint: errcheck
+
+	address := common.HexToAddress("0x1")
+	listing := newCachedTestListing(address)
+	persister.listingCache.add("listing", []common.Address{address},
+		map[common.Address]*model.Listing{address: listing})
+
+	cached, err := persister.ListingByAddress(address)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving the listing: %v", err)
+	}
+	if cached == nil || cached.ContractAddress() != address || cached.Name() != listing.Name() {
+		t.Errorf("Should have gotten the cached listing")
+	}
+	listings, err := persister.ListingsByAddresses([]common.Address{address})
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving the listings: %v", err)
+	}
+	if len(listings) != 1 || listings[0] == nil || listings[0].ContractAddress() != address {
+		t.Errorf("Should have gotten the cached listing in listings")
+	}
+	if recorder.numQueries("listing_cache_test") != 0 {
+		t.Errorf("Should not have queried the db for a cached listing: %v",
+			recorder.numQueries("listing_cache_test"))
+	}
+
+	err = persister.UpdateListing(listing, []string{"Name"})
+	if err != nil {
+		t.Fatalf("Should not have gotten an error updating the listing: %v", err)
+	}
+	// The recording db returns no rows, so the listing is not found after the
+	// cached listing is invalidated
+	_, err = persister.ListingByAddress(address)
+	if err == nil {
+		t.Errorf("Should have queried the db after the update invalidated the listing")
+	}
+	if recorder.numQueries("listing_cache_test") != 2 {
+		t.Errorf("Should have made the update and listing queries: %v",
+			recorder.numQueries("listing_cache_test"))
+	}
+}
+
+func TestPersisterNoListingCacheByDefault(t *testing.T) {
+	db, _ := openRecordingDB(t, "no_listing_cache_test")
+	persister, _ := NewPostgresPersisterFromSqlx(db)
+	defer persister.Close() // nolint: errcheck
+	if persister.listingCache != nil {
+		t.Errorf("Should not have created a listing cache by default")
+	}
+}
@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+)
+
+func TestMultiRowLookupsNoResults(t *testing.T) {
+	// The recording db returns no rows for every query
+	db, _ := openRecordingDB(t, "no_results_test")
+	persister, _ := NewPostgresPersisterFromSqlx(db)
+	defer persister.Close() // nolint: errcheck
+
+	address := common.HexToAddress("0x1")
+	govEvents, err := persister.GovernanceEventsByListingAddress(address)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten ErrPersisterNoResults for gov events by address: %v", err)
+	}
+	if govEvents != nil {
+		t.Errorf("Should have gotten no gov events: %v", govEvents)
+	}
+
+	govEvents, err = persister.GovernanceEventsByTxHash(common.HexToHash("0x2"))
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten ErrPersisterNoResults for gov events by tx hash: %v", err)
+	}
+	if govEvents != nil {
+		t.Errorf("Should have gotten no gov events: %v", govEvents)
+	}
+
+	contRevs, err := persister.ContentRevisions(address, big.NewInt(0))
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten ErrPersisterNoResults for content revisions: %v", err)
+	}
+	if contRevs != nil {
+		t.Errorf("Should have gotten no content revisions: %v", contRevs)
+	}
+}
@@ -24,6 +24,11 @@ func (n *NullPersister) ListingsByCriteria(criteria *model.ListingCriteria) ([]*
 	return []*model.Listing{}, nil
 }
 
+// ListingsByCriteriaIter returns an iterator over listings by ListingCriteria
+func (n *NullPersister) ListingsByCriteriaIter(criteria *model.ListingCriteria) (model.ListingIterator, error) {
+	return model.NewListingSliceIterator([]*model.Listing{}), nil
+}
+
 // ListingsByAddresses returns a slice of Listings based on addresses
 func (n *NullPersister) ListingsByAddresses(addresses []common.Address) ([]*model.Listing, error) {
 	return []*model.Listing{}, nil
@@ -39,6 +44,11 @@ func (n *NullPersister) ListingsByOwnerAddress(address common.Address) ([]*model
 	return []*model.Listing{}, nil
 }
 
+// ListingsWithRecentGovernanceEvent returns the listings with a recent governance event of the given type
+func (n *NullPersister) ListingsWithRecentGovernanceEvent(eventType string, sinceTs int64) ([]*model.Listing, error) {
+	return []*model.Listing{}, nil
+}
+
 // ListingByCleanedNewsroomURL returns listing that matches given url
 func (n *NullPersister) ListingByCleanedNewsroomURL(cleanedURL string) (*model.Listing, error) {
 	return &model.Listing{}, nil
@@ -59,11 +69,36 @@ func (n *NullPersister) AllListingAddresses() ([]string, error) {
 	return []string{}, nil
 }
 
+// CreateListingStateChange records a change to the state of a listing
+func (n *NullPersister) CreateListingStateChange(change *model.ListingStateChange) error {
+	return nil
+}
+
+// ListingStateHistory gets the state changes for a listing ordered by timestamp
+func (n *NullPersister) ListingStateHistory(address common.Address) ([]*model.ListingStateChange, error) {
+	return []*model.ListingStateChange{}, nil
+}
+
+// ListingStats returns the listing state counts between the given timestamps
+func (n *NullPersister) ListingStats(fromTs int64, beforeTs int64) (*model.ListingStatsResult, error) {
+	return &model.ListingStatsResult{}, nil
+}
+
+// ListingGovernanceState returns the listing with its active challenge, poll and appeal
+func (n *NullPersister) ListingGovernanceState(address common.Address) (*model.ListingGovernance, error) {
+	return &model.ListingGovernance{}, nil
+}
+
 // DeleteListing removes a listing
 func (n *NullPersister) DeleteListing(listing *model.Listing) error {
 	return nil
 }
 
+// MarkListingRemoved marks a listing as removed without deleting it
+func (n *NullPersister) MarkListingRemoved(addr common.Address) error {
+	return nil
+}
+
 // ContentRevisionsByCriteria returns all content revisions by ContentRevisionCriteria
 func (n *NullPersister) ContentRevisionsByCriteria(criteria *model.ContentRevisionCriteria) ([]*model.ContentRevision, error) {
 	return []*model.ContentRevision{}, nil
@@ -74,21 +109,62 @@ func (n *NullPersister) ContentRevisions(address common.Address, contentID *big.
 	return []*model.ContentRevision{}, nil
 }
 
+// ContentRevisionCount returns the number of distinct content items on a listing
+func (n *NullPersister) ContentRevisionCount(address common.Address) (int, error) {
+	return 0, nil
+}
+
+// LatestRevisionsByListing returns the newest revision of each content item on a listing
+func (n *NullPersister) LatestRevisionsByListing(address common.Address) ([]*model.ContentRevision, error) {
+	return []*model.ContentRevision{}, nil
+}
+
 // ContentRevision retrieves a specific content revision for newsroom content
 func (n *NullPersister) ContentRevision(address common.Address, contentID *big.Int, revisionID *big.Int) (*model.ContentRevision, error) {
 	return &model.ContentRevision{}, nil
 }
 
+// ContentRevisionByHash retrieves the earliest content revision with the given hash
+func (n *NullPersister) ContentRevisionByHash(hash string) (*model.ContentRevision, error) {
+	return &model.ContentRevision{}, nil
+}
+
+// ContentRevisionsByHashes retrieves the earliest content revision for each hash
+func (n *NullPersister) ContentRevisionsByHashes(hashes []string) ([]*model.ContentRevision, error) {
+	return []*model.ContentRevision{}, nil
+}
+
 // CreateContentRevision creates a new content revision
 func (n *NullPersister) CreateContentRevision(revision *model.ContentRevision) error {
 	return nil
 }
 
+// ContentRevisionsModifiedAfter returns revisions modified after the timestamp
+func (n *NullPersister) ContentRevisionsModifiedAfter(ts int64, limit int) ([]*model.ContentRevision, error) {
+	return []*model.ContentRevision{}, nil
+}
+
+// MostRecentContentRevision returns the newest content revision
+func (n *NullPersister) MostRecentContentRevision() (*model.ContentRevision, error) {
+	return &model.ContentRevision{}, nil
+}
+
+// ContentRevisionExists returns if the content revision exists
+func (n *NullPersister) ContentRevisionExists(address common.Address, contentID *big.Int,
+	revisionID *big.Int) (bool, error) {
+	return false, nil
+}
+
 // UpdateContentRevision updates fields on an existing content revision
 func (n *NullPersister) UpdateContentRevision(revision *model.ContentRevision, updatedFields []string) error {
 	return nil
 }
 
+// UpsertContentRevision creates or updates a content revision
+func (n *NullPersister) UpsertContentRevision(revision *model.ContentRevision) error {
+	return nil
+}
+
 // DeleteContentRevision removes a content revision
 func (n *NullPersister) DeleteContentRevision(revision *model.ContentRevision) error {
 	return nil
@@ -99,11 +175,29 @@ func (n *NullPersister) GovernanceEventsByTxHash(txHash common.Hash) ([]*model.G
 	return []*model.GovernanceEvent{}, nil
 }
 
+// GovernanceEventBySourceEvent gets the governance event processed from the
+// crawler event with the given tx hash and log index
+func (n *NullPersister) GovernanceEventBySourceEvent(txHash common.Hash, logIndex uint) (*model.GovernanceEvent, error) {
+	return &model.GovernanceEvent{}, nil
+}
+
 // GovernanceEventsByCriteria retrieves governance events based on criteria
 func (n *NullPersister) GovernanceEventsByCriteria(criteria *model.GovernanceEventCriteria) ([]*model.GovernanceEvent, error) {
 	return []*model.GovernanceEvent{}, nil
 }
 
+// GovernanceEventsByCursor retrieves governance events based on criteria and
+// the next cursor
+func (n *NullPersister) GovernanceEventsByCursor(criteria *model.GovernanceEventCriteria) (
+	[]*model.GovernanceEvent, string, error) {
+	return []*model.GovernanceEvent{}, "", nil
+}
+
+// CountGovernanceEventsByCriteria returns the number of governance events matching the criteria
+func (n *NullPersister) CountGovernanceEventsByCriteria(criteria *model.GovernanceEventCriteria) (int, error) {
+	return 0, nil
+}
+
 // GovernanceEventsByListingAddress retrieves governance events based on criteria
 func (n *NullPersister) GovernanceEventsByListingAddress(address common.Address) ([]*model.GovernanceEvent, error) {
 	return []*model.GovernanceEvent{}, nil
@@ -119,11 +213,21 @@ func (n *NullPersister) GovernanceEventsByChallengeIDs(challengeIDs []int) ([]*m
 	return []*model.GovernanceEvent{}, nil
 }
 
+// CreateGovernanceEvents creates new governance events
+func (n *NullPersister) CreateGovernanceEvents(govEvents []*model.GovernanceEvent) error {
+	return nil
+}
+
 // CreateGovernanceEvent creates a new governance event
 func (n *NullPersister) CreateGovernanceEvent(govEvent *model.GovernanceEvent) error {
 	return nil
 }
 
+// UpsertGovernanceEvent creates a new governance event or updates an existing one
+func (n *NullPersister) UpsertGovernanceEvent(govEvent *model.GovernanceEvent) error {
+	return nil
+}
+
 // UpdateGovernanceEvent updates fields on an existing governance event
 func (n *NullPersister) UpdateGovernanceEvent(govEvent *model.GovernanceEvent, updatedFields []string) error {
 	return nil
@@ -154,11 +258,21 @@ func (n *NullPersister) UpdateEventHashesForCron(eventHashes []string) error {
 	return nil
 }
 
+// SetCronTimestamp sets the timestamp and clears the event hashes for the cron
+func (n *NullPersister) SetCronTimestamp(timestamp int64) error {
+	return nil
+}
+
 // ChallengeByChallengeID gets a challenge by challengeID
 func (n *NullPersister) ChallengeByChallengeID(challengeID int) (*model.Challenge, error) {
 	return &model.Challenge{}, nil
 }
 
+// ChallengeByPollID gets the challenge associated with the given pollID
+func (n *NullPersister) ChallengeByPollID(pollID int) (*model.Challenge, error) {
+	return &model.Challenge{}, nil
+}
+
 // ChallengesByChallengeIDs returns a slice of challenges in order based on challenge IDs
 func (n *NullPersister) ChallengesByChallengeIDs(challengeIDs []int) ([]*model.Challenge, error) {
 	return []*model.Challenge{}, nil
@@ -181,6 +295,18 @@ func (n *NullPersister) ChallengesByChallengerAddress(addr common.Address) ([]*m
 	return []*model.Challenge{}, nil
 }
 
+// ResolvedChallengesByTimeRange returns the resolved challenges last updated
+// between fromTs and beforeTs inclusive, sorted by timestamp
+func (n *NullPersister) ResolvedChallengesByTimeRange(fromTs int64,
+	beforeTs int64) ([]*model.Challenge, error) {
+	return []*model.Challenge{}, nil
+}
+
+// OrphanedChallenges returns the challenges for listing addresses that have no listing
+func (n *NullPersister) OrphanedChallenges() ([]*model.Challenge, error) {
+	return []*model.Challenge{}, nil
+}
+
 // CreateChallenge creates a new challenge
 func (n *NullPersister) CreateChallenge(challenge *model.Challenge) error {
 	return nil
@@ -196,11 +322,21 @@ func (n *NullPersister) PollByPollID(pollID int) (*model.Poll, error) {
 	return &model.Poll{}, nil
 }
 
+// PollByChallengeID gets the poll associated with the given challengeID
+func (n *NullPersister) PollByChallengeID(challengeID int) (*model.Poll, error) {
+	return &model.Poll{}, nil
+}
+
 // PollsByPollIDs returns a slice of polls in order based on poll IDs
 func (n *NullPersister) PollsByPollIDs(pollIDs []int) ([]*model.Poll, error) {
 	return []*model.Poll{}, nil
 }
 
+// PollsByPollIDsMap returns a map of found polls keyed by poll ID
+func (n *NullPersister) PollsByPollIDsMap(pollIDs []int) (map[int]*model.Poll, error) {
+	return map[int]*model.Poll{}, nil
+}
+
 // CreatePoll creates a new poll
 func (n *NullPersister) CreatePoll(poll *model.Poll) error {
 	return nil
@@ -211,6 +347,11 @@ func (n *NullPersister) UpdatePoll(poll *model.Poll, updatedFields []string) err
 	return nil
 }
 
+// UpsertPoll creates a new poll or updates an existing poll
+func (n *NullPersister) UpsertPoll(poll *model.Poll) error {
+	return nil
+}
+
 // AppealByChallengeID gets an appeal by challengeID
 func (n *NullPersister) AppealByChallengeID(challengeID int) (*model.Appeal, error) {
 	return &model.Appeal{}, nil
@@ -221,11 +362,21 @@ func (n *NullPersister) AppealByAppealChallengeID(challengeID int) (*model.Appea
 	return &model.Appeal{}, nil
 }
 
+// AppealsByAppealChallengeIDs returns a slice of appeals in order based on appeal challenge IDs
+func (n *NullPersister) AppealsByAppealChallengeIDs(appealChallengeIDs []int) ([]*model.Appeal, error) {
+	return []*model.Appeal{}, nil
+}
+
 // AppealsByChallengeIDs returns a slice of appeals in order based on challenge IDs
 func (n *NullPersister) AppealsByChallengeIDs(challengeIDs []int) ([]*model.Appeal, error) {
 	return []*model.Appeal{}, nil
 }
 
+// AppealWithChallenge gets an appeal by challengeID with its challenge
+func (n *NullPersister) AppealWithChallenge(challengeID int) (*model.AppealWithChallenge, error) {
+	return &model.AppealWithChallenge{}, nil
+}
+
 // CreateAppeal creates a new appeal
 func (n *NullPersister) CreateAppeal(appeal *model.Appeal) error {
 	return nil
@@ -246,6 +397,22 @@ func (n *NullPersister) TokenTransfersByToAddress(addr common.Address) ([]*model
 	return []*model.TokenTransfer{}, nil
 }
 
+// TokenTransfersByBlockRange gets a list of token transfers between the given blocks
+func (n *NullPersister) TokenTransfersByBlockRange(fromBlock uint64, toBlock uint64) ([]*model.TokenTransfer, error) {
+	return []*model.TokenTransfer{}, nil
+}
+
+// TokenTransfersByCriteriaIter returns an iterator over token transfers by TokenTransferCriteria
+func (n *NullPersister) TokenTransfersByCriteriaIter(criteria *model.TokenTransferCriteria) (
+	model.TokenTransferIterator, error) {
+	return model.NewTokenTransferSliceIterator([]*model.TokenTransfer{}), nil
+}
+
+// DailyTokenTransferTotals gets the token transfer totals per day between the given timestamps
+func (n *NullPersister) DailyTokenTransferTotals(fromTs int64, beforeTs int64) ([]*model.DayTotal, error) {
+	return []*model.DayTotal{}, nil
+}
+
 // CreateTokenTransfer creates an token transfer
 func (n *NullPersister) CreateTokenTransfer(appeal *model.TokenTransfer) error {
 	return nil
@@ -261,16 +428,47 @@ func (n *NullPersister) ParametersByName(paramName []string) ([]*model.Parameter
 	return []*model.Parameter{}, nil
 }
 
+// ParametersByNameMap gets a map of parameters keyed by name
+func (n *NullPersister) ParametersByNameMap(paramName []string) (map[string]*model.Parameter, error) {
+	return map[string]*model.Parameter{}, nil
+}
+
+// AllParameters gets all parameters ordered by name
+func (n *NullPersister) AllParameters() ([]*model.Parameter, error) {
+	return []*model.Parameter{}, nil
+}
+
 // UpdateParameter updates the value of a parameter in table
 func (n *NullPersister) UpdateParameter(parameter *model.Parameter, updatedFields []string) error {
 	return nil
 }
 
+// CreateParameterChange records a change to a parameter value
+func (n *NullPersister) CreateParameterChange(change *model.ParameterChange) error {
+	return nil
+}
+
+// ParameterHistory gets the changes to a parameter ordered by timestamp
+func (n *NullPersister) ParameterHistory(paramName string) ([]*model.ParameterChange, error) {
+	return []*model.ParameterChange{}, nil
+}
+
 // CreateDefaultValues creates Parameter default values
 func (n *NullPersister) CreateDefaultValues(config *utils.ProcessorConfig) error {
 	return nil
 }
 
+// UpsertParameterDefaults adds default values for parameters that do not exist yet
+func (n *NullPersister) UpsertParameterDefaults(defaults map[string]string) error {
+	return nil
+}
+
+// UpsertGovernmentParameterDefaults adds default values for government
+// parameters that do not exist yet
+func (n *NullPersister) UpsertGovernmentParameterDefaults(defaults map[string]string) error {
+	return nil
+}
+
 // GovernmentParameterByName gets a parameter from persistence using paramName
 func (n *NullPersister) GovernmentParameterByName(paramName string) (*model.GovernmentParameter, error) {
 	return &model.GovernmentParameter{}, nil
@@ -281,6 +479,16 @@ func (n *NullPersister) GovernmentParametersByName(paramName []string) ([]*model
 	return []*model.GovernmentParameter{}, nil
 }
 
+// GovernmentParametersByNameMap gets a map of parameters keyed by name
+func (n *NullPersister) GovernmentParametersByNameMap(paramName []string) (map[string]*model.GovernmentParameter, error) {
+	return map[string]*model.GovernmentParameter{}, nil
+}
+
+// AllGovernmentParameters gets all government parameters ordered by name
+func (n *NullPersister) AllGovernmentParameters() ([]*model.GovernmentParameter, error) {
+	return []*model.GovernmentParameter{}, nil
+}
+
 // UpdateGovernmentParameter updates the value of a parameter in table
 func (n *NullPersister) UpdateGovernmentParameter(parameter *model.GovernmentParameter, updatedFields []string) error {
 	return nil
@@ -336,6 +544,11 @@ func (n *NullPersister) UserChallengeDataByCriteria(criteria *model.UserChalleng
 	return []*model.UserChallengeData{}, nil
 }
 
+// CountUserChallengeDataByCriteria returns the number of UserChallengeData matching the criteria
+func (n *NullPersister) CountUserChallengeDataByCriteria(criteria *model.UserChallengeDataCriteria) (int, error) {
+	return 0, nil
+}
+
 // UpdateUserChallengeData updates UserChallengeData in table.
 // user=true updates for user + pollID, user=false updates for pollID
 func (n *NullPersister) UpdateUserChallengeData(userChallengeData *model.UserChallengeData, updatedFields []string, updateWithUserAddress bool) error {
@@ -108,3 +108,19 @@ func (a *Appeal) DbToAppealData() *model.Appeal {
 	appeal.SetAppealOpenToChallengeExpiry(big.NewInt(a.AppealOpenToChallengeExpiry))
 	return appeal
 }
+
+// AppealWithChallenge is the model for an appeal joined with its challenge
+type AppealWithChallenge struct {
+	Appeal Appeal `db:"appeal"`
+
+	Challenge Challenge `db:"challenge"`
+}
+
+// DbToAppealWithChallengeData creates a model.AppealWithChallenge from
+// postgres.AppealWithChallenge
+func (a *AppealWithChallenge) DbToAppealWithChallengeData() *model.AppealWithChallenge {
+	return &model.AppealWithChallenge{
+		Appeal:    a.Appeal.DbToAppealData(),
+		Challenge: a.Challenge.DbToChallengeData(),
+	}
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/joincivil/civil-events-processor/pkg/model"
-	"github.com/joincivil/go-common/pkg/numbers"
 )
 
 const (
@@ -36,10 +35,19 @@ func CreateChallengeTableQuery(tableName string) string {
 
 // CreateChallengeTableIndicesQuery returns the query to create indices this table
 func CreateChallengeTableIndicesQuery(tableName string) string {
-	queryString := fmt.Sprintf(`
-		CREATE INDEX IF NOT EXISTS challenge_addr_idx ON %s (listing_address);
-	`, tableName)
-	return queryString
+	return createIndicesQuery(challengeTableIndices(tableName))
+}
+
+// CreateChallengeTableIndicesConcurrentlyQueries returns the queries to create
+// indices for this table concurrently
+func CreateChallengeTableIndicesConcurrentlyQueries(tableName string) []string {
+	return createIndicesConcurrentlyQueries(challengeTableIndices(tableName))
+}
+
+func challengeTableIndices(tableName string) []string {
+	return []string{
+		fmt.Sprintf("challenge_addr_idx ON %s (listing_address)", tableName),
+	}
 }
 
 // Challenge is postgres definition of model.Challenge
@@ -50,15 +58,17 @@ type Challenge struct {
 
 	Statement string `db:"statement"`
 
-	RewardPool float64 `db:"reward_pool"`
+	// RewardPool, Stake and TotalTokens are token amounts in wei, read and
+	// written as NUMERIC strings to avoid losing precision
+	RewardPool string `db:"reward_pool"`
 
 	Challenger string `db:"challenger"`
 
 	Resolved bool `db:"resolved"`
 
-	Stake float64 `db:"stake"`
+	Stake string `db:"stake"`
 
-	TotalTokens float64 `db:"total_tokens"`
+	TotalTokens string `db:"total_tokens"`
 
 	RequestAppealExpiry int64 `db:"request_appeal_expiry"`
 
@@ -76,21 +86,9 @@ func NewChallenge(challengeData *model.Challenge) *Challenge {
 	challenge.Challenger = challengeData.Challenger().Hex()
 	challenge.Resolved = challengeData.Resolved()
 	challenge.LastUpdatedDateTs = challengeData.LastUpdatedDateTs()
-	if challengeData.RewardPool() != nil {
-		challenge.RewardPool = numbers.BigIntToFloat64(challengeData.RewardPool())
-	} else {
-		challenge.RewardPool = 0
-	}
-	if challengeData.Stake() != nil {
-		challenge.Stake = numbers.BigIntToFloat64(challengeData.Stake())
-	} else {
-		challenge.Stake = 0
-	}
-	if challengeData.TotalTokens() != nil {
-		challenge.TotalTokens = numbers.BigIntToFloat64(challengeData.TotalTokens())
-	} else {
-		challenge.TotalTokens = 0
-	}
+	challenge.RewardPool = bigIntToNumeric(challengeData.RewardPool())
+	challenge.Stake = bigIntToNumeric(challengeData.Stake())
+	challenge.TotalTokens = bigIntToNumeric(challengeData.TotalTokens())
 	if challengeData.RequestAppealExpiry() != nil {
 		challenge.RequestAppealExpiry = challengeData.RequestAppealExpiry().Int64()
 	} else {
@@ -104,10 +102,10 @@ func NewChallenge(challengeData *model.Challenge) *Challenge {
 func (c *Challenge) DbToChallengeData() *model.Challenge {
 	challengeID := new(big.Int).SetUint64(c.ChallengeID)
 	listingAddress := common.HexToAddress(c.ListingAddress)
-	rewardPool := numbers.Float64ToBigInt(c.RewardPool)
+	rewardPool := numericToBigInt(c.RewardPool)
 	challenger := common.HexToAddress(c.Challenger)
-	stake := numbers.Float64ToBigInt(c.Stake)
-	totalTokens := numbers.Float64ToBigInt(c.TotalTokens)
+	stake := numericToBigInt(c.Stake)
+	totalTokens := numericToBigInt(c.TotalTokens)
 	return model.NewChallenge(challengeID, listingAddress, c.Statement, rewardPool, challenger, c.Resolved,
 		stake, totalTokens, big.NewInt(c.RequestAppealExpiry), c.ChallengeType, c.LastUpdatedDateTs)
 }
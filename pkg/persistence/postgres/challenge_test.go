@@ -0,0 +1,62 @@
+package postgres_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestChallengeLargeValuesRoundTrip(t *testing.T) {
+	// Above 2^64 and not exactly representable as a float64
+	rewardPool, _ := new(big.Int).SetString("18446744073709551617", 10)
+	stake, _ := new(big.Int).SetString("100000000000000000001", 10)
+	totalTokens, _ := new(big.Int).SetString("9223372036854775809", 10)
+
+	challenge := model.NewChallenge(big.NewInt(10), common.HexToAddress("0x01"), "",
+		rewardPool, common.HexToAddress("0x02"), false, stake, totalTokens,
+		big.NewInt(1231312), model.ChallengePollType, 1212141313)
+
+	dbChallenge := postgres.NewChallenge(challenge)
+	if dbChallenge.Stake != "100000000000000000001" {
+		t.Errorf("Should have stored the exact stake, have %v", dbChallenge.Stake)
+	}
+
+	roundTripped := dbChallenge.DbToChallengeData()
+	if roundTripped.RewardPool().Cmp(rewardPool) != 0 {
+		t.Errorf("Should have round tripped the reward pool, have %v", roundTripped.RewardPool())
+	}
+	if roundTripped.Stake().Cmp(stake) != 0 {
+		t.Errorf("Should have round tripped the stake, have %v", roundTripped.Stake())
+	}
+	if roundTripped.TotalTokens().Cmp(totalTokens) != 0 {
+		t.Errorf("Should have round tripped the total tokens, have %v", roundTripped.TotalTokens())
+	}
+}
+
+func TestChallengeNumericValues(t *testing.T) {
+	dbChallenge := &postgres.Challenge{
+		RewardPool:  "",
+		Stake:       "1E+20",
+		TotalTokens: "232323223232.0",
+	}
+	challenge := dbChallenge.DbToChallengeData()
+	if challenge.RewardPool().Sign() != 0 {
+		t.Errorf("Should have defaulted an empty reward pool to 0, have %v", challenge.RewardPool())
+	}
+	if challenge.Stake().String() != "100000000000000000000" {
+		t.Errorf("Should have parsed the exponent stake, have %v", challenge.Stake())
+	}
+	if challenge.TotalTokens().String() != "232323223232" {
+		t.Errorf("Should have parsed the decimal total tokens, have %v", challenge.TotalTokens())
+	}
+
+	dbChallenge = postgres.NewChallenge(model.NewChallenge(big.NewInt(1), common.Address{}, "",
+		nil, common.Address{}, false, nil, nil, nil, model.ChallengePollType, 0))
+	if dbChallenge.RewardPool != "0" || dbChallenge.Stake != "0" || dbChallenge.TotalTokens != "0" {
+		t.Errorf("Should have stored nil values as 0")
+	}
+}
@@ -34,12 +34,63 @@ func CreateContentRevisionTableQuery(tableName string) string {
 
 // CreateContentRevisionTableIndicesQuery returns the query to create indices for this table
 func CreateContentRevisionTableIndicesQuery(tableName string) string {
+	return createIndicesQuery(contentRevisionTableIndices(tableName))
+}
+
+// CreateContentRevisionTableIndicesConcurrentlyQueries returns the queries to
+// create indices for this table concurrently
+func CreateContentRevisionTableIndicesConcurrentlyQueries(tableName string) []string {
+	return createIndicesConcurrentlyQueries(contentRevisionTableIndices(tableName))
+}
+
+func contentRevisionTableIndices(tableName string) []string {
+	return []string{
+		fmt.Sprintf("revision_addr_type_idx ON %s (listing_address)", tableName),
+		fmt.Sprintf("%s_revision_timestamp_idx ON %s (revision_timestamp)", tableName, tableName),
+	}
+}
+
+// CreateContentRevisionTableMigrationQuery returns the query to do db migrations.
+// Revisions are unique by listing address, content ID and revision ID. Before
+// the unique index exists, replayed revisions may have been saved more than
+// once, so all but the latest row of each revision are deleted first.
+func CreateContentRevisionTableMigrationQuery(tableName string) string {
 	queryString := fmt.Sprintf(`
-        CREATE INDEX IF NOT EXISTS revision_addr_type_idx ON %s (listing_address);
-    `, tableName)
+		CREATE INDEX IF NOT EXISTS %s_payload_title_idx ON %s USING GIN (%s);
+		CREATE INDEX IF NOT EXISTS %s_payload_hash_idx ON %s (article_payload_hash);
+		DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema()
+				AND indexname = '%s') THEN
+				DELETE FROM %s a USING %s b WHERE a.listing_address = b.listing_address
+					AND a.contract_content_id = b.contract_content_id
+					AND a.contract_revision_id = b.contract_revision_id AND a.id < b.id;
+				CREATE UNIQUE INDEX %s ON %s (%s);
+			END IF;
+		END $$;
+	`, tableName, tableName, ContentRevisionTitleSearchVector(""), tableName, tableName,
+		contentRevisionUniqueIndexName(tableName), tableName, tableName,
+		contentRevisionUniqueIndexName(tableName), tableName, ContentRevisionUniqueColumns)
 	return queryString
 }
 
+// ContentRevisionUniqueColumns are the columns that identify a revision
+const ContentRevisionUniqueColumns = "listing_address, contract_content_id, contract_revision_id"
+
+func contentRevisionUniqueIndexName(tableName string) string {
+	return fmt.Sprintf("%s_content_rev_uniq_idx", tableName)
+}
+
+// ContentRevisionTitleSearchVector returns the full-text search vector for
+// the article payload title, prefixed with the given table alias if not empty.
+// Queries need to match this expression to use the title index.
+func ContentRevisionTitleSearchVector(alias string) string {
+	column := "article_payload"
+	if alias != "" {
+		column = fmt.Sprintf("%s.%s", alias, column)
+	}
+	return fmt.Sprintf("to_tsvector('english', coalesce(%s->>'title', ''))", column)
+}
+
 // ContentRevision is the model for content_revision table in db
 // Make IDs strings?
 type ContentRevision struct {
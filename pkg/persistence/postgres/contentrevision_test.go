@@ -0,0 +1,25 @@
+package postgres_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestContentRevisionMigrationUniqueIndex(t *testing.T) {
+	migration := postgres.CreateContentRevisionTableMigrationQuery("content_revision_v1")
+	if strings.Contains(migration, "%!") {
+		t.Fatalf("Should have formatted the migration query: %v", migration)
+	}
+	deleteDuplicates := strings.Index(migration, "DELETE FROM content_revision_v1 a USING content_revision_v1 b")
+	createIndex := strings.Index(migration,
+		"CREATE UNIQUE INDEX content_revision_v1_content_rev_uniq_idx ON content_revision_v1 "+
+			"(listing_address, contract_content_id, contract_revision_id)")
+	if deleteDuplicates < 0 || createIndex < 0 {
+		t.Fatalf("Should have deleted duplicates and created the unique index: %v", migration)
+	}
+	if createIndex < deleteDuplicates {
+		t.Errorf("Should have deleted the duplicate revisions before creating the unique index")
+	}
+}
@@ -23,7 +23,10 @@ func CreateGovernanceEventTableQuery(tableName string) string {
             creation_date INT,
             last_updated_timestamp INT,
             event_hash TEXT UNIQUE,
-            block_data JSONB
+            block_data JSONB,
+            contract_address TEXT,
+            source_tx_hash TEXT,
+            source_log_index BIGINT
         );
     `, tableName)
 	return queryString
@@ -31,10 +34,43 @@ func CreateGovernanceEventTableQuery(tableName string) string {
 
 // CreateGovernanceEventTableIndicesQuery returns the query to create indices for this table
 func CreateGovernanceEventTableIndicesQuery(tableName string) string {
+	return createIndicesQuery(governanceEventTableIndices(tableName))
+}
+
+// CreateGovernanceEventTableIndicesConcurrentlyQueries returns the queries to
+// create indices for this table concurrently
+func CreateGovernanceEventTableIndicesConcurrentlyQueries(tableName string) []string {
+	return createIndicesConcurrentlyQueries(governanceEventTableIndices(tableName))
+}
+
+func governanceEventTableIndices(tableName string) []string {
+	return []string{
+		fmt.Sprintf("govevent_addr_idx ON %s (listing_address)", tableName),
+		fmt.Sprintf("govevent_block_data_idx ON %s USING GIN (block_data)", tableName),
+		fmt.Sprintf("%s_metadata_idx ON %s USING GIN (metadata jsonb_path_ops)", tableName, tableName),
+	}
+}
+
+// CreateGovernanceEventTableMigrationQuery returns the query to do db migrations.
+// Indices on added columns are created here after the column is added, since
+// the migration runs after the table indices are created. The source event
+// backfill sets an empty tx hash on events without one, so only rows that
+// were never backfilled are NULL and each run finds them through the index.
+func CreateGovernanceEventTableMigrationQuery(tableName string) string {
 	queryString := fmt.Sprintf(`
-		CREATE INDEX IF NOT EXISTS govevent_addr_idx ON %s (listing_address);
-		CREATE INDEX IF NOT EXISTS govevent_block_data_idx ON %s USING GIN (block_data);
-	`, tableName, tableName)
+		-- Redundant with the unique event_hash column
+		DROP INDEX IF EXISTS %s_event_tx_log_idx;
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS contract_address TEXT;
+		%s
+		CREATE INDEX IF NOT EXISTS %s_contract_addr_idx ON %s (contract_address);
+		%s
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS source_tx_hash TEXT;
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS source_log_index BIGINT;
+		CREATE INDEX IF NOT EXISTS %s_source_event_idx ON %s (source_tx_hash, source_log_index);
+		UPDATE %s SET source_tx_hash = COALESCE(block_data->>'txHash', ''), source_log_index = (block_data->>'index')::BIGINT WHERE source_tx_hash IS NULL;
+	`, tableName, tableName, dropTableIndexQuery("govevent_contract_addr_idx", tableName),
+		tableName, tableName, dropTableIndexQuery("govevent_metadata_idx", tableName),
+		tableName, tableName, tableName, tableName, tableName)
 	return queryString
 }
 
@@ -49,6 +85,12 @@ func NewGovernanceEvent(governanceEvent *model.GovernanceEvent) *GovernanceEvent
 	govEvent.EventHash = governanceEvent.EventHash()
 	govEvent.BlockData = make(cpostgres.JsonbPayload)
 	govEvent.fillBlockData(governanceEvent.BlockData())
+	blockData := governanceEvent.BlockData()
+	govEvent.SourceTxHash = blockData.TxHash()
+	govEvent.SourceLogIndex = int64(blockData.Index())
+	if governanceEvent.ContractAddress() != (common.Address{}) {
+		govEvent.ContractAddress = governanceEvent.ContractAddress().Hex()
+	}
 	return govEvent
 }
 
@@ -67,6 +109,15 @@ type GovernanceEvent struct {
 	EventHash string `db:"event_hash"`
 
 	BlockData cpostgres.JsonbPayload `db:"block_data"`
+
+	ContractAddress string `db:"contract_address"`
+
+	// SourceTxHash and SourceLogIndex identify the log of the crawler event
+	// this governance event was processed from. EventHash is the hash of that
+	// crawler event.
+	SourceTxHash string `db:"source_tx_hash"`
+
+	SourceLogIndex int64 `db:"source_log_index"`
 }
 
 // DbToGovernanceData creates a model.GovernanceEvent from postgres.GovernanceEvent
@@ -82,8 +133,12 @@ func (ge *GovernanceEvent) DbToGovernanceData() *model.GovernanceEvent {
 	blockHash := common.HexToHash(ge.BlockData["blockHash"].(string))
 	// NOTE: Index is stored in DB as float64
 	index := uint(ge.BlockData["index"].(float64))
-	return model.NewGovernanceEvent(listingAddress, metadata, ge.GovernanceEventType, ge.CreationDateTs,
+	govEvent := model.NewGovernanceEvent(listingAddress, metadata, ge.GovernanceEventType, ge.CreationDateTs,
 		ge.LastUpdatedDateTs, ge.EventHash, blockNumber, txHash, txIndex, blockHash, index)
+	if ge.ContractAddress != "" {
+		govEvent.SetContractAddress(common.HexToAddress(ge.ContractAddress))
+	}
+	return govEvent
 }
 
 func (ge *GovernanceEvent) fillBlockData(blockData model.BlockData) {
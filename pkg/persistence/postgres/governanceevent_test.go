@@ -0,0 +1,55 @@
+package postgres_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestGovernanceEventMigrationIndexAfterColumn(t *testing.T) {
+	query := postgres.CreateGovernanceEventTableMigrationQuery("governance_event_v1")
+	if strings.Contains(query, "%!") {
+		t.Fatalf("Should have formatted the migration query: %v", query)
+	}
+	addColumn := strings.Index(query, "ADD COLUMN IF NOT EXISTS contract_address")
+	createIndex := strings.Index(query, "governance_event_v1_contract_addr_idx ON governance_event_v1 (contract_address)")
+	if addColumn < 0 || createIndex < 0 {
+		t.Fatalf("Should have added the contract address column and index: %v", query)
+	}
+	if createIndex < addColumn {
+		t.Errorf("Should have created the contract address index after adding the column")
+	}
+	if strings.Contains(postgres.CreateGovernanceEventTableIndicesQuery("governance_event_v1"),
+		"contract_address") {
+		t.Errorf("Should not create the contract address index before the migration")
+	}
+}
+
+func TestGovernanceEventMetadataIndexPerTable(t *testing.T) {
+	query := postgres.CreateGovernanceEventTableIndicesQuery("governance_event_v1")
+	if !strings.Contains(query, "governance_event_v1_metadata_idx ON governance_event_v1") {
+		t.Errorf("Should have prefixed the metadata index with the table name: %v", query)
+	}
+	// The index with the name shared across versions is replaced
+	migration := postgres.CreateGovernanceEventTableMigrationQuery("governance_event_v1")
+	if !strings.Contains(migration, "DROP INDEX govevent_metadata_idx") {
+		t.Errorf("Should have dropped the old metadata index: %v", migration)
+	}
+}
+
+func TestGovernanceEventMigrationBackfillMarksRows(t *testing.T) {
+	migration := postgres.CreateGovernanceEventTableMigrationQuery("governance_event_v1")
+	createIndex := strings.Index(migration, "governance_event_v1_source_event_idx")
+	backfill := strings.Index(migration, "UPDATE governance_event_v1 SET source_tx_hash")
+	if createIndex < 0 || backfill < 0 {
+		t.Fatalf("Should have the source event index and backfill: %v", migration)
+	}
+	if backfill < createIndex {
+		t.Errorf("Should have created the index used to find rows to backfill first")
+	}
+	// Rows without a tx hash are marked so they are not backfilled again
+	if !strings.Contains(migration, "COALESCE(block_data->>'txHash', '')") {
+		t.Errorf("Should have marked rows without a tx hash as backfilled: %v", migration)
+	}
+}
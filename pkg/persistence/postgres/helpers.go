@@ -2,6 +2,12 @@ package postgres
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
+
+	log "github.com/golang/glog"
+
+	cpostgres "github.com/joincivil/go-common/pkg/persistence/postgres"
 )
 
 // CheckTableCount returns the query to check the count of the table
@@ -9,3 +15,102 @@ func CheckTableCount(tableName string) string {
 	queryString := fmt.Sprintf(`SELECT COUNT(*) FROM %v`, tableName) // nolint: gosec
 	return queryString
 }
+
+// bigIntToNumeric returns the value as a string for a NUMERIC column, "0" if nil
+func bigIntToNumeric(value *big.Int) string {
+	if value == nil {
+		return "0"
+	}
+	return value.String()
+}
+
+// numericToBigInt parses a NUMERIC column value into a big.Int without losing
+// precision. Values with a fractional part or exponent, such as those written
+// from a float64, are truncated to an integer.
+func numericToBigInt(value string) *big.Int {
+	if value == "" {
+		return big.NewInt(0)
+	}
+	bigInt, ok := new(big.Int).SetString(value, 10)
+	if ok {
+		return bigInt
+	}
+	bigFloat, ok := new(big.Float).SetPrec(512).SetString(value)
+	if !ok {
+		log.Errorf("Unable to parse numeric value: %v", value)
+		return big.NewInt(0)
+	}
+	bigInt, _ = bigFloat.Int(nil)
+	return bigInt
+}
+
+// AliasedFieldsForQuery returns the fields of the given struct qualified by
+// the table alias and renamed with the column prefix, e.g. a.statement AS "appeal.statement".
+// Used to scan joined tables into nested structs with the prefix as the db tag.
+func AliasedFieldsForQuery(exampleStruct interface{}, tableAlias string, columnPrefix string) string {
+	fieldNames, _ := cpostgres.StructFieldsForQuery(exampleStruct, false, "")
+	fields := strings.Split(fieldNames, ", ")
+	for i, field := range fields {
+		fields[i] = fmt.Sprintf(`%s.%s AS "%s.%s"`, tableAlias, field, columnPrefix, field) // nolint: gosec
+	}
+	return strings.Join(fields, ", ")
+}
+
+// createIndicesQuery returns a single query to create all the given indices.
+// Each index definition is in the form "<index name> ON <table> (<columns>)"
+func createIndicesQuery(indexDefs []string) string {
+	var queryBuf strings.Builder
+	for _, indexDef := range indexDefs {
+		queryBuf.WriteString(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s;\n", indexDef)) // nolint: gosec
+	}
+	return queryBuf.String()
+}
+
+// createIndicesConcurrentlyQueries returns a query per index to create the given
+// indices without locking the table. CREATE INDEX CONCURRENTLY cannot be run
+// inside a transaction, so each query needs to be executed individually.
+func createIndicesConcurrentlyQueries(indexDefs []string) []string {
+	queries := make([]string, len(indexDefs))
+	for i, indexDef := range indexDefs {
+		queries[i] = fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s;", indexDef) // nolint: gosec
+	}
+	return queries
+}
+
+// InvalidIndicesQuery returns the query to retrieve the names of any invalid
+// indices on a table, such as those left by a failed CREATE INDEX CONCURRENTLY
+func InvalidIndicesQuery() string {
+	return `SELECT i.relname FROM pg_index x
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_class t ON t.oid = x.indrelid
+		WHERE t.relname = $1 AND NOT x.indisvalid;`
+}
+
+// AdvisoryLockQuery returns the query to take the session advisory lock for
+// the given key, waiting until it is available
+func AdvisoryLockQuery() string {
+	return "SELECT pg_advisory_lock(hashtext($1));"
+}
+
+// AdvisoryUnlockQuery returns the query to release the session advisory lock
+// for the given key
+func AdvisoryUnlockQuery() string {
+	return "SELECT pg_advisory_unlock(hashtext($1));"
+}
+
+// dropTableIndexQuery returns the query to drop the index with the given name
+// only if it is on the given table. Used to replace indices that were created
+// with a name shared by all versions of a table.
+func dropTableIndexQuery(indexName string, tableName string) string {
+	return fmt.Sprintf(`DO $$ BEGIN
+			IF EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema()
+				AND indexname = '%s' AND tablename = '%s') THEN
+				DROP INDEX %s;
+			END IF;
+		END $$;`, indexName, tableName, indexName) // nolint: gosec
+}
+
+// DropIndexConcurrentlyQuery returns the query to drop an index without locking the table
+func DropIndexConcurrentlyQuery(indexName string) string {
+	return fmt.Sprintf(`DROP INDEX CONCURRENTLY IF EXISTS "%s";`, indexName) // nolint: gosec
+}
@@ -3,6 +3,7 @@ package postgres // import "github.com/joincivil/civil-events-processor/pkg/pers
 import (
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 
 	log "github.com/golang/glog"
@@ -18,6 +19,13 @@ const (
 	// ListingTableBaseName is the type of table this code defines
 	ListingTableBaseName = "listing"
 	nilChallengeID       = int64(-1)
+
+	// ListingWhitelistedPredicate matches whitelisted listings. Shared by the
+	// listing queries and the partial index so the planner can use the index.
+	ListingWhitelistedPredicate = "whitelisted = true"
+	// ListingCurrentApplicationPredicate matches listings with an application in
+	// progress. Shared by the listing queries and the partial index.
+	ListingCurrentApplicationPredicate = "app_expiry > 0 AND whitelisted = false AND challenge_id <= 0"
 )
 
 // CreateListingTableQuery returns the query to create the listing table
@@ -40,7 +48,8 @@ func CreateListingTableQuery(tableName string) string {
             app_expiry INT,
             challenge_id INT,
 			unstaked_deposit NUMERIC,
-			cleaned_url TEXT
+			cleaned_url TEXT,
+			removed BOOL NOT NULL DEFAULT false
         );
     `, tableName)
 	return queryString
@@ -48,19 +57,81 @@ func CreateListingTableQuery(tableName string) string {
 
 // CreateListingTableIndicesQuery returns the query to create indices for this table
 func CreateListingTableIndicesQuery(tableName string) string {
-	queryString := fmt.Sprintf(`
-		CREATE INDEX IF NOT EXISTS listing_whitelisted_type_idx ON %s (whitelisted);
-		CREATE INDEX IF NOT EXISTS listing_creation_timestamp_idx ON %s (creation_timestamp);
-		CREATE INDEX IF NOT EXISTS cleaned_url_idx ON %s (cleaned_url)
-	`, tableName, tableName, tableName)
-	return queryString
+	return createIndicesQuery(listingTableIndices(tableName))
+}
+
+// CreateListingTableIndicesConcurrentlyQueries returns the queries to create
+// indices for this table concurrently
+func CreateListingTableIndicesConcurrentlyQueries(tableName string) []string {
+	return createIndicesConcurrentlyQueries(listingTableIndices(tableName))
+}
+
+func listingTableIndices(tableName string) []string {
+	return []string{
+		fmt.Sprintf("listing_whitelisted_type_idx ON %s (whitelisted)", tableName),
+		fmt.Sprintf("listing_creation_timestamp_idx ON %s (creation_timestamp)", tableName),
+		fmt.Sprintf("cleaned_url_idx ON %s (cleaned_url)", tableName),
+		fmt.Sprintf("%s_whitelisted_partial_idx ON %s (creation_timestamp) WHERE %s",
+			tableName, tableName, ListingWhitelistedPredicate),
+		fmt.Sprintf("%s_current_app_partial_idx ON %s (creation_timestamp) WHERE %s",
+			tableName, tableName, ListingCurrentApplicationPredicate),
+		// Supports the case insensitive lookup by owner
+		fmt.Sprintf("%s_owner_lower_idx ON %s (lower(owner))", tableName, tableName),
+		// Support the listing sorts, creation_timestamp uses the index above
+		fmt.Sprintf("%s_name_sort_idx ON %s (name)", tableName, tableName),
+		fmt.Sprintf("%s_application_timestamp_sort_idx ON %s (application_timestamp)",
+			tableName, tableName),
+		fmt.Sprintf("%s_approval_timestamp_sort_idx ON %s (approval_timestamp)",
+			tableName, tableName),
+	}
+}
+
+// ListingSortColumns maps each listing sort type to the column it orders by.
+// Each column needs an index in listingTableIndices, the persister warns on
+// startup about sort columns without one. SortByLastGovernanceActivity is not a listing
+// column, it sorts by ListingLastGovernanceActivityQuery.
+var ListingSortColumns = map[model.SortByType]string{
+	model.SortByUndefined:   "creation_timestamp",
+	model.SortByCreated:     "creation_timestamp",
+	model.SortByName:        "name",
+	model.SortByApplied:     "application_timestamp",
+	model.SortByWhitelisted: "approval_timestamp",
+}
+
+// ListingLastGovernanceActivityQuery returns the subquery for the creation date
+// of the latest governance event of the listing referenced by listingRef, NULL
+// if the listing has no governance events. Uses the governance event listing
+// address index.
+func ListingLastGovernanceActivityQuery(listingRef string, govEventTableName string) string {
+	return fmt.Sprintf( // nolint: gosec
+		"(SELECT max(ge.creation_date) FROM %s ge WHERE ge.listing_address = %s.contract_address)",
+		govEventTableName,
+		listingRef,
+	)
+}
+
+// ListingSortColumnNames returns the distinct listing sort columns in order
+func ListingSortColumnNames() []string {
+	seen := map[string]bool{}
+	columns := []string{}
+	for _, column := range ListingSortColumns {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
 }
 
 // CreateListingTableMigrationQuery returns the query to do db migrations
 func CreateListingTableMigrationQuery(tableName string) string {
 	queryString := fmt.Sprintf(`
 		ALTER TABLE %s ADD COLUMN IF NOT EXISTS cleaned_url TEXT;
-	`, tableName)
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS removed BOOL NOT NULL DEFAULT false;
+		-- Redundant with listing_creation_timestamp_idx
+		DROP INDEX IF EXISTS %s_creation_timestamp_sort_idx;
+	`, tableName, tableName, tableName)
 	return queryString
 }
 
@@ -102,6 +173,8 @@ type Listing struct {
 	ChallengeID int64 `db:"challenge_id"`
 
 	CleanedURL string `db:"cleaned_url"`
+
+	Removed bool `db:"removed"`
 }
 
 // NewListing constructs a listing for DB from a model.Listing
@@ -161,6 +234,7 @@ func NewListing(listing *model.Listing) *Listing {
 		UnstakedDeposit:      unstakedDeposit,
 		ChallengeID:          challengeID,
 		CleanedURL:           listing.CleanedURL(),
+		Removed:              listing.Removed(),
 	}
 }
 
@@ -205,6 +279,23 @@ func (l *Listing) DbToListingData() *model.Listing {
 		UnstakedDeposit:      unstakedDeposit,
 		ChallengeID:          challengeID,
 		CleanedURL:           l.CleanedURL,
+		Removed:              l.Removed,
 	}
 	return model.NewListing(listingParams)
 }
+
+// ListingWithChallengeCount is a Listing scanned with the number of challenges
+// for the listing. The count is not a column of the listing table.
+type ListingWithChallengeCount struct {
+	Listing
+
+	ChallengeCount int `db:"challenge_count"`
+}
+
+// DbToListingData creates a model.Listing with the challenge count from
+// postgres ListingWithChallengeCount
+func (l *ListingWithChallengeCount) DbToListingData() *model.Listing {
+	listing := l.Listing.DbToListingData()
+	listing.SetChallengeCount(l.ChallengeCount)
+	return listing
+}
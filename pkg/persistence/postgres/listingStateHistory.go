@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+const (
+	// ListingStateHistoryTableBaseName is the type of table this code defines
+	ListingStateHistoryTableBaseName = "listing_state_history"
+)
+
+// CreateListingStateHistoryTableQuery returns the query to create this table
+func CreateListingStateHistoryTableQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+        CREATE TABLE IF NOT EXISTS %s(
+            id SERIAL PRIMARY KEY,
+            listing_address TEXT,
+            old_whitelisted BOOLEAN,
+            new_whitelisted BOOLEAN,
+            old_governance_state BIGINT,
+            new_governance_state BIGINT,
+            event_hash TEXT,
+            timestamp INT
+        );
+    `, tableName)
+	return queryString
+}
+
+// CreateListingStateHistoryTableIndicesQuery returns the query to create indices for this table
+func CreateListingStateHistoryTableIndicesQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+        CREATE INDEX IF NOT EXISTS %s_listing_address_idx ON %s (listing_address);
+    `, tableName, tableName)
+	return queryString
+}
+
+// CreateListingStateHistoryTableMigrationQuery returns the query to do db
+// migrations. A state change is unique by the event that made it and the
+// listing. Before the unique index exists, replayed events may have saved a
+// change more than once, so all but the first row of each change are deleted
+// first.
+func CreateListingStateHistoryTableMigrationQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+		DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema()
+				AND indexname = '%s') THEN
+				DELETE FROM %s a USING %s b WHERE a.event_hash = b.event_hash
+					AND a.listing_address = b.listing_address
+					AND a.id > b.id;
+				CREATE UNIQUE INDEX %s ON %s (%s);
+			END IF;
+		END $$;
+	`, listingStateHistoryUniqueIndexName(tableName), tableName, tableName,
+		listingStateHistoryUniqueIndexName(tableName), tableName, ListingStateHistoryUniqueColumns)
+	return queryString
+}
+
+// ListingStateHistoryUniqueColumns are the columns that identify a state change
+const ListingStateHistoryUniqueColumns = "event_hash, listing_address"
+
+func listingStateHistoryUniqueIndexName(tableName string) string {
+	return fmt.Sprintf("%s_event_listing_uniq_idx", tableName)
+}
+
+// ListingStateChange is the model for the listing_state_history table in db
+type ListingStateChange struct {
+	ListingAddress string `db:"listing_address"`
+
+	OldWhitelisted bool `db:"old_whitelisted"`
+
+	NewWhitelisted bool `db:"new_whitelisted"`
+
+	OldGovernanceState int `db:"old_governance_state"`
+
+	NewGovernanceState int `db:"new_governance_state"`
+
+	EventHash string `db:"event_hash"`
+
+	Timestamp int64 `db:"timestamp"`
+}
+
+// NewListingStateChange creates a new postgres ListingStateChange
+func NewListingStateChange(change *model.ListingStateChange) *ListingStateChange {
+	return &ListingStateChange{
+		ListingAddress:     change.ListingAddress().Hex(),
+		OldWhitelisted:     change.OldWhitelisted(),
+		NewWhitelisted:     change.NewWhitelisted(),
+		OldGovernanceState: int(change.OldState()),
+		NewGovernanceState: int(change.NewState()),
+		EventHash:          change.EventHash(),
+		Timestamp:          change.Timestamp(),
+	}
+}
+
+// DbToListingStateChangeData creates a model.ListingStateChange from postgres.ListingStateChange
+func (l *ListingStateChange) DbToListingStateChangeData() *model.ListingStateChange {
+	return model.NewListingStateChange(&model.NewListingStateChangeParams{
+		ListingAddress: common.HexToAddress(l.ListingAddress),
+		OldWhitelisted: l.OldWhitelisted,
+		NewWhitelisted: l.NewWhitelisted,
+		OldState:       model.GovernanceState(l.OldGovernanceState),
+		NewState:       model.GovernanceState(l.NewGovernanceState),
+		EventHash:      l.EventHash,
+		Timestamp:      l.Timestamp,
+	})
+}
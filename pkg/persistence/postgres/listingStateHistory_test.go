@@ -0,0 +1,26 @@
+package postgres_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestListingStateHistoryMigrationUniqueIndex(t *testing.T) {
+	migration := postgres.CreateListingStateHistoryTableMigrationQuery("listing_state_history_v1")
+	if strings.Contains(migration, "%!") {
+		t.Fatalf("Should have formatted the migration query: %v", migration)
+	}
+	deleteDuplicates := strings.Index(migration,
+		"DELETE FROM listing_state_history_v1 a USING listing_state_history_v1 b")
+	createIndex := strings.Index(migration,
+		"CREATE UNIQUE INDEX listing_state_history_v1_event_listing_uniq_idx ON "+
+			"listing_state_history_v1 (event_hash, listing_address)")
+	if deleteDuplicates < 0 || createIndex < 0 {
+		t.Fatalf("Should have deleted duplicates and created the unique index: %v", migration)
+	}
+	if createIndex < deleteDuplicates {
+		t.Errorf("Should have deleted the duplicate changes before creating the unique index")
+	}
+}
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -11,6 +12,7 @@ import (
 	cstrings "github.com/joincivil/go-common/pkg/strings"
 
 	// "reflect"
+	"strings"
 	"testing"
 )
 
@@ -103,9 +105,61 @@ func TestNewDBListingEmptyCharter(t *testing.T) {
 	_ = dbListing.DbToListingData()
 }
 
+func TestNewDBListingZeroChallengeID(t *testing.T) {
+	modelListing, _ := setupSampleListing()
+	// A challenge ID of 0 means the challenge was resolved and must be kept
+	modelListing.SetChallengeID(big.NewInt(0))
+	dbListing := postgres.NewListing(modelListing)
+	if dbListing.ChallengeID != 0 {
+		t.Errorf("Should have kept the 0 challenge ID: %v", dbListing.ChallengeID)
+	}
+	listing := dbListing.DbToListingData()
+	if listing.ChallengeID() == nil || listing.ChallengeID().Int64() != 0 {
+		t.Errorf("Should have converted the 0 challenge ID: %v", listing.ChallengeID())
+	}
+
+	// No challenge ID is stored as -1
+	modelListing.SetChallengeID(nil)
+	dbListing = postgres.NewListing(modelListing)
+	if dbListing.ChallengeID != -1 {
+		t.Errorf("Should have stored a nil challenge ID as -1: %v", dbListing.ChallengeID)
+	}
+}
+
 func TestEqualEmptyJsonB(t *testing.T) {
 	var emptyJsonb cpostgres.JsonbPayload
 	if len(emptyJsonb) != 0 {
 		t.Error("Not equal")
 	}
 }
+
+func TestListingSortColumnsComplete(t *testing.T) {
+	indicesQuery := postgres.CreateListingTableIndicesQuery("listing")
+	for _, sortBy := range model.AllSortByType {
+		if sortBy == model.SortByLastGovernanceActivity {
+			// Sorts by a subquery on the governance event table
+			continue
+		}
+		column, ok := postgres.ListingSortColumns[sortBy]
+		if !ok {
+			t.Errorf("Should have a sort column for sort type %v", sortBy)
+			continue
+		}
+		if !strings.Contains(indicesQuery, fmt.Sprintf("ON listing (%v)", column)) {
+			t.Errorf("Should have an index for sort column %v", column)
+		}
+	}
+	for sortBy := range postgres.ListingSortColumns {
+		if !sortBy.IsValid() {
+			t.Errorf("Should have only mapped valid sort types: %v", sortBy)
+		}
+	}
+	// Only the partial indices share a leading column with another index
+	for _, column := range postgres.ListingSortColumnNames() {
+		index := fmt.Sprintf("ON listing (%v);", column)
+		if strings.Count(indicesQuery, index) != 1 {
+			t.Errorf("Should have exactly one index for sort column %v: %v", column,
+				strings.Count(indicesQuery, index))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/go-common/pkg/bytes"
+)
+
+const (
+	// ParameterHistoryTableBaseName is the type of table this code defines
+	ParameterHistoryTableBaseName = "parameter_history"
+)
+
+// CreateParameterHistoryTableQuery returns the query to create this table
+func CreateParameterHistoryTableQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+        CREATE TABLE IF NOT EXISTS %s(
+            prop_id TEXT PRIMARY KEY,
+            param_name TEXT,
+            old_value NUMERIC,
+            new_value NUMERIC,
+            timestamp INT
+        );
+    `, tableName)
+	return queryString
+}
+
+// CreateParameterHistoryTableIndicesQuery returns the query to create indices for this table
+func CreateParameterHistoryTableIndicesQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+        CREATE INDEX IF NOT EXISTS %s_param_name_idx ON %s (param_name);
+    `, tableName, tableName)
+	return queryString
+}
+
+// ParameterChange is model for parameter history object
+type ParameterChange struct {
+	PropID string `db:"prop_id"`
+
+	ParamName string `db:"param_name"`
+
+	// OldValue and NewValue are strings to keep the precision of NUMERIC values
+	OldValue string `db:"old_value"`
+
+	NewValue string `db:"new_value"`
+
+	Timestamp int64 `db:"timestamp"`
+}
+
+// NewParameterChange creates a new parameter change
+func NewParameterChange(change *model.ParameterChange) *ParameterChange {
+	parameterChange := &ParameterChange{}
+	parameterChange.PropID = bytes.Byte32ToHexString(change.PropID())
+	parameterChange.ParamName = change.ParamName()
+	// Old value may be missing if the parameter had no value before the change
+	parameterChange.OldValue = bigIntToNumeric(change.OldValue())
+	parameterChange.NewValue = bigIntToNumeric(change.NewValue())
+	parameterChange.Timestamp = change.Timestamp()
+
+	return parameterChange
+}
+
+// DbToParameterChangeData creates a model.ParameterChange from postgres.ParameterChange
+func (p *ParameterChange) DbToParameterChangeData() (*model.ParameterChange, error) {
+	propID, err := bytes.HexStringToByte32(p.PropID)
+	if err != nil {
+		return nil, err
+	}
+	change := model.NewParameterChange(
+		p.ParamName,
+		numericToBigInt(p.OldValue),
+		numericToBigInt(p.NewValue),
+		propID,
+		p.Timestamp,
+	)
+
+	return change, nil
+}
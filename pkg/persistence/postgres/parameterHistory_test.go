@@ -0,0 +1,45 @@
+package postgres_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestParameterChangeLargeValuesRoundTrip(t *testing.T) {
+	// Above 2^64 and not exactly representable as a float64
+	oldValue, _ := new(big.Int).SetString("100000000000000000001", 10)
+	newValue, _ := new(big.Int).SetString("18446744073709551617", 10)
+	propID := [32]byte{0x01}
+
+	change := model.NewParameterChange("minDeposit", oldValue, newValue, propID, 1212141313)
+	dbChange := postgres.NewParameterChange(change)
+	if dbChange.OldValue != "100000000000000000001" || dbChange.NewValue != "18446744073709551617" {
+		t.Errorf("Should have stored the exact values, have %v, %v", dbChange.OldValue,
+			dbChange.NewValue)
+	}
+
+	roundTripped, err := dbChange.DbToParameterChangeData()
+	if err != nil {
+		t.Fatalf("Should not have gotten an error converting the change: err: %v", err)
+	}
+	if roundTripped.OldValue().Cmp(oldValue) != 0 {
+		t.Errorf("Should have round tripped the old value, have %v", roundTripped.OldValue())
+	}
+	if roundTripped.NewValue().Cmp(newValue) != 0 {
+		t.Errorf("Should have round tripped the new value, have %v", roundTripped.NewValue())
+	}
+	if roundTripped.PropID() != propID {
+		t.Errorf("Should have round tripped the prop ID, have %v", roundTripped.PropID())
+	}
+}
+
+func TestParameterChangeNoOldValue(t *testing.T) {
+	change := model.NewParameterChange("minDeposit", nil, big.NewInt(100), [32]byte{0x01}, 0)
+	dbChange := postgres.NewParameterChange(change)
+	if dbChange.OldValue != "0" {
+		t.Errorf("Should have stored a missing old value as 0, have %v", dbChange.OldValue)
+	}
+}
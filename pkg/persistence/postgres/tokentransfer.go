@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/joincivil/go-common/pkg/numbers"
@@ -23,12 +25,23 @@ func CreateTokenTransferTableQuery(tableName string) string {
 			from_address TEXT,
 			amount NUMERIC,
 			transfer_date INT,
-			block_data JSONB
+			block_data JSONB,
+			block_number BIGINT
 		);
 	`, tableName)
 	return queryString
 }
 
+// CreateTokenTransferTableMigrationQuery returns the query to do db migrations
+func CreateTokenTransferTableMigrationQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS block_number BIGINT;
+		UPDATE %s SET block_number = (block_data->>'blockNumber')::BIGINT WHERE block_number IS NULL;
+		CREATE INDEX IF NOT EXISTS %s_block_number_idx ON %s (block_number);
+	`, tableName, tableName, tableName, tableName)
+	return queryString
+}
+
 // CreateTokenTransferTableIndicesQuery returns the query to create indices for this table
 func CreateTokenTransferTableIndicesQuery(tableName string) string {
 	queryString := fmt.Sprintf(`
@@ -44,8 +57,10 @@ func NewTokenTransfer(transfer *model.TokenTransfer) *TokenTransfer {
 	dbTransfer.FromAddress = transfer.FromAddress().Hex()
 	dbTransfer.Amount = numbers.BigIntToFloat64(transfer.Amount())
 	dbTransfer.TransferDate = transfer.TransferDate()
+	blockData := transfer.BlockData()
+	dbTransfer.BlockNumber = int64(blockData.BlockNumber())
 	dbTransfer.BlockData = make(cpostgres.JsonbPayload)
-	dbTransfer.fillBlockData(transfer.BlockData())
+	dbTransfer.fillBlockData(blockData)
 	return dbTransfer
 }
 
@@ -60,6 +75,8 @@ type TokenTransfer struct {
 	TransferDate int64 `db:"transfer_date"`
 
 	BlockData cpostgres.JsonbPayload `db:"block_data"`
+
+	BlockNumber int64 `db:"block_number"`
 }
 
 // DbToTokenTransfer creates a model.TokenTransfer from a postgres.TokenTransfer
@@ -81,6 +98,28 @@ func (t *TokenTransfer) DbToTokenTransfer() *model.TokenTransfer {
 	return model.NewTokenTransfer(params)
 }
 
+// TokenTransferDayTotal is the postgres definition of a model.DayTotal
+type TokenTransferDayTotal struct {
+	Day time.Time `db:"day"`
+
+	Total string `db:"total"`
+
+	Count int `db:"count"`
+}
+
+// DbToDayTotal creates a model.DayTotal from a postgres.TokenTransferDayTotal
+func (t *TokenTransferDayTotal) DbToDayTotal() (*model.DayTotal, error) {
+	total, ok := new(big.Int).SetString(t.Total, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid day total: %v", t.Total)
+	}
+	return &model.DayTotal{
+		Day:   t.Day.UTC(),
+		Total: total,
+		Count: t.Count,
+	}, nil
+}
+
 func (t *TokenTransfer) fillBlockData(blockData model.BlockData) {
 	t.BlockData["blockNumber"] = blockData.BlockNumber()
 	t.BlockData["txHash"] = blockData.TxHash()
@@ -56,6 +56,36 @@ func UserChallengeDataTableIndicesQuery(tableName string) string {
 	return queryString
 }
 
+// CreateUserChallengeDataTableMigrationQuery returns the query to do db
+// migrations. A committed vote is unique by poll ID, user address and commit
+// timestamp. Before the unique index exists, replayed commits may have been
+// saved more than once, so all but one row of each commit are deleted first,
+// keeping the latest vote.
+func CreateUserChallengeDataTableMigrationQuery(tableName string) string {
+	queryString := fmt.Sprintf(`
+		DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema()
+				AND indexname = '%s') THEN
+				DELETE FROM %s a USING %s b WHERE a.poll_id = b.poll_id
+					AND a.user_address = b.user_address
+					AND a.vote_committed_timestamp = b.vote_committed_timestamp
+					AND (a.latest_vote < b.latest_vote
+						OR (a.latest_vote = b.latest_vote AND a.ctid < b.ctid));
+				CREATE UNIQUE INDEX %s ON %s (%s);
+			END IF;
+		END $$;
+	`, userChallengeDataUniqueIndexName(tableName), tableName, tableName,
+		userChallengeDataUniqueIndexName(tableName), tableName, UserChallengeDataUniqueColumns)
+	return queryString
+}
+
+// UserChallengeDataUniqueColumns are the columns that identify a committed vote
+const UserChallengeDataUniqueColumns = "poll_id, user_address, vote_committed_timestamp"
+
+func userChallengeDataUniqueIndexName(tableName string) string {
+	return fmt.Sprintf("%s_user_vote_uniq_idx", tableName)
+}
+
 // UserChallengeData is the postgres definition of model.UserChallengeData
 type UserChallengeData struct {
 	PollID            uint64  `db:"poll_id"`
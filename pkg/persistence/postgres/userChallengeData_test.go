@@ -0,0 +1,29 @@
+package postgres_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestUserChallengeDataMigrationUniqueIndex(t *testing.T) {
+	migration := postgres.CreateUserChallengeDataTableMigrationQuery("user_challenge_data_v1")
+	if strings.Contains(migration, "%!") {
+		t.Fatalf("Should have formatted the migration query: %v", migration)
+	}
+	deleteDuplicates := strings.Index(migration,
+		"DELETE FROM user_challenge_data_v1 a USING user_challenge_data_v1 b")
+	createIndex := strings.Index(migration,
+		"CREATE UNIQUE INDEX user_challenge_data_v1_user_vote_uniq_idx ON user_challenge_data_v1 "+
+			"(poll_id, user_address, vote_committed_timestamp)")
+	if deleteDuplicates < 0 || createIndex < 0 {
+		t.Fatalf("Should have deleted duplicates and created the unique index: %v", migration)
+	}
+	if createIndex < deleteDuplicates {
+		t.Errorf("Should have deleted the duplicate votes before creating the unique index")
+	}
+	if !strings.Contains(migration, "a.latest_vote < b.latest_vote") {
+		t.Errorf("Should have kept the latest vote of the duplicate votes")
+	}
+}
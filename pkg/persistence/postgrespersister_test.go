@@ -7,20 +7,25 @@ package persistence
 
 import (
 	"bytes"
+	"context"
+	goerrors "errors"
 	"fmt"
 	"math"
 	"math/big"
 	mathrand "math/rand"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 
 	"github.com/joincivil/civil-events-processor/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
 	"github.com/joincivil/civil-events-processor/pkg/testutils"
+	"github.com/joincivil/civil-events-processor/pkg/utils"
 
 	crawlerPostgres "github.com/joincivil/civil-events-crawler/pkg/persistence/postgres"
 
@@ -58,7 +63,7 @@ func setupDBConnection(t *testing.T) *PostgresPersister {
 	creds := testutils.GetTestDBCreds()
 
 	postgresPersister, err := NewPostgresPersister(creds.Host, creds.Port, creds.User,
-		creds.Password, creds.Dbname, nil, nil, nil)
+		creds.Password, creds.Dbname, nil, nil, nil, nil)
 	if err != nil {
 		t.Errorf("Error setting up new persister: err: %v", err)
 	}
@@ -66,6 +71,73 @@ func setupDBConnection(t *testing.T) *PostgresPersister {
 	return postgresPersister
 }
 
+func TestSlowQueryThreshold(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+
+	logged := []string{}
+	defer func(logf func(string, ...interface{})) { slowQueryLogf = logf }(slowQueryLogf)
+	slowQueryLogf = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	// Disabled by default
+	_, err := persister.db.Exec("SELECT pg_sleep(0.05);")
+	if err != nil {
+		t.Fatalf("Should not have gotten error running query: err: %v", err)
+	}
+	if len(logged) != 0 {
+		t.Errorf("Should not have logged a slow query when disabled: %v", logged)
+	}
+
+	persister.SetSlowQueryThreshold(time.Millisecond)
+	_, err = persister.db.Exec("SELECT pg_sleep(0.05);")
+	if err != nil {
+		t.Fatalf("Should not have gotten error running query: err: %v", err)
+	}
+	if len(logged) != 1 {
+		t.Fatalf("Should have logged 1 slow query: %v", logged)
+	}
+	if !strings.Contains(logged[0], "TestSlowQueryThreshold") {
+		t.Errorf("Should have logged the calling method name: %v", logged[0])
+	}
+
+	// Pinned persisters share the threshold
+	var slept string
+	err = persister.WithVersion("f").db.Get(&slept, "SELECT pg_sleep(0.05)::TEXT;")
+	if err != nil {
+		t.Fatalf("Should not have gotten error running query: err: %v", err)
+	}
+	if len(logged) != 2 {
+		t.Errorf("Should have logged a slow query from the pinned persister: %v", logged)
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	creds := testutils.GetTestDBCreds()
+	maxConns := 3
+	persister, err := NewPostgresPersister(creds.Host, creds.Port, creds.User,
+		creds.Password, creds.Dbname, &maxConns, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Error setting up new persister: err: %v", err)
+	}
+	defer persister.Close()
+
+	openBefore := persister.db.Stats().OpenConnections
+	err = persister.WarmUp(context.Background(), maxConns+2)
+	if err != nil {
+		t.Fatalf("Error warming up persister: err: %v", err)
+	}
+	openAfter := persister.db.Stats().OpenConnections
+	if openAfter <= openBefore {
+		t.Errorf("Should have increased open conns after warm up: before: %v, after: %v",
+			openBefore, openAfter)
+	}
+	if openAfter > maxConns {
+		t.Errorf("Should not have opened more than max conns: %v, opened: %v", maxConns, openAfter)
+	}
+}
+
 func setupTestTable(t *testing.T, tableName string) *PostgresPersister {
 	persister := setupDBConnection(t)
 	version := "f"
@@ -91,7 +163,8 @@ func setupTestTable(t *testing.T, tableName string) *PostgresPersister {
 	case "parameter_proposal_test":
 		queryString = postgres.CreateParameterProposalTableQuery(persister.GetTableName(tableName))
 	case "user_challenge_data_test":
-		queryString = postgres.CreateUserChallengeDataTableQuery(persister.GetTableName(tableName))
+		queryString = postgres.CreateUserChallengeDataTableQuery(persister.GetTableName(tableName)) +
+			postgres.CreateUserChallengeDataTableMigrationQuery(persister.GetTableName(tableName))
 	case "parameter_test":
 		queryString = postgres.CreateParameterTableQuery(persister.GetTableName(tableName))
 	case "government_parameter_test":
@@ -162,7 +235,8 @@ func setupAllTestTables(t *testing.T, persister *PostgresPersister) {
 		t.Errorf("Couldn't create test table %s: %v", parameterProposalTestTableName, err)
 	}
 
-	queryString = postgres.CreateUserChallengeDataTableQuery(persister.GetTableName(userChallengeDataTestTableName))
+	queryString = postgres.CreateUserChallengeDataTableQuery(persister.GetTableName(userChallengeDataTestTableName)) +
+		postgres.CreateUserChallengeDataTableMigrationQuery(persister.GetTableName(userChallengeDataTestTableName))
 	_, err = persister.db.Exec(queryString)
 	if err != nil {
 		t.Errorf("Couldn't create test table %s: %v", userChallengeDataTestTableName, err)
@@ -299,6 +373,63 @@ func TestDBConnection(t *testing.T) {
 	}
 }
 
+func TestPoolStats(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	defer deleteTestVersionTable(t, persister)
+
+	stats := persister.PoolStats()
+	if stats.MaxOpenConnections != maxOpenConns {
+		t.Errorf("Should have had the default max open conns: %v", stats.MaxOpenConnections)
+	}
+	if stats.InUse != 0 {
+		t.Errorf("Should have had no connections in use: %v", stats.InUse)
+	}
+	if stats.OpenConnections > maxOpenConns {
+		t.Errorf("Should not have more open conns than max: %v", stats.OpenConnections)
+	}
+	if stats.WaitCount != 0 {
+		t.Errorf("Should have had no waits on a fresh persister: %v", stats.WaitCount)
+	}
+
+	nilPersister := &PostgresPersister{}
+	stats = nilPersister.PoolStats()
+	if stats.OpenConnections != 0 || stats.MaxOpenConnections != 0 {
+		t.Errorf("Should have returned zero value stats with no db")
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	persister := setupDBConnection(t)
+	err := persister.Close()
+	if err != nil {
+		t.Errorf("Should not have gotten error closing persister: err: %v", err)
+	}
+	err = persister.Close()
+	if err != nil {
+		t.Errorf("Should not have gotten error closing persister twice: err: %v", err)
+	}
+}
+
+func TestPoolStatsConfigured(t *testing.T) {
+	creds := testutils.GetTestDBCreds()
+	maxConns := 12
+	maxIdle := 3
+	connLife := 60
+
+	persister, err := NewPostgresPersister(creds.Host, creds.Port, creds.User,
+		creds.Password, creds.Dbname, &maxConns, &maxIdle, &connLife, nil)
+	if err != nil {
+		t.Fatalf("Error setting up new persister: err: %v", err)
+	}
+	defer persister.Close()
+
+	stats := persister.PoolStats()
+	if stats.MaxOpenConnections != maxConns {
+		t.Errorf("Should have had the configured max open conns: %v", stats.MaxOpenConnections)
+	}
+}
+
 func TestTableSetup(t *testing.T) {
 	// run function to create tables, and test table exists
 	persister := setupDBConnection(t)
@@ -330,6 +461,313 @@ func TestTableSetup(t *testing.T) {
 	deleteTestVersionTable(t, persister)
 }
 
+func TestAllVersions(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	defer deleteTestVersionTable(t, persister)
+
+	versions, err := persister.allVersionsFromTable(versionTestTableName)
+	if err != nil {
+		t.Errorf("Error getting all versions: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("Should have retrieved no versions, retrieved %v", len(versions))
+	}
+
+	seed := []struct {
+		version     string
+		serviceName string
+		ts          int64
+		exists      bool
+	}{
+		{"v3", ProcessorServiceName, 300, true},
+		{"v1", ProcessorServiceName, 100, false},
+		{"v2", ProcessorServiceName, 200, false},
+		{"crawler1", "crawler", 150, true},
+	}
+	for _, s := range seed {
+		version := s.version
+		dbVersion := crawlerPostgres.Version{
+			Version:           &version,
+			ServiceName:       s.serviceName,
+			LastUpdatedDateTs: s.ts,
+			Exists:            s.exists,
+		}
+		_, err = persister.db.NamedExec(
+			persister.insertIntoDBQueryString(versionTestTableName, crawlerPostgres.Version{}),
+			dbVersion,
+		)
+		if err != nil {
+			t.Fatalf("Error saving version: %v", err)
+		}
+	}
+
+	versions, err = persister.allVersionsFromTable(versionTestTableName)
+	if err != nil {
+		t.Errorf("Error getting all versions: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("Should have retrieved 3 processor versions, retrieved %v", len(versions))
+	}
+	for index, expected := range []string{"v1", "v2", "v3"} {
+		if *versions[index].Version != expected {
+			t.Errorf("Should have retrieved version %v at %v, got %v", expected, index,
+				*versions[index].Version)
+		}
+		if versions[index].ServiceName != ProcessorServiceName {
+			t.Errorf("Should have only retrieved processor versions")
+		}
+	}
+	if versions[0].Exists || !versions[2].Exists {
+		t.Errorf("Should have retrieved the exists field of the versions")
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	versionNo := "createtable"
+	persister.version = &versionNo
+
+	err := persister.CreateTable(postgres.TokenTransferTableBaseName)
+	if err != nil {
+		t.Fatalf("Error creating table: %v", err)
+	}
+	defer deleteTestTable(t, persister, persister.GetTableName(postgres.TokenTransferTableBaseName))
+	checkTableExists(t, postgres.TokenTransferTableBaseName, persister)
+
+	// Only the requested table should have been created
+	var exists bool
+	queryString := fmt.Sprintf(`SELECT EXISTS ( SELECT 1
+        FROM   information_schema.tables
+        WHERE  table_schema = 'public'
+        AND    table_name = '%s'
+        );`, persister.GetTableName(postgres.ListingTableBaseName))
+	err = persister.db.QueryRow(queryString).Scan(&exists)
+	if err != nil {
+		t.Errorf("Couldn't query for listing table: %v", err)
+	}
+	if exists {
+		t.Errorf("Listing table should not have been created")
+	}
+
+	// Should be a no-op if the table already exists
+	err = persister.CreateTable(postgres.TokenTransferTableBaseName)
+	if err != nil {
+		t.Errorf("Error creating existing table: %v", err)
+	}
+
+	err = persister.CreateTable("not_a_table")
+	if err == nil {
+		t.Errorf("Should have received an error for an unknown table")
+	}
+}
+
+func TestCreateIndicesConcurrently(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	versionNo := "concurrentidx"
+	persister.version = &versionNo
+
+	baseNames := []string{
+		postgres.ContentRevisionTableBaseName,
+		postgres.GovernanceEventTableBaseName,
+		postgres.ListingTableBaseName,
+		postgres.ChallengeTableBaseName,
+		// Remaining tables with indices created non-concurrently
+		postgres.UserChallengeDataTableBaseName,
+		postgres.AppealTableBaseName,
+		postgres.TokenTransferTableBaseName,
+		postgres.MultiSigOwnerTableBaseName,
+		postgres.ParameterHistoryTableBaseName,
+		postgres.ListingStateHistoryTableBaseName,
+	}
+	for _, baseName := range baseNames {
+		err := persister.CreateTable(baseName)
+		if err != nil {
+			t.Fatalf("Error creating table: %v", err)
+		}
+		defer deleteTestTable(t, persister, persister.GetTableName(baseName))
+	}
+
+	// Leave behind an invalid index from a failed concurrent build
+	listingTableName := persister.GetTableName(postgres.ListingTableBaseName)
+	for i := 0; i < 2; i++ {
+		listing, _ := setupSampleListing()
+		err := persister.createListingForTable(listing, listingTableName)
+		if err != nil {
+			t.Fatalf("Error creating listing: %v", err)
+		}
+	}
+	_, err := persister.db.Exec(fmt.Sprintf( // nolint: gosec
+		"CREATE UNIQUE INDEX CONCURRENTLY %s_failed_idx ON %s ((1));",
+		listingTableName, listingTableName,
+	))
+	if err == nil {
+		t.Fatalf("Should have failed to create unique index")
+	}
+	invalid, err := persister.invalidIndices(listingTableName)
+	if err != nil {
+		t.Fatalf("Error retrieving invalid indices: %v", err)
+	}
+	if len(invalid) != 1 {
+		t.Fatalf("Should have 1 invalid index, have %v", len(invalid))
+	}
+
+	// Should wait for another processor creating the indices before dropping
+	// what may be its in progress builds
+	unlock, err := persister.advisoryLock(listingTableName)
+	if err != nil {
+		t.Fatalf("Error taking advisory lock: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- persister.CreateIndicesConcurrently()
+	}()
+	time.Sleep(500 * time.Millisecond)
+	invalid, err = persister.invalidIndices(listingTableName)
+	if err != nil {
+		t.Fatalf("Error retrieving invalid indices: %v", err)
+	}
+	if len(invalid) != 1 {
+		t.Errorf("Should not have dropped invalid index while locked, have %v", len(invalid))
+	}
+	unlock()
+
+	err = <-done
+	if err != nil {
+		t.Fatalf("Error creating indices concurrently: %v", err)
+	}
+	// Should be a no-op the second time around
+	err = persister.CreateIndicesConcurrently()
+	if err != nil {
+		t.Errorf("Error creating existing indices concurrently: %v", err)
+	}
+
+	for _, baseName := range baseNames {
+		tableName := persister.GetTableName(baseName)
+		invalid, err = persister.invalidIndices(tableName)
+		if err != nil {
+			t.Errorf("Error retrieving invalid indices: %v", err)
+		}
+		if len(invalid) != 0 {
+			t.Errorf("Should have no invalid indices on %v, have %v", tableName, invalid)
+		}
+		var numIndices int
+		err = persister.db.QueryRow(
+			"SELECT COUNT(*) FROM pg_indexes WHERE tablename = $1;",
+			tableName,
+		).Scan(&numIndices)
+		if err != nil {
+			t.Errorf("Error counting indices: %v", err)
+		}
+		if numIndices == 0 {
+			t.Errorf("Should have created indices on %v", tableName)
+		}
+	}
+}
+
+func TestWithVersion(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+
+	versions := []string{"readv1", "readv2"}
+	listingAddresses := []common.Address{}
+	for _, version := range versions {
+		versionNo := version
+		persister.version = &versionNo
+		err := persister.CreateTable(postgres.ListingTableBaseName)
+		if err != nil {
+			t.Fatalf("Error creating table: %v", err)
+		}
+		defer deleteTestTable(t, persister, persister.GetTableName(postgres.ListingTableBaseName))
+
+		listing, listingAddress := setupSampleListing()
+		err = persister.CreateListing(listing)
+		if err != nil {
+			t.Fatalf("Error creating listing: %v", err)
+		}
+		listingAddresses = append(listingAddresses, listingAddress)
+	}
+	persister.version = nil
+
+	for i, version := range versions {
+		pinned := persister.WithVersion(version)
+		if pinned.GetTableName(postgres.ListingTableBaseName) != fmt.Sprintf("listing_%v", version) {
+			t.Errorf("Wrong table name for pinned version: %v",
+				pinned.GetTableName(postgres.ListingTableBaseName))
+		}
+		listing, err := pinned.ListingByAddress(listingAddresses[i])
+		if err != nil {
+			t.Errorf("Error getting listing from version %v: %v", version, err)
+		}
+		if listing != nil && listing.ContractAddress() != listingAddresses[i] {
+			t.Errorf("Wrong listing returned from version %v", version)
+		}
+		// Listing from the other version should not be found
+		_, err = pinned.ListingByAddress(listingAddresses[(i+1)%len(versions)])
+		if err != cpersist.ErrPersisterNoResults {
+			t.Errorf("Should not have found listing from other version: %v", err)
+		}
+
+		versionNo := "other"
+		err = pinned.SaveVersion(&versionNo)
+		if err != ErrPinnedVersion {
+			t.Errorf("Should have gotten pinned version error on save: %v", err)
+		}
+		err = pinned.InitProcessorVersion(&versionNo)
+		if err != ErrPinnedVersion {
+			t.Errorf("Should have gotten pinned version error on init: %v", err)
+		}
+	}
+	if persister.version != nil {
+		t.Errorf("Should not have changed the version of the original persister")
+	}
+}
+
+// TestReadRecoversFromBrokenConnection tests that a read transparently retries
+// on a new connection after the pooled connection is terminated
+func TestReadRecoversFromBrokenConnection(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	// Use a single connection so the terminated connection is the pooled one
+	persister.db.SetMaxOpenConns(1)
+
+	versionNo := "reconnect"
+	persister.version = &versionNo
+	err := persister.CreateTable(postgres.ListingTableBaseName)
+	if err != nil {
+		t.Fatalf("Error creating table: %v", err)
+	}
+	defer deleteTestTable(t, persister, persister.GetTableName(postgres.ListingTableBaseName))
+
+	modelListing, listingAddress := setupSampleListing()
+	err = persister.CreateListing(modelListing)
+	if err != nil {
+		t.Fatalf("Error creating listing: %v", err)
+	}
+
+	var pid int
+	err = persister.db.Get(&pid, "SELECT pg_backend_pid();")
+	if err != nil {
+		t.Fatalf("Error getting backend pid: %v", err)
+	}
+	killer := setupDBConnection(t)
+	defer killer.Close()
+	_, err = killer.db.Exec("SELECT pg_terminate_backend($1);", pid)
+	if err != nil {
+		t.Fatalf("Error terminating backend: %v", err)
+	}
+
+	listing, err := persister.ListingByAddress(listingAddress)
+	if err != nil {
+		t.Fatalf("Should have recovered from the broken connection: %v", err)
+	}
+	if listing.ContractAddress() != listingAddress {
+		t.Errorf("Should have returned the listing: %v", listing.ContractAddress().Hex())
+	}
+}
+
 /*
 Helpers for listing table tests:
 */
@@ -863,6 +1301,79 @@ func TestUpdateListing(t *testing.T) {
 
 }
 
+// TestUpdateListingClearChallengeID tests that updating the ChallengeID of a
+// listing to 0 after the challenge resolves is persisted and not skipped as
+// an empty value
+func TestUpdateListingClearChallengeID(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(listingTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	modelListing, modelListingAddress := setupSampleListing()
+	err := persister.createListingForTable(modelListing, tableName)
+	if err != nil {
+		t.Fatalf("error saving listing: %v", err)
+	}
+	dbListing, err := persister.listingByAddressFromTable(modelListingAddress, tableName)
+	if err != nil {
+		t.Fatalf("Wasn't able to get listing from postgres table: %v", err)
+	}
+	if dbListing.ChallengeID().Int64() != 10 {
+		t.Fatalf("Should have saved the challenge ID: %v", dbListing.ChallengeID())
+	}
+
+	modelListing.SetChallengeID(big.NewInt(0))
+	err = persister.updateListingInTable(modelListing, []string{"ChallengeID"}, tableName)
+	if err != nil {
+		t.Fatalf("Error updating fields: %v", err)
+	}
+
+	dbListing, err = persister.listingByAddressFromTable(modelListingAddress, tableName)
+	if err != nil {
+		t.Fatalf("Wasn't able to get listing from postgres table: %v", err)
+	}
+	if dbListing.ChallengeID() == nil || dbListing.ChallengeID().Int64() != 0 {
+		t.Errorf("ChallengeID field was not updated to 0: %v", dbListing.ChallengeID())
+	}
+	if dbListing.Name() != modelListing.Name() {
+		t.Errorf("Should not have updated other fields: %v", dbListing.Name())
+	}
+}
+
+func TestUpdateListingNoRowsAffected(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(listingTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// Never saved to the table
+	modelListing, modelListingAddress := setupSampleListing()
+
+	err := persister.updateListingInTable(modelListing, []string{"Name"}, tableName)
+	if err == nil {
+		t.Fatalf("Should have received an error updating a missing listing")
+	}
+	if !goerrors.Is(err, ErrNoRowsAffected) {
+		t.Errorf("Error should match ErrNoRowsAffected: %v", err)
+	}
+	noRowsErr, ok := err.(*UpdateNoRowsError)
+	if !ok {
+		t.Fatalf("Error should be an UpdateNoRowsError: %T", err)
+	}
+	if noRowsErr.Table != tableName {
+		t.Errorf("Error table should be %v but is %v", tableName, noRowsErr.Table)
+	}
+	if noRowsErr.Key != modelListingAddress.Hex() {
+		t.Errorf("Error key should be %v but is %v", modelListingAddress.Hex(), noRowsErr.Key)
+	}
+	if !strings.Contains(err.Error(), modelListingAddress.Hex()) {
+		t.Errorf("Error string should contain the key: %v", err.Error())
+	}
+}
+
 // TestDeleteListing tests that the deleting the Listing works
 func TestDeleteListing(t *testing.T) {
 
@@ -905,31 +1416,125 @@ func TestDeleteListing(t *testing.T) {
 	}
 }
 
-func TestListingByCriteriaSorts(t *testing.T) {
-	tableBaseName := "listing_test"
-	joinTableBaseName := "challenge_test"
-	persister := setupTestTable(t, tableBaseName)
+func TestMarkListingRemoved(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
 	defer persister.Close()
-	tableName := persister.GetTableName(tableBaseName)
-	persister2 := setupTestTable(t, joinTableBaseName)
-	persister2.Close()
-	joinTableName := persister.GetTableName(joinTableBaseName)
+	tableName := persister.GetTableName(listingTestTableName)
 
 	defer deleteTestTable(t, persister, tableName)
-	defer deleteTestTable(t, persister, joinTableName)
 
-	now := ctime.CurrentEpochSecsInInt64()
+	listing1, listingAddr1 := setupSampleListing()
+	listing2, listingAddr2 := setupSampleListing()
+	listing1.SetLastUpdatedDateTs(0)
+	for _, listing := range []*model.Listing{listing1, listing2} {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Fatalf("error saving listing: %v", err)
+		}
+	}
 
-	listing1, _ := setupSampleListing()
-	listing1.SetName("Test Listing E")
-	listing1.SetApprovalDateTs(now + int64(3))
-	listing1.SetApplicationDateTs(now + int64(3))
-	listing1.SetWhitelisted(true)
+	err := persister.markListingRemovedInTable(listingAddr1, tableName)
+	if err != nil {
+		t.Fatalf("Error marking listing removed: %v", err)
+	}
+	listingFromDB, err := persister.listingByAddressFromTable(listingAddr1, tableName)
+	if err != nil {
+		t.Fatalf("Error getting removed listing: %v", err)
+	}
+	if !listingFromDB.Removed() {
+		t.Errorf("Should have marked the listing removed")
+	}
+	if listingFromDB.LastUpdatedDateTs() == 0 {
+		t.Errorf("Should have updated the last updated timestamp")
+	}
 
-	listing2, _ := setupSampleListing()
-	listing2.SetName("Test Listing C")
-	listing2.SetApprovalDateTs(0)
-	listing2.SetApplicationDateTs(0)
+	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{},
+		tableName, "", "", "")
+	if err != nil {
+		t.Fatalf("Error getting listings by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 1 || listingsFromDB[0].ContractAddress() != listingAddr2 {
+		t.Errorf("Should have excluded the removed listing by default")
+	}
+
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		IncludeRemoved: true,
+	}, tableName, "", "", "")
+	if err != nil {
+		t.Fatalf("Error getting listings by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Should have included the removed listing, got %v listings", len(listingsFromDB))
+	}
+
+	address, _ := cstrings.RandomHexStr(32)
+	err = persister.markListingRemovedInTable(common.HexToAddress(address), tableName)
+	if _, ok := err.(*UpdateNoRowsError); !ok {
+		t.Errorf("Should have gotten an UpdateNoRowsError for a missing listing: err: %v", err)
+	}
+}
+
+func TestListingTableMigrationRemoved(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	tableName := "listing_migration_test"
+	defer deleteTestTable(t, persister, tableName)
+
+	// Create the table as it was before the removed column
+	_, err := persister.db.Exec(postgres.CreateListingTableQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error creating listing table: %v", err)
+	}
+	_, err = persister.db.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN removed;", tableName))
+	if err != nil {
+		t.Fatalf("Error dropping removed column: %v", err)
+	}
+	_, err = persister.db.Exec(fmt.Sprintf(
+		"INSERT INTO %s (contract_address, name) VALUES ('0x1', 'old listing');", tableName))
+	if err != nil {
+		t.Fatalf("Error inserting listing: %v", err)
+	}
+
+	_, err = persister.db.Exec(postgres.CreateListingTableMigrationQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error running listing migration: %v", err)
+	}
+	var removed bool
+	err = persister.db.QueryRow(fmt.Sprintf(
+		"SELECT removed FROM %s WHERE contract_address = '0x1';", tableName)).Scan(&removed)
+	if err != nil {
+		t.Fatalf("Error getting removed for existing listing: %v", err)
+	}
+	if removed {
+		t.Errorf("Should have defaulted removed to false for existing listings")
+	}
+}
+
+func TestListingByCriteriaSorts(t *testing.T) {
+	tableBaseName := "listing_test"
+	joinTableBaseName := "challenge_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	persister2 := setupTestTable(t, joinTableBaseName)
+	defer persister2.Close()
+	joinTableName := persister.GetTableName(joinTableBaseName)
+
+	defer deleteTestTable(t, persister, tableName)
+	defer deleteTestTable(t, persister, joinTableName)
+
+	now := ctime.CurrentEpochSecsInInt64()
+
+	listing1, _ := setupSampleListing()
+	listing1.SetName("Test Listing E")
+	listing1.SetApprovalDateTs(now + int64(3))
+	listing1.SetApplicationDateTs(now + int64(3))
+	listing1.SetWhitelisted(true)
+
+	listing2, _ := setupSampleListing()
+	listing2.SetName("Test Listing C")
+	listing2.SetApprovalDateTs(0)
+	listing2.SetApplicationDateTs(0)
 
 	listing3, _ := setupSampleListingUnchallenged()
 	listing3.SetName("Test Listing G")
@@ -981,7 +1586,7 @@ func TestListingByCriteriaSorts(t *testing.T) {
 
 	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
 		SortBy: model.SortByName,
-	}, tableName, joinTableName)
+	}, tableName, joinTableName, "", "")
 	if err != nil {
 		t.Errorf("Error getting listing by criteria: %v", err)
 	}
@@ -1000,7 +1605,7 @@ func TestListingByCriteriaSorts(t *testing.T) {
 	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
 		SortBy:   model.SortByName,
 		SortDesc: true,
-	}, tableName, joinTableName)
+	}, tableName, joinTableName, "", "")
 	if err != nil {
 		t.Errorf("Error getting listing by criteria: %v", err)
 	}
@@ -1019,7 +1624,7 @@ func TestListingByCriteriaSorts(t *testing.T) {
 	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
 		SortBy: model.SortByName,
 		Offset: 3,
-	}, tableName, joinTableName)
+	}, tableName, joinTableName, "", "")
 	if err != nil {
 		t.Errorf("Error getting listing by criteria: %v", err)
 	}
@@ -1037,7 +1642,7 @@ func TestListingByCriteriaSorts(t *testing.T) {
 
 	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
 		SortBy: model.SortByApplied,
-	}, tableName, joinTableName)
+	}, tableName, joinTableName, "", "")
 	if err != nil {
 		t.Errorf("Error getting listing by criteria: %v", err)
 	}
@@ -1058,7 +1663,7 @@ func TestListingByCriteriaSorts(t *testing.T) {
 
 	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
 		SortBy: model.SortByWhitelisted,
-	}, tableName, joinTableName)
+	}, tableName, joinTableName, "", "")
 	if err != nil {
 		t.Errorf("Error getting listing by criteria: %v", err)
 	}
@@ -1079,562 +1684,2004 @@ func TestListingByCriteriaSorts(t *testing.T) {
 
 }
 
-func TestListingsByCriteria(t *testing.T) {
+func TestListingsByCriteriaUpdatedAfter(t *testing.T) {
 	tableBaseName := "listing_test"
-	joinTableBaseName := "challenge_test"
 	persister := setupTestTable(t, tableBaseName)
 	defer persister.Close()
 	tableName := persister.GetTableName(tableBaseName)
-	persister2 := setupTestTable(t, joinTableBaseName)
-	persister2.Close()
-	joinTableName := persister.GetTableName(joinTableBaseName)
-
 	defer deleteTestTable(t, persister, tableName)
-	defer deleteTestTable(t, persister, joinTableName)
-
-	now := ctime.CurrentEpochSecsInInt64()
-
-	// whitelisted modellisting with active challenge
-	modelListingWhitelistedActiveChallenge, _ := setupSampleListing()
-	modelListingWhitelistedActiveChallenge.SetName("Test Listing E")
-	modelListingWhitelistedActiveChallenge.SetApprovalDateTs(now + int64(3))
-	challenge := setupChallengeByChallengeID(10, false)
-	// Create another modelListing that was rejected after challenge succeeded
-	modelListingRejected, _ := setupSampleListing()
-	modelListingRejected.SetName("Test Listing D")
-	modelListingRejected.SetApprovalDateTs(now + int64(2))
-	modelListingRejected.SetWhitelisted(false)
-	modelListingRejected.SetChallengeID(big.NewInt(0))
-	modelListingRejected.SetAppExpiry(big.NewInt(0))
-	// modelListing that is still in application phase, not whitelisted
-	modelListingApplicationPhase, _ := setupSampleListingUnchallenged()
-	modelListingApplicationPhase.SetName("Test Listing C")
-	modelListingApplicationPhase.SetApprovalDateTs(now)
-	appExpiry := big.NewInt(ctime.CurrentEpochSecsInInt64() + 100)
-	modelListingApplicationPhase.SetAppExpiry(appExpiry)
-	// modellisting that is whitelisted, never had a challenge
-	modelListingWhitelisted, _ := setupSampleListingUnchallenged()
-	modelListingWhitelisted.SetName("Test Listing G")
-	modelListingWhitelisted.SetApprovalDateTs(now + int64(10))
-	modelListingWhitelisted.SetWhitelisted(true)
-	// Create another modelListing where challenge failed
-	modelListingNoChallenge, _ := setupSampleListing()
-	modelListingNoChallenge.SetName("Test Listing A")
-	modelListingNoChallenge.SetApprovalDateTs(now + int64(5))
-	modelListingNoChallenge.SetChallengeID(big.NewInt(0))
-	// modelListing that passed application phase but not challenged so ready to be whitelisted
-	modelListingPastApplicationPhase, _ := setupSampleListingUnchallenged()
-	modelListingPastApplicationPhase.SetName("Test Listing Z")
-	modelListingPastApplicationPhase.SetApprovalDateTs(now + int64(8))
-	appExpiry = big.NewInt(ctime.CurrentEpochSecsInInt64() - 100)
-	modelListingPastApplicationPhase.SetAppExpiry(appExpiry)
-	// Create another listing that was withdrawn
-	modelListingWithdrawn, _ := setupSampleListing()
-	modelListingWithdrawn.SetLastGovernanceState(model.GovernanceStateListingWithdrawn)
-	modelListingWithdrawn.SetName("Test Listing F")
-	modelListingWithdrawn.SetApprovalDateTs(now + int64(3))
-	modelListingWithdrawn.SetWhitelisted(false)
-	modelListingWithdrawn.SetChallengeID(big.NewInt(0))
-	modelListingWithdrawn.SetAppExpiry(big.NewInt(0))
 
-	// save to test table
-	err := persister.createListingForTable(modelListingWhitelistedActiveChallenge, tableName)
-	if err != nil {
-		t.Errorf("error saving listing: %v", err)
-	}
-	err = persister.createListingForTable(modelListingRejected, tableName)
-	if err != nil {
-		t.Errorf("error saving listing: %v", err)
+	past := ctime.CurrentEpochSecsInInt64() - 1000
+	listings := []*model.Listing{}
+	for i := 0; i < 4; i++ {
+		modelListing, _ := setupSampleListing()
+		modelListing.SetName(fmt.Sprintf("Test Listing %v", i))
+		modelListing.SetLastUpdatedDateTs(past)
+		err := persister.createListingForTable(modelListing, tableName)
+		if err != nil {
+			t.Errorf("error saving listing: %v", err)
+		}
+		listings = append(listings, modelListing)
 	}
-	err = persister.createListingForTable(modelListingApplicationPhase, tableName)
-	if err != nil {
-		t.Errorf("error saving listing: %v", err)
+
+	// Update a subset of the listings, which sets the last updated ts to now
+	updatedAfter := past + 1
+	for _, modelListing := range listings[:2] {
+		modelListing.SetWhitelisted(true)
+		err := persister.updateListingInTable(modelListing, []string{"Whitelisted"}, tableName)
+		if err != nil {
+			t.Errorf("error updating listing: %v", err)
+		}
 	}
-	err = persister.createListingForTable(modelListingWhitelisted, tableName)
+
+	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		UpdatedAfterTs: updatedAfter,
+	}, tableName, "", "", "")
 	if err != nil {
-		t.Errorf("error saving listing: %v", err)
+		t.Errorf("Error getting listing by criteria: %v", err)
 	}
-	err = persister.createListingForTable(modelListingNoChallenge, tableName)
-	if err != nil {
-		t.Errorf("error saving listing: %v", err)
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Should have returned 2 updated listings but returned %v", len(listingsFromDB))
 	}
-	err = persister.createListingForTable(modelListingPastApplicationPhase, tableName)
+
+	// Compose with another predicate and sort
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		UpdatedAfterTs:  updatedAfter,
+		WhitelistedOnly: true,
+		SortBy:          model.SortByName,
+		SortDesc:        true,
+	}, tableName, "", "", "")
 	if err != nil {
-		t.Errorf("error saving listing: %v", err)
+		t.Errorf("Error getting listing by criteria: %v", err)
 	}
-	err = persister.createListingForTable(modelListingWithdrawn, tableName)
-	if err != nil {
-		t.Errorf("error saving listing: %v", err)
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Should have returned 2 updated listings but returned %v", len(listingsFromDB))
 	}
-	err = persister.createChallengeInTable(challenge, joinTableName)
-	if err != nil {
-		t.Errorf("error saving challenge: %v", err)
+	if len(listingsFromDB) == 2 && listingsFromDB[0].Name() != "Test Listing 1" {
+		t.Errorf("Should have returned Test Listing 1 first but returned %v", listingsFromDB[0].Name())
 	}
 
-	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		RejectedOnly: true,
-	}, tableName, joinTableName)
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		UpdatedAfterTs: ctime.CurrentEpochSecsInInt64() + 1000,
+	}, tableName, "", "", "")
 	if err != nil {
 		t.Errorf("Error getting listing by criteria: %v", err)
 	}
-	if len(listingsFromDB) != 1 {
-		t.Errorf("Only one listing should have been returned but there are %v", len(listingsFromDB))
-	}
-	if listingsFromDB[0].Whitelisted() {
-		t.Error("Listing should not be whitelisted.")
+	if len(listingsFromDB) != 0 {
+		t.Errorf("Should have returned no listings but returned %v", len(listingsFromDB))
 	}
-	if !reflect.DeepEqual(listingsFromDB[0].ChallengeID(), big.NewInt(0)) {
-		t.Error("Listing should have challengeID = 0")
+}
+
+func TestListingsByCriteriaIter(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	defer deleteTestTable(t, persister, tableName)
+
+	numListings := 25
+	modelListings, _ := setupSampleListings(numListings)
+	for _, modelListing := range modelListings {
+		err := persister.createListingForTable(modelListing, tableName)
+		if err != nil {
+			t.Errorf("error saving listing: %v", err)
+		}
 	}
 
-	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		Offset: 0,
-		Count:  10,
-	}, tableName, joinTableName)
+	iter, err := persister.listingsByCriteriaIterFromTable(&model.ListingCriteria{}, tableName, "", "", "")
 	if err != nil {
-		t.Errorf("Error getting listing by criteria: %v", err)
+		t.Fatalf("Error getting listing iterator: %v", err)
 	}
-	if len(listingsFromDB) != 7 {
-		t.Error("Number of listings should be 7")
+	count := 0
+	for iter.Next() {
+		if iter.Listing() == nil {
+			t.Errorf("Should have returned a listing")
+		}
+		count++
 	}
-	if listingsFromDB[0].ContractAddress().Hex() != modelListingWhitelistedActiveChallenge.ContractAddress().Hex() {
-		t.Error("First listing is incorrect, ordering might be wrong")
+	if iter.Err() != nil {
+		t.Errorf("Should not have gotten an error iterating: %v", iter.Err())
 	}
-	if listingsFromDB[1].ContractAddress().Hex() != modelListingRejected.ContractAddress().Hex() {
-		t.Error("Second listing is incorrect, ordering might be wrong")
+	err = iter.Close()
+	if err != nil {
+		t.Errorf("Should not have gotten an error closing iterator: %v", err)
 	}
-	if listingsFromDB[2].ContractAddress().Hex() != modelListingApplicationPhase.ContractAddress().Hex() {
-		t.Error("Third listing is incorrect, ordering might be wrong")
+	if count != numListings {
+		t.Errorf("Should have iterated over %v listings but got %v", numListings, count)
 	}
-	if listingsFromDB[3].ContractAddress().Hex() != modelListingWhitelisted.ContractAddress().Hex() {
-		t.Error("Fourth listing is incorrect, ordering might be wrong")
+}
+
+func TestListingsByCriteriaHasContent(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	persister2 := setupTestTable(t, contentRevisionTestTableName)
+	defer persister2.Close()
+	contentTableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+	defer deleteTestTable(t, persister, contentTableName)
+
+	// Whitelisted with content
+	listing1, listingAddr1 := setupSampleListing()
+	// Whitelisted without content
+	listing2, _ := setupSampleListing()
+	// Not whitelisted with content
+	listing3, listingAddr3 := setupSampleListing()
+	listing3.SetWhitelisted(false)
+
+	for _, listing := range []*model.Listing{listing1, listing2, listing3} {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Errorf("error saving listing: %v", err)
+		}
 	}
-	if listingsFromDB[4].ContractAddress().Hex() != modelListingNoChallenge.ContractAddress().Hex() {
-		t.Error("Fifth listing is incorrect, ordering might be wrong")
+	for _, listingAddr := range []common.Address{listingAddr1, listingAddr1, listingAddr3} {
+		contRev, _, _, _ := setupSampleContentRevision(listingAddr, big.NewInt(mathrand.Int63()))
+		err := persister.createContentRevisionForTable(contRev, contentTableName)
+		if err != nil {
+			t.Errorf("Couldn't save content revision to table: %v", err)
+		}
 	}
-	if listingsFromDB[5].ContractAddress().Hex() != modelListingPastApplicationPhase.ContractAddress().Hex() {
-		t.Error("Sixth listing is incorrect, ordering might be wrong")
+
+	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		HasContent: true,
+	}, tableName, "", contentTableName, "")
+	if err != nil {
+		t.Errorf("Error getting listings by criteria: %v", err)
 	}
-	if listingsFromDB[6].ContractAddress().Hex() != modelListingWithdrawn.ContractAddress().Hex() {
-		t.Error("Last listing is incorrect, ordering might be wrong")
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Should have retrieved 2 listings with content, retrieved %v", len(listingsFromDB))
+	}
+	for _, listing := range listingsFromDB {
+		if listing.ContractAddress() == listing2.ContractAddress() {
+			t.Errorf("Should not have retrieved listing without content")
+		}
 	}
 
 	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		ActiveChallenge: true,
-	}, tableName, joinTableName)
+		HasContent:      true,
+		WhitelistedOnly: true,
+	}, tableName, "", contentTableName, "")
 	if err != nil {
-		t.Errorf("Error getting listing by criteria: %v", err)
+		t.Errorf("Error getting listings by criteria: %v", err)
 	}
 	if len(listingsFromDB) != 1 {
-		t.Errorf("One listing should have been returned but there are %v", len(listingsFromDB))
+		t.Fatalf("Should have retrieved 1 whitelisted listing with content, retrieved %v",
+			len(listingsFromDB))
 	}
-	if listingsFromDB[0].ChallengeID().Int64() <= int64(0) {
-		t.Error("Listing should have challengeID > 0")
+	if listingsFromDB[0].ContractAddress() != listingAddr1 {
+		t.Errorf("Should have retrieved whitelisted listing with content")
 	}
-	if !listingsFromDB[0].Whitelisted() {
-		t.Error("Listing should be currently whitelisted")
+
+	_, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		HasContent: true,
+	}, tableName, "", "", "")
+	if err == nil {
+		t.Errorf("Should have gotten an error with no content table name")
 	}
+}
 
-	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		CurrentApplication: true,
-	}, tableName, joinTableName)
-	if err != nil {
-		t.Errorf("Error getting listing by criteria: %v", err)
+func TestListingsByCriteriaWithChallengeCount(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	persister2 := setupTestTable(t, challengeTestTableName)
+	defer persister2.Close()
+	challengeTableName := persister.GetTableName(challengeTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+	defer deleteTestTable(t, persister, challengeTableName)
+
+	listing1, listingAddr1 := setupSampleListing()
+	listing2, listingAddr2 := setupSampleListing()
+	listing3, listingAddr3 := setupSampleListing()
+	for _, listing := range []*model.Listing{listing1, listing2, listing3} {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Errorf("error saving listing: %v", err)
+		}
 	}
-	if len(listingsFromDB) != 2 {
-		t.Errorf("Two listings should have been returned but there are %v", len(listingsFromDB))
+	// 3 challenges for listing 1, 1 for listing 2 and none for listing 3
+	for i, listingAddr := range []common.Address{listingAddr1, listingAddr1, listingAddr1, listingAddr2} {
+		challenge := setupChallengeByChallengeID(i+1, true)
+		challenge = model.NewChallenge(challenge.ChallengeID(), listingAddr, challenge.Statement(),
+			challenge.RewardPool(), challenge.Challenger(), challenge.Resolved(), challenge.Stake(),
+			challenge.TotalTokens(), challenge.RequestAppealExpiry(), challenge.ChallengeType(),
+			challenge.LastUpdatedDateTs())
+		err := persister.createChallengeInTable(challenge, challengeTableName)
+		if err != nil {
+			t.Errorf("error saving challenge: %v", err)
+		}
 	}
+	expectedCounts := map[common.Address]int{listingAddr1: 3, listingAddr2: 1, listingAddr3: 0}
 
-	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		ActiveChallenge:    true,
-		CurrentApplication: true,
-	}, tableName, joinTableName)
+	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		WithChallengeCount: true,
+	}, tableName, challengeTableName, "", "")
 	if err != nil {
-		t.Errorf("Error getting listing by criteria: %v", err)
+		t.Fatalf("Error getting listings by criteria: %v", err)
 	}
 	if len(listingsFromDB) != 3 {
-		t.Errorf("Three listings should have been returned but there are %v", len(listingsFromDB))
+		t.Fatalf("Should have retrieved 3 listings, retrieved %v", len(listingsFromDB))
+	}
+	for _, listing := range listingsFromDB {
+		if listing.ChallengeCount() != expectedCounts[listing.ContractAddress()] {
+			t.Errorf("Should have retrieved %v challenges for listing, retrieved %v",
+				expectedCounts[listing.ContractAddress()], listing.ChallengeCount())
+		}
 	}
 
-	// Update active challenge listing resolved = true
-	challenge.SetResolved(true)
-	err = persister.updateChallengeInTable(challenge, []string{"Resolved"}, joinTableName)
+	iter, err := persister.listingsByCriteriaIterFromTable(&model.ListingCriteria{
+		WithChallengeCount: true,
+	}, tableName, challengeTableName, "", "")
 	if err != nil {
-		t.Errorf("Error updating challenge: %v", err)
+		t.Fatalf("Error getting listings iterator by criteria: %v", err)
 	}
-	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		ActiveChallenge:    true,
-		CurrentApplication: true,
-	}, tableName, joinTableName)
-	if err != nil {
-		t.Errorf("Error getting listing by criteria: %v", err)
+	defer iter.Close() // nolint: errcheck
+	for iter.Next() {
+		listing := iter.Listing()
+		if listing.ChallengeCount() != expectedCounts[listing.ContractAddress()] {
+			t.Errorf("Should have iterated %v challenges for listing, retrieved %v",
+				expectedCounts[listing.ContractAddress()], listing.ChallengeCount())
+		}
 	}
-	if len(listingsFromDB) != 2 {
-		t.Errorf("Two listings should have been returned but there are %v", len(listingsFromDB))
+	if iter.Err() != nil {
+		t.Errorf("Error iterating listings: %v", iter.Err())
 	}
 
-	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
-		WhitelistedOnly: true,
-	}, tableName, joinTableName)
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{}, tableName,
+		challengeTableName, "", "")
 	if err != nil {
-		t.Errorf("Error getting listing by criteria: %v", err)
+		t.Fatalf("Error getting listings by criteria: %v", err)
 	}
-	if len(listingsFromDB) != 3 {
-		t.Errorf("Three listings should have been returned but there are %v", len(listingsFromDB))
+	for _, listing := range listingsFromDB {
+		if listing.ChallengeCount() != 0 {
+			t.Errorf("Should not have retrieved challenge counts without WithChallengeCount")
+		}
 	}
-}
-
-/*
-Helpers for content_revision table tests:
-*/
 
-func setupRandomSampleContentRevision() (*model.ContentRevision, common.Address, *big.Int, *big.Int) {
-	address, _ := cstrings.RandomHexStr(32)
-	listingAddr := common.HexToAddress(address)
-	contractContentID := big.NewInt(mathrand.Int63())
-	return setupSampleContentRevision(listingAddr, contractContentID)
+	_, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		WithChallengeCount: true,
+	}, tableName, "", "", "")
+	if err == nil {
+		t.Errorf("Should have gotten an error with no challenge table name")
+	}
 }
 
-func setupSampleContentRevision(listingAddr common.Address, contractContentID *big.Int) (*model.ContentRevision, common.Address, *big.Int, *big.Int) {
-	address2, _ := cstrings.RandomHexStr(32)
-	address3, _ := cstrings.RandomHexStr(32)
-	payload := model.ArticlePayload{}
-	payloadHash := address2
-	editorAddress := common.HexToAddress(address3)
-	contractRevisionID := big.NewInt(mathrand.Int63())
-	revisionURI := "revisionURI"
-	revisionDateTs := ctime.CurrentEpochSecsInInt64()
-	testContentRevision := model.NewContentRevision(listingAddr, payload, payloadHash, editorAddress,
-		contractContentID, contractRevisionID, revisionURI, revisionDateTs)
-	return testContentRevision, listingAddr, contractContentID, contractRevisionID
-}
+func TestListingsByCriteriaSortByLastGovernanceActivity(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	persister2 := setupGovEventTable(t)
+	defer persister2.Close()
+	govEventTableName := persister.GetTableName(govTestTableName)
 
-func setupSampleContentRevisionsSameAddressContentID(numRevisions int) ([]*model.ContentRevision, common.Address, *big.Int, []*big.Int) {
-	address, _ := cstrings.RandomHexStr(32)
-	listingAddr := common.HexToAddress(address)
-	contractContentID := big.NewInt(mathrand.Int63())
-	testContentRevisions := make([]*model.ContentRevision, numRevisions)
-	testContractRevisionIDs := make([]*big.Int, numRevisions)
-	for i := 0; i < numRevisions; i++ {
-		testContentRevision, _, _, testContractRevisionID := setupSampleContentRevision(listingAddr, contractContentID)
-		testContentRevisions[i] = testContentRevision
-		testContractRevisionIDs[i] = testContractRevisionID
+	defer deleteTestTable(t, persister, tableName)
+	defer deleteTestTable(t, persister, govEventTableName)
+
+	listing1, listingAddr1 := setupSampleListing()
+	listing2, listingAddr2 := setupSampleListing()
+	listing3, listingAddr3 := setupSampleListing()
+	for _, listing := range []*model.Listing{listing1, listing2, listing3} {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Errorf("error saving listing: %v", err)
+		}
+	}
+	// Listing 2 has the latest activity, then listing 1, listing 3 has none
+	baseTs := ctime.CurrentEpochSecsInInt64()
+	eventTimes := map[common.Address][]int64{
+		listingAddr1: {baseTs, baseTs + 10},
+		listingAddr2: {baseTs + 5, baseTs + 20},
+	}
+	for listingAddr, times := range eventTimes {
+		for _, ts := range times {
+			govEvent, _, eventHash, txHash := setupSampleGovernanceEvent(true)
+			blockData := govEvent.BlockData()
+			govEvent = model.NewGovernanceEvent(listingAddr, model.Metadata{}, "Application",
+				ts, govEvent.LastUpdatedDateTs(), eventHash, blockData.BlockNumber(), txHash,
+				blockData.TxIndex(), common.Hash{}, blockData.Index())
+			err := persister.createGovernanceEventInTable(govEvent, govEventTableName)
+			if err != nil {
+				t.Fatalf("error saving GovernanceEvent: %v", err)
+			}
+		}
 	}
-	return testContentRevisions, listingAddr, contractContentID, testContractRevisionIDs
-}
 
-/*
-All tests for content_revision table:
-*/
+	checkOrder := func(sortDesc bool, expected []common.Address) {
+		listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+			SortBy:   model.SortByLastGovernanceActivity,
+			SortDesc: sortDesc,
+		}, tableName, "", "", govEventTableName)
+		if err != nil {
+			t.Fatalf("Error getting listings by criteria: %v", err)
+		}
+		if len(listingsFromDB) != len(expected) {
+			t.Fatalf("Should have retrieved %v listings, retrieved %v", len(expected),
+				len(listingsFromDB))
+		}
+		for i, listing := range listingsFromDB {
+			if listing.ContractAddress() != expected[i] {
+				t.Errorf("Should have retrieved %v at position %v, got %v", expected[i].Hex(), i,
+					listing.ContractAddress().Hex())
+			}
+		}
+	}
+	checkOrder(false, []common.Address{listingAddr1, listingAddr2, listingAddr3})
+	checkOrder(true, []common.Address{listingAddr2, listingAddr1, listingAddr3})
 
-// TestCreateContentRevision tests that a ContentRevision is created
-func TestCreateContentRevision(t *testing.T) {
+	_, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		SortBy: model.SortByLastGovernanceActivity,
+	}, tableName, "", "", "")
+	if err == nil {
+		t.Errorf("Should have gotten an error with no gov event table name")
+	}
+}
 
-	persister := setupTestTable(t, contentRevisionTestTableName)
+func TestListingsByCriteriaExcludeAddresses(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
 	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
-
+	tableName := persister.GetTableName(tableBaseName)
 	defer deleteTestTable(t, persister, tableName)
 
-	// sample contentRevision
-	modelContentRevision, _, _, _ := setupRandomSampleContentRevision()
+	listings, listingAddrs := setupSampleListings(5)
+	// Only the first three listings are whitelisted
+	for index, listing := range listings {
+		listing.SetWhitelisted(index < 3)
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Errorf("error saving listing: %v", err)
+		}
+	}
 
-	// insert to table
-	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
-	if err != nil {
-		t.Errorf("error saving content revision: %v", err)
+	excluded := map[common.Address]bool{
+		listingAddrs[0]: true,
+		listingAddrs[3]: true,
 	}
-	// check row is there
-	var numRowsb int
-	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v", tableName)).Scan(&numRowsb)
+	criteria := &model.ListingCriteria{
+		ExcludeAddresses: []string{
+			listingAddrs[0].Hex(),
+			// Lowercase addresses should be normalized
+			strings.ToLower(listingAddrs[3].Hex()),
+		},
+	}
+	listingsFromDB, err := persister.listingsByCriteriaFromTable(criteria, tableName, "", "", "")
 	if err != nil {
-		t.Errorf("Error querying row: err: %v", err)
+		t.Errorf("Error getting listings by criteria: %v", err)
 	}
-	if numRowsb != 1 {
-		t.Errorf("Number of rows in table should be 1 but is: %v", numRowsb)
+	if len(listingsFromDB) != 3 {
+		t.Errorf("Should have retrieved 3 listings, retrieved %v", len(listingsFromDB))
+	}
+	for _, listing := range listingsFromDB {
+		if excluded[listing.ContractAddress()] {
+			t.Errorf("Should not have retrieved excluded listing %v", listing.ContractAddress().Hex())
+		}
 	}
-}
-
-// TestContentRevision tests that a content revision can be retrieved
-func TestContentRevision(t *testing.T) {
 
-	persister := setupTestTable(t, contentRevisionTestTableName)
-	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
+	criteria.WhitelistedOnly = true
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(criteria, tableName, "", "", "")
+	if err != nil {
+		t.Errorf("Error getting listings by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Should have retrieved 2 whitelisted listings, retrieved %v", len(listingsFromDB))
+	}
+	for _, listing := range listingsFromDB {
+		if excluded[listing.ContractAddress()] {
+			t.Errorf("Should not have retrieved excluded listing %v", listing.ContractAddress().Hex())
+		}
+		if !listing.Whitelisted() {
+			t.Errorf("Should have only retrieved whitelisted listings")
+		}
+	}
 
-	defer deleteTestTable(t, persister, tableName)
+	_, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		ExcludeAddresses: []string{"notanaddress"},
+	}, tableName, "", "", "")
+	if err == nil {
+		t.Errorf("Should have gotten an error for an invalid exclude address")
+	}
+}
 
-	// sample contentRevision
-	modelContentRevision, listingAddr, contentID, revisionID := setupRandomSampleContentRevision()
+// seedListingsForIndices saves listings where 1 in 10 is whitelisted and 1 in
+// 10 has an application in progress, then analyzes the table
+func seedListingsForIndices(persister *PostgresPersister, tableName string, numListings int) error {
+	for i := 0; i < numListings; i++ {
+		listing, _ := setupSampleListingUnchallenged()
+		listing.SetWhitelisted(i%10 == 0)
+		if i%10 != 1 {
+			listing.SetAppExpiry(big.NewInt(0))
+		}
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := persister.db.Exec(fmt.Sprintf("ANALYZE %s;", tableName))
+	return err
+}
 
-	// insert to table
-	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
+func explainListingsByCriteria(t *testing.T, persister *PostgresPersister,
+	criteria *model.ListingCriteria, tableName string) string {
+	queryString, err := persister.listingsByCriteriaQuery(criteria, tableName, "", "", "")
 	if err != nil {
-		t.Errorf("error saving content revision: %v", err)
+		t.Fatalf("Error building listings query: %v", err)
 	}
+	return explainQuery(t, persister, queryString)
+}
 
-	// retrieve from table
-	_, err = persister.contentRevisionFromTable(listingAddr, contentID, revisionID, tableName)
+func explainQuery(t *testing.T, persister *PostgresPersister, queryString string) string {
+	tx, err := persister.db.Beginx()
 	if err != nil {
-		t.Errorf("Wasn't able to get content revision from postgres table: %v", err)
+		t.Fatalf("Error starting transaction: %v", err)
 	}
-
+	defer tx.Rollback() // nolint: errcheck
+	// Small test tables favor seq scans, only compare the index scans
+	_, err = tx.Exec("SET LOCAL enable_seqscan = off;")
+	if err != nil {
+		t.Fatalf("Error disabling seq scans: %v", err)
+	}
+	rows, err := tx.Query("EXPLAIN " + queryString)
+	if err != nil {
+		t.Fatalf("Error explaining query: %v", err)
+	}
+	defer rows.Close()
+	plan := []string{}
+	for rows.Next() {
+		var line string
+		err = rows.Scan(&line)
+		if err != nil {
+			t.Fatalf("Error scanning plan: %v", err)
+		}
+		plan = append(plan, line)
+	}
+	return strings.Join(plan, "\n")
 }
 
-// TestDBCRToModelCR tests that the db listing can be properly converted to model listing
-func TestDBCRToModelCR(t *testing.T) {
-
-	persister := setupTestTable(t, contentRevisionTestTableName)
+func TestListingsByCriteriaPartialIndices(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
 	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
-
+	tableName := persister.GetTableName(listingTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	// sample contentRevision
-	modelContentRevision, listingAddr, contentID, revisionID := setupRandomSampleContentRevision()
-
-	// insert to table
-	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
+	_, err := persister.db.Exec(postgres.CreateListingTableIndicesQuery(tableName))
 	if err != nil {
-		t.Errorf("error saving content revision: %v", err)
+		t.Fatalf("Error creating listing indices: %v", err)
 	}
-
-	// retrieve from table
-	modelCRFromDB, err := persister.contentRevisionFromTable(listingAddr, contentID, revisionID, tableName)
+	err = seedListingsForIndices(persister, tableName, 500)
 	if err != nil {
-		t.Errorf("Wasn't able to get content revision from postgres table: %v", err)
+		t.Fatalf("Error seeding listings: %v", err)
 	}
 
-	// do a deep equal
-	// check that retrieved fields match with inserted listing
-	if !reflect.DeepEqual(modelContentRevision, modelCRFromDB) {
-		t.Errorf("listing from DB: %v, doesn't match inserted listing: %v", modelCRFromDB, modelContentRevision)
+	plan := explainListingsByCriteria(t, persister,
+		&model.ListingCriteria{WhitelistedOnly: true}, tableName)
+	if !strings.Contains(plan, tableName+"_whitelisted_partial_idx") {
+		t.Errorf("Should have used the whitelisted partial index, plan: %v", plan)
+	}
+	plan = explainListingsByCriteria(t, persister,
+		&model.ListingCriteria{CurrentApplication: true}, tableName)
+	if !strings.Contains(plan, tableName+"_current_app_partial_idx") {
+		t.Errorf("Should have used the current application partial index, plan: %v", plan)
 	}
-}
 
-// TestContentRevision tests that multiple content revisions can be retrieved
-func TestContentRevisions(t *testing.T) {
+	listings, err := persister.listingsByCriteriaFromTable(
+		&model.ListingCriteria{WhitelistedOnly: true}, tableName, "", "", "")
+	if err != nil {
+		t.Fatalf("Error getting listings by criteria: %v", err)
+	}
+	if len(listings) != 50 {
+		t.Errorf("Should have retrieved 50 whitelisted listings, retrieved %v", len(listings))
+	}
+}
 
-	persister := setupTestTable(t, contentRevisionTestTableName)
+func BenchmarkListingsByCriteriaWhitelisted(b *testing.B) {
+	creds := testutils.GetTestDBCreds()
+	persister, err := NewPostgresPersister(creds.Host, creds.Port, creds.User,
+		creds.Password, creds.Dbname, nil, nil, nil, nil)
+	if err != nil {
+		b.Fatalf("Error setting up new persister: err: %v", err)
+	}
 	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
+	tableName := "listing_bench"
+	_, err = persister.db.Exec(postgres.CreateListingTableQuery(tableName))
+	if err != nil {
+		b.Fatalf("Error creating table: %v", err)
+	}
+	defer persister.db.Exec(fmt.Sprintf("DROP TABLE %s;", tableName)) // nolint: errcheck
 
-	defer deleteTestTable(t, persister, tableName)
+	err = seedListingsForIndices(persister, tableName, 5000)
+	if err != nil {
+		b.Fatalf("Error seeding listings: %v", err)
+	}
 
-	// create multiple contentRevisions
-	numRevisions := 10
-	testContentRevisions, listingAddr, contractContentID, testContractRevisionIDs := setupSampleContentRevisionsSameAddressContentID(numRevisions)
+	// Compare against an index on the whitelisted column alone
+	_, err = persister.db.Exec(fmt.Sprintf("CREATE INDEX %s_whitelisted_idx ON %s (whitelisted);",
+		tableName, tableName))
+	if err != nil {
+		b.Fatalf("Error creating whitelisted index: %v", err)
+	}
 
-	// save all to table
-	for _, contRev := range testContentRevisions {
-		err := persister.createContentRevisionForTable(contRev, tableName)
-		if err != nil {
-			t.Errorf("Couldn't save content revision to table: %v", err)
+	criteria := &model.ListingCriteria{WhitelistedOnly: true, Count: 20}
+	run := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := persister.listingsByCriteriaFromTable(criteria, tableName, "", "", "")
+			if err != nil {
+				b.Fatalf("Error getting listings by criteria: %v", err)
+			}
 		}
 	}
+	b.Run("WhitelistedIndex", run)
 
-	// retrieve from table
-	dbContentRevisions, err := persister.contentRevisionsFromTable(listingAddr, contractContentID, tableName)
+	_, err = persister.db.Exec(postgres.CreateListingTableIndicesQuery(tableName))
 	if err != nil {
-		t.Errorf("Error with persister.ContentRevisions(): %v", err)
+		b.Fatalf("Error creating listing indices: %v", err)
+	}
+	_, err = persister.db.Exec(fmt.Sprintf("ANALYZE %s;", tableName))
+	if err != nil {
+		b.Fatalf("Error analyzing table: %v", err)
 	}
+	b.Run("PartialIndex", run)
+}
 
-	// test various things
-	// test length of both
-	if len(dbContentRevisions) != numRevisions {
-		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
+func TestUnindexedListingSortColumns(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(listingTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	missing, err := persister.unindexedListingSortColumns(tableName)
+	if err != nil {
+		t.Fatalf("Error checking sort indices: %v", err)
 	}
-	// order will be the same:
-	contractRevisionIDsFromDB := make([]*big.Int, numRevisions)
-	for i, contrev := range dbContentRevisions {
-		contractRevisionIDsFromDB[i] = contrev.ContractRevisionID()
+	if len(missing) == 0 {
+		t.Errorf("Should have returned unindexed sort columns before creating indices")
 	}
-	reflect.DeepEqual(contractRevisionIDsFromDB, testContractRevisionIDs)
 
+	_, err = persister.db.Exec(postgres.CreateListingTableIndicesQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error creating listing indices: %v", err)
+	}
+	missing, err = persister.unindexedListingSortColumns(tableName)
+	if err != nil {
+		t.Fatalf("Error checking sort indices: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("Should have indexed all sort columns: %v", missing)
+	}
 }
 
-func TestContentRevisionsByCriteria(t *testing.T) {
-	persister := setupTestTable(t, contentRevisionTestTableName)
+func TestListingsByOwnerAddressIndex(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
 	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
-
+	tableName := persister.GetTableName(listingTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	// create multiple contentRevisions
-	numRevisions := 10
-	testContentRevisions, listingAddr, contractContentID, contractRevisionIDs :=
-		setupSampleContentRevisionsSameAddressContentID(numRevisions)
-
-	// save all to table
-	for _, contRev := range testContentRevisions {
-		err := persister.createContentRevisionForTable(contRev, tableName)
-		if err != nil {
-			t.Errorf("Couldn't save content revision to table: %v", err)
-		}
+	_, err := persister.db.Exec(postgres.CreateListingTableIndicesQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error creating listing indices: %v", err)
 	}
-
-	// Test listing address
-	criteria := &model.ContentRevisionCriteria{
-		ListingAddress: listingAddr.Hex(),
+	err = seedListingsForIndices(persister, tableName, 500)
+	if err != nil {
+		t.Fatalf("Error seeding listings: %v", err)
 	}
-
-	// retrieve from table
-	dbContentRevisions, err := persister.contentRevisionsByCriteriaFromTable(criteria,
-		tableName)
+	listing, _ := setupSampleListing()
+	err = persister.createListingForTable(listing, tableName)
 	if err != nil {
-		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		t.Fatalf("error saving listing: %v", err)
 	}
 
-	if len(dbContentRevisions) != numRevisions {
-		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
+	plan := explainQuery(t, persister,
+		persister.listingByOwnerAddressQuery(tableName, listing.Owner().Hex()))
+	if !strings.Contains(plan, tableName+"_owner_lower_idx") {
+		t.Errorf("Should have used the lower owner index, plan: %v", plan)
 	}
 
-	// Test latest only
-	criteria = &model.ContentRevisionCriteria{
-		ListingAddress: listingAddr.Hex(),
-		LatestOnly:     true,
+	// Lookup is case insensitive
+	listings, err := persister.listingsByOwnerAddressFromTable(listing.Owner(), tableName)
+	if err != nil {
+		t.Fatalf("Error getting listings by owner: %v", err)
+	}
+	if len(listings) != 1 || listings[0].ContractAddress() != listing.ContractAddress() {
+		t.Errorf("Should have retrieved the listing by owner")
 	}
+}
 
-	// retrieve from table
-	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
-		tableName)
+func BenchmarkListingsByOwnerAddress(b *testing.B) {
+	creds := testutils.GetTestDBCreds()
+	persister, err := NewPostgresPersister(creds.Host, creds.Port, creds.User,
+		creds.Password, creds.Dbname, nil, nil, nil, nil)
 	if err != nil {
-		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		b.Fatalf("Error setting up new persister: err: %v", err)
 	}
+	defer persister.Close()
+	tableName := "listing_owner_bench"
+	_, err = persister.db.Exec(postgres.CreateListingTableQuery(tableName))
+	if err != nil {
+		b.Fatalf("Error creating table: %v", err)
+	}
+	defer persister.db.Exec(fmt.Sprintf("DROP TABLE %s;", tableName)) // nolint: errcheck
 
-	if len(dbContentRevisions) != numRevisions {
+	err = seedListingsForIndices(persister, tableName, 5000)
+	if err != nil {
+		b.Fatalf("Error seeding listings: %v", err)
+	}
+	listing, _ := setupSampleListing()
+	err = persister.createListingForTable(listing, tableName)
+	if err != nil {
+		b.Fatalf("Error saving listing: %v", err)
+	}
+
+	run := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := persister.listingsByOwnerAddressFromTable(listing.Owner(), tableName)
+			if err != nil {
+				b.Fatalf("Error getting listings by owner: %v", err)
+			}
+		}
+	}
+	b.Run("NoIndex", run)
+
+	_, err = persister.db.Exec(postgres.CreateListingTableIndicesQuery(tableName))
+	if err != nil {
+		b.Fatalf("Error creating listing indices: %v", err)
+	}
+	_, err = persister.db.Exec(fmt.Sprintf("ANALYZE %s;", tableName))
+	if err != nil {
+		b.Fatalf("Error analyzing table: %v", err)
+	}
+	b.Run("LowerOwnerIndex", run)
+}
+
+func TestListingStats(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	defer deleteTestTable(t, persister, tableName)
+
+	// Whitelisted in the window
+	whitelisted1, _ := setupSampleListing()
+	whitelisted2, _ := setupSampleListing()
+	// Rejected in the window
+	rejected, _ := setupSampleListing()
+	rejected.SetWhitelisted(false)
+	rejected.SetChallengeID(big.NewInt(0))
+	rejected.SetLastGovernanceState(model.GovernanceStateChallengeSucceeded)
+	// Withdrawn in the window
+	withdrawn, _ := setupSampleListing()
+	withdrawn.SetWhitelisted(false)
+	withdrawn.SetChallengeID(big.NewInt(0))
+	withdrawn.SetLastGovernanceState(model.GovernanceStateListingWithdrawn)
+	// Application in progress in the window
+	applied, _ := setupSampleListingUnchallenged()
+	applied.SetLastGovernanceState(model.GovernanceStateApplied)
+	// Whitelisted before the window
+	oldWhitelisted, _ := setupSampleListing()
+	oldWhitelisted.SetApplicationDateTs(1000)
+	oldWhitelisted.SetApprovalDateTs(1000)
+	oldWhitelisted.SetLastUpdatedDateTs(1000)
+
+	for _, listing := range []*model.Listing{whitelisted1, whitelisted2, rejected, withdrawn,
+		applied, oldWhitelisted} {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Fatalf("error saving listing: %v", err)
+		}
+	}
+
+	stats, err := persister.listingStatsFromTable(1257890000, 1257900000, tableName)
+	if err != nil {
+		t.Fatalf("Error getting listing stats: %v", err)
+	}
+	if stats.Applications != 5 {
+		t.Errorf("Should have counted 5 applications, counted %v", stats.Applications)
+	}
+	if stats.Whitelisted != 2 {
+		t.Errorf("Should have counted 2 whitelisted, counted %v", stats.Whitelisted)
+	}
+	if stats.Rejected != 1 {
+		t.Errorf("Should have counted 1 rejected, counted %v", stats.Rejected)
+	}
+	if stats.Withdrawn != 1 {
+		t.Errorf("Should have counted 1 withdrawn, counted %v", stats.Withdrawn)
+	}
+
+	stats, err = persister.listingStatsFromTable(0, 1000, tableName)
+	if err != nil {
+		t.Fatalf("Error getting listing stats: %v", err)
+	}
+	if *stats != (model.ListingStatsResult{}) {
+		t.Errorf("Should have counted no listings before the window, counted %+v", stats)
+	}
+}
+
+func TestListingsByCriteria(t *testing.T) {
+	tableBaseName := "listing_test"
+	joinTableBaseName := "challenge_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	persister2 := setupTestTable(t, joinTableBaseName)
+	defer persister2.Close()
+	joinTableName := persister.GetTableName(joinTableBaseName)
+
+	defer deleteTestTable(t, persister, tableName)
+	defer deleteTestTable(t, persister, joinTableName)
+
+	now := ctime.CurrentEpochSecsInInt64()
+
+	// whitelisted modellisting with active challenge
+	modelListingWhitelistedActiveChallenge, _ := setupSampleListing()
+	modelListingWhitelistedActiveChallenge.SetName("Test Listing E")
+	modelListingWhitelistedActiveChallenge.SetApprovalDateTs(now + int64(3))
+	challenge := setupChallengeByChallengeID(10, false)
+	// Create another modelListing that was rejected after challenge succeeded
+	modelListingRejected, _ := setupSampleListing()
+	modelListingRejected.SetName("Test Listing D")
+	modelListingRejected.SetApprovalDateTs(now + int64(2))
+	modelListingRejected.SetWhitelisted(false)
+	modelListingRejected.SetChallengeID(big.NewInt(0))
+	modelListingRejected.SetAppExpiry(big.NewInt(0))
+	// modelListing that is still in application phase, not whitelisted
+	modelListingApplicationPhase, _ := setupSampleListingUnchallenged()
+	modelListingApplicationPhase.SetName("Test Listing C")
+	modelListingApplicationPhase.SetApprovalDateTs(now)
+	appExpiry := big.NewInt(ctime.CurrentEpochSecsInInt64() + 100)
+	modelListingApplicationPhase.SetAppExpiry(appExpiry)
+	// modellisting that is whitelisted, never had a challenge
+	modelListingWhitelisted, _ := setupSampleListingUnchallenged()
+	modelListingWhitelisted.SetName("Test Listing G")
+	modelListingWhitelisted.SetApprovalDateTs(now + int64(10))
+	modelListingWhitelisted.SetWhitelisted(true)
+	// Create another modelListing where challenge failed
+	modelListingNoChallenge, _ := setupSampleListing()
+	modelListingNoChallenge.SetName("Test Listing A")
+	modelListingNoChallenge.SetApprovalDateTs(now + int64(5))
+	modelListingNoChallenge.SetChallengeID(big.NewInt(0))
+	// modelListing that passed application phase but not challenged so ready to be whitelisted
+	modelListingPastApplicationPhase, _ := setupSampleListingUnchallenged()
+	modelListingPastApplicationPhase.SetName("Test Listing Z")
+	modelListingPastApplicationPhase.SetApprovalDateTs(now + int64(8))
+	appExpiry = big.NewInt(ctime.CurrentEpochSecsInInt64() - 100)
+	modelListingPastApplicationPhase.SetAppExpiry(appExpiry)
+	// Create another listing that was withdrawn
+	modelListingWithdrawn, _ := setupSampleListing()
+	modelListingWithdrawn.SetLastGovernanceState(model.GovernanceStateListingWithdrawn)
+	modelListingWithdrawn.SetName("Test Listing F")
+	modelListingWithdrawn.SetApprovalDateTs(now + int64(3))
+	modelListingWithdrawn.SetWhitelisted(false)
+	modelListingWithdrawn.SetChallengeID(big.NewInt(0))
+	modelListingWithdrawn.SetAppExpiry(big.NewInt(0))
+
+	// save to test table
+	err := persister.createListingForTable(modelListingWhitelistedActiveChallenge, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createListingForTable(modelListingRejected, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createListingForTable(modelListingApplicationPhase, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createListingForTable(modelListingWhitelisted, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createListingForTable(modelListingNoChallenge, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createListingForTable(modelListingPastApplicationPhase, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createListingForTable(modelListingWithdrawn, tableName)
+	if err != nil {
+		t.Errorf("error saving listing: %v", err)
+	}
+	err = persister.createChallengeInTable(challenge, joinTableName)
+	if err != nil {
+		t.Errorf("error saving challenge: %v", err)
+	}
+
+	listingsFromDB, err := persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		RejectedOnly: true,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 1 {
+		t.Errorf("Only one listing should have been returned but there are %v", len(listingsFromDB))
+	}
+	if listingsFromDB[0].Whitelisted() {
+		t.Error("Listing should not be whitelisted.")
+	}
+	if !reflect.DeepEqual(listingsFromDB[0].ChallengeID(), big.NewInt(0)) {
+		t.Error("Listing should have challengeID = 0")
+	}
+
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		Offset: 0,
+		Count:  10,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 7 {
+		t.Error("Number of listings should be 7")
+	}
+	if listingsFromDB[0].ContractAddress().Hex() != modelListingWhitelistedActiveChallenge.ContractAddress().Hex() {
+		t.Error("First listing is incorrect, ordering might be wrong")
+	}
+	if listingsFromDB[1].ContractAddress().Hex() != modelListingRejected.ContractAddress().Hex() {
+		t.Error("Second listing is incorrect, ordering might be wrong")
+	}
+	if listingsFromDB[2].ContractAddress().Hex() != modelListingApplicationPhase.ContractAddress().Hex() {
+		t.Error("Third listing is incorrect, ordering might be wrong")
+	}
+	if listingsFromDB[3].ContractAddress().Hex() != modelListingWhitelisted.ContractAddress().Hex() {
+		t.Error("Fourth listing is incorrect, ordering might be wrong")
+	}
+	if listingsFromDB[4].ContractAddress().Hex() != modelListingNoChallenge.ContractAddress().Hex() {
+		t.Error("Fifth listing is incorrect, ordering might be wrong")
+	}
+	if listingsFromDB[5].ContractAddress().Hex() != modelListingPastApplicationPhase.ContractAddress().Hex() {
+		t.Error("Sixth listing is incorrect, ordering might be wrong")
+	}
+	if listingsFromDB[6].ContractAddress().Hex() != modelListingWithdrawn.ContractAddress().Hex() {
+		t.Error("Last listing is incorrect, ordering might be wrong")
+	}
+
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		ActiveChallenge: true,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 1 {
+		t.Errorf("One listing should have been returned but there are %v", len(listingsFromDB))
+	}
+	if listingsFromDB[0].ChallengeID().Int64() <= int64(0) {
+		t.Error("Listing should have challengeID > 0")
+	}
+	if !listingsFromDB[0].Whitelisted() {
+		t.Error("Listing should be currently whitelisted")
+	}
+
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		CurrentApplication: true,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Two listings should have been returned but there are %v", len(listingsFromDB))
+	}
+
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		ActiveChallenge:    true,
+		CurrentApplication: true,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 3 {
+		t.Errorf("Three listings should have been returned but there are %v", len(listingsFromDB))
+	}
+
+	// Update active challenge listing resolved = true
+	challenge.SetResolved(true)
+	err = persister.updateChallengeInTable(challenge, []string{"Resolved"}, joinTableName)
+	if err != nil {
+		t.Errorf("Error updating challenge: %v", err)
+	}
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		ActiveChallenge:    true,
+		CurrentApplication: true,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 2 {
+		t.Errorf("Two listings should have been returned but there are %v", len(listingsFromDB))
+	}
+
+	listingsFromDB, err = persister.listingsByCriteriaFromTable(&model.ListingCriteria{
+		WhitelistedOnly: true,
+	}, tableName, joinTableName, "", "")
+	if err != nil {
+		t.Errorf("Error getting listing by criteria: %v", err)
+	}
+	if len(listingsFromDB) != 3 {
+		t.Errorf("Three listings should have been returned but there are %v", len(listingsFromDB))
+	}
+}
+
+/*
+Helpers for content_revision table tests:
+*/
+
+func setupRandomSampleContentRevision() (*model.ContentRevision, common.Address, *big.Int, *big.Int) {
+	address, _ := cstrings.RandomHexStr(32)
+	listingAddr := common.HexToAddress(address)
+	contractContentID := big.NewInt(mathrand.Int63())
+	return setupSampleContentRevision(listingAddr, contractContentID)
+}
+
+func setupSampleContentRevision(listingAddr common.Address, contractContentID *big.Int) (*model.ContentRevision, common.Address, *big.Int, *big.Int) {
+	address2, _ := cstrings.RandomHexStr(32)
+	address3, _ := cstrings.RandomHexStr(32)
+	payload := model.ArticlePayload{}
+	payloadHash := address2
+	editorAddress := common.HexToAddress(address3)
+	contractRevisionID := big.NewInt(mathrand.Int63())
+	revisionURI := "revisionURI"
+	revisionDateTs := ctime.CurrentEpochSecsInInt64()
+	testContentRevision := model.NewContentRevision(listingAddr, payload, payloadHash, editorAddress,
+		contractContentID, contractRevisionID, revisionURI, revisionDateTs)
+	return testContentRevision, listingAddr, contractContentID, contractRevisionID
+}
+
+func setupSampleContentRevisionsSameAddressContentID(numRevisions int) ([]*model.ContentRevision, common.Address, *big.Int, []*big.Int) {
+	address, _ := cstrings.RandomHexStr(32)
+	listingAddr := common.HexToAddress(address)
+	contractContentID := big.NewInt(mathrand.Int63())
+	testContentRevisions := make([]*model.ContentRevision, numRevisions)
+	testContractRevisionIDs := make([]*big.Int, numRevisions)
+	for i := 0; i < numRevisions; i++ {
+		testContentRevision, _, _, testContractRevisionID := setupSampleContentRevision(listingAddr, contractContentID)
+		testContentRevisions[i] = testContentRevision
+		testContractRevisionIDs[i] = testContractRevisionID
+	}
+	return testContentRevisions, listingAddr, contractContentID, testContractRevisionIDs
+}
+
+/*
+All tests for content_revision table:
+*/
+
+// TestCreateContentRevision tests that a ContentRevision is created
+func TestCreateContentRevision(t *testing.T) {
+
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// sample contentRevision
+	modelContentRevision, _, _, _ := setupRandomSampleContentRevision()
+
+	// insert to table
+	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
+	if err != nil {
+		t.Errorf("error saving content revision: %v", err)
+	}
+	// check row is there
+	var numRowsb int
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v", tableName)).Scan(&numRowsb)
+	if err != nil {
+		t.Errorf("Error querying row: err: %v", err)
+	}
+	if numRowsb != 1 {
+		t.Errorf("Number of rows in table should be 1 but is: %v", numRowsb)
+	}
+}
+
+// TestContentRevision tests that a content revision can be retrieved
+func TestContentRevision(t *testing.T) {
+
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// sample contentRevision
+	modelContentRevision, listingAddr, contentID, revisionID := setupRandomSampleContentRevision()
+
+	// insert to table
+	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
+	if err != nil {
+		t.Errorf("error saving content revision: %v", err)
+	}
+
+	// retrieve from table
+	_, err = persister.contentRevisionFromTable(listingAddr, contentID, revisionID, tableName)
+	if err != nil {
+		t.Errorf("Wasn't able to get content revision from postgres table: %v", err)
+	}
+
+}
+
+// TestDBCRToModelCR tests that the db listing can be properly converted to model listing
+func TestDBCRToModelCR(t *testing.T) {
+
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// sample contentRevision
+	modelContentRevision, listingAddr, contentID, revisionID := setupRandomSampleContentRevision()
+
+	// insert to table
+	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
+	if err != nil {
+		t.Errorf("error saving content revision: %v", err)
+	}
+
+	// retrieve from table
+	modelCRFromDB, err := persister.contentRevisionFromTable(listingAddr, contentID, revisionID, tableName)
+	if err != nil {
+		t.Errorf("Wasn't able to get content revision from postgres table: %v", err)
+	}
+
+	// do a deep equal
+	// check that retrieved fields match with inserted listing
+	if !reflect.DeepEqual(modelContentRevision, modelCRFromDB) {
+		t.Errorf("listing from DB: %v, doesn't match inserted listing: %v", modelCRFromDB, modelContentRevision)
+	}
+}
+
+// TestContentRevision tests that multiple content revisions can be retrieved
+func TestNoResultsContentRevisions(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	contRevs, err := persister.contentRevisionsFromTable(common.HexToAddress(testAddress),
+		big.NewInt(0), tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Error should be ErrPersisterNoResults but is %v", err)
+	}
+	if len(contRevs) != 0 {
+		t.Errorf("contRevs should be empty but is %v", contRevs)
+	}
+}
+
+func TestContentRevisions(t *testing.T) {
+
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// create multiple contentRevisions
+	numRevisions := 10
+	testContentRevisions, listingAddr, contractContentID, testContractRevisionIDs := setupSampleContentRevisionsSameAddressContentID(numRevisions)
+
+	// save all to table
+	for _, contRev := range testContentRevisions {
+		err := persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Errorf("Couldn't save content revision to table: %v", err)
+		}
+	}
+
+	// retrieve from table
+	dbContentRevisions, err := persister.contentRevisionsFromTable(listingAddr, contractContentID, tableName)
+	if err != nil {
+		t.Errorf("Error with persister.ContentRevisions(): %v", err)
+	}
+
+	// test various things
+	// test length of both
+	if len(dbContentRevisions) != numRevisions {
+		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
+	}
+	// order will be the same:
+	contractRevisionIDsFromDB := make([]*big.Int, numRevisions)
+	for i, contrev := range dbContentRevisions {
+		contractRevisionIDsFromDB[i] = contrev.ContractRevisionID()
+	}
+	reflect.DeepEqual(contractRevisionIDsFromDB, testContractRevisionIDs)
+
+}
+
+func TestContentRevisionsByCriteria(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// create multiple contentRevisions
+	numRevisions := 10
+	testContentRevisions, listingAddr, contractContentID, contractRevisionIDs :=
+		setupSampleContentRevisionsSameAddressContentID(numRevisions)
+
+	// save all to table
+	for _, contRev := range testContentRevisions {
+		err := persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Errorf("Couldn't save content revision to table: %v", err)
+		}
+	}
+
+	// Test listing address
+	criteria := &model.ContentRevisionCriteria{
+		ListingAddress: listingAddr.Hex(),
+	}
+
+	// retrieve from table
+	dbContentRevisions, err := persister.contentRevisionsByCriteriaFromTable(criteria,
+		tableName)
+	if err != nil {
+		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+	}
+
+	if len(dbContentRevisions) != numRevisions {
+		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
+	}
+
+	// Test latest only
+	criteria = &model.ContentRevisionCriteria{
+		ListingAddress: listingAddr.Hex(),
+		LatestOnly:     true,
+	}
+
+	// retrieve from table
+	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
+		tableName)
+	if err != nil {
+		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+	}
+
+	if len(dbContentRevisions) != numRevisions {
+		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
+	}
+
+	// Test addr and content id
+	contentID := contractContentID.Int64()
+	criteria = &model.ContentRevisionCriteria{
+		ListingAddress: listingAddr.Hex(),
+		ContentID:      &contentID,
+	}
+
+	// retrieve from table
+	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
+		tableName)
+	if err != nil {
+		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+	}
+
+	if len(dbContentRevisions) != numRevisions {
 		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
 	}
 
-	// Test addr and content id
-	contentID := contractContentID.Int64()
-	criteria = &model.ContentRevisionCriteria{
-		ListingAddress: listingAddr.Hex(),
-		ContentID:      &contentID,
+	// Test addr, content id, and revision id
+	contentID = contractContentID.Int64()
+	revisionID := contractRevisionIDs[0].Int64()
+	criteria = &model.ContentRevisionCriteria{
+		ListingAddress: listingAddr.Hex(),
+		ContentID:      &contentID,
+		RevisionID:     &revisionID,
+	}
+
+	// retrieve from table
+	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
+		tableName)
+	if err != nil {
+		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+	}
+
+	if len(dbContentRevisions) != 1 {
+		t.Errorf("Should have retrieved one revision: %v", len(dbContentRevisions))
+	}
+
+	// Test offset, limit
+	criteria = &model.ContentRevisionCriteria{
+		ListingAddress: listingAddr.Hex(),
+		Offset:         1,
+		Count:          3,
+	}
+
+	// retrieve from table
+	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
+		tableName)
+	if err != nil {
+		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+	}
+
+	if len(dbContentRevisions) != 3 {
+		t.Errorf("Should have retrieved 3 revisions: %v", len(dbContentRevisions))
+	}
+
+	// Test from and before
+	minTs := int64(math.MaxInt64)
+	maxTs := int64(0)
+	for _, rev := range testContentRevisions {
+		revTs := rev.RevisionDateTs()
+		if revTs < minTs {
+			minTs = revTs
+		}
+		if revTs > maxTs {
+			maxTs = revTs
+		}
+	}
+	criteria = &model.ContentRevisionCriteria{
+		ListingAddress: listingAddr.Hex(),
+		FromTs:         minTs - 10,
+		BeforeTs:       maxTs + 10,
+	}
+
+	// retrieve from table
+	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
+		tableName)
+	if err != nil {
+		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+	}
+
+	if len(dbContentRevisions) != numRevisions {
+		t.Errorf("Should have retrieved %v revisions: %v", numRevisions, len(dbContentRevisions))
+	}
+}
+
+func TestContentRevisionsByCriteriaTitleContains(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	_, err := persister.db.Exec(postgres.CreateContentRevisionTableMigrationQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error creating title index: %v", err)
+	}
+
+	titles := []string{
+		"City council approves new budget",
+		"Local school budget debate continues",
+		"Weather report for the weekend",
+	}
+	for _, title := range titles {
+		contRev, _, _, _ := setupRandomSampleContentRevision()
+		contRev.Payload()["title"] = title
+		err = persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Errorf("Couldn't save content revision to table: %v", err)
+		}
+	}
+	// Revision without a title should not match or break the search
+	contRev, _, _, _ := setupRandomSampleContentRevision()
+	err = persister.createContentRevisionForTable(contRev, tableName)
+	if err != nil {
+		t.Errorf("Couldn't save content revision to table: %v", err)
+	}
+
+	tests := []struct {
+		search   string
+		expected int
+	}{
+		{"budget", 2},
+		{"BUDGET", 2},
+		{"council budget", 1},
+		{"weekend", 1},
+		{"sports", 0},
+	}
+	for _, test := range tests {
+		dbContentRevisions, err := persister.contentRevisionsByCriteriaFromTable(
+			&model.ContentRevisionCriteria{TitleContains: test.search}, tableName)
+		if err != nil {
+			t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		}
+		if len(dbContentRevisions) != test.expected {
+			t.Errorf("Should have retrieved %v revisions for %v, retrieved %v", test.expected,
+				test.search, len(dbContentRevisions))
+		}
+		for _, rev := range dbContentRevisions {
+			title, _ := rev.Payload()["title"].(string)
+			if !strings.Contains(strings.ToLower(title), strings.Fields(strings.ToLower(test.search))[0]) {
+				t.Errorf("Retrieved revision with non-matching title: %v", title)
+			}
+		}
+	}
+}
+
+func TestContentRevisionsByCriteriaEditorAddress(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	editor1 := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	editor2 := common.HexToAddress("0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A")
+	editors := []common.Address{editor1, editor1, editor1, editor2, editor2}
+	for _, editor := range editors {
+		address, _ := cstrings.RandomHexStr(32)
+		contRev := model.NewContentRevision(common.HexToAddress(address), model.ArticlePayload{},
+			"payloadHash", editor, big.NewInt(mathrand.Int63()), big.NewInt(mathrand.Int63()),
+			"revisionURI", ctime.CurrentEpochSecsInInt64())
+		err := persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Errorf("Couldn't save content revision to table: %v", err)
+		}
+	}
+
+	tests := []struct {
+		editorAddress string
+		expected      int
+	}{
+		{editor1.Hex(), 3},
+		{strings.ToLower(editor1.Hex()), 3},
+		{editor2.Hex(), 2},
+		{common.HexToAddress("0x1").Hex(), 0},
+	}
+	for _, test := range tests {
+		dbContentRevisions, err := persister.contentRevisionsByCriteriaFromTable(
+			&model.ContentRevisionCriteria{EditorAddress: test.editorAddress}, tableName)
+		if err != nil {
+			t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		}
+		if len(dbContentRevisions) != test.expected {
+			t.Errorf("Should have retrieved %v revisions for %v, retrieved %v", test.expected,
+				test.editorAddress, len(dbContentRevisions))
+		}
+		for _, rev := range dbContentRevisions {
+			if !strings.EqualFold(rev.EditorAddress().Hex(), test.editorAddress) {
+				t.Errorf("Retrieved revision with non-matching editor: %v", rev.EditorAddress().Hex())
+			}
+		}
+	}
+}
+
+func TestContentRevisionsByCriteriaContentIDs(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	address, _ := cstrings.RandomHexStr(32)
+	listingAddr := common.HexToAddress(address)
+	// 2 revisions for each of 3 content IDs
+	contentIDs := []int64{10, 11, 12}
+	for _, contentID := range contentIDs {
+		for _, ts := range []int64{1000, 2000} {
+			contRev := model.NewContentRevision(listingAddr, model.ArticlePayload{},
+				"payloadHash", listingAddr, big.NewInt(contentID), big.NewInt(mathrand.Int63()),
+				"revisionURI", ts)
+			err := persister.createContentRevisionForTable(contRev, tableName)
+			if err != nil {
+				t.Errorf("Couldn't save content revision to table: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		criteria *model.ContentRevisionCriteria
+		expected int
+	}{
+		{&model.ContentRevisionCriteria{ContentIDs: []int64{10, 12}}, 4},
+		{&model.ContentRevisionCriteria{ContentIDs: []int64{11}}, 2},
+		{&model.ContentRevisionCriteria{ContentIDs: []int64{10, 12}, LatestOnly: true}, 2},
+		{&model.ContentRevisionCriteria{ContentIDs: []int64{10, 11, 12},
+			ListingAddress: listingAddr.Hex()}, 6},
+		{&model.ContentRevisionCriteria{ContentIDs: []int64{10},
+			ListingAddress: common.HexToAddress("0x1").Hex()}, 0},
+		{&model.ContentRevisionCriteria{ContentIDs: []int64{13}}, 0},
+	}
+	for _, test := range tests {
+		dbContentRevisions, err := persister.contentRevisionsByCriteriaFromTable(test.criteria,
+			tableName)
+		if err != nil {
+			t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		}
+		if len(dbContentRevisions) != test.expected {
+			t.Errorf("Should have retrieved %v revisions for %v, retrieved %v", test.expected,
+				test.criteria.ContentIDs, len(dbContentRevisions))
+		}
+		for _, rev := range dbContentRevisions {
+			found := false
+			for _, contentID := range test.criteria.ContentIDs {
+				if rev.ContractContentID().Int64() == contentID {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Retrieved revision with non-matching content ID: %v",
+					rev.ContractContentID())
+			}
+			if test.criteria.LatestOnly && rev.RevisionDateTs() != 2000 {
+				t.Errorf("Should have only retrieved the latest revision: %v", rev.RevisionDateTs())
+			}
+		}
+	}
+}
+
+func TestContentRevisionByHash(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	seeds := []struct {
+		hash string
+		ts   int64
+	}{
+		{"0xhash1", 2000},
+		{"0xhash1", 1000},
+		{"0xhash1", 3000},
+		{"0xhash2", 1500},
+	}
+	for _, seed := range seeds {
+		address, _ := cstrings.RandomHexStr(32)
+		contRev := model.NewContentRevision(common.HexToAddress(address), model.ArticlePayload{},
+			seed.hash, common.HexToAddress(address), big.NewInt(mathrand.Int63()),
+			big.NewInt(mathrand.Int63()), "revisionURI", seed.ts)
+		err := persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Errorf("Couldn't save content revision to table: %v", err)
+		}
+	}
+
+	contRev, err := persister.contentRevisionByHashFromTable("0xhash1", tableName)
+	if err != nil {
+		t.Fatalf("Error getting content revision by hash: %v", err)
+	}
+	if contRev.PayloadHash() != "0xhash1" {
+		t.Errorf("Should have retrieved a revision with the hash, got %v", contRev.PayloadHash())
+	}
+	if contRev.RevisionDateTs() != 1000 {
+		t.Errorf("Should have retrieved the earliest revision, got %v", contRev.RevisionDateTs())
+	}
+
+	_, err = persister.contentRevisionByHashFromTable("0xnotfound", tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results for an unknown hash: err: %v", err)
+	}
+
+	contRevs, err := persister.contentRevisionsByHashesFromTableInOrder(
+		[]string{"0xhash2", "0xnotfound", "0xhash1"}, tableName)
+	if err != nil {
+		t.Fatalf("Error getting content revisions by hashes: %v", err)
+	}
+	if len(contRevs) != 3 {
+		t.Fatalf("Should have retrieved 3 results, got %v", len(contRevs))
+	}
+	if contRevs[0] == nil || contRevs[0].PayloadHash() != "0xhash2" {
+		t.Errorf("Should have retrieved the revision for the first hash")
+	}
+	if contRevs[1] != nil {
+		t.Errorf("Should have retrieved nil for the unknown hash")
+	}
+	if contRevs[2] == nil || contRevs[2].RevisionDateTs() != 1000 {
+		t.Errorf("Should have retrieved the earliest revision for the last hash")
+	}
+
+	_, err = persister.contentRevisionsByHashesFromTableInOrder([]string{}, tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results for no hashes: err: %v", err)
+	}
+}
+
+func TestContentRevisionCount(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	address, _ := cstrings.RandomHexStr(32)
+	listingAddr := common.HexToAddress(address)
+	// 2 articles with several revisions each
+	for _, numRevisions := range []int{3, 4} {
+		contentID := big.NewInt(mathrand.Int63())
+		for i := 0; i < numRevisions; i++ {
+			contRev, _, _, _ := setupSampleContentRevision(listingAddr, contentID)
+			err := persister.createContentRevisionForTable(contRev, tableName)
+			if err != nil {
+				t.Errorf("Couldn't save content revision to table: %v", err)
+			}
+		}
+	}
+	// Revision on another listing should not be counted
+	otherAddress, _ := cstrings.RandomHexStr(32)
+	contRev, _, _, _ := setupSampleContentRevision(common.HexToAddress(otherAddress),
+		big.NewInt(mathrand.Int63()))
+	err := persister.createContentRevisionForTable(contRev, tableName)
+	if err != nil {
+		t.Errorf("Couldn't save content revision to table: %v", err)
+	}
+
+	count, err := persister.contentRevisionCountFromTable(listingAddr, tableName)
+	if err != nil {
+		t.Errorf("Error getting content revision count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Should have counted 2 articles, counted %v", count)
 	}
 
-	// retrieve from table
-	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
-		tableName)
+	count, err = persister.contentRevisionCountFromTable(common.HexToAddress("0x1"), tableName)
 	if err != nil {
-		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		t.Errorf("Error getting content revision count: %v", err)
 	}
+	if count != 0 {
+		t.Errorf("Should have counted 0 articles, counted %v", count)
+	}
+}
 
-	if len(dbContentRevisions) != numRevisions {
-		t.Errorf("Only retrieved %v listings but should have retrieved %v", len(dbContentRevisions), numRevisions)
+func TestLatestRevisionsByListing(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	address, _ := cstrings.RandomHexStr(32)
+	listingAddr := common.HexToAddress(address)
+	editorAddr := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	ts := ctime.CurrentEpochSecsInInt64()
+	// Newest revision ID for each content ID
+	latestRevisionIDs := map[int64]int64{}
+	for contentID := int64(1); contentID <= 3; contentID++ {
+		for revisionID := int64(0); revisionID < contentID+1; revisionID++ {
+			contRev := model.NewContentRevision(listingAddr, model.ArticlePayload{},
+				"payloadHash", editorAddr, big.NewInt(contentID), big.NewInt(revisionID),
+				"revisionURI", ts+revisionID)
+			err := persister.createContentRevisionForTable(contRev, tableName)
+			if err != nil {
+				t.Errorf("Couldn't save content revision to table: %v", err)
+			}
+			latestRevisionIDs[contentID] = revisionID
+		}
 	}
 
-	// Test addr, content id, and revision id
-	contentID = contractContentID.Int64()
-	revisionID := contractRevisionIDs[0].Int64()
-	criteria = &model.ContentRevisionCriteria{
-		ListingAddress: listingAddr.Hex(),
-		ContentID:      &contentID,
-		RevisionID:     &revisionID,
+	revisions, err := persister.latestRevisionsByListingFromTable(listingAddr, tableName)
+	if err != nil {
+		t.Errorf("Error getting latest revisions: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("Should have retrieved one revision per article, retrieved %v", len(revisions))
+	}
+	for i, rev := range revisions {
+		contentID := rev.ContractContentID().Int64()
+		if contentID != int64(i+1) {
+			t.Errorf("Should have sorted revisions by content ID, got %v at %v", contentID, i)
+		}
+		if rev.ContractRevisionID().Int64() != latestRevisionIDs[contentID] {
+			t.Errorf("Should have retrieved latest revision %v for content %v, got %v",
+				latestRevisionIDs[contentID], contentID, rev.ContractRevisionID())
+		}
+	}
+
+	revisions, err = persister.latestRevisionsByListingFromTable(common.HexToAddress("0x1"), tableName)
+	if err != nil {
+		t.Errorf("Error getting latest revisions: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("Should have retrieved no revisions, retrieved %v", len(revisions))
+	}
+}
+
+func TestContentRevisionsModifiedAfter(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	editorAddr := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	ts := ctime.CurrentEpochSecsInInt64()
+	// Revisions on 2 listings at ts+1 to ts+6, with 2 revisions at ts+4
+	revisionOffsets := []int64{1, 2, 3, 4, 4, 5, 6}
+	for i, offset := range revisionOffsets {
+		address, _ := cstrings.RandomHexStr(32)
+		if i%2 == 0 {
+			address = "0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d"
+		}
+		contRev := model.NewContentRevision(common.HexToAddress(address), model.ArticlePayload{},
+			"payloadHash", editorAddr, big.NewInt(int64(i)), big.NewInt(0), "revisionURI",
+			ts+offset)
+		err := persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Fatalf("Couldn't save content revision to table: %v", err)
+		}
+	}
+
+	checkWindow := func(afterTs int64, limit int, expected []int64) []*model.ContentRevision {
+		revisions, err := persister.contentRevisionsModifiedAfterFromTable(afterTs, limit, tableName)
+		if err != nil {
+			t.Fatalf("Error getting revisions modified after: %v", err)
+		}
+		if len(revisions) != len(expected) {
+			t.Fatalf("Should have retrieved %v revisions, retrieved %v", len(expected), len(revisions))
+		}
+		for i, rev := range revisions {
+			if rev.RevisionDateTs() != ts+expected[i] {
+				t.Errorf("Should have retrieved revision at ts+%v, got ts+%v", expected[i],
+					rev.RevisionDateTs()-ts)
+			}
+		}
+		return revisions
+	}
+
+	// Poll incrementally from the last revision timestamp
+	revisions := checkWindow(ts, 2, []int64{1, 2})
+	lastTs := revisions[len(revisions)-1].RevisionDateTs()
+	// Revisions sharing the timestamp of the last revision are not cut off
+	revisions = checkWindow(lastTs, 2, []int64{3, 4, 4})
+	lastTs = revisions[len(revisions)-1].RevisionDateTs()
+	revisions = checkWindow(lastTs, 2, []int64{5, 6})
+	lastTs = revisions[len(revisions)-1].RevisionDateTs()
+	checkWindow(lastTs, 2, []int64{})
+
+	// No limit returns all revisions in the window
+	checkWindow(ts+2, 0, []int64{3, 4, 4, 5, 6})
+}
+
+func TestMostRecentContentRevision(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	_, err := persister.mostRecentContentRevisionFromTable(tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten ErrPersisterNoResults for an empty table: %v", err)
+	}
+
+	editorAddr := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	ts := ctime.CurrentEpochSecsInInt64()
+	// Revisions on different listings, saved out of timestamp order
+	revisionOffsets := []int64{3, 1, 5, 2, 4}
+	for i, offset := range revisionOffsets {
+		address, _ := cstrings.RandomHexStr(32)
+		contRev := model.NewContentRevision(common.HexToAddress(address), model.ArticlePayload{},
+			fmt.Sprintf("payloadHash%v", i), editorAddr, big.NewInt(int64(i)), big.NewInt(0),
+			"revisionURI", ts+offset)
+		err = persister.createContentRevisionForTable(contRev, tableName)
+		if err != nil {
+			t.Fatalf("Couldn't save content revision to table: %v", err)
+		}
+	}
+
+	contRev, err := persister.mostRecentContentRevisionFromTable(tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error getting the most recent revision: %v", err)
+	}
+	if contRev.RevisionDateTs() != ts+5 {
+		t.Errorf("Should have retrieved the revision at ts+5, got ts+%v", contRev.RevisionDateTs()-ts)
+	}
+	if contRev.PayloadHash() != "payloadHash2" {
+		t.Errorf("Should have retrieved the newest revision: %v", contRev.PayloadHash())
+	}
+}
+
+func TestNilResultsContentRevision(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	address1, _ := cstrings.RandomHexStr(32)
+	contractAddress := common.HexToAddress(address1)
+	contentID := big.NewInt(0)
+	revisionID := big.NewInt(0)
+	cr, err := persister.contentRevisionFromTable(contractAddress, contentID, revisionID, tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Error message is not %v but %v", cpersist.ErrPersisterNoResults, err)
+	}
+	if cr != nil {
+		t.Errorf("Content Revision should be nil but is %v", cr)
+	}
+}
+
+// Test update content revision -- TODO(IS) create an update where address, contentid, revisionid don't change
+func TestUpdateContentRevision(t *testing.T) {
+}
+
+// TestDeleteContentRevision tests that the deleting the ContentRevision works
+func TestDeleteContentRevision(t *testing.T) {
+
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+
+	defer deleteTestTable(t, persister, tableName)
+
+	// sample contentRevision
+	modelContentRevision, _, _, _ := setupRandomSampleContentRevision()
+
+	// insert to table
+	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
+	if err != nil {
+		t.Errorf("error saving content revision: %v", err)
+	}
+
+	var numRowsb int
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRowsb)
+	if err != nil {
+		t.Errorf("Problem getting count from table: %v", err)
+	}
+	if numRowsb != 1 {
+		t.Errorf("Number of rows in table should be 1 but is: %v", numRowsb)
+	}
+
+	//delete rows
+	err = persister.deleteContentRevisionFromTable(modelContentRevision, tableName)
+	if err != nil {
+		t.Errorf("Coud not delete content revision: %v", err)
+	}
+
+	var numRows int
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRows)
+	if err != nil {
+		t.Errorf("Problem getting count from table: %v", err)
+	}
+	if numRows != 0 {
+		t.Errorf("Number of rows in table should be 0 but is: %v", numRows)
+	}
+}
+
+/*
+Helpers for governance_event table tests:
+*/
+
+func setupSampleGovernanceEvent(randListing bool) (*model.GovernanceEvent, common.Address, string, common.Hash) {
+	var listingAddr common.Address
+	if randListing {
+		address1, _ := cstrings.RandomHexStr(32)
+		listingAddr = common.HexToAddress(address1)
+	} else {
+		// keep listingAddress constant
+		listingAddr = common.HexToAddress(testAddress)
+	}
+
+	metadata := model.Metadata{}
+	governanceEventType := "governanceeventtypehere"
+	creationDateTs := ctime.CurrentEpochSecsInInt64()
+	lastUpdatedDateTs := ctime.CurrentEpochSecsInInt64() + 1
+	eventHash, _ := cstrings.RandomHexStr(5)
+	blockNumber := uint64(88888)
+	tHash, _ := cstrings.RandomHexStr(5)
+	txHash := common.HexToHash(tHash)
+	txIndex := uint(4)
+	blockHash := common.Hash{}
+	index := uint(2)
+	testGovernanceEvent := model.NewGovernanceEvent(listingAddr, metadata, governanceEventType,
+		creationDateTs, lastUpdatedDateTs, eventHash, blockNumber, txHash, txIndex, blockHash, index)
+	return testGovernanceEvent, listingAddr, eventHash, txHash
+}
+
+func setupGovEventTable(t *testing.T) *PostgresPersister {
+	persister := setupTestTable(t, govTestTableName)
+	return persister
+}
+
+func createAndSaveTestGovEvent(t *testing.T, persister *PostgresPersister, randListing bool) (*model.GovernanceEvent, common.Address, string, common.Hash) {
+	// sample govEvent
+	modelGovernanceEvent, listingAddr, eventHash, txHash := setupSampleGovernanceEvent(false)
+
+	// insert to table
+	err := persister.createGovernanceEventInTable(modelGovernanceEvent, persister.GetTableName(govTestTableName))
+	if err != nil {
+		t.Errorf("error saving GovernanceEvent: %v", err)
+	}
+	return modelGovernanceEvent, listingAddr, eventHash, txHash
+}
+
+/*
+All tests for governance_event table:
+*/
+
+// TestCreateGovernanceEvent tests that a GovernanceEvent is created
+func TestCreateGovernanceEvent(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	_, _, _, _ = createAndSaveTestGovEvent(t, persister, false)
+
+	// check row is there
+	var numRowsb int
+	err := persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRowsb)
+	if err != nil {
+		t.Errorf("Problem getting count from table: %v", err)
+	}
+	if numRowsb != 1 {
+		t.Errorf("Number of rows in table should be 0 but is: %v", numRowsb)
+	}
+}
+
+func TestCreateGovernanceEvents(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	numEvents := maxInsertBatchSize + 3
+	govEvents := make([]*model.GovernanceEvent, numEvents)
+	for index := range govEvents {
+		govEvents[index], _, _, _ = setupSampleGovernanceEvent(true)
+	}
+	err := persister.createGovernanceEventsInTable(govEvents, tableName)
+	if err != nil {
+		t.Fatalf("Should not have failed to create governance events: err: %v", err)
+	}
+
+	var numRows int
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRows)
+	if err != nil {
+		t.Errorf("Problem getting count from table: %v", err)
+	}
+	if numRows != numEvents {
+		t.Errorf("Number of rows in table should be %v but is: %v", numEvents, numRows)
+	}
+
+	lastBlockData := govEvents[numEvents-1].BlockData()
+	dbGovEvent, err := persister.governanceEventsByTxHashFromTable(
+		common.HexToHash(lastBlockData.TxHash()), tableName)
+	if err != nil || len(dbGovEvent) != 1 {
+		t.Errorf("Should have retrieved the last event in the batch: err: %v", err)
+	}
+
+	// Fails the whole batch if an event is invalid
+	validEvent, _, _, _ := setupSampleGovernanceEvent(true)
+	invalidEvent := model.NewGovernanceEvent(common.HexToAddress(testAddress), model.Metadata{},
+		"Challenge", 1000, 1000, "eventhash", 88888, common.Hash{}, 4, common.Hash{}, 2)
+	err = persister.createGovernanceEventsInTable([]*model.GovernanceEvent{validEvent, invalidEvent},
+		tableName)
+	if err == nil {
+		t.Errorf("Should have failed to create governance events with an invalid event")
+	}
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRows)
+	if err != nil {
+		t.Errorf("Problem getting count from table: %v", err)
+	}
+	if numRows != numEvents {
+		t.Errorf("Should not have created any events from the failed batch, rows: %v", numRows)
+	}
+}
+
+func TestUpsertGovernanceEvent(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	_, err := persister.db.Exec(postgres.CreateGovernanceEventTableMigrationQuery(tableName))
+	if err != nil {
+		t.Errorf("Error running gov event migration: %v", err)
 	}
 
-	// retrieve from table
-	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
-		tableName)
+	modelGovernanceEvent, _, eventHash, _ := setupSampleGovernanceEvent(false)
+	err = persister.upsertGovernanceEventInTable(modelGovernanceEvent, tableName)
 	if err != nil {
-		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		t.Errorf("Error upserting gov event: %v", err)
 	}
 
-	if len(dbContentRevisions) != 1 {
-		t.Errorf("Should have retrieved one revision: %v", len(dbContentRevisions))
+	// Upsert the same event again with a new last updated ts
+	newLastUpdated := modelGovernanceEvent.LastUpdatedDateTs() + 100
+	modelGovernanceEvent.SetLastUpdatedDateTs(newLastUpdated)
+	err = persister.upsertGovernanceEventInTable(modelGovernanceEvent, tableName)
+	if err != nil {
+		t.Errorf("Error upserting gov event again: %v", err)
 	}
 
-	// Test offset, limit
-	criteria = &model.ContentRevisionCriteria{
-		ListingAddress: listingAddr.Hex(),
-		Offset:         1,
-		Count:          3,
+	var numRowsb int
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRowsb)
+	if err != nil {
+		t.Errorf("Problem getting count from table: %v", err)
+	}
+	if numRowsb != 1 {
+		t.Errorf("Number of rows in table should be 1 but is: %v", numRowsb)
 	}
 
-	// retrieve from table
-	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
-		tableName)
+	var lastUpdated int64
+	err = persister.db.QueryRow(
+		fmt.Sprintf("SELECT last_updated_timestamp FROM %v WHERE event_hash=$1;", tableName),
+		eventHash,
+	).Scan(&lastUpdated)
 	if err != nil {
-		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		t.Errorf("Problem getting last updated from table: %v", err)
+	}
+	if lastUpdated != newLastUpdated {
+		t.Errorf("Last updated should have been updated to %v but is %v", newLastUpdated, lastUpdated)
 	}
+}
 
-	if len(dbContentRevisions) != 3 {
-		t.Errorf("Should have retrieved 3 revisions: %v", len(dbContentRevisions))
+func TestNilResultsGovernanceEvent(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	txHashSample, _ := cstrings.RandomHexStr(30)
+	txHash := common.HexToHash(txHashSample)
+	govEvent, err := persister.governanceEventsByTxHashFromTable(txHash, tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Error should be ErrPersisterNoResults but is %v", err)
+	}
+	if len(govEvent) != 0 {
+		t.Errorf("govEvent list should be empty but is %v", govEvent)
 	}
+}
 
-	// Test from and before
-	minTs := int64(math.MaxInt64)
-	maxTs := int64(0)
-	for _, rev := range testContentRevisions {
-		revTs := rev.RevisionDateTs()
-		if revTs < minTs {
-			minTs = revTs
-		}
-		if revTs > maxTs {
-			maxTs = revTs
-		}
+func TestNoResultsGovernanceEventsByListingAddress(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	govEvents, err := persister.governanceEventsByListingAddressFromTable(
+		common.HexToAddress(testAddress), tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Error should be ErrPersisterNoResults but is %v", err)
 	}
-	criteria = &model.ContentRevisionCriteria{
-		ListingAddress: listingAddr.Hex(),
-		FromTs:         minTs - 10,
-		BeforeTs:       maxTs + 10,
+	if len(govEvents) != 0 {
+		t.Errorf("govEvents should be empty but is %v", govEvents)
 	}
+}
+
+// TestGovernanceEventsByListingAddress tests that a GovernanceEvent is properly retrieved
+func TestGovernanceEventsByListingAddress(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	_, listingAddr, _, _ := createAndSaveTestGovEvent(t, persister, false)
 
 	// retrieve from table
-	dbContentRevisions, err = persister.contentRevisionsByCriteriaFromTable(criteria,
-		tableName)
+	dbGovEvents, err := persister.governanceEventsByListingAddressFromTable(listingAddr, tableName)
 	if err != nil {
-		t.Errorf("Error with persister.contentRevisionsByCriteria: %v", err)
+		t.Errorf("Wasn't able to get governance event from postgres table: %v", err)
 	}
 
-	if len(dbContentRevisions) != numRevisions {
-		t.Errorf("Should have retrieved %v revisions: %v", numRevisions, len(dbContentRevisions))
+	if len(dbGovEvents) != 1 {
+		t.Errorf("length of governance events should be 1 but is: %v", len(dbGovEvents))
 	}
+
 }
 
-func TestNilResultsContentRevision(t *testing.T) {
-	persister := setupTestTable(t, contentRevisionTestTableName)
+// TestDBGovEventToModelGovEvent tests that the db listing can be properly converted to model listing
+func TestDBGovEventToModelGovEvent(t *testing.T) {
+	persister := setupGovEventTable(t)
 	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
+	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	address1, _ := cstrings.RandomHexStr(32)
-	contractAddress := common.HexToAddress(address1)
-	contentID := big.NewInt(0)
-	revisionID := big.NewInt(0)
-	cr, err := persister.contentRevisionFromTable(contractAddress, contentID, revisionID, tableName)
-	if err != cpersist.ErrPersisterNoResults {
-		t.Errorf("Error message is not %v but %v", cpersist.ErrPersisterNoResults, err)
+	modelGovernanceEvent, listingAddr, _, _ := createAndSaveTestGovEvent(t, persister, false)
+
+	// retrieve from table
+	dbGovEvents, err := persister.governanceEventsByListingAddressFromTable(listingAddr, tableName)
+	if err != nil {
+		t.Errorf("Wasn't able to get governance event from postgres table: %v", err)
 	}
-	if cr != nil {
-		t.Errorf("Content Revision should be nil but is %v", cr)
+	modelGovEventFromDB := dbGovEvents[0]
+
+	// do a deep equal
+	// check that retrieved fields match with inserted listing
+	if !reflect.DeepEqual(modelGovernanceEvent, modelGovEventFromDB) {
+		t.Errorf("listing from DB: %v, doesn't match inserted listing: %v", modelGovEventFromDB, modelGovernanceEvent)
 	}
-}
 
-// Test update content revision -- TODO(IS) create an update where address, contentid, revisionid don't change
-func TestUpdateContentRevision(t *testing.T) {
 }
 
-// TestDeleteContentRevision tests that the deleting the ContentRevision works
-func TestDeleteContentRevision(t *testing.T) {
+// TODO(IS): test update gov event -- test update that's not by listing
 
-	persister := setupTestTable(t, contentRevisionTestTableName)
+// TestDeleteGovernanceEvent tests that the deleting the Governance Event works
+// TODO(IS) : this will delete more than you want. need to put some kind of hash for the gov event.
+func TestDeleteGovernanceEvent(t *testing.T) {
+	persister := setupGovEventTable(t)
 	defer persister.Close()
-	tableName := persister.GetTableName(contentRevisionTestTableName)
-
+	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	// sample contentRevision
-	modelContentRevision, _, _, _ := setupRandomSampleContentRevision()
-
-	// insert to table
-	err := persister.createContentRevisionForTable(modelContentRevision, tableName)
-	if err != nil {
-		t.Errorf("error saving content revision: %v", err)
-	}
+	modelGovernanceEvent, _, _, _ := createAndSaveTestGovEvent(t, persister, false)
 
 	var numRowsb int
-	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRowsb)
+	err := persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v", tableName)).Scan(&numRowsb)
 	if err != nil {
 		t.Errorf("Problem getting count from table: %v", err)
 	}
@@ -1643,13 +3690,13 @@ func TestDeleteContentRevision(t *testing.T) {
 	}
 
 	//delete rows
-	err = persister.deleteContentRevisionFromTable(modelContentRevision, tableName)
+	err = persister.deleteGovernanceEventFromTable(modelGovernanceEvent, tableName)
 	if err != nil {
-		t.Errorf("Coud not delete content revision: %v", err)
+		t.Errorf("Error deleting governance event: %v", err)
 	}
 
 	var numRows int
-	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRows)
+	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v", tableName)).Scan(&numRows)
 	if err != nil {
 		t.Errorf("Problem getting count from table: %v", err)
 	}
@@ -1658,245 +3705,550 @@ func TestDeleteContentRevision(t *testing.T) {
 	}
 }
 
-/*
-Helpers for governance_event table tests:
-*/
+// TestGovEventsByCriteria tests GovernanceEvent by criteria query
+func TestGovEventsByCriteria(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
 
-func setupSampleGovernanceEvent(randListing bool) (*model.GovernanceEvent, common.Address, string, common.Hash) {
 	var listingAddr common.Address
-	if randListing {
-		address1, _ := cstrings.RandomHexStr(32)
-		listingAddr = common.HexToAddress(address1)
-	} else {
-		// keep listingAddress constant
-		listingAddr = common.HexToAddress(testAddress)
+	var timeMiddle int64
+	var timeStart int64
+	var modelGovernanceEvent *model.GovernanceEvent
+	// create some governance events w constant listing address and save them to DB
+	for i := 1; i <= 30; i++ {
+		// TODO: just set timestamp for event bc there is still a probability these times won't be what you think.
+		if i < 20 {
+			timeStart = ctime.CurrentEpochSecsInInt64()
+		}
+		if i == 20 {
+			time.Sleep(1 * time.Second)
+			timeMiddle = ctime.CurrentEpochSecsInInt64()
+		}
+		modelGovernanceEvent, listingAddr, _, _ = createAndSaveTestGovEvent(t, persister, true)
 	}
 
-	metadata := model.Metadata{}
-	governanceEventType := "governanceeventtypehere"
-	creationDateTs := ctime.CurrentEpochSecsInInt64()
-	lastUpdatedDateTs := ctime.CurrentEpochSecsInInt64() + 1
-	eventHash, _ := cstrings.RandomHexStr(5)
-	blockNumber := uint64(88888)
-	tHash, _ := cstrings.RandomHexStr(5)
-	txHash := common.HexToHash(tHash)
-	txIndex := uint(4)
-	blockHash := common.Hash{}
-	index := uint(2)
-	testGovernanceEvent := model.NewGovernanceEvent(listingAddr, metadata, governanceEventType,
-		creationDateTs, lastUpdatedDateTs, eventHash, blockNumber, txHash, txIndex, blockHash, index)
-	return testGovernanceEvent, listingAddr, eventHash, txHash
-}
+	govEvents, err := persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
+		ListingAddress: listingAddr.Hex(),
+		Count:          1,
+	}, tableName)
 
-func setupGovEventTable(t *testing.T) *PostgresPersister {
-	persister := setupTestTable(t, govTestTableName)
-	return persister
-}
+	if err != nil {
+		t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
+	}
 
-func createAndSaveTestGovEvent(t *testing.T, persister *PostgresPersister, randListing bool) (*model.GovernanceEvent, common.Address, string, common.Hash) {
-	// sample govEvent
-	modelGovernanceEvent, listingAddr, eventHash, txHash := setupSampleGovernanceEvent(false)
+	if len(govEvents) != 1 {
+		t.Errorf("Should have only retrieved one governance event but got %v", len(govEvents))
+	}
+
+	if modelGovernanceEvent.ListingAddress() != listingAddr {
+		t.Errorf("Listing address is %v but should be %v ", modelGovernanceEvent.ListingAddress().Hex(), listingAddr.Hex())
+	}
+
+	govEvents, err = persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
+		ListingAddress: listingAddr.Hex(),
+		CreatedFromTs:  timeStart,
+	}, tableName)
 
-	// insert to table
-	err := persister.createGovernanceEventInTable(modelGovernanceEvent, persister.GetTableName(govTestTableName))
 	if err != nil {
-		t.Errorf("error saving GovernanceEvent: %v", err)
+		t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
 	}
-	return modelGovernanceEvent, listingAddr, eventHash, txHash
+
+	if len(govEvents) != 11 {
+		t.Errorf("Should have retrieved 11 governance events but only got %v", len(govEvents))
+	}
+
+	govEvents, err = persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
+		ListingAddress:  listingAddr.Hex(),
+		CreatedBeforeTs: timeMiddle,
+	}, tableName)
+
+	if err != nil {
+		t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
+	}
+	if len(govEvents) != 19 {
+		t.Errorf("Should have retrieved 19 governance events but only got %v", len(govEvents))
+	}
+
 }
 
-/*
-All tests for governance_event table:
-*/
+// TestGovEventsByCriteria tests GovernanceEvent by txhash query
+func TestGovEventsByCriteriaContractAddress(t *testing.T) {
+	persister := setupGovEventTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	tcrAddr1 := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	tcrAddr2 := common.HexToAddress("0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A")
+	contractAddrs := []common.Address{tcrAddr1, tcrAddr1, tcrAddr2, {}}
+	for _, contractAddr := range contractAddrs {
+		govEvent, _, _, _ := setupSampleGovernanceEvent(true)
+		govEvent.SetContractAddress(contractAddr)
+		err := persister.createGovernanceEventInTable(govEvent, tableName)
+		if err != nil {
+			t.Errorf("error saving GovernanceEvent: %v", err)
+		}
+	}
+
+	tests := []struct {
+		contractAddress string
+		expected        int
+	}{
+		{tcrAddr1.Hex(), 2},
+		{strings.ToLower(tcrAddr1.Hex()), 2},
+		{tcrAddr2.Hex(), 1},
+		{"", 4},
+	}
+	for _, test := range tests {
+		govEvents, err := persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
+			ContractAddress: test.contractAddress,
+		}, tableName)
+		if err != nil {
+			t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
+		}
+		if len(govEvents) != test.expected {
+			t.Errorf("Should have retrieved %v gov events for %v, got %v", test.expected,
+				test.contractAddress, len(govEvents))
+		}
+		if test.contractAddress == "" {
+			continue
+		}
+		for _, govEvent := range govEvents {
+			if !strings.EqualFold(govEvent.ContractAddress().Hex(), test.contractAddress) {
+				t.Errorf("Retrieved gov event with non-matching contract: %v",
+					govEvent.ContractAddress().Hex())
+			}
+		}
+	}
+}
 
-// TestCreateGovernanceEvent tests that a GovernanceEvent is created
-func TestCreateGovernanceEvent(t *testing.T) {
+func TestCountGovernanceEventsByCriteria(t *testing.T) {
 	persister := setupGovEventTable(t)
 	defer persister.Close()
 	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	_, _, _, _ = createAndSaveTestGovEvent(t, persister, false)
+	var listingAddr common.Address
+	for i := 0; i < 5; i++ {
+		_, listingAddr, _, _ = createAndSaveTestGovEvent(t, persister, true)
+	}
+	// Events for other listings
+	for i := 0; i < 3; i++ {
+		govEvent, _, _, _ := setupSampleGovernanceEvent(true)
+		err := persister.createGovernanceEventInTable(govEvent, tableName)
+		if err != nil {
+			t.Errorf("error saving GovernanceEvent: %v", err)
+		}
+	}
 
-	// check row is there
-	var numRowsb int
-	err := persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v;", tableName)).Scan(&numRowsb)
+	criterias := []*model.GovernanceEventCriteria{
+		{},
+		{ListingAddress: listingAddr.Hex()},
+		{ListingAddress: listingAddr.Hex(), Count: 2},
+		{Offset: 6},
+		{CreatedBeforeTs: 1},
+	}
+	for _, criteria := range criterias {
+		count, err := persister.countGovernanceEventsByCriteriaFromTable(criteria, tableName)
+		if err != nil {
+			t.Errorf("Error getting count from table: %v", err)
+		}
+		unlimited := *criteria
+		unlimited.Offset = 0
+		unlimited.Count = 0
+		govEvents, err := persister.governanceEventsByCriteriaFromTable(&unlimited, tableName)
+		if err != nil {
+			t.Errorf("Error getting gov events from table: %v", err)
+		}
+		if count != len(govEvents) {
+			t.Errorf("Count should match number of unlimited results: %v, %v", count, len(govEvents))
+		}
+	}
+
+	count, err := persister.countGovernanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ListingAddress: listingAddr.Hex(), Count: 2}, tableName)
 	if err != nil {
-		t.Errorf("Problem getting count from table: %v", err)
+		t.Errorf("Error getting count from table: %v", err)
 	}
-	if numRowsb != 1 {
-		t.Errorf("Number of rows in table should be 0 but is: %v", numRowsb)
+	if count != 5 {
+		t.Errorf("Should have counted 5 gov events for the listing ignoring count, got %v", count)
 	}
 }
 
-func TestNilResultsGovernanceEvent(t *testing.T) {
+func TestGovernanceEventsByCriteriaChallengeID(t *testing.T) {
 	persister := setupGovEventTable(t)
 	defer persister.Close()
 	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	txHashSample, _ := cstrings.RandomHexStr(30)
-	txHash := common.HexToHash(txHashSample)
-	govEvent, err := persister.governanceEventsByTxHashFromTable(txHash, tableName)
+	// Seed 2 events per challenge for 3 challenges
+	for i := 0; i < 6; i++ {
+		govEvent, listingAddr, eventHash, txHash := setupSampleGovernanceEvent(true)
+		metadata := model.Metadata{
+			"ListingAddress": listingAddr.Hex(),
+			"ChallengeID":    big.NewInt(int64(i%3 + 1)),
+		}
+		blockData := govEvent.BlockData()
+		govEvent = model.NewGovernanceEvent(listingAddr, metadata, "AppealGranted",
+			govEvent.CreationDateTs(), govEvent.LastUpdatedDateTs(), eventHash,
+			blockData.BlockNumber(), txHash, blockData.TxIndex(), common.Hash{},
+			blockData.Index())
+		err := persister.createGovernanceEventInTable(govEvent, tableName)
+		if err != nil {
+			t.Fatalf("error saving GovernanceEvent: %v", err)
+		}
+	}
+	// Event without a challenge ID
+	_, _, _, _ = createAndSaveTestGovEvent(t, persister, true)
+
+	challengeID := 2
+	govEvents, err := persister.governanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ChallengeID: &challengeID}, tableName)
 	if err != nil {
-		t.Errorf("Error should be nil but is %v", err)
+		t.Fatalf("Error getting gov events from table: %v", err)
 	}
-	if len(govEvent) != 0 {
-		t.Errorf("govEvent list should be empty but is %v", govEvent)
+	if len(govEvents) != 2 {
+		t.Fatalf("Should have retrieved 2 gov events for the challenge, got %v", len(govEvents))
+	}
+	for _, govEvent := range govEvents {
+		if fmt.Sprint(govEvent.Metadata()["ChallengeID"]) != "2" {
+			t.Errorf("Should have only retrieved events for challenge 2, got %v",
+				govEvent.Metadata()["ChallengeID"])
+		}
+	}
+
+	count, err := persister.countGovernanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ChallengeID: &challengeID, Count: 1}, tableName)
+	if err != nil {
+		t.Errorf("Error getting count from table: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Should have counted 2 gov events for the challenge, got %v", count)
+	}
+
+	missingID := 4
+	govEvents, err = persister.governanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ChallengeID: &missingID}, tableName)
+	if err != nil {
+		t.Errorf("Error getting gov events from table: %v", err)
+	}
+	if len(govEvents) != 0 {
+		t.Errorf("Should have retrieved no gov events for a missing challenge, got %v", len(govEvents))
 	}
 }
 
-// TestGovernanceEventsByListingAddress tests that a GovernanceEvent is properly retrieved
-func TestGovernanceEventsByListingAddress(t *testing.T) {
+func TestGovernanceEventsByCriteriaLatestPerListing(t *testing.T) {
 	persister := setupGovEventTable(t)
 	defer persister.Close()
 	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	_, listingAddr, _, _ := createAndSaveTestGovEvent(t, persister, false)
+	// Seed 3 events each for 3 listings
+	baseTs := ctime.CurrentEpochSecsInInt64()
+	latestHashes := map[common.Address]string{}
+	lastHashes := map[int64]string{}
+	var listingAddr common.Address
+	for i := 0; i < 3; i++ {
+		address, _ := cstrings.RandomHexStr(32)
+		listingAddr = common.HexToAddress(address)
+		for j := 0; j < 3; j++ {
+			govEvent, _, eventHash, txHash := setupSampleGovernanceEvent(true)
+			blockData := govEvent.BlockData()
+			govEvent = model.NewGovernanceEvent(listingAddr, model.Metadata{}, "Application",
+				baseTs+int64(j), govEvent.LastUpdatedDateTs(), eventHash,
+				blockData.BlockNumber(), txHash, blockData.TxIndex(), common.Hash{},
+				blockData.Index())
+			err := persister.createGovernanceEventInTable(govEvent, tableName)
+			if err != nil {
+				t.Fatalf("error saving GovernanceEvent: %v", err)
+			}
+			latestHashes[listingAddr] = eventHash
+			lastHashes[baseTs+int64(j)] = eventHash
+		}
+	}
 
-	// retrieve from table
-	dbGovEvents, err := persister.governanceEventsByListingAddressFromTable(listingAddr, tableName)
+	govEvents, err := persister.governanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{LatestPerListing: true}, tableName)
 	if err != nil {
-		t.Errorf("Wasn't able to get governance event from postgres table: %v", err)
+		t.Fatalf("Error getting gov events from table: %v", err)
+	}
+	if len(govEvents) != 3 {
+		t.Fatalf("Should have retrieved 1 gov event per listing, got %v", len(govEvents))
+	}
+	for _, govEvent := range govEvents {
+		if govEvent.EventHash() != latestHashes[govEvent.ListingAddress()] {
+			t.Errorf("Should have retrieved the latest event for %v", govEvent.ListingAddress().Hex())
+		}
+		if govEvent.CreationDateTs() != baseTs+2 {
+			t.Errorf("Should have retrieved the latest creation date, got %v", govEvent.CreationDateTs())
+		}
 	}
 
-	if len(dbGovEvents) != 1 {
-		t.Errorf("length of governance events should be 1 but is: %v", len(dbGovEvents))
+	count, err := persister.countGovernanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{LatestPerListing: true}, tableName)
+	if err != nil {
+		t.Errorf("Error getting count from table: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Should have counted 1 gov event per listing, got %v", count)
+	}
+
+	govEvents, err = persister.governanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ListingAddress: listingAddr.Hex(), LatestPerListing: true},
+		tableName)
+	if err != nil {
+		t.Fatalf("Error getting gov events from table: %v", err)
+	}
+	if len(govEvents) != 1 {
+		t.Fatalf("Should have retrieved 1 gov event for the listing, got %v", len(govEvents))
+	}
+	if govEvents[0].EventHash() != latestHashes[listingAddr] {
+		t.Errorf("Should have retrieved the latest event for the listing")
+	}
+	// Filters are applied before picking the latest event
+	govEvents, err = persister.governanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ListingAddress: listingAddr.Hex(),
+			CreatedBeforeTs: baseTs + 2, LatestPerListing: true}, tableName)
+	if err != nil {
+		t.Fatalf("Error getting gov events from table: %v", err)
+	}
+	if len(govEvents) != 1 || govEvents[0].EventHash() != lastHashes[baseTs+1] {
+		t.Errorf("Should have retrieved the latest event matching the filters: %v", govEvents)
 	}
 
+	// Events at the same creation date return one event, the greatest event hash
+	govEvent, _, eventHash, txHash := setupSampleGovernanceEvent(true)
+	blockData := govEvent.BlockData()
+	govEvent = model.NewGovernanceEvent(listingAddr, model.Metadata{}, "Application",
+		baseTs+2, govEvent.LastUpdatedDateTs(), eventHash, blockData.BlockNumber(), txHash,
+		blockData.TxIndex(), common.Hash{}, blockData.Index())
+	err = persister.createGovernanceEventInTable(govEvent, tableName)
+	if err != nil {
+		t.Fatalf("error saving GovernanceEvent: %v", err)
+	}
+	expectedHash := latestHashes[listingAddr]
+	if eventHash > expectedHash {
+		expectedHash = eventHash
+	}
+	govEvents, err = persister.governanceEventsByCriteriaFromTable(
+		&model.GovernanceEventCriteria{ListingAddress: listingAddr.Hex(), LatestPerListing: true},
+		tableName)
+	if err != nil {
+		t.Fatalf("Error getting gov events from table: %v", err)
+	}
+	if len(govEvents) != 1 || govEvents[0].EventHash() != expectedHash {
+		t.Errorf("Should have retrieved 1 event with the greatest hash for a tie: %v", govEvents)
+	}
 }
 
-// TestDBGovEventToModelGovEvent tests that the db listing can be properly converted to model listing
-func TestDBGovEventToModelGovEvent(t *testing.T) {
+func TestGovernanceEventsByCursor(t *testing.T) {
 	persister := setupGovEventTable(t)
 	defer persister.Close()
 	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	modelGovernanceEvent, listingAddr, _, _ := createAndSaveTestGovEvent(t, persister, false)
+	createEvent := func(creationDateTs int64) *model.GovernanceEvent {
+		govEvent, listingAddr, eventHash, txHash := setupSampleGovernanceEvent(true)
+		blockData := govEvent.BlockData()
+		govEvent = model.NewGovernanceEvent(listingAddr, model.Metadata{}, "Application",
+			creationDateTs, govEvent.LastUpdatedDateTs(), eventHash, blockData.BlockNumber(),
+			txHash, blockData.TxIndex(), common.Hash{}, blockData.Index())
+		err := persister.createGovernanceEventInTable(govEvent, tableName)
+		if err != nil {
+			t.Fatalf("error saving GovernanceEvent: %v", err)
+		}
+		return govEvent
+	}
 
-	// retrieve from table
-	dbGovEvents, err := persister.governanceEventsByListingAddressFromTable(listingAddr, tableName)
-	if err != nil {
-		t.Errorf("Wasn't able to get governance event from postgres table: %v", err)
+	// Seed 7 events, with creation date ties to page through
+	baseTs := ctime.CurrentEpochSecsInInt64()
+	expectedHashes := map[string]bool{}
+	for i := 0; i < 7; i++ {
+		govEvent := createEvent(baseTs + int64(i/2))
+		expectedHashes[govEvent.EventHash()] = true
 	}
-	modelGovEventFromDB := dbGovEvents[0]
 
-	// do a deep equal
-	// check that retrieved fields match with inserted listing
-	if !reflect.DeepEqual(modelGovernanceEvent, modelGovEventFromDB) {
-		t.Errorf("listing from DB: %v, doesn't match inserted listing: %v", modelGovEventFromDB, modelGovernanceEvent)
+	seenHashes := map[string]bool{}
+	var lastEvent *model.GovernanceEvent
+	criteria := &model.GovernanceEventCriteria{Count: 2}
+	pages := 0
+	for {
+		govEvents, nextCursor, err := persister.governanceEventsByCursorFromTable(criteria, tableName)
+		if err != nil {
+			t.Fatalf("Error getting gov events by cursor: %v", err)
+		}
+		for _, govEvent := range govEvents {
+			if seenHashes[govEvent.EventHash()] {
+				t.Errorf("Should not have retrieved event %v more than once", govEvent.EventHash())
+			}
+			seenHashes[govEvent.EventHash()] = true
+			if lastEvent != nil && (govEvent.CreationDateTs() < lastEvent.CreationDateTs() ||
+				(govEvent.CreationDateTs() == lastEvent.CreationDateTs() &&
+					govEvent.EventHash() < lastEvent.EventHash())) {
+				t.Errorf("Should have retrieved events ordered by creation date and hash")
+			}
+			lastEvent = govEvent
+		}
+		pages++
+		if nextCursor == "" {
+			break
+		}
+		// Insert events before and after the cursor while paging. Earlier events
+		// must not shift the remaining pages.
+		createEvent(baseTs - 1)
+		laterEvent := createEvent(baseTs + 100 + int64(pages))
+		expectedHashes[laterEvent.EventHash()] = true
+		criteria.AfterCursor = nextCursor
 	}
 
-}
+	for hash := range expectedHashes {
+		if !seenHashes[hash] {
+			t.Errorf("Should have retrieved event %v while paging", hash)
+		}
+	}
+	if len(seenHashes) != len(expectedHashes) {
+		t.Errorf("Should have only retrieved events after the cursor, got %v, expected %v",
+			len(seenHashes), len(expectedHashes))
+	}
 
-// TODO(IS): test update gov event -- test update that's not by listing
+	_, _, err := persister.governanceEventsByCursorFromTable(
+		&model.GovernanceEventCriteria{AfterCursor: "invalid cursor"}, tableName)
+	if err == nil {
+		t.Errorf("Should have gotten an error for an invalid cursor")
+	}
+}
 
-// TestDeleteGovernanceEvent tests that the deleting the Governance Event works
-// TODO(IS) : this will delete more than you want. need to put some kind of hash for the gov event.
-func TestDeleteGovernanceEvent(t *testing.T) {
+func TestGovernanceEventBySourceEvent(t *testing.T) {
 	persister := setupGovEventTable(t)
 	defer persister.Close()
 	tableName := persister.GetTableName(govTestTableName)
 	defer deleteTestTable(t, persister, tableName)
 
-	modelGovernanceEvent, _, _, _ := createAndSaveTestGovEvent(t, persister, false)
+	govEvent1, _, eventHash1, txHash1 := createAndSaveTestGovEvent(t, persister, true)
+	_, _, _, _ = createAndSaveTestGovEvent(t, persister, true)
+	blockData := govEvent1.BlockData()
 
-	var numRowsb int
-	err := persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v", tableName)).Scan(&numRowsb)
+	govEvent, err := persister.governanceEventBySourceEventFromTable(txHash1, blockData.Index(),
+		tableName)
 	if err != nil {
-		t.Errorf("Problem getting count from table: %v", err)
+		t.Fatalf("Error getting gov event by source event: %v", err)
 	}
-	if numRowsb != 1 {
-		t.Errorf("Number of rows in table should be 1 but is: %v", numRowsb)
+	if govEvent.EventHash() != eventHash1 {
+		t.Errorf("Should have retrieved the gov event for the source event, got %v",
+			govEvent.EventHash())
 	}
 
-	//delete rows
-	err = persister.deleteGovernanceEventFromTable(modelGovernanceEvent, tableName)
-	if err != nil {
-		t.Errorf("Error deleting governance event: %v", err)
+	_, err = persister.governanceEventBySourceEventFromTable(txHash1, blockData.Index()+1,
+		tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results for another log index: err: %v", err)
 	}
 
-	var numRows int
-	err = persister.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %v", tableName)).Scan(&numRows)
+	// Rows stored before the source columns existed are backfilled by the migration
+	_, err = persister.db.Exec(fmt.Sprintf(
+		"UPDATE %s SET source_tx_hash = NULL, source_log_index = NULL;", tableName))
 	if err != nil {
-		t.Errorf("Problem getting count from table: %v", err)
+		t.Fatalf("Error clearing source event columns: %v", err)
 	}
-	if numRows != 0 {
-		t.Errorf("Number of rows in table should be 0 but is: %v", numRows)
+	_, err = persister.db.Exec(postgres.CreateGovernanceEventTableMigrationQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error running gov event migration: %v", err)
+	}
+	govEvent, err = persister.governanceEventBySourceEventFromTable(txHash1, blockData.Index(),
+		tableName)
+	if err != nil {
+		t.Fatalf("Error getting gov event by source event after migration: %v", err)
+	}
+	if govEvent.EventHash() != eventHash1 {
+		t.Errorf("Should have retrieved the gov event after migration, got %v",
+			govEvent.EventHash())
 	}
 }
 
-// TestGovEventsByCriteria tests GovernanceEvent by criteria query
-func TestGovEventsByCriteria(t *testing.T) {
-	persister := setupGovEventTable(t)
+func TestListingsWithRecentGovernanceEvent(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
 	defer persister.Close()
-	tableName := persister.GetTableName(govTestTableName)
+	tableName := persister.GetTableName(listingTestTableName)
 	defer deleteTestTable(t, persister, tableName)
+	persister2 := setupGovEventTable(t)
+	defer persister2.Close()
+	govTableName := persister.GetTableName(govTestTableName)
+	defer deleteTestTable(t, persister, govTableName)
+
+	listings, addresses := setupSampleListings(3)
+	for _, listing := range listings {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Fatalf("error saving listing: %v", err)
+		}
+	}
 
-	var listingAddr common.Address
-	var timeMiddle int64
-	var timeStart int64
-	var modelGovernanceEvent *model.GovernanceEvent
-	// create some governance events w constant listing address and save them to DB
-	for i := 1; i <= 30; i++ {
-		// TODO: just set timestamp for event bc there is still a probability these times won't be what you think.
-		if i < 20 {
-			timeStart = ctime.CurrentEpochSecsInInt64()
+	saveEvent := func(listingAddr common.Address, eventType string, creationTs int64) {
+		govEvent, _, eventHash, txHash := setupSampleGovernanceEvent(true)
+		metadata := model.Metadata{
+			"ListingAddress": listingAddr.Hex(),
+			"ChallengeID":    big.NewInt(1),
+			"CommitEndDate":  big.NewInt(creationTs),
+			"RevealEndDate":  big.NewInt(creationTs),
+			"Challenger":     common.HexToAddress(testAddress),
 		}
-		if i == 20 {
-			time.Sleep(1 * time.Second)
-			timeMiddle = ctime.CurrentEpochSecsInInt64()
+		govEvent = model.NewGovernanceEvent(listingAddr, metadata, eventType, creationTs,
+			creationTs, eventHash, 88888, txHash, 4, common.Hash{}, 2)
+		err := persister.createGovernanceEventInTable(govEvent, govTableName)
+		if err != nil {
+			t.Fatalf("error saving GovernanceEvent: %v", err)
 		}
-		modelGovernanceEvent, listingAddr, _, _ = createAndSaveTestGovEvent(t, persister, true)
 	}
+	// Listing with 2 recent challenges
+	saveEvent(addresses[0], "Challenge", 2000)
+	saveEvent(addresses[0], "Challenge", 3000)
+	// Listing with an old challenge and a recent appeal granted
+	saveEvent(addresses[1], "Challenge", 500)
+	saveEvent(addresses[1], "AppealGranted", 2000)
 
-	govEvents, err := persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
-		ListingAddress: listingAddr.Hex(),
-		Count:          1,
-	}, tableName)
-
+	recent, err := persister.listingsWithRecentGovernanceEventFromTable("Challenge", 1000,
+		tableName, govTableName)
 	if err != nil {
-		t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
+		t.Fatalf("Error getting listings with recent gov event: %v", err)
 	}
-
-	if len(govEvents) != 1 {
-		t.Errorf("Should have only retrieved one governance event but got %v", len(govEvents))
+	if len(recent) != 1 {
+		t.Fatalf("Should have retrieved 1 distinct listing, retrieved %v", len(recent))
 	}
-
-	if modelGovernanceEvent.ListingAddress() != listingAddr {
-		t.Errorf("Listing address is %v but should be %v ", modelGovernanceEvent.ListingAddress().Hex(), listingAddr.Hex())
+	if recent[0].ContractAddress() != addresses[0] {
+		t.Errorf("Should have retrieved the recently challenged listing, got %v",
+			recent[0].ContractAddress().Hex())
 	}
 
-	govEvents, err = persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
-		ListingAddress: listingAddr.Hex(),
-		CreatedFromTs:  timeStart,
-	}, tableName)
-
+	recent, err = persister.listingsWithRecentGovernanceEventFromTable("Challenge", 0,
+		tableName, govTableName)
 	if err != nil {
-		t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
+		t.Fatalf("Error getting listings with recent gov event: %v", err)
 	}
-
-	if len(govEvents) != 11 {
-		t.Errorf("Should have retrieved 11 governance events but only got %v", len(govEvents))
+	if len(recent) != 2 {
+		t.Errorf("Should have retrieved 2 challenged listings, retrieved %v", len(recent))
 	}
 
-	govEvents, err = persister.governanceEventsByCriteriaFromTable(&model.GovernanceEventCriteria{
-		ListingAddress:  listingAddr.Hex(),
-		CreatedBeforeTs: timeMiddle,
-	}, tableName)
-
+	recent, err = persister.listingsWithRecentGovernanceEventFromTable("AppealGranted", 1000,
+		tableName, govTableName)
 	if err != nil {
-		t.Errorf("Wasn't able to get governance events from postgres table: %v", err)
+		t.Fatalf("Error getting listings with recent gov event: %v", err)
 	}
-	if len(govEvents) != 19 {
-		t.Errorf("Should have retrieved 19 governance events but only got %v", len(govEvents))
+	if len(recent) != 1 || recent[0].ContractAddress() != addresses[1] {
+		t.Errorf("Should have retrieved the listing with the recent appeal granted")
 	}
 
+	recent, err = persister.listingsWithRecentGovernanceEventFromTable("Challenge", 5000,
+		tableName, govTableName)
+	if err != nil {
+		t.Errorf("Should not have gotten an error for no recent events: err: %v", err)
+	}
+	if recent == nil || len(recent) != 0 {
+		t.Errorf("Should have gotten an empty slice for no recent events: %v", recent)
+	}
 }
 
-// TestGovEventsByCriteria tests GovernanceEvent by txhash query
 func TestGovEventsByTxHash(t *testing.T) {
 
 	persister := setupGovEventTable(t)
@@ -2063,6 +4415,105 @@ func insertTestChallengeToTable(t *testing.T, persister *PostgresPersister, mode
 	return modelChallenge, challengeID
 }
 
+func TestResolvedChallengesByTimeRange(t *testing.T) {
+	persister := setupChallengeTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	challenges := []struct {
+		challengeID int
+		resolved    bool
+		ts          int64
+	}{
+		{1, true, 1000},
+		{2, false, 1500},
+		{3, true, 2500},
+		{4, true, 2000},
+		{5, false, 2200},
+		{6, true, 3000},
+		{7, true, 3500},
+	}
+	for _, c := range challenges {
+		challenge := model.NewChallenge(big.NewInt(int64(c.challengeID)),
+			common.HexToAddress(testAddress), "", big.NewInt(50), common.HexToAddress(testAddress),
+			c.resolved, big.NewInt(100), big.NewInt(1000), big.NewInt(1231312),
+			model.ChallengePollType, c.ts)
+		insertTestChallengeToTable(t, persister, challenge, c.challengeID)
+	}
+
+	results, err := persister.resolvedChallengesByTimeRangeInTable(1500, 3000, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten error retrieving resolved challenges: err: %v", err)
+	}
+	expectedIDs := []int64{4, 3, 6}
+	if len(results) != len(expectedIDs) {
+		t.Fatalf("Should have gotten %v challenges, got %v", len(expectedIDs), len(results))
+	}
+	for i, challenge := range results {
+		if challenge.ChallengeID().Int64() != expectedIDs[i] {
+			t.Errorf("Should have gotten challenge %v at %v, got %v", expectedIDs[i], i,
+				challenge.ChallengeID())
+		}
+		if !challenge.Resolved() {
+			t.Errorf("Should have only gotten resolved challenges")
+		}
+	}
+
+	_, err = persister.resolvedChallengesByTimeRangeInTable(1100, 1900, tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results with no resolved challenges: err: %v", err)
+	}
+}
+
+func TestOrphanedChallenges(t *testing.T) {
+	persister := setupChallengeTestTable(t)
+	defer persister.Close()
+	challengeTableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, challengeTableName)
+
+	listingTableName := persister.GetTableName(listingTestTableName)
+	_, err := persister.db.Exec(postgres.CreateListingTableQuery(listingTableName))
+	if err != nil {
+		t.Fatalf("Couldn't create test table %s: %v", listingTableName, err)
+	}
+	defer deleteTestTable(t, persister, listingTableName)
+
+	_, err = persister.orphanedChallengesInTable(challengeTableName, listingTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results with no challenges: err: %v", err)
+	}
+
+	modelListing, listingAddr := setupSampleListing()
+	err = persister.createListingForTable(modelListing, listingTableName)
+	if err != nil {
+		t.Fatalf("error saving listing: %v", err)
+	}
+	challenge := model.NewChallenge(big.NewInt(1), listingAddr, "", big.NewInt(50),
+		common.HexToAddress(testAddress), false, big.NewInt(100), big.NewInt(1000),
+		big.NewInt(1231312), model.ChallengePollType, int64(1212141313))
+	insertTestChallengeToTable(t, persister, challenge, 1)
+
+	// Challenge for a listing that was never created
+	orphan := setupChallengeByChallengeID(2, false)
+	insertTestChallengeToTable(t, persister, orphan, 2)
+
+	orphans, err := persister.orphanedChallengesInTable(challengeTableName, listingTableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten error retrieving orphaned challenges: err: %v", err)
+	}
+	if len(orphans) != 1 {
+		t.Fatalf("Should have gotten 1 orphaned challenge, got %v", len(orphans))
+	}
+	if orphans[0].ChallengeID().Int64() != 2 {
+		t.Errorf("Should have gotten the orphaned challenge, got %v", orphans[0].ChallengeID())
+	}
+	if orphans[0].ListingAddress() != common.HexToAddress(testAddress) {
+		t.Errorf("Should have the listing address of the orphan, got %v",
+			orphans[0].ListingAddress().Hex())
+	}
+}
+
 func TestCreateChallenge(t *testing.T) {
 	persister := setupChallengeTestTable(t)
 	defer persister.Close()
@@ -2137,7 +4588,85 @@ func TestGetChallenge(t *testing.T) {
 	if challengesFromDB[0] != nil {
 		t.Errorf("Should have received a nil value for an unfound challenge")
 	}
-
+
+}
+
+func TestChallengeLargeValues(t *testing.T) {
+	persister := setupChallengeTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	// Above 2^63 and not exactly representable as a float64
+	stake, _ := new(big.Int).SetString("100000000000000000001", 10)
+	rewardPool, _ := new(big.Int).SetString("9223372036854775809", 10)
+	modelChallenge := setupChallengeByChallengeID(77, false)
+	modelChallenge = model.NewChallenge(modelChallenge.ChallengeID(), modelChallenge.ListingAddress(),
+		modelChallenge.Statement(), rewardPool, modelChallenge.Challenger(), false, stake,
+		modelChallenge.TotalTokens(), modelChallenge.RequestAppealExpiry(),
+		modelChallenge.ChallengeType(), modelChallenge.LastUpdatedDateTs())
+	err := persister.createChallengeInTable(modelChallenge, tableName)
+	if err != nil {
+		t.Fatalf("error saving challenge: %v", err)
+	}
+
+	challengesFromDB, err := persister.challengesByChallengeIDsInTableInOrder([]int{77}, tableName)
+	if err != nil {
+		t.Fatalf("Error getting value from DB: %v", err)
+	}
+	if len(challengesFromDB) != 1 || challengesFromDB[0] == nil {
+		t.Fatalf("Should have gotten the challenge from the DB")
+	}
+	if challengesFromDB[0].Stake().Cmp(stake) != 0 {
+		t.Errorf("Should have round tripped the stake, have %v", challengesFromDB[0].Stake())
+	}
+	if challengesFromDB[0].RewardPool().Cmp(rewardPool) != 0 {
+		t.Errorf("Should have round tripped the reward pool, have %v", challengesFromDB[0].RewardPool())
+	}
+}
+
+func TestGetChallengesChunked(t *testing.T) {
+	persister := setupChallengeTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	// Save every 3rd challenge so that the IDs span multiple chunks
+	numIDs := 2500
+	challengeIDs := make([]int, numIDs)
+	for i := 0; i < numIDs; i++ {
+		challengeIDs[i] = i + 1
+		if challengeIDs[i]%3 == 0 {
+			modelChallenge := setupChallengeByChallengeID(challengeIDs[i], false)
+			insertTestChallengeToTable(t, persister, modelChallenge, challengeIDs[i])
+		}
+	}
+	challengeIDs = shuffleInts(challengeIDs)
+
+	challengesFromDB, err := persister.challengesByChallengeIDsInTableInOrder(challengeIDs, tableName)
+	if err != nil {
+		t.Fatalf("Error getting challenges from DB: %v", err)
+	}
+	if len(challengesFromDB) != numIDs {
+		t.Fatalf("Should have received %v challenges, got %v", numIDs, len(challengesFromDB))
+	}
+	for i, challengeID := range challengeIDs {
+		challenge := challengesFromDB[i]
+		if challengeID%3 != 0 {
+			if challenge != nil {
+				t.Errorf("Should have received a nil value for an unfound challenge %v", challengeID)
+			}
+			continue
+		}
+		if challenge == nil {
+			t.Errorf("Should have received a challenge for %v", challengeID)
+			continue
+		}
+		if int(challenge.ChallengeID().Int64()) != challengeID {
+			t.Errorf("Challenge out of order, expected %v, got %v", challengeID,
+				challenge.ChallengeID().Int64())
+		}
+	}
 }
 
 func TestGetChallengesForListingAddresses(t *testing.T) {
@@ -2379,6 +4908,40 @@ func TestUpdateChallenge(t *testing.T) {
 	}
 }
 
+func TestUpdateChallengeUnknownField(t *testing.T) {
+	persister := setupChallengeTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	challenge, challengeID := createAndSaveTestChallenge(t, persister, true)
+	challenge.SetTotalTokens(big.NewInt(int64(231231312312)))
+
+	err := persister.updateChallengeInTable(challenge, []string{"TotalTokens", "Bogus"}, tableName)
+	if err == nil {
+		t.Fatalf("Should have received an error updating an unknown field")
+	}
+	fieldErr, ok := errors.Cause(err).(*UnknownFieldError)
+	if !ok {
+		t.Fatalf("Error should be an UnknownFieldError: %T", errors.Cause(err))
+	}
+	if fieldErr.Field != "Bogus" {
+		t.Errorf("Error field should be Bogus but is %v", fieldErr.Field)
+	}
+
+	// No fields should have been updated
+	challengesFromDB, err := persister.challengesByChallengeIDsInTableInOrder([]int{challengeID}, tableName)
+	if err != nil {
+		t.Errorf("Error getting value from DB: %v", err)
+	}
+	if len(challengesFromDB) == 0 {
+		t.Fatalf("Didn't get anything from DB challenge test")
+	}
+	if challengesFromDB[0].TotalTokens().Int64() == int64(231231312312) {
+		t.Errorf("Should not have updated total tokens")
+	}
+}
+
 /*
 All tests for poll table:
 */
@@ -2438,6 +5001,142 @@ func TestNilResultsPoll(t *testing.T) {
 	}
 }
 
+func TestPollByChallengeID(t *testing.T) {
+	persister := setupPollTestTable(t)
+	defer persister.Close()
+	pollTableName := persister.GetTableName(pollTestTableName)
+	defer deleteTestTable(t, persister, pollTableName)
+
+	_, pollID := createAndSaveTestPoll(t, persister, true)
+	pollIDInt := int(pollID.Int64())
+
+	poll, err := persister.pollByChallengeIDFromTable(pollIDInt, pollTableName)
+	if err != nil {
+		t.Errorf("Should have gotten poll for challenge: err: %v", err)
+	}
+	if poll == nil || poll.PollID().Int64() != pollID.Int64() {
+		t.Errorf("Should have gotten the poll matching the challenge ID")
+	}
+
+	// No poll for the challenge
+	poll, err = persister.pollByChallengeIDFromTable(pollIDInt+1, pollTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results with no poll: err: %v", err)
+	}
+	if poll != nil {
+		t.Errorf("Poll should be nil but is %v", poll)
+	}
+}
+
+func TestChallengeByPollID(t *testing.T) {
+	persister := setupPollTestTable(t)
+	defer persister.Close()
+	pollTableName := persister.GetTableName(pollTestTableName)
+	defer deleteTestTable(t, persister, pollTableName)
+
+	challengeTableName := persister.GetTableName(challengeTestTableName)
+	_, err := persister.db.Exec(postgres.CreateChallengeTableQuery(challengeTableName))
+	if err != nil {
+		t.Fatalf("Couldn't create test table %s: %v", challengeTableName, err)
+	}
+	defer deleteTestTable(t, persister, challengeTableName)
+
+	_, pollID := createAndSaveTestPoll(t, persister, true)
+	pollIDInt := int(pollID.Int64())
+
+	// No challenge for the poll yet
+	challenge, err := persister.challengeByPollIDFromTable(pollIDInt, challengeTableName, pollTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results with no challenge: err: %v", err)
+	}
+	if challenge != nil {
+		t.Errorf("Challenge should be nil but is %v", challenge)
+	}
+
+	testChallenge := setupChallengeByChallengeID(pollIDInt, false)
+	insertTestChallengeToTable(t, persister, testChallenge, pollIDInt)
+
+	challenge, err = persister.challengeByPollIDFromTable(pollIDInt, challengeTableName, pollTableName)
+	if err != nil {
+		t.Errorf("Should have gotten challenge for poll: err: %v", err)
+	}
+	if challenge == nil || challenge.ChallengeID().Int64() != pollID.Int64() {
+		t.Errorf("Should have gotten the challenge matching the poll ID")
+	}
+
+	// Challenge exists, but no poll
+	otherPollID := pollIDInt + 1
+	testChallenge = setupChallengeByChallengeID(otherPollID, false)
+	insertTestChallengeToTable(t, persister, testChallenge, otherPollID)
+
+	challenge, err = persister.challengeByPollIDFromTable(otherPollID, challengeTableName, pollTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results with no poll: err: %v", err)
+	}
+	if challenge != nil {
+		t.Errorf("Challenge should be nil but is %v", challenge)
+	}
+}
+
+func TestPollsByPollIDsMap(t *testing.T) {
+	persister := setupPollTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(pollTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	pollIDs := []int{10, 11, 12}
+	for _, pollID := range pollIDs {
+		modelPoll := model.NewPoll(
+			big.NewInt(int64(pollID)),
+			big.NewInt(232232323),
+			big.NewInt(232232350),
+			big.NewInt(40),
+			big.NewInt(50),
+			big.NewInt(50),
+			int64(232232323),
+		)
+		err := persister.createPollInTable(modelPoll, tableName)
+		if err != nil {
+			t.Errorf("error saving poll: %v", err)
+		}
+	}
+
+	// Includes a poll ID that does not exist
+	queryIDs := []int{12, 10, 99, 11}
+	pollsSlice, err := persister.pollsByPollIDsInTableInOrder(queryIDs, tableName)
+	if err != nil {
+		t.Errorf("Error getting polls from table: %v", err)
+	}
+	pollsMap, err := persister.pollsByPollIDsMapFromTable(queryIDs, tableName)
+	if err != nil {
+		t.Errorf("Error getting polls map from table: %v", err)
+	}
+
+	if len(pollsMap) != len(pollIDs) {
+		t.Errorf("Should have only returned found polls, got %v", len(pollsMap))
+	}
+	if _, ok := pollsMap[99]; ok {
+		t.Errorf("Should not have returned a poll that does not exist")
+	}
+	for index, pollID := range queryIDs {
+		slicePoll := pollsSlice[index]
+		mapPoll := pollsMap[pollID]
+		if slicePoll == nil && mapPoll == nil {
+			continue
+		}
+		if slicePoll == nil || mapPoll == nil {
+			t.Errorf("Should have matching results for poll %v", pollID)
+			continue
+		}
+		if slicePoll.PollID().Cmp(mapPoll.PollID()) != 0 {
+			t.Errorf("Poll IDs should have matched: %v, %v", slicePoll.PollID(), mapPoll.PollID())
+		}
+		if !reflect.DeepEqual(slicePoll, mapPoll) {
+			t.Errorf("Polls should have matched for poll %v", pollID)
+		}
+	}
+}
+
 func TestUpdatePoll(t *testing.T) {
 	persister := setupPollTestTable(t)
 	defer persister.Close()
@@ -2476,10 +5175,221 @@ func TestUpdatePoll(t *testing.T) {
 	}
 }
 
+func TestUpsertPoll(t *testing.T) {
+	persister := setupPollTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(pollTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	modelPoll, pollID := setupSamplePoll(true)
+	for i := int64(1); i <= 3; i++ {
+		modelPoll.UpdateVotesFor(big.NewInt(100 * i))
+		modelPoll.UpdateVotesAgainst(big.NewInt(10 * i))
+		err := persister.upsertPollInTable(modelPoll, tableName)
+		if err != nil {
+			t.Fatalf("Error upserting poll: %v", err)
+		}
+	}
+
+	var numRows int
+	err := persister.db.Get(&numRows, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)) // nolint: gosec
+	if err != nil {
+		t.Errorf("Error counting polls: %v", err)
+	}
+	if numRows != 1 {
+		t.Errorf("Should have only 1 poll row, have %v", numRows)
+	}
+
+	pollFromDB, err := persister.pollByPollIDFromTable(int(pollID.Int64()), tableName)
+	if err != nil {
+		t.Fatalf("Error getting poll from DB: %v", err)
+	}
+	if pollFromDB.VotesFor().Int64() != 300 {
+		t.Errorf("Should have latest votes for, got %v", pollFromDB.VotesFor())
+	}
+	if pollFromDB.VotesAgainst().Int64() != 30 {
+		t.Errorf("Should have latest votes against, got %v", pollFromDB.VotesAgainst())
+	}
+	if pollFromDB.LastUpdatedDateTs() != modelPoll.LastUpdatedDateTs() {
+		t.Errorf("Should have latest last updated timestamp, got %v", pollFromDB.LastUpdatedDateTs())
+	}
+}
+
 /*
 All tests for appeal table:
 */
 
+func TestListingGovernanceState(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
+	defer persister.Close()
+	listingTableName := persister.GetTableName(listingTestTableName)
+	defer deleteTestTable(t, persister, listingTableName)
+	persister2 := setupChallengeTestTable(t)
+	defer persister2.Close()
+	challengeTableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, challengeTableName)
+	persister3 := setupPollTestTable(t)
+	defer persister3.Close()
+	pollTableName := persister.GetTableName(pollTestTableName)
+	defer deleteTestTable(t, persister, pollTableName)
+	persister4 := setupAppealTestTable(t)
+	defer persister4.Close()
+	appealTableName := persister.GetTableName(appealTestTableName)
+	defer deleteTestTable(t, persister, appealTableName)
+
+	governanceState := func(address common.Address) *model.ListingGovernance {
+		governance, err := persister.listingGovernanceStateFromTables(address, listingTableName,
+			challengeTableName, pollTableName, appealTableName)
+		if err != nil {
+			t.Fatalf("Error getting listing governance state: %v", err)
+		}
+		return governance
+	}
+
+	_, err := persister.listingGovernanceStateFromTables(common.HexToAddress(testAddress),
+		listingTableName, challengeTableName, pollTableName, appealTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results for a missing listing: err: %v", err)
+	}
+
+	// Application
+	listing, listingAddr := setupSampleListingUnchallenged()
+	listing.SetChallengeID(big.NewInt(0))
+	err = persister.createListingForTable(listing, listingTableName)
+	if err != nil {
+		t.Fatalf("error saving listing: %v", err)
+	}
+	governance := governanceState(listingAddr)
+	if governance.Listing == nil || governance.Listing.ContractAddress() != listingAddr {
+		t.Errorf("Should have retrieved the listing")
+	}
+	if governance.Challenge != nil || governance.Poll != nil || governance.Appeal != nil {
+		t.Errorf("Should not have retrieved governance data for an unchallenged listing")
+	}
+
+	// Challenge with its poll
+	challengeIDInt := mathrand.Intn(10000) + 1
+	challengeID := big.NewInt(int64(challengeIDInt))
+	challenge := setupChallengeByChallengeID(challengeIDInt, false)
+	insertTestChallengeToTable(t, persister, challenge, challengeIDInt)
+	poll := model.NewPoll(challengeID, big.NewInt(232232323), big.NewInt(232232350),
+		big.NewInt(40), big.NewInt(0), big.NewInt(0), int64(232232323))
+	err = persister.createPollInTable(poll, pollTableName)
+	if err != nil {
+		t.Fatalf("error saving poll: %v", err)
+	}
+	listing.SetChallengeID(challengeID)
+	err = persister.updateListingInTable(listing, []string{"ChallengeID"}, listingTableName)
+	if err != nil {
+		t.Fatalf("error updating listing: %v", err)
+	}
+	governance = governanceState(listingAddr)
+	if governance.Challenge == nil || governance.Challenge.ChallengeID().Cmp(challengeID) != 0 {
+		t.Errorf("Should have retrieved the active challenge")
+	}
+	if governance.Poll == nil || governance.Poll.PollID().Cmp(challengeID) != 0 {
+		t.Errorf("Should have retrieved the challenge poll")
+	}
+	if governance.Appeal != nil {
+		t.Errorf("Should not have retrieved an appeal before it was requested")
+	}
+
+	// Appeal requested
+	appellant, _ := cstrings.RandomHexStr(32)
+	appeal := model.NewAppeal(challengeID, common.HexToAddress(appellant), big.NewInt(2322),
+		big.NewInt(401123243), false, "", int64(232323), "")
+	err = persister.createAppealInTable(appeal, appealTableName)
+	if err != nil {
+		t.Fatalf("error saving appeal: %v", err)
+	}
+	governance = governanceState(listingAddr)
+	if governance.Appeal == nil || governance.Appeal.OriginalChallengeID().Cmp(challengeID) != 0 {
+		t.Errorf("Should have retrieved the appeal")
+	}
+	if governance.Challenge == nil || governance.Poll == nil {
+		t.Errorf("Should have retrieved the challenge and poll with the appeal")
+	}
+
+	// Challenge resolved, the listing challenge ID is reset
+	listing.SetChallengeID(big.NewInt(0))
+	err = persister.updateListingInTable(listing, []string{"ChallengeID"}, listingTableName)
+	if err != nil {
+		t.Fatalf("error updating listing: %v", err)
+	}
+	governance = governanceState(listingAddr)
+	if governance.Listing == nil {
+		t.Errorf("Should have retrieved the listing")
+	}
+	if governance.Challenge != nil || governance.Poll != nil || governance.Appeal != nil {
+		t.Errorf("Should not have retrieved governance data after the challenge was resolved")
+	}
+}
+
+func TestAppealWithChallenge(t *testing.T) {
+	persister := setupChallengeTestTable(t)
+	defer persister.Close()
+	challengeTableName := persister.GetTableName(challengeTestTableName)
+	defer deleteTestTable(t, persister, challengeTableName)
+	persister2 := setupAppealTestTable(t)
+	defer persister2.Close()
+	appealTableName := persister.GetTableName(appealTestTableName)
+	defer deleteTestTable(t, persister, appealTableName)
+
+	challenger, _ := cstrings.RandomHexStr(32)
+	listingAddr, _ := cstrings.RandomHexStr(32)
+	challengeID := 4242
+	modelChallenge := model.NewChallenge(big.NewInt(int64(challengeID)), common.HexToAddress(listingAddr),
+		"challenge statement", big.NewInt(50), common.HexToAddress(challenger), false, big.NewInt(100),
+		big.NewInt(232323223232), big.NewInt(1231312), model.ChallengePollType, int64(1212141313))
+	err := persister.createChallengeInTable(modelChallenge, challengeTableName)
+	if err != nil {
+		t.Fatalf("error saving challenge: %v", err)
+	}
+
+	_, err = persister.appealWithChallengeFromTables(challengeID, appealTableName, challengeTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have returned no results before the appeal was requested: %v", err)
+	}
+
+	appellant, _ := cstrings.RandomHexStr(32)
+	appeal := model.NewAppeal(big.NewInt(int64(challengeID)), common.HexToAddress(appellant),
+		big.NewInt(2322), big.NewInt(401123243), false, "appeal statement", int64(232323), "")
+	err = persister.createAppealInTable(appeal, appealTableName)
+	if err != nil {
+		t.Fatalf("error saving appeal: %v", err)
+	}
+
+	appealWithChallenge, err := persister.appealWithChallengeFromTables(challengeID, appealTableName,
+		challengeTableName)
+	if err != nil {
+		t.Fatalf("Error getting appeal with challenge: %v", err)
+	}
+	if appealWithChallenge.Appeal.Requester() != appeal.Requester() {
+		t.Errorf("Should have retrieved the appeal requester")
+	}
+	if appealWithChallenge.Appeal.Statement() != "appeal statement" {
+		t.Errorf("Should have retrieved the appeal statement: %v", appealWithChallenge.Appeal.Statement())
+	}
+	if appealWithChallenge.Challenge.Challenger() != modelChallenge.Challenger() {
+		t.Errorf("Should have retrieved the challenger")
+	}
+	if appealWithChallenge.Challenge.Stake().Cmp(modelChallenge.Stake()) != 0 {
+		t.Errorf("Should have retrieved the challenge stake")
+	}
+	if appealWithChallenge.Challenge.ListingAddress() != modelChallenge.ListingAddress() {
+		t.Errorf("Should have retrieved the challenge listing address")
+	}
+	if appealWithChallenge.Challenge.Statement() != "challenge statement" {
+		t.Errorf("Should have retrieved the challenge statement: %v",
+			appealWithChallenge.Challenge.Statement())
+	}
+
+	_, err = persister.appealWithChallengeFromTables(challengeID+1, appealTableName, challengeTableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have returned no results for an unknown challenge: %v", err)
+	}
+}
+
 func setupSampleAppeal(randListing bool) (*model.Appeal, *big.Int) {
 	originalChallengeID := big.NewInt(23)
 	address2, _ := cstrings.RandomHexStr(32)
@@ -2574,6 +5484,62 @@ func TestNilResultsAppeal(t *testing.T) {
 	}
 }
 
+func TestAppealsByAppealChallengeIDs(t *testing.T) {
+	persister := setupAppealTestTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(appealTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	// Appeal challenge IDs 100, 101, 102 for original challenge IDs 10, 11, 12
+	for i := 0; i < 3; i++ {
+		address, _ := cstrings.RandomHexStr(32)
+		appeal := model.NewAppeal(
+			big.NewInt(int64(10+i)),
+			common.HexToAddress(address),
+			big.NewInt(2322),
+			big.NewInt(401123243),
+			true,
+			"",
+			int64(232323),
+			"",
+		)
+		appeal.SetAppealChallengeID(big.NewInt(int64(100 + i)))
+		err := persister.createAppealInTable(appeal, tableName)
+		if err != nil {
+			t.Fatalf("error saving appeal: %v", err)
+		}
+	}
+
+	appealChallengeIDs := []int{102, 999, 100, 101}
+	appeals, err := persister.appealsByAppealChallengeIDsInTableInOrder(appealChallengeIDs, tableName)
+	if err != nil {
+		t.Fatalf("Error getting appeals: %v", err)
+	}
+	if len(appeals) != len(appealChallengeIDs) {
+		t.Fatalf("Should have %v appeals, have %v", len(appealChallengeIDs), len(appeals))
+	}
+	if appeals[1] != nil {
+		t.Errorf("Appeal for missing appeal challenge ID should be nil")
+	}
+	for i, appealChallengeID := range appealChallengeIDs {
+		if appeals[i] == nil {
+			continue
+		}
+		if appeals[i].AppealChallengeID().Int64() != int64(appealChallengeID) {
+			t.Errorf("Appeal not in order, expected %v, got %v", appealChallengeID,
+				appeals[i].AppealChallengeID())
+		}
+		if appeals[i].OriginalChallengeID().Int64() != int64(appealChallengeID-90) {
+			t.Errorf("Wrong original challenge ID: %v", appeals[i].OriginalChallengeID())
+		}
+	}
+
+	_, err = persister.appealsByAppealChallengeIDsInTableInOrder([]int{}, tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results error for empty IDs: %v", err)
+	}
+}
+
 /*
 All tests for cron table:
 */
@@ -2661,6 +5627,57 @@ func TestUpdateTimestampForCron(t *testing.T) {
 	}
 }
 
+func TestSetCronTimestamp(t *testing.T) {
+	persister := setupTestTable(t, cronTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(cronTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	err := persister.updateCronTimestampInTable(int64(1212121212), tableName)
+	if err != nil {
+		t.Errorf("Error updating cron table, %v", err)
+	}
+	err = persister.updateEventHashesInTable([]string{"hash1", "hash2"}, tableName)
+	if err != nil {
+		t.Errorf("Error updating cron table, %v", err)
+	}
+
+	// Set to an earlier timestamp to replay
+	replayTimestamp := int64(1000000000)
+	err = persister.setCronTimestampInTable(replayTimestamp, tableName)
+	if err != nil {
+		t.Errorf("Error setting cron timestamp, %v", err)
+	}
+
+	timestamp, err := persister.lastCronTimestampFromTable(tableName)
+	if err != nil {
+		t.Errorf("Error retrieving from cron table: %v", err)
+	}
+	if timestamp != replayTimestamp {
+		t.Errorf("Timestamp should be %v but it is %v", replayTimestamp, timestamp)
+	}
+
+	hashes, err := persister.lastEventHashesFromTable(tableName)
+	if err != nil {
+		t.Errorf("Error retrieving from cron table: %v", err)
+	}
+	for _, hash := range hashes {
+		if hash != "" {
+			t.Errorf("Event hashes should have been cleared but found %v", hash)
+		}
+	}
+
+	// Only a single row per data type should exist after the set
+	var numRows int
+	err = persister.db.Get(&numRows, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName))
+	if err != nil {
+		t.Errorf("Error counting cron rows: %v", err)
+	}
+	if numRows != 2 {
+		t.Errorf("Should have 2 rows in cron table, have %v", numRows)
+	}
+}
+
 func TestLastEventHashesFromTable(t *testing.T) {
 
 	persister := setupTestTable(t, cronTestTableName)
@@ -2693,16 +5710,60 @@ func TestUpdateEventHashes(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error updating cron table, %v", err)
 	}
-
-	eventHashes, err := persister.lastEventHashesFromTable(tableName)
+
+	eventHashes, err := persister.lastEventHashesFromTable(tableName)
+	if err != nil {
+		t.Errorf("Error retrieving from cron table: %v", err)
+	}
+
+	if !reflect.DeepEqual(eventHashes, newEventHashes) {
+		t.Errorf("EventHashes should be %v but is %v", newEventHashes, eventHashes)
+	}
+
+}
+
+func TestUpdateEventHashesDedupeAndTrim(t *testing.T) {
+	persister := setupTestTable(t, cronTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(cronTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	err := persister.updateEventHashesInTable(
+		[]string{"testhash1", "testhash2", "testhash1", "", "testhash3", "testhash2"},
+		tableName,
+	)
+	if err != nil {
+		t.Errorf("Error updating cron table, %v", err)
+	}
+	eventHashes, err := persister.lastEventHashesFromTable(tableName)
+	if err != nil {
+		t.Errorf("Error retrieving from cron table: %v", err)
+	}
+	expected := []string{"testhash1", "testhash2", "testhash3"}
+	if !reflect.DeepEqual(eventHashes, expected) {
+		t.Errorf("EventHashes should be %v but is %v", expected, eventHashes)
+	}
+
+	manyHashes := make([]string, maxCronEventHashes+10)
+	for i := range manyHashes {
+		manyHashes[i] = fmt.Sprintf("testhash%v", i)
+	}
+	err = persister.updateEventHashesInTable(manyHashes, tableName)
+	if err != nil {
+		t.Errorf("Error updating cron table, %v", err)
+	}
+	eventHashes, err = persister.lastEventHashesFromTable(tableName)
 	if err != nil {
 		t.Errorf("Error retrieving from cron table: %v", err)
 	}
-
-	if !reflect.DeepEqual(eventHashes, newEventHashes) {
-		t.Errorf("EventHashes should be %v but is %v", newEventHashes, eventHashes)
+	if len(eventHashes) != maxCronEventHashes {
+		t.Fatalf("Should have trimmed event hashes to %v but have %v", maxCronEventHashes,
+			len(eventHashes))
+	}
+	if eventHashes[0] != "testhash10" || eventHashes[len(eventHashes)-1] != manyHashes[len(manyHashes)-1] {
+		t.Errorf("Should have kept the last event hashes: %v...%v", eventHashes[0],
+			eventHashes[len(eventHashes)-1])
 	}
-
 }
 
 /*
@@ -2784,6 +5845,243 @@ func TestGetTokenTransfersForToAddress(t *testing.T) {
 	}
 }
 
+func createAndSaveTestTokenTransferAtBlock(t *testing.T, persister *PostgresPersister,
+	blockNumber uint64, index uint) *model.TokenTransfer {
+	address1, _ := cstrings.RandomHexStr(32)
+	address2, _ := cstrings.RandomHexStr(32)
+	hex1, _ := cstrings.RandomHexStr(30)
+	hex2, _ := cstrings.RandomHexStr(30)
+	transfer := model.NewTokenTransfer(&model.TokenTransferParams{
+		ToAddress:    common.HexToAddress(address1),
+		FromAddress:  common.HexToAddress(address2),
+		Amount:       big.NewInt(int64(mathrand.Intn(1000))),
+		TransferDate: ctime.CurrentEpochSecsInInt64(),
+		BlockNumber:  blockNumber,
+		TxHash:       common.HexToHash(hex1),
+		TxIndex:      0,
+		BlockHash:    common.HexToHash(hex2),
+		Index:        index,
+	})
+	err := persister.createTokenTransferInTable(transfer, persister.GetTableName(tokenTransferTestTableName))
+	if err != nil {
+		t.Errorf("error saving token transfer: %v", err)
+	}
+	return transfer
+}
+
+func TestGetTokenTransfersByBlockRange(t *testing.T) {
+	persister := setupTokenTransferTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(tokenTransferTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	_, err := persister.db.Exec(postgres.CreateTokenTransferTableMigrationQuery(tableName))
+	if err != nil {
+		t.Errorf("Error running token transfer migration: %v", err)
+	}
+
+	// Seed out of order across blocks
+	_ = createAndSaveTestTokenTransferAtBlock(t, persister, 105, 0)
+	_ = createAndSaveTestTokenTransferAtBlock(t, persister, 102, 3)
+	_ = createAndSaveTestTokenTransferAtBlock(t, persister, 100, 1)
+	_ = createAndSaveTestTokenTransferAtBlock(t, persister, 102, 1)
+	_ = createAndSaveTestTokenTransferAtBlock(t, persister, 110, 0)
+
+	purchases, err := persister.tokenTransfersByBlockRangeFromTable(100, 105, tableName)
+	if err != nil {
+		t.Errorf("Should have not gotten error from transfer query: err: %v", err)
+	}
+	if len(purchases) != 4 {
+		t.Fatalf("Should have gotten 4 results for transfers, got %v", len(purchases))
+	}
+
+	expected := []struct {
+		blockNumber uint64
+		index       uint
+	}{{100, 1}, {102, 1}, {102, 3}, {105, 0}}
+	for i, purchase := range purchases {
+		blockData := purchase.BlockData()
+		if blockData.BlockNumber() != expected[i].blockNumber || blockData.Index() != expected[i].index {
+			t.Errorf("Transfer out of order at %v: block %v, index %v", i, blockData.BlockNumber(),
+				blockData.Index())
+		}
+	}
+
+	purchases, err = persister.tokenTransfersByBlockRangeFromTable(102, 102, tableName)
+	if err != nil {
+		t.Errorf("Should have not gotten error from transfer query: err: %v", err)
+	}
+	if len(purchases) != 2 {
+		t.Errorf("Should have gotten 2 results for transfers, got %v", len(purchases))
+	}
+
+	_, err = persister.tokenTransfersByBlockRangeFromTable(200, 300, tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten ErrPersisterNoResults for empty range: err: %v", err)
+	}
+}
+
+func TestDailyTokenTransferTotals(t *testing.T) {
+	persister := setupTokenTransferTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(tokenTransferTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	day1 := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day4 := day1.AddDate(0, 0, 3)
+	// Larger than an int64
+	bigAmount, _ := new(big.Int).SetString("100000000000000000000", 10)
+
+	seeds := []struct {
+		ts     time.Time
+		amount *big.Int
+	}{
+		{day1.Add(time.Hour), bigAmount},
+		{day1.Add(23 * time.Hour), bigAmount},
+		{day1.Add(12 * time.Hour), big.NewInt(5)},
+		{day2, big.NewInt(10)},
+		{day4.Add(time.Minute), big.NewInt(20)},
+		// After the range
+		{day4.AddDate(0, 0, 1), big.NewInt(30)},
+	}
+	for _, seed := range seeds {
+		address1, _ := cstrings.RandomHexStr(32)
+		hex1, _ := cstrings.RandomHexStr(30)
+		transfer := model.NewTokenTransfer(&model.TokenTransferParams{
+			ToAddress:    common.HexToAddress(address1),
+			Amount:       seed.amount,
+			TransferDate: seed.ts.Unix(),
+			BlockNumber:  uint64(mathrand.Intn(1000000)),
+			TxHash:       common.HexToHash(hex1),
+		})
+		err := persister.createTokenTransferInTable(transfer, tableName)
+		if err != nil {
+			t.Errorf("error saving token transfer: %v", err)
+		}
+	}
+
+	totals, err := persister.dailyTokenTransferTotalsFromTable(day1.Unix(),
+		day4.AddDate(0, 0, 1).Unix(), tableName)
+	if err != nil {
+		t.Fatalf("Should have not gotten error from daily totals query: err: %v", err)
+	}
+	expectedDay1Total, _ := new(big.Int).SetString("200000000000000000005", 10)
+	expected := []struct {
+		day   time.Time
+		total *big.Int
+		count int
+	}{
+		{day1, expectedDay1Total, 3},
+		{day2, big.NewInt(10), 1},
+		{day4, big.NewInt(20), 1},
+	}
+	if len(totals) != len(expected) {
+		t.Fatalf("Should have gotten %v daily totals, got %v", len(expected), len(totals))
+	}
+	for i, total := range totals {
+		if !total.Day.Equal(expected[i].day) {
+			t.Errorf("Should have gotten day %v at %v, got %v", expected[i].day, i, total.Day)
+		}
+		if total.Total.Cmp(expected[i].total) != 0 {
+			t.Errorf("Should have gotten total %v at %v, got %v", expected[i].total, i, total.Total)
+		}
+		if total.Count != expected[i].count {
+			t.Errorf("Should have gotten count %v at %v, got %v", expected[i].count, i, total.Count)
+		}
+	}
+
+	_, err = persister.dailyTokenTransferTotalsFromTable(day4.AddDate(0, 0, 2).Unix(),
+		day4.AddDate(0, 0, 3).Unix(), tableName)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten ErrPersisterNoResults for empty range: err: %v", err)
+	}
+}
+
+func TestTokenTransfersByCriteriaIter(t *testing.T) {
+	persister := setupTokenTransferTable(t)
+	defer persister.Close()
+	tableName := persister.GetTableName(tokenTransferTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	fromAddr := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	otherFromAddr := common.HexToAddress("0x22e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d331d")
+	seeds := []struct {
+		from common.Address
+		ts   int64
+	}{
+		{fromAddr, 3000},
+		{fromAddr, 1000},
+		{otherFromAddr, 2000},
+		{fromAddr, 2000},
+		// After the range
+		{fromAddr, 5000},
+	}
+	for index, seed := range seeds {
+		address1, _ := cstrings.RandomHexStr(32)
+		hex1, _ := cstrings.RandomHexStr(30)
+		transfer := model.NewTokenTransfer(&model.TokenTransferParams{
+			ToAddress:    common.HexToAddress(address1),
+			FromAddress:  seed.from,
+			Amount:       big.NewInt(int64(index)),
+			TransferDate: seed.ts,
+			BlockNumber:  uint64(index),
+			TxHash:       common.HexToHash(hex1),
+		})
+		err := persister.createTokenTransferInTable(transfer, tableName)
+		if err != nil {
+			t.Errorf("error saving token transfer: %v", err)
+		}
+	}
+
+	criteria := &model.TokenTransferCriteria{
+		FromAddress: fromAddr.Hex(),
+		FromTs:      1000,
+		BeforeTs:    5000,
+	}
+	iter, err := persister.tokenTransfersByCriteriaIterFromTable(criteria, tableName)
+	if err != nil {
+		t.Fatalf("Should have not gotten error from criteria iter: err: %v", err)
+	}
+	defer iter.Close() // nolint: errcheck
+
+	dates := []int64{}
+	for iter.Next() {
+		transfer := iter.TokenTransfer()
+		if transfer.FromAddress() != fromAddr {
+			t.Errorf("Should have only returned transfers from the address")
+		}
+		dates = append(dates, transfer.TransferDate())
+	}
+	if iter.Err() != nil {
+		t.Fatalf("Should have not gotten error iterating: err: %v", iter.Err())
+	}
+	if len(dates) != 3 {
+		t.Fatalf("Should have returned 3 transfers, got %v", len(dates))
+	}
+	if dates[0] != 1000 || dates[1] != 2000 || dates[2] != 3000 {
+		t.Errorf("Should have returned transfers sorted by transfer date: %v", dates)
+	}
+
+	criteria.Offset = 1
+	criteria.Count = 1
+	iter2, err := persister.tokenTransfersByCriteriaIterFromTable(criteria, tableName)
+	if err != nil {
+		t.Fatalf("Should have not gotten error from criteria iter: err: %v", err)
+	}
+	defer iter2.Close() // nolint: errcheck
+	numTransfers := 0
+	for iter2.Next() {
+		if iter2.TokenTransfer().TransferDate() != 2000 {
+			t.Errorf("Should have returned the second transfer")
+		}
+		numTransfers++
+	}
+	if numTransfers != 1 {
+		t.Errorf("Should have returned 1 transfer, got %v", numTransfers)
+	}
+}
+
 func TestGetTokenTransfersForTxHash(t *testing.T) {
 	persister := setupTokenTransferTable(t)
 	defer persister.Close()
@@ -2930,46 +6228,416 @@ func TestParamProposalByName(t *testing.T) {
 		t.Errorf("Number of active proposals should be 1 but is %v", len(dbParamProposalsActive))
 	}
 
-	dbAllParamProposals, err := persister.paramProposalByNameFromTable(name, getAll, tableName)
+	dbAllParamProposals, err := persister.paramProposalByNameFromTable(name, getAll, tableName)
+	if err != nil {
+		t.Errorf("Error getting parameter proposal from db %v", err)
+	}
+
+	if len(dbAllParamProposals) != 2 {
+		t.Errorf("Number of proposals should be 2 but is %v", len(dbAllParamProposals))
+	}
+}
+
+func TestUpdateParamProposal(t *testing.T) {
+	persister := setupParamProposalTable(t)
+	tableName := persister.GetTableName(parameterProposalTestTableName)
+	defer persister.Close()
+	defer deleteTestTable(t, persister, tableName)
+
+	paramProposal := createAndSaveTestParamProposal(t, persister)
+	paramProposal.SetAccepted(false)
+	paramProposal.SetExpired(true)
+
+	propID := paramProposal.PropID()
+
+	updatedFields := []string{"Accepted", "Expired"}
+
+	err := persister.updateParamProposalInTable(paramProposal, updatedFields, tableName)
+	if err != nil {
+		t.Errorf("Error updating parameter proposal, %v", err)
+	}
+
+	dbParamProposal, err := persister.paramProposalByPropIDFromTable(propID, false, tableName)
+	if err != nil {
+		t.Errorf("Error getting param proposal from db, err %v", err)
+	}
+
+	if dbParamProposal.Accepted() {
+		t.Error("Wrong value for accepted field after update")
+	}
+
+	if !dbParamProposal.Expired() {
+		t.Error("Wrong value for expired field after update")
+	}
+}
+
+/*
+ * All tests for parameter tables:
+ */
+
+func TestParametersByNameMap(t *testing.T) {
+	persister := setupTestTable(t, governmentParameterTableTestName)
+	defer persister.Close()
+	paramTableName := persister.GetTableName(parameterTableTestName)
+	_, err := persister.db.Exec(postgres.CreateParameterTableQuery(paramTableName))
+	if err != nil {
+		t.Fatalf("Couldn't create test table %s: %v", paramTableName, err)
+	}
+	defer deleteTestTable(t, persister, paramTableName)
+	govtParamTableName := persister.GetTableName(governmentParameterTableTestName)
+	defer deleteTestTable(t, persister, govtParamTableName)
+
+	defaults := map[string]string{"commitStageLen": "100", "revealStageLen": "200"}
+	err = persister.upsertParameterDefaults(defaults, paramTableName)
+	if err != nil {
+		t.Fatalf("Error creating parameters: %v", err)
+	}
+	err = persister.upsertParameterDefaults(defaults, govtParamTableName)
+	if err != nil {
+		t.Fatalf("Error creating government parameters: %v", err)
+	}
+
+	names := []string{"commitStageLen", "notAParam", "revealStageLen"}
+
+	params, err := persister.parametersByName(names, paramTableName)
+	if err != nil {
+		t.Fatalf("Error getting parameters: %v", err)
+	}
+	if len(params) != 3 || params[1] != nil {
+		t.Errorf("Should have 3 parameters with a nil for the missing name")
+	}
+	paramsMap, err := persister.parametersByNameMap(names, paramTableName)
+	if err != nil {
+		t.Fatalf("Error getting parameters map: %v", err)
+	}
+	if len(paramsMap) != 2 {
+		t.Errorf("Should have 2 parameters in map, have %v", len(paramsMap))
+	}
+	if _, ok := paramsMap["notAParam"]; ok {
+		t.Errorf("Should have omitted the missing name from the map")
+	}
+	if paramsMap["revealStageLen"].Value().Int64() != 200 {
+		t.Errorf("Wrong value for parameter: %v", paramsMap["revealStageLen"].Value())
+	}
+
+	govtParams, err := persister.govtParametersByName(names, govtParamTableName)
+	if err != nil {
+		t.Fatalf("Error getting government parameters: %v", err)
+	}
+	if len(govtParams) != 3 || govtParams[1] != nil {
+		t.Errorf("Should have 3 government parameters with a nil for the missing name")
+	}
+	govtParamsMap, err := persister.govtParametersByNameMap(names, govtParamTableName)
+	if err != nil {
+		t.Fatalf("Error getting government parameters map: %v", err)
+	}
+	if len(govtParamsMap) != 2 {
+		t.Errorf("Should have 2 government parameters in map, have %v", len(govtParamsMap))
+	}
+	if _, ok := govtParamsMap["notAParam"]; ok {
+		t.Errorf("Should have omitted the missing name from the map")
+	}
+}
+
+func TestAllParameters(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	versionNo := "allparams"
+	persister.version = &versionNo
+
+	for _, baseName := range []string{
+		postgres.ParameterTableBaseName,
+		postgres.GovernmentParameterTableBaseName,
+	} {
+		err := persister.CreateTable(baseName)
+		if err != nil {
+			t.Fatalf("Error creating table: %v", err)
+		}
+		defer deleteTestTable(t, persister, persister.GetTableName(baseName))
+	}
+
+	_, err := persister.AllParameters()
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results error for empty table: %v", err)
+	}
+
+	config := &utils.ProcessorConfig{
+		ParameterizerDefaultValues: map[string]string{
+			"revealStageLen": "200",
+			"applyStageLen":  "300",
+			"commitStageLen": "100",
+		},
+		GovernmentParameterDefaultValues: map[string]string{
+			"govtPRevealStageLen": "20",
+			"govtPCommitStageLen": "10",
+		},
+	}
+	err = persister.CreateDefaultValues(config)
+	if err != nil {
+		t.Fatalf("Error creating default values: %v", err)
+	}
+
+	params, err := persister.AllParameters()
+	if err != nil {
+		t.Fatalf("Error getting all parameters: %v", err)
+	}
+	expectedNames := []string{"applyStageLen", "commitStageLen", "revealStageLen"}
+	if len(params) != len(expectedNames) {
+		t.Fatalf("Should have %v parameters, have %v", len(expectedNames), len(params))
+	}
+	for i, param := range params {
+		if param.ParamName() != expectedNames[i] {
+			t.Errorf("Parameter not in order, expected %v, got %v", expectedNames[i], param.ParamName())
+		}
+		if param.Value().String() != config.ParameterizerDefaultValues[param.ParamName()] {
+			t.Errorf("Wrong value for %v: %v", param.ParamName(), param.Value())
+		}
+	}
+
+	govtParams, err := persister.AllGovernmentParameters()
+	if err != nil {
+		t.Fatalf("Error getting all government parameters: %v", err)
+	}
+	expectedNames = []string{"govtPCommitStageLen", "govtPRevealStageLen"}
+	if len(govtParams) != len(expectedNames) {
+		t.Fatalf("Should have %v government parameters, have %v", len(expectedNames), len(govtParams))
+	}
+	for i, param := range govtParams {
+		if param.ParamName() != expectedNames[i] {
+			t.Errorf("Government parameter not in order, expected %v, got %v", expectedNames[i], param.ParamName())
+		}
+		if param.Value().String() != config.GovernmentParameterDefaultValues[param.ParamName()] {
+			t.Errorf("Wrong value for %v: %v", param.ParamName(), param.Value())
+		}
+	}
+}
+
+func TestUpsertParameterDefaults(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	versionNo := "upsertparams"
+	persister.version = &versionNo
+
+	for _, baseName := range []string{
+		postgres.ParameterTableBaseName,
+		postgres.GovernmentParameterTableBaseName,
+	} {
+		err := persister.CreateTable(baseName)
+		if err != nil {
+			t.Fatalf("Error creating table: %v", err)
+		}
+		defer deleteTestTable(t, persister, persister.GetTableName(baseName))
+	}
+
+	config := &utils.ProcessorConfig{
+		ParameterizerDefaultValues: map[string]string{
+			"commitStageLen": "100",
+		},
+		GovernmentParameterDefaultValues: map[string]string{
+			"govtPCommitStageLen": "10",
+		},
+	}
+	err := persister.CreateDefaultValues(config)
+	if err != nil {
+		t.Fatalf("Error creating default values: %v", err)
+	}
+
+	// Update an existing value to ensure it is not overwritten by the defaults
+	param, err := persister.ParameterByName("commitStageLen")
+	if err != nil {
+		t.Fatalf("Error getting parameter: %v", err)
+	}
+	param.SetValue(big.NewInt(150))
+	err = persister.UpdateParameter(param, []string{"Value"})
+	if err != nil {
+		t.Fatalf("Error updating parameter: %v", err)
+	}
+
+	err = persister.UpsertParameterDefaults(map[string]string{
+		"commitStageLen": "100",
+		"revealStageLen": "200",
+	})
+	if err != nil {
+		t.Fatalf("Error upserting parameter defaults: %v", err)
+	}
+	params, err := persister.ParametersByNameMap([]string{"commitStageLen", "revealStageLen"})
+	if err != nil {
+		t.Fatalf("Error getting parameters: %v", err)
+	}
+	if len(params) != 2 {
+		t.Fatalf("Should have added the new default to the non-empty table, have %v", len(params))
+	}
+	if params["commitStageLen"].Value().Int64() != 150 {
+		t.Errorf("Should not have overwritten the existing value: %v", params["commitStageLen"].Value())
+	}
+	if params["revealStageLen"].Value().Int64() != 200 {
+		t.Errorf("Should have added the new default value: %v", params["revealStageLen"].Value())
+	}
+
+	err = persister.UpsertGovernmentParameterDefaults(map[string]string{
+		"govtPCommitStageLen": "20",
+		"govtPRevealStageLen": "30",
+	})
+	if err != nil {
+		t.Fatalf("Error upserting government parameter defaults: %v", err)
+	}
+	govtParams, err := persister.GovernmentParametersByNameMap(
+		[]string{"govtPCommitStageLen", "govtPRevealStageLen"})
+	if err != nil {
+		t.Fatalf("Error getting government parameters: %v", err)
+	}
+	if len(govtParams) != 2 {
+		t.Fatalf("Should have added the new government default, have %v", len(govtParams))
+	}
+	if govtParams["govtPCommitStageLen"].Value().Int64() != 10 {
+		t.Errorf("Should not have overwritten the existing value: %v",
+			govtParams["govtPCommitStageLen"].Value())
+	}
+	if govtParams["govtPRevealStageLen"].Value().Int64() != 30 {
+		t.Errorf("Should have added the new default value: %v", govtParams["govtPRevealStageLen"].Value())
+	}
+}
+
+func TestParameterHistory(t *testing.T) {
+	persister := setupDBConnection(t)
+	defer persister.Close()
+	versionNo := "paramhistory"
+	persister.version = &versionNo
+
+	err := persister.CreateTable(postgres.ParameterHistoryTableBaseName)
+	if err != nil {
+		t.Fatalf("Error creating table: %v", err)
+	}
+	defer deleteTestTable(t, persister, persister.GetTableName(postgres.ParameterHistoryTableBaseName))
+
+	_, err = persister.ParameterHistory("commitStageLen")
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results error for empty history: %v", err)
+	}
+
+	changes := []*model.ParameterChange{
+		model.NewParameterChange("commitStageLen", big.NewInt(1800), big.NewInt(2400),
+			[32]byte{0x00, 0x02}, 2000),
+		model.NewParameterChange("commitStageLen", big.NewInt(500), big.NewInt(1800),
+			[32]byte{0x00, 0x01}, 1000),
+		model.NewParameterChange("revealStageLen", big.NewInt(100), big.NewInt(200),
+			[32]byte{0x00, 0x03}, 1500),
+	}
+	for _, change := range changes {
+		err = persister.CreateParameterChange(change)
+		if err != nil {
+			t.Fatalf("Error creating parameter change: %v", err)
+		}
+	}
+
+	history, err := persister.ParameterHistory("commitStageLen")
 	if err != nil {
-		t.Errorf("Error getting parameter proposal from db %v", err)
+		t.Fatalf("Error getting parameter history: %v", err)
 	}
-
-	if len(dbAllParamProposals) != 2 {
-		t.Errorf("Number of proposals should be 2 but is %v", len(dbAllParamProposals))
+	if len(history) != 2 {
+		t.Fatalf("Should have 2 changes, have %v", len(history))
+	}
+	if history[0].PropID() != [32]byte{0x00, 0x01} || history[1].PropID() != [32]byte{0x00, 0x02} {
+		t.Errorf("Changes should be ordered by timestamp")
+	}
+	if history[0].OldValue().Int64() != 500 || history[0].NewValue().Int64() != 1800 {
+		t.Errorf("Wrong values for first change: %v -> %v", history[0].OldValue(),
+			history[0].NewValue())
+	}
+	if history[1].OldValue().Int64() != 1800 || history[1].NewValue().Int64() != 2400 {
+		t.Errorf("Wrong values for second change: %v -> %v", history[1].OldValue(),
+			history[1].NewValue())
 	}
 }
 
-func TestUpdateParamProposal(t *testing.T) {
-	persister := setupParamProposalTable(t)
-	tableName := persister.GetTableName(parameterProposalTestTableName)
+func TestListingStateHistory(t *testing.T) {
+	persister := setupDBConnection(t)
 	defer persister.Close()
-	defer deleteTestTable(t, persister, tableName)
-
-	paramProposal := createAndSaveTestParamProposal(t, persister)
-	paramProposal.SetAccepted(false)
-	paramProposal.SetExpired(true)
-
-	propID := paramProposal.PropID()
-
-	updatedFields := []string{"Accepted", "Expired"}
+	versionNo := "statehistory"
+	persister.version = &versionNo
 
-	err := persister.updateParamProposalInTable(paramProposal, updatedFields, tableName)
+	err := persister.CreateTable(postgres.ListingStateHistoryTableBaseName)
 	if err != nil {
-		t.Errorf("Error updating parameter proposal, %v", err)
+		t.Fatalf("Error creating table: %v", err)
 	}
-
-	dbParamProposal, err := persister.paramProposalByPropIDFromTable(propID, false, tableName)
+	defer deleteTestTable(t, persister, persister.GetTableName(postgres.ListingStateHistoryTableBaseName))
+	_, err = persister.db.Exec(postgres.CreateListingStateHistoryTableMigrationQuery(
+		persister.GetTableName(postgres.ListingStateHistoryTableBaseName)))
 	if err != nil {
-		t.Errorf("Error getting param proposal from db, err %v", err)
+		t.Fatalf("Error migrating table: %v", err)
 	}
 
-	if dbParamProposal.Accepted() {
-		t.Error("Wrong value for accepted field after update")
+	listingAddress := common.HexToAddress(testAddress)
+	_, err = persister.ListingStateHistory(listingAddress)
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results error for empty history: %v", err)
+	}
+
+	// Whitelist then challenge, persisted out of order
+	changes := []*model.ListingStateChange{
+		model.NewListingStateChange(&model.NewListingStateChangeParams{
+			ListingAddress: listingAddress,
+			OldWhitelisted: true,
+			NewWhitelisted: true,
+			OldState:       model.GovernanceStateAppWhitelisted,
+			NewState:       model.GovernanceStateChallenged,
+			EventHash:      "challengehash",
+			Timestamp:      2000,
+		}),
+		model.NewListingStateChange(&model.NewListingStateChangeParams{
+			ListingAddress: listingAddress,
+			OldWhitelisted: false,
+			NewWhitelisted: true,
+			OldState:       model.GovernanceStateApplied,
+			NewState:       model.GovernanceStateAppWhitelisted,
+			EventHash:      "whitelistedhash",
+			Timestamp:      1000,
+		}),
+		model.NewListingStateChange(&model.NewListingStateChangeParams{
+			ListingAddress: common.HexToAddress(testAddress2),
+			OldWhitelisted: false,
+			NewWhitelisted: true,
+			OldState:       model.GovernanceStateApplied,
+			NewState:       model.GovernanceStateAppWhitelisted,
+			EventHash:      "otherhash",
+			Timestamp:      1500,
+		}),
+	}
+	for _, change := range changes {
+		err = persister.CreateListingStateChange(change)
+		if err != nil {
+			t.Fatalf("Error creating listing state change: %v", err)
+		}
+	}
+	// A replayed event does not add the change again
+	err = persister.CreateListingStateChange(changes[0])
+	if err != nil {
+		t.Fatalf("Error creating replayed listing state change: %v", err)
 	}
 
-	if !dbParamProposal.Expired() {
-		t.Error("Wrong value for expired field after update")
+	history, err := persister.ListingStateHistory(listingAddress)
+	if err != nil {
+		t.Fatalf("Error getting listing state history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Should have 2 changes, have %v", len(history))
+	}
+	if history[0].EventHash() != "whitelistedhash" || history[1].EventHash() != "challengehash" {
+		t.Errorf("Changes should be ordered by timestamp")
+	}
+	if history[0].OldWhitelisted() || !history[0].NewWhitelisted() {
+		t.Errorf("Should have been whitelisted in first change")
+	}
+	if history[0].NewState() != model.GovernanceStateAppWhitelisted {
+		t.Errorf("Wrong new state for first change: %v", history[0].NewState())
+	}
+	if history[1].OldState() != model.GovernanceStateAppWhitelisted ||
+		history[1].NewState() != model.GovernanceStateChallenged {
+		t.Errorf("Wrong states for second change: %v -> %v", history[1].OldState(),
+			history[1].NewState())
+	}
+	if history[1].ListingAddress() != listingAddress {
+		t.Errorf("Wrong listing address: %v", history[1].ListingAddress().Hex())
 	}
 }
 
@@ -3130,6 +6798,64 @@ func TestUserChallengeByCriteria(t *testing.T) {
 	}
 }
 
+func TestCountUserChallengeDataByCriteria(t *testing.T) {
+	persister := setupUserChallengeDataTable(t)
+	tableName := persister.GetTableName(userChallengeDataTestTableName)
+	defer persister.Close()
+	defer deleteTestTable(t, persister, tableName)
+	userAddress := common.HexToAddress(testAddress)
+	pollRevealEndDate := big.NewInt(ctime.CurrentEpochSecsInInt64() + int64(60*2))
+	earlierRevealDate := big.NewInt(ctime.CurrentEpochSecsInInt64() - int64(2))
+
+	_ = createAndSaveTestUserChallengeData(t, persister, userAddress, big.NewInt(1),
+		pollRevealEndDate, true)
+	_ = createAndSaveTestUserChallengeData(t, persister, userAddress, big.NewInt(2),
+		pollRevealEndDate, true)
+	_ = createAndSaveTestUserChallengeData(t, persister, userAddress, big.NewInt(3),
+		earlierRevealDate, true)
+	_ = createAndSaveTestUserChallengeDataForCollect(t, persister, userAddress,
+		big.NewInt(4), earlierRevealDate, true)
+	// Not the latest vote, should never be counted
+	_ = createAndSaveTestUserChallengeData(t, persister, userAddress, big.NewInt(1),
+		pollRevealEndDate, false)
+
+	criterias := []*model.UserChallengeDataCriteria{
+		{UserAddress: userAddress.Hex()},
+		{UserAddress: userAddress.Hex(), PollID: 1},
+		{UserAddress: userAddress.Hex(), CanUserReveal: true},
+		{UserAddress: userAddress.Hex(), CanUserRescue: true},
+		{CanUserCollect: true},
+	}
+	expectedCounts := []int{4, 1, 2, 2, 1}
+
+	for index, criteria := range criterias {
+		count, err := persister.countUserChallengeDataByCriteriaFromTable(criteria, tableName)
+		if err != nil {
+			t.Errorf("Error getting count from table: %v", err)
+		}
+		if count != expectedCounts[index] {
+			t.Errorf("Should have gotten a count of %v but got %v", expectedCounts[index], count)
+		}
+		userChallengeDataDB, err := persister.userChallengeDataByCriteriaFromTable(criteria, tableName)
+		if err != nil {
+			t.Errorf("Error getting data from table: %v", err)
+		}
+		if count != len(userChallengeDataDB) {
+			t.Errorf("Count should match number of results: %v, %v", count, len(userChallengeDataDB))
+		}
+	}
+
+	count, err := persister.countUserChallengeDataByCriteriaFromTable(&model.UserChallengeDataCriteria{
+		PollID: 100,
+	}, tableName)
+	if err != nil {
+		t.Errorf("Error getting count from table: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Should have gotten a count of 0 but got %v", count)
+	}
+}
+
 func TestUpdateUserChallengeData(t *testing.T) {
 	persister := setupUserChallengeDataTable(t)
 	tableName := persister.GetTableName(userChallengeDataTestTableName)
@@ -3230,3 +6956,356 @@ func TestMultipleVoteCommitted(t *testing.T) {
 		t.Error("latestVote should be false")
 	}
 }
+
+func TestReplayedVoteCommitted(t *testing.T) {
+	persister := setupUserChallengeDataTable(t)
+	tableName := persister.GetTableName(userChallengeDataTestTableName)
+	defer persister.Close()
+	defer deleteTestTable(t, persister, tableName)
+
+	pollID1 := big.NewInt(1)
+	userAddress := common.HexToAddress(testAddress)
+	pollRevealEndDate := big.NewInt(ctime.CurrentEpochSecsInInt64() + int64(60*2))
+
+	// Replay the same committed vote twice
+	userChallengeData := setupSampleUserChallengeData(userAddress, pollID1, pollRevealEndDate, true)
+	for i := 0; i < 2; i++ {
+		err := persister.createUserChallengeDataInTable(userChallengeData, tableName)
+		if err != nil {
+			t.Errorf("error saving user challenge data: %v", err)
+		}
+	}
+
+	userChallengeDataDB, err := persister.userChallengeDataByCriteriaFromTable(&model.UserChallengeDataCriteria{
+		UserAddress: userAddress.Hex(),
+		PollID:      pollID1.Uint64(),
+	}, tableName)
+	if err != nil {
+		t.Errorf("Error getting userchallengedata: err %v", err)
+	}
+	if len(userChallengeDataDB) != 1 {
+		t.Errorf("Should have only 1 latest vote, have %v", len(userChallengeDataDB))
+	}
+
+	allUserChallengeDataDB, err := persister.userChallengeDataByCriteriaFromTable(&model.UserChallengeDataCriteria{
+		UserAddress:     userAddress.Hex(),
+		PollID:          pollID1.Uint64(),
+		IncludeAllVotes: true,
+	}, tableName)
+	if err != nil {
+		t.Errorf("Error getting userchallengedata: err %v", err)
+	}
+	numLatest := 0
+	for _, data := range allUserChallengeDataDB {
+		if data.LatestVote() {
+			numLatest++
+		}
+	}
+	if numLatest != 1 {
+		t.Errorf("Should have only 1 row with latest vote, have %v", numLatest)
+	}
+	if len(allUserChallengeDataDB) != 1 {
+		t.Errorf("Should not have inserted a row for the replayed commit, have %v",
+			len(allUserChallengeDataDB))
+	}
+}
+
+func TestUserChallengeDataIncludeAllVotes(t *testing.T) {
+	persister := setupUserChallengeDataTable(t)
+	tableName := persister.GetTableName(userChallengeDataTestTableName)
+	defer persister.Close()
+	defer deleteTestTable(t, persister, tableName)
+
+	pollID1 := big.NewInt(1)
+	userAddress := common.HexToAddress(testAddress)
+	pollRevealEndDate := big.NewInt(ctime.CurrentEpochSecsInInt64() + int64(60*2))
+	_ = createAndSaveTestUserChallengeData(t, persister, userAddress, pollID1,
+		pollRevealEndDate, false)
+	_ = createAndSaveTestUserChallengeData(t, persister, userAddress, pollID1,
+		pollRevealEndDate, true)
+
+	criteria := &model.UserChallengeDataCriteria{
+		UserAddress: userAddress.Hex(),
+		PollID:      pollID1.Uint64(),
+	}
+	count, err := persister.countUserChallengeDataByCriteriaFromTable(criteria, tableName)
+	if err != nil {
+		t.Errorf("Error getting count from table: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Should have only counted the latest vote, got %v", count)
+	}
+
+	criteria.IncludeAllVotes = true
+	allCount, err := persister.countUserChallengeDataByCriteriaFromTable(criteria, tableName)
+	if err != nil {
+		t.Errorf("Error getting count from table: %v", err)
+	}
+	if allCount != 2 {
+		t.Errorf("Should have counted all votes, got %v", allCount)
+	}
+
+	userChallengeDataDB, err := persister.userChallengeDataByCriteriaFromTable(criteria, tableName)
+	if err != nil {
+		t.Errorf("Error getting userchallengedata: err %v", err)
+	}
+	if len(userChallengeDataDB) != 2 {
+		t.Errorf("Should have returned all votes, got %v", len(userChallengeDataDB))
+	}
+}
+
+func TestVerifyTableSchema(t *testing.T) {
+	tableBaseName := "listing_test"
+	persister := setupTestTable(t, tableBaseName)
+	defer persister.Close()
+	tableName := persister.GetTableName(tableBaseName)
+	defer deleteTestTable(t, persister, tableName)
+
+	mismatch, err := persister.verifyTableSchema(postgres.Listing{}, tableName, nil)
+	if err != nil {
+		t.Fatalf("Error verifying table schema: %v", err)
+	}
+	if mismatch != nil {
+		t.Errorf("Should not have found a mismatch for a new table: %v", mismatch)
+	}
+
+	// Deliberately drift the table from the model
+	_, err = persister.db.Exec(fmt.Sprintf(
+		"ALTER TABLE %s DROP COLUMN charter, ADD COLUMN unexpected TEXT;", tableName))
+	if err != nil {
+		t.Fatalf("Error altering table: %v", err)
+	}
+	mismatch, err = persister.verifyTableSchema(postgres.Listing{}, tableName, nil)
+	if err != nil {
+		t.Fatalf("Error verifying table schema: %v", err)
+	}
+	if mismatch == nil {
+		t.Fatalf("Should have found a mismatch for the altered table")
+	}
+	if mismatch.TableName != tableName {
+		t.Errorf("Should have returned the table name: %v", mismatch.TableName)
+	}
+	if !reflect.DeepEqual(mismatch.MissingColumns, []string{"charter"}) {
+		t.Errorf("Should have found charter missing: %v", mismatch.MissingColumns)
+	}
+	if !reflect.DeepEqual(mismatch.ExtraColumns, []string{"unexpected"}) {
+		t.Errorf("Should have found the extra column: %v", mismatch.ExtraColumns)
+	}
+
+	mismatch, err = persister.verifyTableSchema(postgres.Listing{}, "nonexistent_table", nil)
+	if err != nil {
+		t.Fatalf("Error verifying table schema: %v", err)
+	}
+	if mismatch == nil || len(mismatch.MissingColumns) != len(modelColumns(postgres.Listing{})) {
+		t.Errorf("Should have found all columns missing for a nonexistent table: %v", mismatch)
+	}
+}
+
+func TestRetryOnSerializationErrorDeadlock(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(listingTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	listing1, listingAddr1 := setupSampleListing()
+	listing2, listingAddr2 := setupSampleListing()
+	for _, listing := range []*model.Listing{listing1, listing2} {
+		err := persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Fatalf("error saving listing: %v", err)
+		}
+	}
+
+	// Each transaction locks one listing, waits for the other to lock the
+	// other listing, then updates it, forcing a deadlock on the first attempt
+	var locked sync.WaitGroup
+	locked.Add(2)
+	updateQuery := fmt.Sprintf("UPDATE %s SET name = $1 WHERE contract_address = $2;", tableName)
+	lockInOrder := func(name string, first common.Address, second common.Address,
+		attempts *int) error {
+		*attempts++
+		tx, err := persister.db.Beginx()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(updateQuery, name, first.Hex())
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if *attempts == 1 {
+			locked.Done()
+			locked.Wait()
+		}
+		_, err = tx.Exec(updateQuery, name, second.Hex())
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	attempts := make([]int, 2)
+	addrs := [][]common.Address{{listingAddr1, listingAddr2}, {listingAddr2, listingAddr1}}
+	for i := range addrs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = persister.retryOnSerializationError(func() error {
+				return lockInOrder(fmt.Sprintf("name%v", i), addrs[i][0], addrs[i][1], &attempts[i])
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Should have eventually succeeded transaction %v: err: %v", i, err)
+		}
+	}
+	if attempts[0]+attempts[1] < 3 {
+		t.Errorf("Should have retried the deadlocked transaction, attempts: %v", attempts)
+	}
+}
+
+func TestTruncateTable(t *testing.T) {
+	persister := setupTestTable(t, listingTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(listingTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+
+	_, err := persister.db.Exec(postgres.CreateListingTableIndicesQuery(tableName))
+	if err != nil {
+		t.Fatalf("Error creating listing indices: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		listing, _ := setupSampleListing()
+		err = persister.createListingForTable(listing, tableName)
+		if err != nil {
+			t.Fatalf("Error creating listing: %v", err)
+		}
+	}
+	indexCountQuery := "SELECT COUNT(*) FROM pg_indexes WHERE tablename = $1;"
+	var numIndices int
+	err = persister.db.Get(&numIndices, indexCountQuery, tableName)
+	if err != nil {
+		t.Fatalf("Error counting indices: %v", err)
+	}
+
+	err = persister.TruncateTable(tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error truncating the table: %v", err)
+	}
+
+	var numRows int
+	err = persister.db.Get(&numRows, fmt.Sprintf("SELECT COUNT(*) FROM %s;", tableName))
+	if err != nil {
+		t.Fatalf("Error counting rows: %v", err)
+	}
+	if numRows != 0 {
+		t.Errorf("Should have deleted all the rows, have %v", numRows)
+	}
+	mismatch, err := persister.verifyTableSchema(postgres.Listing{}, tableName, nil)
+	if err != nil {
+		t.Fatalf("Error verifying table schema: %v", err)
+	}
+	if mismatch != nil {
+		t.Errorf("Should have kept the table schema: %v", mismatch)
+	}
+	var numIndicesAfter int
+	err = persister.db.Get(&numIndicesAfter, indexCountQuery, tableName)
+	if err != nil {
+		t.Fatalf("Error counting indices: %v", err)
+	}
+	if numIndicesAfter != numIndices {
+		t.Errorf("Should have kept the %v indices, have %v", numIndices, numIndicesAfter)
+	}
+
+	// The table can be written to after truncating
+	listing, _ := setupSampleListing()
+	err = persister.createListingForTable(listing, tableName)
+	if err != nil {
+		t.Errorf("Should have been able to create a listing after truncating: %v", err)
+	}
+}
+
+func TestUpsertContentRevision(t *testing.T) {
+	persister := setupTestTable(t, contentRevisionTestTableName)
+	defer persister.Close()
+	tableName := persister.GetTableName(contentRevisionTestTableName)
+	defer deleteTestTable(t, persister, tableName)
+	// The migration adds the unique index the upsert conflicts on
+	_, err := persister.db.Exec(postgres.CreateContentRevisionTableMigrationQuery(tableName))
+	if err != nil {
+		t.Fatalf("Should not have gotten an error migrating the table: %v", err)
+	}
+
+	listingAddr := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	editorAddr := common.HexToAddress("0x39eB410144784010b84B076087B073889411F878")
+	contentID := big.NewInt(1)
+	revisionID := big.NewInt(0)
+	ts := ctime.CurrentEpochSecsInInt64()
+
+	exists, err := persister.contentRevisionExistsInTable(listingAddr, contentID, revisionID, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error checking the revision exists: %v", err)
+	}
+	if exists {
+		t.Errorf("Should not have found a revision in an empty table")
+	}
+
+	contRev := model.NewContentRevision(listingAddr, model.ArticlePayload{}, "payloadHash",
+		editorAddr, contentID, revisionID, "revisionURI", ts)
+	err = persister.upsertContentRevisionInTable(contRev, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error upserting a new revision: %v", err)
+	}
+	exists, err = persister.contentRevisionExistsInTable(listingAddr, contentID, revisionID, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error checking the revision exists: %v", err)
+	}
+	if !exists {
+		t.Errorf("Should have found the upserted revision")
+	}
+
+	// Replaying the same revision updates the existing row
+	replayed := model.NewContentRevision(listingAddr, model.ArticlePayload{}, "newPayloadHash",
+		editorAddr, contentID, revisionID, "newRevisionURI", ts+1)
+	err = persister.upsertContentRevisionInTable(replayed, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error upserting an existing revision: %v", err)
+	}
+	contRevs, err := persister.contentRevisionsFromTable(listingAddr, contentID, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving the revisions: %v", err)
+	}
+	if len(contRevs) != 1 {
+		t.Fatalf("Should have only 1 revision after the replay, have %v", len(contRevs))
+	}
+	if contRevs[0].PayloadHash() != "newPayloadHash" {
+		t.Errorf("Should have updated the payload hash: %v", contRevs[0].PayloadHash())
+	}
+	if contRevs[0].RevisionURI() != "newRevisionURI" {
+		t.Errorf("Should have updated the revision uri: %v", contRevs[0].RevisionURI())
+	}
+	if contRevs[0].RevisionDateTs() != ts+1 {
+		t.Errorf("Should have updated the revision timestamp: %v", contRevs[0].RevisionDateTs())
+	}
+
+	// A new revision ID is created as a separate revision
+	nextRev := model.NewContentRevision(listingAddr, model.ArticlePayload{}, "payloadHash",
+		editorAddr, contentID, big.NewInt(1), "revisionURI", ts+2)
+	err = persister.upsertContentRevisionInTable(nextRev, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error upserting a new revision: %v", err)
+	}
+	contRevs, err = persister.contentRevisionsFromTable(listingAddr, contentID, tableName)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving the revisions: %v", err)
+	}
+	if len(contRevs) != 2 {
+		t.Errorf("Should have 2 revisions, have %v", len(contRevs))
+	}
+}
@@ -0,0 +1,43 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// SetReadReplica sets the connection to a read replica. All the data queries are
+// made on the replica, while all writes, and the version, cron, index and schema
+// queries used to manage the tables, are made on the primary.
+// Replica reads may lag behind the primary, so only set a replica on a read only
+// persister, such as for an API. The processor reads rows to update them and
+// must not have a replica set. Persisters from WithVersion share the replica.
+func (p *PostgresPersister) SetReadReplica(db *sqlx.DB) {
+	p.replicaDB = newTimedDB(db)
+}
+
+// ConnectReadReplica connects to the read replica at the given connection URL
+// and sets it as the read replica. See SetReadReplica.
+func (p *PostgresPersister) ConnectReadReplica(url string, poolOpts ...PoolOption) error {
+	psqlInfo, err := postgresConnInfoFromURL(url)
+	if err != nil {
+		return err
+	}
+	config := &poolConfig{}
+	for _, opt := range poolOpts {
+		opt(config)
+	}
+	db, err := connectPool(psqlInfo, config)
+	if err != nil {
+		return err
+	}
+	p.SetReadReplica(db)
+	return nil
+}
+
+// readDB returns the connection for read queries, the replica if set or
+// the primary if not
+func (p *PostgresPersister) readDB() *timedDB {
+	if p.replicaDB != nil {
+		return p.replicaDB
+	}
+	return p.db
+}
@@ -0,0 +1,194 @@
+package persistence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+)
+
+const recordingDriverName = "persistence_recording"
+
+func init() {
+	sql.Register(recordingDriverName, &recordingDriver{queries: map[string][]string{}})
+}
+
+// recordingDriver is a database/sql driver that records the queries made on
+// each DSN and returns no rows
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries map[string][]string
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) {
+	return &recordingConn{driver: d, dsn: dsn}, nil
+}
+
+func (d *recordingDriver) record(dsn string, query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries[dsn] = append(d.queries[dsn], query)
+}
+
+func (d *recordingDriver) numQueries(dsn string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queries[dsn])
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+	dsn    string
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *recordingConn) Commit() error { return nil }
+
+func (c *recordingConn) Rollback() error { return nil }
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error { return nil }
+
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.record(s.conn.dsn, s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.record(s.conn.dsn, s.query)
+	return &emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (r *emptyRows) Columns() []string { return []string{} }
+
+func (r *emptyRows) Close() error { return nil }
+
+func (r *emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func openRecordingDB(t *testing.T, dsn string) (*sqlx.DB, *recordingDriver) {
+	db, err := sql.Open(recordingDriverName, dsn)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error opening db: %v", err)
+	}
+	// Use the postgres bind type for the persister queries
+	return sqlx.NewDb(db, "postgres"), db.Driver().(*recordingDriver)
+}
+
+func TestReadReplicaQueries(t *testing.T) {
+	primaryDB, recorder := openRecordingDB(t, "replica_test_primary")
+	replicaDB, _ := openRecordingDB(t, "replica_test_replica")
+	persister, _ := NewPostgresPersisterFromSqlx(primaryDB)
+	persister.SetReadReplica(replicaDB)
+	defer persister.Close() // nolint: errcheck
+
+	_, err := persister.ListingsByCriteria(&model.ListingCriteria{})
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving listings: %v", err)
+	}
+	_, err = persister.GovernanceEventsByListingAddress(common.HexToAddress("0x1"))
+	if err != nil && err != cpersist.ErrPersisterNoResults {
+		t.Fatalf("Should not have gotten an error retrieving gov events: %v", err)
+	}
+	if recorder.numQueries("replica_test_replica") != 2 {
+		t.Errorf("Should have made 2 queries on the replica: %v",
+			recorder.numQueries("replica_test_replica"))
+	}
+	if recorder.numQueries("replica_test_primary") != 0 {
+		t.Errorf("Should not have made reads on the primary: %v",
+			recorder.numQueries("replica_test_primary"))
+	}
+
+	err = persister.MarkListingRemoved(common.HexToAddress("0x1"))
+	if err != nil {
+		t.Fatalf("Should not have gotten an error marking listing removed: %v", err)
+	}
+	if recorder.numQueries("replica_test_primary") != 1 {
+		t.Errorf("Should have made the write on the primary: %v",
+			recorder.numQueries("replica_test_primary"))
+	}
+	if recorder.numQueries("replica_test_replica") != 2 {
+		t.Errorf("Should not have made the write on the replica: %v",
+			recorder.numQueries("replica_test_replica"))
+	}
+
+	versioned := persister.WithVersion("123")
+	_, err = versioned.ListingsByCriteria(&model.ListingCriteria{})
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving listings: %v", err)
+	}
+	if recorder.numQueries("replica_test_replica") != 3 {
+		t.Errorf("Should have shared the replica with the versioned persister: %v",
+			recorder.numQueries("replica_test_replica"))
+	}
+}
+
+func TestReadReplicaDataQueries(t *testing.T) {
+	primaryDB, recorder := openRecordingDB(t, "replica_data_test_primary")
+	replicaDB, _ := openRecordingDB(t, "replica_data_test_replica")
+	persister, _ := NewPostgresPersisterFromSqlx(primaryDB)
+	persister.SetReadReplica(replicaDB)
+	defer persister.Close() // nolint: errcheck
+
+	address := common.HexToAddress("0x1")
+	txHash := common.HexToHash("0x2")
+	// The recording db returns no rows, so only the queries made are checked
+	reads := []func(){
+		func() { _, _ = persister.ChallengesByChallengerAddress(address) },
+		func() { _, _ = persister.TokenTransfersByTxHash(txHash) },
+		func() { _, _ = persister.TokenTransfersByToAddress(address) },
+		func() { _, _ = persister.ContentRevision(address, big.NewInt(0), big.NewInt(0)) },
+		func() { _, _ = persister.ContentRevisions(address, big.NewInt(0)) },
+		func() { _, _ = persister.GovernanceEventsByTxHash(txHash) },
+		func() { _, _ = persister.ListingsWithRecentGovernanceEvent("Challenge", 0) },
+	}
+	for _, read := range reads {
+		read()
+	}
+	if recorder.numQueries("replica_data_test_replica") != len(reads) {
+		t.Errorf("Should have made %v queries on the replica: %v", len(reads),
+			recorder.numQueries("replica_data_test_replica"))
+	}
+	if recorder.numQueries("replica_data_test_primary") != 0 {
+		t.Errorf("Should not have made reads on the primary: %v",
+			recorder.numQueries("replica_data_test_primary"))
+	}
+}
+
+func TestReadReplicaFallbackToPrimary(t *testing.T) {
+	primaryDB, recorder := openRecordingDB(t, "fallback_test_primary")
+	persister, _ := NewPostgresPersisterFromSqlx(primaryDB)
+	defer persister.Close() // nolint: errcheck
+
+	_, err := persister.ListingsByCriteria(&model.ListingCriteria{})
+	if err != nil {
+		t.Fatalf("Should not have gotten an error retrieving listings: %v", err)
+	}
+	if recorder.numQueries("fallback_test_primary") != 1 {
+		t.Errorf("Should have made the read on the primary: %v",
+			recorder.numQueries("fallback_test_primary"))
+	}
+}
@@ -0,0 +1,114 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"database/sql/driver"
+	"io"
+	"net"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Postgres error class for connection exceptions
+	pqConnectionExceptionClass = "08"
+	// Postgres error codes for transactions rolled back due to concurrent writes
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+
+	// maxSerializationRetries is the number of times a write is retried after
+	// a serialization failure or deadlock
+	maxSerializationRetries = 3
+
+	// database/sql default for max idle connections, used when the idle
+	// limit of a given sqlx.DB is unknown
+	defaultSQLMaxIdleConns = 2
+)
+
+// isConnError returns true if the error indicates a broken connection rather
+// than an error with the query itself
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	cause := errors.Cause(err)
+	if cause == driver.ErrBadConn || cause == io.EOF || cause == io.ErrUnexpectedEOF {
+		return true
+	}
+	switch e := cause.(type) {
+	case net.Error:
+		return true
+	case *pq.Error:
+		return e.Code.Class() == pqConnectionExceptionClass
+	}
+	return false
+}
+
+// serializationRetryBackoff is the wait before the first retry after a
+// serialization failure, doubled for each following retry. Set in tests.
+var serializationRetryBackoff = 100 * time.Millisecond
+
+// isSerializationError returns true if the error indicates the statement or
+// transaction was rolled back due to a serialization failure or deadlock with
+// a concurrent transaction
+func isSerializationError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := errors.Cause(err).(*pq.Error); ok {
+		return e.Code == pqSerializationFailure || e.Code == pqDeadlockDetected
+	}
+	return false
+}
+
+// resetIdleConns closes all the idle connections in the pool so the next
+// query is made on a fresh connection
+func (p *PostgresPersister) resetIdleConns() {
+	maxIdle := p.idleConnsLimit()
+	p.db.SetMaxIdleConns(0)
+	p.db.SetMaxIdleConns(maxIdle)
+	if p.replicaDB != nil {
+		p.replicaDB.SetMaxIdleConns(0)
+		p.replicaDB.SetMaxIdleConns(maxIdle)
+	}
+}
+
+// idleConnsLimit returns the idle conn limit to restore after resetting the
+// pool, the database/sql default if the limit of the pool is unknown
+func (p *PostgresPersister) idleConnsLimit() int {
+	if p.maxIdleConns != nil {
+		return *p.maxIdleConns
+	}
+	return defaultSQLMaxIdleConns
+}
+
+// retryOnConnError runs fn and, if it fails with a connection error, retries
+// it once on a fresh connection. Query errors are returned without a retry.
+func (p *PostgresPersister) retryOnConnError(fn func() error) error {
+	err := fn()
+	if !isConnError(err) {
+		return err
+	}
+	log.Warningf("Connection error, retrying on a new connection: err: %v", err)
+	p.resetIdleConns()
+	return fn()
+}
+
+// retryOnSerializationError runs the write fn and, if it fails with a
+// serialization failure or deadlock, retries it up to maxSerializationRetries
+// times with an exponential backoff. Postgres rolls back the failed statement
+// or transaction, so fn must be a single statement or a complete transaction.
+// Writes made up of several transactions are not safe to retry.
+func (p *PostgresPersister) retryOnSerializationError(fn func() error) error {
+	backoff := serializationRetryBackoff
+	err := fn()
+	for i := 0; i < maxSerializationRetries && isSerializationError(err); i++ {
+		log.Warningf("Serialization failure, retrying write in %v: err: %v", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package persistence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+)
+
+func TestIsConnError(t *testing.T) {
+	connErrors := []error{
+		driver.ErrBadConn,
+		io.EOF,
+		io.ErrUnexpectedEOF,
+		&net.OpError{Op: "read", Err: errors.New("connection reset by peer")},
+		&pq.Error{Code: "08006"},
+		errors.Wrap(driver.ErrBadConn, "error retrieving listings"),
+	}
+	for _, err := range connErrors {
+		if !isConnError(err) {
+			t.Errorf("Should have been a connection error: %v", err)
+		}
+	}
+
+	queryErrors := []error{
+		nil,
+		sql.ErrNoRows,
+		cpersist.ErrPersisterNoResults,
+		errors.New("some error"),
+		&pq.Error{Code: "42P01"},
+		errors.Wrap(&pq.Error{Code: "23505"}, "error inserting listing"),
+	}
+	for _, err := range queryErrors {
+		if isConnError(err) {
+			t.Errorf("Should not have been a connection error: %v", err)
+		}
+	}
+}
+
+func TestRetryOnConnError(t *testing.T) {
+	// Opening does not connect to the DB
+	db, err := sqlx.Open("postgres", "host=localhost sslmode=disable")
+	if err != nil {
+		t.Fatalf("Should not have gotten an error opening db: %v", err)
+	}
+	defer db.Close() // nolint: errcheck
+	persister, _ := NewPostgresPersisterFromSqlx(db)
+
+	// Recovers after a connection error
+	calls := 0
+	err = persister.retryOnConnError(func() error {
+		calls++
+		if calls == 1 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Should not have gotten an error after retry: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Should have been called twice, called %v", calls)
+	}
+
+	// Only retries once
+	calls = 0
+	err = persister.retryOnConnError(func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+	if err != driver.ErrBadConn {
+		t.Errorf("Should have returned the connection error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Should have been called twice, called %v", calls)
+	}
+
+	// Does not retry query errors
+	calls = 0
+	err = persister.retryOnConnError(func() error {
+		calls++
+		return cpersist.ErrPersisterNoResults
+	})
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have returned the query error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Should have been called once, called %v", calls)
+	}
+}
+
+func TestIdleConnsLimit(t *testing.T) {
+	persister := &PostgresPersister{}
+	if persister.idleConnsLimit() != defaultSQLMaxIdleConns {
+		t.Errorf("Should have used the default for an unknown limit: %v",
+			persister.idleConnsLimit())
+	}
+
+	// Idle conns can be disabled
+	pool := &poolConfig{}
+	WithMaxIdleConns(0)(pool)
+	maxIdle := pool.maxIdleConns()
+	persister.maxIdleConns = &maxIdle
+	if persister.idleConnsLimit() != 0 {
+		t.Errorf("Should have kept a limit of 0: %v", persister.idleConnsLimit())
+	}
+
+	pool = &poolConfig{}
+	maxIdle = pool.maxIdleConns()
+	if maxIdle != maxIdleConns {
+		t.Errorf("Should have used the default for an unset limit: %v", maxIdle)
+	}
+}
+
+func TestIsSerializationError(t *testing.T) {
+	serializationErrors := []error{
+		&pq.Error{Code: "40001"},
+		&pq.Error{Code: "40P01"},
+		errors.Wrap(&pq.Error{Code: "40P01"}, "error updating fields in db"),
+	}
+	for _, err := range serializationErrors {
+		if !isSerializationError(err) {
+			t.Errorf("Should have been a serialization error: %v", err)
+		}
+	}
+
+	otherErrors := []error{
+		nil,
+		driver.ErrBadConn,
+		errors.New("some error"),
+		&pq.Error{Code: "40002"},
+		&pq.Error{Code: "23505"},
+	}
+	for _, err := range otherErrors {
+		if isSerializationError(err) {
+			t.Errorf("Should not have been a serialization error: %v", err)
+		}
+	}
+}
+
+func TestRetryOnSerializationError(t *testing.T) {
+	defer func(backoff time.Duration) { serializationRetryBackoff = backoff }(serializationRetryBackoff)
+	serializationRetryBackoff = time.Millisecond
+
+	persister := &PostgresPersister{}
+	deadlockErr := &pq.Error{Code: "40P01"}
+
+	// Recovers after serialization failures
+	calls := 0
+	err := persister.retryOnSerializationError(func() error {
+		calls++
+		if calls < 3 {
+			return deadlockErr
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Should not have gotten an error after retries: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Should have been called 3 times, called %v", calls)
+	}
+
+	// Retries a bounded number of times
+	calls = 0
+	err = persister.retryOnSerializationError(func() error {
+		calls++
+		return deadlockErr
+	})
+	if err != deadlockErr {
+		t.Errorf("Should have returned the deadlock error: %v", err)
+	}
+	if calls != maxSerializationRetries+1 {
+		t.Errorf("Should have been called %v times, called %v", maxSerializationRetries+1, calls)
+	}
+
+	// Does not retry other errors
+	calls = 0
+	err = persister.retryOnSerializationError(func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+	if err != driver.ErrBadConn {
+		t.Errorf("Should have returned the connection error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Should have been called once, called %v", calls)
+	}
+}
@@ -0,0 +1,166 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+// schemaTableModels maps each table base name to the postgres model that is
+// scanned from it
+var schemaTableModels = map[string]interface{}{
+	postgres.AppealTableBaseName:                      postgres.Appeal{},
+	postgres.ChallengeTableBaseName:                   postgres.Challenge{},
+	postgres.ContentRevisionTableBaseName:             postgres.ContentRevision{},
+	postgres.CronTableBaseName:                        postgres.CronData{},
+	postgres.GovernanceEventTableBaseName:             postgres.GovernanceEvent{},
+	postgres.GovernmentParameterTableBaseName:         postgres.GovernmentParameter{},
+	postgres.GovernmentParameterProposalTableBaseName: postgres.GovernmentParameterProposal{},
+	postgres.ListingTableBaseName:                     postgres.Listing{},
+	postgres.ListingStateHistoryTableBaseName:         postgres.ListingStateChange{},
+	postgres.MultiSigTableBaseName:                    postgres.MultiSig{},
+	postgres.MultiSigOwnerTableBaseName:               postgres.MultiSigOwner{},
+	postgres.ParameterTableBaseName:                   postgres.Parameter{},
+	postgres.ParameterHistoryTableBaseName:            postgres.ParameterChange{},
+	postgres.ParameterProposalTableBaseName:           postgres.ParameterProposal{},
+	postgres.PollTableBaseName:                        postgres.Poll{},
+	postgres.TokenTransferTableBaseName:               postgres.TokenTransfer{},
+	postgres.UserChallengeDataTableBaseName:           postgres.UserChallengeData{},
+}
+
+// schemaIgnoredColumns are table columns that are expected to have no field
+// in the model, such as serial primary keys
+var schemaIgnoredColumns = map[string][]string{
+	postgres.ContentRevisionTableBaseName:     {"id"},
+	postgres.ListingStateHistoryTableBaseName: {"id"},
+}
+
+// SchemaMismatch describes the differences between the columns of a table and
+// the db tags of its model
+type SchemaMismatch struct {
+	TableName string
+	// MissingColumns are model columns that do not exist in the table
+	MissingColumns []string
+	// ExtraColumns are table columns that do not exist in the model
+	ExtraColumns []string
+}
+
+// VerifySchema compares the columns of each table to the db tags of its model
+// and returns a SchemaMismatch for each table that differs. A table that does
+// not exist is reported with all of its columns missing.
+func (p *PostgresPersister) VerifySchema() ([]SchemaMismatch, error) {
+	baseNames := make([]string, 0, len(schemaTableModels))
+	for baseName := range schemaTableModels {
+		baseNames = append(baseNames, baseName)
+	}
+	sort.Strings(baseNames)
+
+	mismatches := []SchemaMismatch{}
+	for _, baseName := range baseNames {
+		mismatch, err := p.verifyTableSchema(
+			schemaTableModels[baseName],
+			p.GetTableName(baseName),
+			schemaIgnoredColumns[baseName],
+		)
+		if err != nil {
+			return nil, err
+		}
+		if mismatch != nil {
+			mismatches = append(mismatches, *mismatch)
+		}
+	}
+	return mismatches, nil
+}
+
+// verifyTableSchema returns the mismatch between the given table and model or
+// nil if the columns match
+func (p *PostgresPersister) verifyTableSchema(dbModel interface{}, tableName string,
+	ignoredColumns []string) (*SchemaMismatch, error) {
+	queryString := `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1;`
+	columns := []string{}
+	err := p.db.Select(&columns, queryString, tableName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving columns for %v", tableName)
+	}
+	missing, extra := compareColumns(modelColumns(dbModel), columns, ignoredColumns)
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil, nil
+	}
+	return &SchemaMismatch{
+		TableName:      tableName,
+		MissingColumns: missing,
+		ExtraColumns:   extra,
+	}, nil
+}
+
+// modelColumns returns the db tag names of the given model struct, including
+// those of embedded structs
+func modelColumns(dbModel interface{}) []string {
+	modelType := reflect.TypeOf(dbModel)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	columns := []string{}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, modelColumns(reflect.Zero(field.Type).Interface())...)
+			continue
+		}
+		if tag != "" {
+			columns = append(columns, tag)
+		}
+	}
+	return columns
+}
+
+// compareColumns returns the sorted model columns missing from the table
+// columns and the table columns missing from the model columns, excluding
+// the ignored columns
+func compareColumns(modelCols []string, tableCols []string, ignoredCols []string) ([]string, []string) {
+	inModel := map[string]bool{}
+	for _, col := range modelCols {
+		inModel[col] = true
+	}
+	inTable := map[string]bool{}
+	for _, col := range tableCols {
+		inTable[col] = true
+	}
+	for _, col := range ignoredCols {
+		inModel[col] = true
+	}
+
+	missing := []string{}
+	for col := range inModel {
+		if !inTable[col] && !stringInSlice(col, ignoredCols) {
+			missing = append(missing, col)
+		}
+	}
+	extra := []string{}
+	for col := range inTable {
+		if !inModel[col] {
+			extra = append(extra, col)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+func stringInSlice(str string, strs []string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
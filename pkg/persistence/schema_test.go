@@ -0,0 +1,62 @@
+package persistence
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+type testSchemaEmbedded struct {
+	Embedded string `db:"embedded"`
+}
+
+type testSchemaModel struct {
+	testSchemaEmbedded
+	Name     string `db:"name"`
+	Skipped  string `db:"-"`
+	Untagged string
+}
+
+func TestModelColumns(t *testing.T) {
+	columns := modelColumns(testSchemaModel{})
+	expected := []string{"embedded", "name"}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("Should have returned the model columns: %v != %v", columns, expected)
+	}
+	columns = modelColumns(&postgres.CronData{})
+	expected = []string{"data_persisted", "data_type"}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("Should have returned the model columns for a pointer: %v != %v", columns, expected)
+	}
+}
+
+func TestCompareColumns(t *testing.T) {
+	missing, extra := compareColumns(
+		[]string{"name", "url", "owner"},
+		[]string{"id", "owner", "name", "charter"},
+		[]string{"id"},
+	)
+	if !reflect.DeepEqual(missing, []string{"url"}) {
+		t.Errorf("Should have returned the missing columns: %v", missing)
+	}
+	if !reflect.DeepEqual(extra, []string{"charter"}) {
+		t.Errorf("Should have returned the extra columns: %v", extra)
+	}
+
+	missing, extra = compareColumns([]string{"name"}, []string{}, []string{"id"})
+	if !reflect.DeepEqual(missing, []string{"name"}) {
+		t.Errorf("Should have returned all columns missing for no table: %v", missing)
+	}
+	if len(extra) != 0 {
+		t.Errorf("Should have returned no extra columns: %v", extra)
+	}
+}
+
+func TestSchemaTableModelsColumns(t *testing.T) {
+	for baseName, dbModel := range schemaTableModels {
+		if len(modelColumns(dbModel)) == 0 {
+			t.Errorf("Should have db columns for the %v model", baseName)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"database/sql"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/jmoiron/sqlx"
+)
+
+// slowQueryLogf logs slow queries, set in tests to capture the output
+var slowQueryLogf = log.Warningf
+
+// timedDB wraps a sqlx.DB for the persister reads and writes. Any query that
+// takes longer than the slow query threshold is logged with the name of the
+// calling persister method and the elapsed time.
+type timedDB struct {
+	*sqlx.DB
+	// slowQueryThreshold is in nanosecs, 0 disables logging
+	slowQueryThreshold int64
+}
+
+func newTimedDB(db *sqlx.DB) *timedDB {
+	return &timedDB{DB: db}
+}
+
+func (db *timedDB) setSlowQueryThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&db.slowQueryThreshold, int64(threshold))
+}
+
+func (db *timedDB) logSlowQuery(start time.Time) {
+	threshold := time.Duration(atomic.LoadInt64(&db.slowQueryThreshold))
+	if threshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < threshold {
+		return
+	}
+	slowQueryLogf("Slow query: method: %v, elapsed: %v, threshold: %v", queryCallerName(), elapsed,
+		threshold)
+}
+
+// Select wraps sqlx.DB.Select
+func (db *timedDB) Select(dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := db.DB.Select(dest, query, args...)
+	db.logSlowQuery(start)
+	return err
+}
+
+// Get wraps sqlx.DB.Get
+func (db *timedDB) Get(dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := db.DB.Get(dest, query, args...)
+	db.logSlowQuery(start)
+	return err
+}
+
+// Exec wraps sqlx.DB.Exec
+func (db *timedDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.Exec(query, args...)
+	db.logSlowQuery(start)
+	return result, err
+}
+
+// NamedExec wraps sqlx.DB.NamedExec
+func (db *timedDB) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.NamedExec(query, arg)
+	db.logSlowQuery(start)
+	return result, err
+}
+
+// Queryx wraps sqlx.DB.Queryx. Only the time until the rows are returned
+// is measured.
+func (db *timedDB) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.Queryx(query, args...)
+	db.logSlowQuery(start)
+	return rows, err
+}
+
+// QueryRow wraps sqlx.DB.QueryRow. Only the time until the row is returned
+// is measured.
+func (db *timedDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRow(query, args...)
+	db.logSlowQuery(start)
+	return row
+}
+
+// PrepareNamed wraps sqlx.DB.PrepareNamed so queries on the returned
+// statement are also timed
+func (db *timedDB) PrepareNamed(query string) (*timedNamedStmt, error) {
+	nstmt, err := db.DB.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	return &timedNamedStmt{NamedStmt: nstmt, db: db}, nil
+}
+
+// timedNamedStmt wraps a sqlx.NamedStmt prepared by a timedDB
+type timedNamedStmt struct {
+	*sqlx.NamedStmt
+	db *timedDB
+}
+
+// Select wraps sqlx.NamedStmt.Select
+func (s *timedNamedStmt) Select(dest interface{}, arg interface{}) error {
+	start := time.Now()
+	err := s.NamedStmt.Select(dest, arg)
+	s.db.logSlowQuery(start)
+	return err
+}
+
+// Get wraps sqlx.NamedStmt.Get
+func (s *timedNamedStmt) Get(dest interface{}, arg interface{}) error {
+	start := time.Now()
+	err := s.NamedStmt.Get(dest, arg)
+	s.db.logSlowQuery(start)
+	return err
+}
+
+// Queryx wraps sqlx.NamedStmt.Queryx. Only the time until the rows are
+// returned is measured.
+func (s *timedNamedStmt) Queryx(arg interface{}) (*sqlx.Rows, error) {
+	start := time.Now()
+	rows, err := s.NamedStmt.Queryx(arg)
+	s.db.logSlowQuery(start)
+	return rows, err
+}
+
+// queryCallerName returns the name of the first function in the call stack
+// outside of the timed wrappers, which is the persister method that made
+// the query.
+func queryCallerName() string {
+	pcs := make([]uintptr, 10)
+	num := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:num])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "timedDB") &&
+			!strings.Contains(frame.Function, "timedNamedStmt") {
+			return frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestLogSlowQuery(t *testing.T) {
+	// Opening does not connect to the DB
+	db, err := sqlx.Open("postgres", "host=localhost sslmode=disable")
+	if err != nil {
+		t.Fatalf("Should not have gotten an error opening db: %v", err)
+	}
+	defer db.Close() // nolint: errcheck
+	persister, _ := NewPostgresPersisterFromSqlx(db)
+
+	logged := []string{}
+	defer func(logf func(string, ...interface{})) { slowQueryLogf = logf }(slowQueryLogf)
+	slowQueryLogf = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	// Disabled by default
+	persister.db.logSlowQuery(time.Now().Add(-time.Hour))
+	if len(logged) != 0 {
+		t.Errorf("Should not have logged a slow query when disabled: %v", logged)
+	}
+
+	persister.SetSlowQueryThreshold(time.Second)
+	persister.db.logSlowQuery(time.Now())
+	if len(logged) != 0 {
+		t.Errorf("Should not have logged a query under the threshold: %v", logged)
+	}
+
+	persister.db.logSlowQuery(time.Now().Add(-2 * time.Second))
+	if len(logged) != 1 {
+		t.Fatalf("Should have logged 1 slow query: %v", logged)
+	}
+	if !strings.Contains(logged[0], "method: persistence.TestLogSlowQuery") {
+		t.Errorf("Should have logged the calling method name: %v", logged[0])
+	}
+}
@@ -0,0 +1,62 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"regexp"
+
+	log "github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+// indexLeadingColumnRegex matches the first column of an index definition
+// as returned by pg_indexes, e.g. CREATE INDEX name ON table USING btree (column)
+var indexLeadingColumnRegex = regexp.MustCompile(`USING \w+ \(\s*"?(\w+)"?`)
+
+// sortIndexWarningf logs sort columns without an index, set in tests to
+// capture the output
+var sortIndexWarningf = log.Warningf
+
+// CheckListingSortIndices logs a warning for each listing sort column that is
+// not the leading column of an index on the listing table. Sorting on an
+// unindexed column results in a full table sort.
+func (p *PostgresPersister) CheckListingSortIndices() error {
+	tableName := p.GetTableName(postgres.ListingTableBaseName)
+	missing, err := p.unindexedListingSortColumns(tableName)
+	if err != nil {
+		return err
+	}
+	for _, column := range missing {
+		sortIndexWarningf("Listing sort column %v on %v has no index", column, tableName)
+	}
+	return nil
+}
+
+func (p *PostgresPersister) unindexedListingSortColumns(tableName string) ([]string, error) {
+	queryString := "SELECT indexdef FROM pg_indexes WHERE tablename = $1;"
+	indexDefs := []string{}
+	err := p.db.Select(&indexDefs, queryString, tableName)
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving listing indices")
+	}
+	return unindexedColumns(postgres.ListingSortColumnNames(), indexDefs), nil
+}
+
+// unindexedColumns returns the columns that are not the leading column of
+// any of the given index definitions
+func unindexedColumns(columns []string, indexDefs []string) []string {
+	indexed := map[string]bool{}
+	for _, indexDef := range indexDefs {
+		matches := indexLeadingColumnRegex.FindStringSubmatch(indexDef)
+		if len(matches) > 1 {
+			indexed[matches[1]] = true
+		}
+	}
+	missing := []string{}
+	for _, column := range columns {
+		if !indexed[column] {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnindexedColumns(t *testing.T) {
+	indexDefs := []string{
+		"CREATE UNIQUE INDEX listing_pkey ON public.listing USING btree (contract_address)",
+		"CREATE INDEX listing_name_sort_idx ON public.listing USING btree (name)",
+		"CREATE INDEX listing_owner_lower_idx ON public.listing USING btree (lower((owner)::text))",
+		"CREATE INDEX listing_app_idx ON public.listing USING btree (\"application_timestamp\", whitelisted)",
+		"CREATE INDEX listing_partial_idx ON public.listing USING btree (creation_timestamp) WHERE whitelisted",
+	}
+	columns := []string{"application_timestamp", "approval_timestamp", "creation_timestamp", "name", "whitelisted"}
+	missing := unindexedColumns(columns, indexDefs)
+	expected := []string{"approval_timestamp", "whitelisted"}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Should have returned the unindexed columns: %v != %v", missing, expected)
+	}
+	missing = unindexedColumns(columns, []string{})
+	if !reflect.DeepEqual(missing, columns) {
+		t.Errorf("Should have returned all columns with no indices: %v", missing)
+	}
+}
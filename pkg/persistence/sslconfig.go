@@ -0,0 +1,47 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	// DefaultSSLMode is the Postgres SSL mode used if none is given
+	DefaultSSLMode = "disable"
+)
+
+// SSLConfig configures the SSL mode and certs for the Postgres connection.
+// Cert paths are optional and only added to the connection string if set.
+type SSLConfig struct {
+	Mode     string
+	RootCert string
+	Cert     string
+	Key      string
+}
+
+// postgresConnInfo returns the connection string for the given params. If
+// ssl is nil or has no mode, uses DefaultSSLMode.
+func postgresConnInfo(host string, port int, user string, password string,
+	dbname string, ssl *SSLConfig) string {
+	connInfo := bytes.NewBufferString(
+		fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s", host, port, user, password, dbname),
+	)
+	sslMode := DefaultSSLMode
+	if ssl != nil && ssl.Mode != "" {
+		sslMode = ssl.Mode
+	}
+	connInfo.WriteString(fmt.Sprintf(" sslmode=%s", sslMode)) // nolint: gosec
+	if ssl == nil {
+		return connInfo.String()
+	}
+	if ssl.RootCert != "" {
+		connInfo.WriteString(fmt.Sprintf(" sslrootcert=%s", ssl.RootCert)) // nolint: gosec
+	}
+	if ssl.Cert != "" {
+		connInfo.WriteString(fmt.Sprintf(" sslcert=%s", ssl.Cert)) // nolint: gosec
+	}
+	if ssl.Key != "" {
+		connInfo.WriteString(fmt.Sprintf(" sslkey=%s", ssl.Key)) // nolint: gosec
+	}
+	return connInfo.String()
+}
@@ -0,0 +1,37 @@
+package persistence
+
+import (
+	"testing"
+)
+
+func TestPostgresConnInfo(t *testing.T) {
+	base := "host=localhost port=5432 user=user password=pw dbname=civil"
+	tests := []struct {
+		ssl      *SSLConfig
+		expected string
+	}{
+		{nil, base + " sslmode=disable"},
+		{&SSLConfig{}, base + " sslmode=disable"},
+		{&SSLConfig{Mode: "require"}, base + " sslmode=require"},
+		{
+			&SSLConfig{
+				Mode:     "verify-full",
+				RootCert: "/certs/root.crt",
+				Cert:     "/certs/client.crt",
+				Key:      "/certs/client.key",
+			},
+			base + " sslmode=verify-full sslrootcert=/certs/root.crt" +
+				" sslcert=/certs/client.crt sslkey=/certs/client.key",
+		},
+		{
+			&SSLConfig{Mode: "verify-ca", RootCert: "/certs/root.crt"},
+			base + " sslmode=verify-ca sslrootcert=/certs/root.crt",
+		},
+	}
+	for _, test := range tests {
+		connInfo := postgresConnInfo("localhost", 5432, "user", "pw", "civil", test.ssl)
+		if connInfo != test.expected {
+			t.Errorf("Should have gotten conn info %v, got %v", test.expected, connInfo)
+		}
+	}
+}
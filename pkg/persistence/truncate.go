@@ -0,0 +1,26 @@
+package persistence // import "github.com/joincivil/civil-events-processor/pkg/persistence"
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// validTableName matches the unquoted table names used in the queries
+var validTableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// TruncateTable deletes all the rows in the given table. Unlike dropping the
+// table, the schema and indices are kept, so the table can be reused without
+// being recreated. The table name includes any version suffix, see GetTableName.
+func (p *PostgresPersister) TruncateTable(tableName string) error {
+	if !validTableName.MatchString(tableName) {
+		return errors.Errorf("invalid table name: %v", tableName)
+	}
+	queryString := fmt.Sprintf("TRUNCATE TABLE %s;", tableName) // nolint: gosec
+	_, err := p.db.Exec(queryString)
+	if err != nil {
+		return errors.Wrapf(err, "error truncating table %v", tableName)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package persistence
+
+import (
+	"testing"
+)
+
+func TestTruncateTableInvalidName(t *testing.T) {
+	persister := &PostgresPersister{}
+	names := []string{"", "listing; DROP TABLE listing", "listing-1", "1listing", "public.listing"}
+	for _, name := range names {
+		err := persister.TruncateTable(name)
+		if err == nil {
+			t.Errorf("Should have gotten an error for table name %q", name)
+		}
+	}
+}
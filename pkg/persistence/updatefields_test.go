@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/persistence/postgres"
+)
+
+func TestUpdateDBQueryBufferUnknownField(t *testing.T) {
+	p := &PostgresPersister{}
+	_, err := p.updateDBQueryBuffer([]string{"Resolved", "NotAField"}, "challenge_test",
+		postgres.Challenge{})
+	if err == nil {
+		t.Fatalf("Should have gotten an error for an unknown field")
+	}
+	fieldErr, ok := errors.Cause(err).(*UnknownFieldError)
+	if !ok {
+		t.Fatalf("Error should be an UnknownFieldError: %T", err)
+	}
+	if fieldErr.Unwrap() != ErrUnknownField {
+		t.Errorf("Error should unwrap to ErrUnknownField")
+	}
+	if fieldErr.Field != "NotAField" {
+		t.Errorf("Error field should be NotAField but is %v", fieldErr.Field)
+	}
+	if fieldErr.Table != "challenge_test" {
+		t.Errorf("Error table should be challenge_test but is %v", fieldErr.Table)
+	}
+	if !strings.Contains(err.Error(), "Resolved") {
+		t.Errorf("Error string should list the valid fields: %v", err.Error())
+	}
+}
+
+func TestUpdateDBQueryBufferDbTagName(t *testing.T) {
+	p := &PostgresPersister{}
+	// Must use the model field name, not the db tag
+	_, err := p.updateDBQueryBuffer([]string{"resolved"}, "challenge_test", postgres.Challenge{})
+	if _, ok := errors.Cause(err).(*UnknownFieldError); !ok {
+		t.Errorf("Should have gotten an UnknownFieldError for a db tag name: %v", err)
+	}
+}
+
+func TestUpdateDBQueryBuffer(t *testing.T) {
+	p := &PostgresPersister{}
+	queryBuf, err := p.updateDBQueryBuffer([]string{"Resolved", "LastUpdatedDateTs"},
+		"challenge_test", postgres.Challenge{})
+	if err != nil {
+		t.Fatalf("Should not have gotten an error for valid fields: %v", err)
+	}
+	expected := "UPDATE challenge_test SET resolved=:resolved, last_updated_timestamp=:last_updated_timestamp"
+	if queryBuf.String() != expected {
+		t.Errorf("Should have gotten query %v, got %v", expected, queryBuf.String())
+	}
+}
@@ -0,0 +1,33 @@
+package persistence
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+func TestUserChallengeDataByCriteriaQueryOffsetCount(t *testing.T) {
+	p := &PostgresPersister{}
+	queryString, err := p.userChallengeDataByCriteriaQuery(&model.UserChallengeDataCriteria{
+		PollID: 10,
+		Offset: 20,
+		Count:  5,
+	}, "user_challenge_data_test")
+	if err != nil {
+		t.Fatalf("Should not have gotten an error building the query: %v", err)
+	}
+	if !strings.HasSuffix(queryString, " ORDER BY u.poll_id OFFSET :offset LIMIT :count") {
+		t.Errorf("Should have ordered before the offset and limit: %v", queryString)
+	}
+
+	queryString, err = p.userChallengeDataByCriteriaQuery(&model.UserChallengeDataCriteria{
+		PollID: 10,
+	}, "user_challenge_data_test")
+	if err != nil {
+		t.Fatalf("Should not have gotten an error building the query: %v", err)
+	}
+	if !strings.HasSuffix(queryString, " ORDER BY u.poll_id") {
+		t.Errorf("Should have ended with the ordering: %v", queryString)
+	}
+}
@@ -0,0 +1,24 @@
+package processor
+
+import (
+	"github.com/jmoiron/sqlx"
+
+	crawlerhelpers "github.com/joincivil/civil-events-crawler/pkg/helpers"
+	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
+	crawlerutils "github.com/joincivil/civil-events-crawler/pkg/utils"
+)
+
+// EventSource is the store of crawler events read by the processor. It is the
+// read only subset of the crawler EventDataPersister, so alternative event
+// stores, such as a replay from files, can be processed.
+type EventSource interface {
+	RetrieveEvents(criteria *crawlermodel.RetrieveEventsCriteria) ([]*crawlermodel.Event, error)
+}
+
+// NewPostgresEventSource returns an EventSource backed by the latest version of
+// the crawler event tables in the given Postgres db
+func NewPostgresEventSource(db *sqlx.DB) (EventSource, error) {
+	// Empty config here will set this to latest version of event tables
+	crawlerConfig := &crawlerutils.CrawlerConfig{}
+	return crawlerhelpers.EventPersisterFromSqlx(db, crawlerConfig)
+}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/joincivil/civil-events-processor/pkg/model"
 	cpersist "github.com/joincivil/go-common/pkg/persistence"
-	"github.com/joincivil/go-common/pkg/pubsub"
 )
 
 const (
@@ -27,7 +26,7 @@ const (
 
 // NewMultiSigEventProcessor is a convenience function to init an Event processor
 func NewMultiSigEventProcessor(client bind.ContractBackend,
-	multiSigPersister model.MultiSigPersister, multiSigOwnerPersister model.MultiSigOwnerPersister, googlePubSub *pubsub.GooglePubSub, pubSubMultiSigTopicName string) *MultiSigEventProcessor {
+	multiSigPersister model.MultiSigPersister, multiSigOwnerPersister model.MultiSigOwnerPersister, googlePubSub Publisher, pubSubMultiSigTopicName string) *MultiSigEventProcessor {
 	return &MultiSigEventProcessor{
 		client:                  client,
 		multiSigPersister:       multiSigPersister,
@@ -43,7 +42,7 @@ type MultiSigEventProcessor struct {
 	client                  bind.ContractBackend
 	multiSigPersister       model.MultiSigPersister
 	multiSigOwnerPersister  model.MultiSigOwnerPersister
-	googlePubSub            *pubsub.GooglePubSub
+	googlePubSub            Publisher
 	pubSubMultiSigTopicName string
 }
 
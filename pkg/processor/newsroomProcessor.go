@@ -35,12 +35,29 @@ const (
 )
 
 // NewNewsroomEventProcessor is a convenience function to init an EventProcessor
+//
+// If charterScraper, contentScraper or metadataScraper are nil, scrapers with
+// the default timeouts and retries are used.
 func NewNewsroomEventProcessor(client bind.ContractBackend, listingPersister model.ListingPersister,
-	revisionPersister model.ContentRevisionPersister, errRep cerrors.ErrorReporter) *NewsroomEventProcessor {
+	revisionPersister model.ContentRevisionPersister, charterScraper model.ContentScraper,
+	contentScraper model.ContentScraper, metadataScraper model.CivilMetadataScraper,
+	errRep cerrors.ErrorReporter) *NewsroomEventProcessor {
+	if charterScraper == nil {
+		charterScraper = &scraper.CharterIPFSScraper{}
+	}
+	if contentScraper == nil {
+		contentScraper = &scraper.ContentScraper{}
+	}
+	if metadataScraper == nil {
+		metadataScraper = &scraper.CivilMetadataScraper{}
+	}
 	return &NewsroomEventProcessor{
 		client:            client,
 		listingPersister:  listingPersister,
 		revisionPersister: revisionPersister,
+		charterScraper:    charterScraper,
+		contentScraper:    contentScraper,
+		metadataScraper:   metadataScraper,
 		errRep:            errRep,
 	}
 }
@@ -51,6 +68,9 @@ type NewsroomEventProcessor struct {
 	client            bind.ContractBackend
 	listingPersister  model.ListingPersister
 	revisionPersister model.ContentRevisionPersister
+	charterScraper    model.ContentScraper
+	contentScraper    model.ContentScraper
+	metadataScraper   model.CivilMetadataScraper
 	errRep            cerrors.ErrorReporter
 }
 
@@ -173,7 +193,8 @@ func (n *NewsroomEventProcessor) processNewsroomRevisionUpdated(event *crawlermo
 		event.Timestamp(),
 	)
 
-	err = n.revisionPersister.CreateContentRevision(revision)
+	// Upsert so replaying the event does not duplicate the revision
+	err = n.revisionPersister.UpsertContentRevision(revision)
 	if err != nil {
 		return err
 	}
@@ -380,20 +401,22 @@ func (n *NewsroomEventProcessor) scrapeData(contentID *big.Int, revisionURI stri
 	// Ignore self-tx links for now
 	// This is context embedded in the transaction input data
 
-	// Basic IPFS charter support
+	// Basic IPFS charter and content support
 	// Charter is content 0
-	if strings.Contains(revisionURI, "ipfs://") && contentID.Int64() == 0 {
-		charterScraper := &scraper.CharterIPFSScraper{}
-		charterContent, err := charterScraper.ScrapeContent(revisionURI)
+	if strings.Contains(revisionURI, "ipfs://") {
+		contentScraper := n.contentScraper
+		if contentID.Int64() == defaultCharterContentID {
+			contentScraper = n.charterScraper
+		}
+		content, err := contentScraper.ScrapeContent(revisionURI)
 		if err != nil {
 			return nil, nil, err
 		}
-		return nil, charterContent, nil
+		return nil, content, nil
 
 		// If it looks like a wordpress metadata URI
 	} else if strings.Contains(revisionURI, "/wp-json/") {
-		metadataScraper := &scraper.CivilMetadataScraper{}
-		civilMetadata, err := metadataScraper.ScrapeCivilMetadata(revisionURI)
+		civilMetadata, err := n.metadataScraper.ScrapeCivilMetadata(revisionURI)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -401,7 +424,7 @@ func (n *NewsroomEventProcessor) scrapeData(contentID *big.Int, revisionURI stri
 		// Remove this later after testing
 		if civilMetadata.Title() == "" && civilMetadata.RevisionContentHash() == "" {
 			revisionURI = strings.Replace(revisionURI, "/wp-json", "/crawler-pod/wp-json", -1)
-			civilMetadata, err = metadataScraper.ScrapeCivilMetadata(revisionURI)
+			civilMetadata, err = n.metadataScraper.ScrapeCivilMetadata(revisionURI)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -468,3 +491,63 @@ func (n *NewsroomEventProcessor) buildImages(metadata *model.ScraperCivilMetadat
 	}
 	return images
 }
+
+// ListingsWithOutdatedCharter returns the addresses of the listings whose stored
+// charter revision is behind the latest charter revision in the newsroom
+// contract, read with the given caller. These listings need their charter
+// updated, such as after a missed RevisionUpdated event. Listings whose newsroom
+// contract cannot be read are reported and skipped.
+func (n *NewsroomEventProcessor) ListingsWithOutdatedCharter(
+	client bind.ContractCaller) (addresses []common.Address, err error) {
+	iter, err := n.listingPersister.ListingsByCriteriaIter(&model.ListingCriteria{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error retrieving listings")
+	}
+	defer func() {
+		closeErr := iter.Close()
+		if err == nil && closeErr != nil {
+			err = errors.WithMessage(closeErr, "error closing listing iterator")
+		}
+	}()
+
+	addresses = []common.Address{}
+	for iter.Next() {
+		listing := iter.Listing()
+		newsroom, err := contract.NewNewsroomContractCaller(listing.ContractAddress(), client)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error creating newsroom contract caller")
+		}
+		revisionCount, err := newsroom.RevisionCount(
+			&bind.CallOpts{},
+			big.NewInt(defaultCharterContentID),
+		)
+		if err != nil {
+			log.Errorf("Error getting charter revision count for %v: err: %v",
+				listing.ContractAddress().Hex(), err)
+			n.errRep.Error(errors.Wrapf(err, "error getting charter revision count for %v",
+				listing.ContractAddress().Hex()), nil)
+			continue
+		}
+		if charterOutdated(listing.Charter(), revisionCount) {
+			addresses = append(addresses, listing.ContractAddress())
+		}
+	}
+	err = iter.Err()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error iterating listings")
+	}
+	return addresses, nil
+}
+
+// charterOutdated returns true if the charter is not the latest of the given
+// number of charter revisions in the contract
+func charterOutdated(charter *model.Charter, revisionCount *big.Int) bool {
+	if revisionCount == nil || revisionCount.Sign() <= 0 {
+		return false
+	}
+	if charter == nil || charter.RevisionID() == nil {
+		return true
+	}
+	latestRevisionID := new(big.Int).Sub(revisionCount, big.NewInt(1))
+	return charter.RevisionID().Cmp(latestRevisionID) < 0
+}
@@ -1,9 +1,16 @@
 package processor_test
 
 import (
+	"context"
 	"math/big"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
@@ -11,6 +18,7 @@ import (
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
 	"github.com/joincivil/go-common/pkg/generated/contract"
 
+	"github.com/joincivil/civil-events-processor/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/processor"
 	"github.com/joincivil/civil-events-processor/pkg/testutils"
 
@@ -160,6 +168,12 @@ func createAndProcOwnershipTransferredEvent(t *testing.T, contracts *contractuti
 }
 
 func setupApplicationAndNewsroomProcessor(t *testing.T) (*contractutils.AllTestContracts, *testutils.TestPersister,
+	*processor.NewsroomEventProcessor) {
+	return setupApplicationAndNewsroomProcessorWithScrapers(t, nil, nil, nil)
+}
+
+func setupApplicationAndNewsroomProcessorWithScrapers(t *testing.T, charterScraper model.ContentScraper,
+	contentScraper model.ContentScraper, metadataScraper model.CivilMetadataScraper) (*contractutils.AllTestContracts, *testutils.TestPersister,
 	*processor.NewsroomEventProcessor) {
 	contracts, err := contractutils.SetupAllTestContracts()
 	if err != nil {
@@ -180,10 +194,166 @@ func setupApplicationAndNewsroomProcessor(t *testing.T) (*contractutils.AllTestC
 		contracts.Client,
 		persister,
 		persister,
+		charterScraper,
+		contentScraper,
+		metadataScraper,
 		&cerrors.NullErrorReporter{})
 	return contracts, persister, newsroomProc
 }
 
+// timeoutScraper is a stub scraper that fails every scrape with a timeout
+type timeoutScraper struct {
+	scrapes int
+}
+
+func (s *timeoutScraper) ScrapeContent(uri string) (*model.ScraperContent, error) {
+	s.scrapes++
+	time.Sleep(10 * time.Millisecond)
+	return nil, errors.Errorf("timed out scraping %v", uri)
+}
+
+func (s *timeoutScraper) ScrapeCivilMetadata(uri string) (*model.ScraperCivilMetadata, error) {
+	s.scrapes++
+	time.Sleep(10 * time.Millisecond)
+	return nil, errors.Errorf("timed out scraping %v", uri)
+}
+
+func TestProcRevisionUpdatedEventScraperTimeout(t *testing.T) {
+	uris := []string{
+		"ipfs://zb34W52j4ctZtqo99ko7D64TWbsaF5DzFuw1A7gntSJfFfEwV",
+		"https://example.com/wp-json/civil-publisher/v1/revisions/11",
+	}
+	for _, uri := range uris {
+		scraper := &timeoutScraper{}
+		contracts, persister, nwsrmProc := setupApplicationAndNewsroomProcessorWithScrapers(t,
+			scraper, scraper, scraper)
+		listingAddress := contracts.NewsroomAddr.Hex()
+
+		revision := &contract.NewsroomContractRevisionUpdated{
+			Editor:     common.HexToAddress(editorAddress),
+			ContentId:  big.NewInt(0),
+			RevisionId: big.NewInt(0),
+			Uri:        uri,
+			Raw: types.Log{
+				Address:     contracts.NewsroomAddr,
+				Topics:      []common.Hash{},
+				Data:        []byte{},
+				BlockNumber: 888889,
+				TxHash:      common.Hash{},
+				TxIndex:     3,
+				BlockHash:   common.Hash{},
+				Index:       4,
+				Removed:     false,
+			},
+		}
+		event, _ := crawlermodel.NewEventFromContractEvent(
+			"RevisionUpdated",
+			"NewsroomContract",
+			contracts.NewsroomAddr,
+			revision,
+			ctime.CurrentEpochSecsInInt64(),
+			crawlermodel.Watcher,
+		)
+		_, err := nwsrmProc.Process(event)
+		if err != nil {
+			t.Errorf("Should not have failed processing event with scraper timeout: err: %v", err)
+		}
+
+		if scraper.scrapes == 0 {
+			t.Errorf("Should have attempted to scrape %v", uri)
+		}
+		// The revision and charter are still stored without the scraped data
+		revisions := persister.Revisions[listingAddress]
+		if len(revisions) != 1 {
+			t.Fatalf("Should have stored 1 revision for %v, have %v", uri, len(revisions))
+		}
+		if revisions[0].RevisionURI() != uri {
+			t.Errorf("Should have stored the revision uri %v, have %v", uri, revisions[0].RevisionURI())
+		}
+		if len(revisions[0].Payload()) != 0 {
+			t.Errorf("Should have stored an empty payload, have %v", revisions[0].Payload())
+		}
+		listing := persister.Listings[listingAddress]
+		if listing.Charter().URI() != uri {
+			t.Errorf("Should have updated the charter uri to %v, have %v", uri, listing.Charter().URI())
+		}
+		memoryCheck(contracts)
+	}
+}
+
+// stubContentScraper is a stub scraper that returns the scraped URI as the
+// content text
+type stubContentScraper struct {
+	uris []string
+}
+
+func (s *stubContentScraper) ScrapeContent(uri string) (*model.ScraperContent, error) {
+	s.uris = append(s.uris, uri)
+	return model.NewScraperContent(uri, "", uri, "", nil), nil
+}
+
+func TestProcRevisionUpdatedEventIPFSContent(t *testing.T) {
+	charterScraper := &stubContentScraper{}
+	contentScraper := &stubContentScraper{}
+	contracts, persister, nwsrmProc := setupApplicationAndNewsroomProcessorWithScrapers(t,
+		charterScraper, contentScraper, nil)
+	listingAddress := contracts.NewsroomAddr.Hex()
+
+	// Publish content 1 so the processor can retrieve it from the newsroom
+	uri := "ipfs://zb34W52j4ctZtqo99ko7D64TWbsaF5DzFuw1A7gntSJfFfEwV"
+	_, err := contracts.NewsroomContract.PublishContent(contracts.Auth, uri, [32]byte{},
+		common.Address{}, []byte{})
+	if err != nil {
+		t.Fatalf("Should not have failed publishing content: err: %v", err)
+	}
+	contracts.Client.Commit()
+
+	revision := &contract.NewsroomContractRevisionUpdated{
+		Editor:     common.HexToAddress(editorAddress),
+		ContentId:  big.NewInt(1),
+		RevisionId: big.NewInt(0),
+		Uri:        uri,
+		Raw: types.Log{
+			Address:     contracts.NewsroomAddr,
+			Topics:      []common.Hash{},
+			Data:        []byte{},
+			BlockNumber: 888889,
+			TxHash:      common.Hash{},
+			TxIndex:     3,
+			BlockHash:   common.Hash{},
+			Index:       4,
+			Removed:     false,
+		},
+	}
+	event, _ := crawlermodel.NewEventFromContractEvent(
+		"RevisionUpdated",
+		"NewsroomContract",
+		contracts.NewsroomAddr,
+		revision,
+		ctime.CurrentEpochSecsInInt64(),
+		crawlermodel.Watcher,
+	)
+	_, err = nwsrmProc.Process(event)
+	if err != nil {
+		t.Errorf("Should not have failed processing event: err: %v", err)
+	}
+
+	if len(charterScraper.uris) != 0 {
+		t.Errorf("Should not have scraped content with the charter scraper: %v", charterScraper.uris)
+	}
+	if len(contentScraper.uris) != 1 || contentScraper.uris[0] != uri {
+		t.Errorf("Should have scraped %v with the content scraper: %v", uri, contentScraper.uris)
+	}
+	revisions := persister.Revisions[listingAddress]
+	if len(revisions) != 1 {
+		t.Fatalf("Should have stored 1 revision, have %v", len(revisions))
+	}
+	if revisions[0].Payload()["contentText"] != uri {
+		t.Errorf("Should have stored the scraped content, have %v", revisions[0].Payload())
+	}
+	memoryCheck(contracts)
+}
+
 func TestNewsroomProcessor(t *testing.T) {
 	contracts, persister, nwsrmProc := setupApplicationAndNewsroomProcessor(t)
 	_ = createAndProcRevisionUpdatedEventCharter(t, contracts, nwsrmProc)
@@ -257,6 +427,31 @@ func TestCreateAndProcRevisionUpdatedEvent(t *testing.T) {
 	memoryCheck(contracts)
 }
 
+func TestReplayRevisionUpdatedEvent(t *testing.T) {
+	contracts, persister, nwsrmProc := setupApplicationAndNewsroomProcessor(t)
+	listingAddress := contracts.NewsroomAddr.Hex()
+
+	event := createAndProcRevisionUpdatedEventCharter(t, contracts, nwsrmProc)
+	// Replaying the same revision updates it rather than saving a duplicate
+	_, err := nwsrmProc.Process(event)
+	if err != nil {
+		t.Errorf("Should not have failed replaying the event: err: %v", err)
+	}
+	if len(persister.Revisions[listingAddress]) != 1 {
+		t.Errorf("Should have only 1 content revision, have %v",
+			len(persister.Revisions[listingAddress]))
+	}
+	exists, err := persister.ContentRevisionExists(contracts.NewsroomAddr, big.NewInt(0),
+		big.NewInt(0))
+	if err != nil {
+		t.Errorf("Should not have gotten an error checking the revision: err: %v", err)
+	}
+	if !exists {
+		t.Errorf("Should have found the charter revision")
+	}
+	memoryCheck(contracts)
+}
+
 func TestCreateAndProcOwnershipTransferredEvent(t *testing.T) {
 	contracts, persister, nwsrmProc := setupApplicationAndNewsroomProcessor(t)
 	listingAddress := contracts.NewsroomAddr.Hex()
@@ -333,3 +528,112 @@ func TestCreateAndProcOwnershipTransferredEvent(t *testing.T) {
 // 	}
 // 	memoryCheck(contracts)
 // }
+
+// stubNewsroomCaller is a bind.ContractCaller that returns the given charter
+// revision count for calls to a newsroom contract revisionCount function, or an
+// error for the failing addresses
+type stubNewsroomCaller struct {
+	revisionCounts   map[common.Address]*big.Int
+	failingAddresses map[common.Address]bool
+}
+
+func (s *stubNewsroomCaller) CodeAt(ctx context.Context, contract common.Address,
+	blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (s *stubNewsroomCaller) CallContract(ctx context.Context, call ethereum.CallMsg,
+	blockNumber *big.Int) ([]byte, error) {
+	if s.failingAddresses[*call.To] {
+		return nil, errors.New("call failed")
+	}
+	newsroomABI, err := abi.JSON(strings.NewReader(contract.NewsroomContractABI))
+	if err != nil {
+		return nil, err
+	}
+	count, ok := s.revisionCounts[*call.To]
+	if !ok {
+		count = big.NewInt(0)
+	}
+	return newsroomABI.Methods["revisionCount"].Outputs.Pack(count)
+}
+
+func TestListingsWithOutdatedCharter(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	upToDateAddress := common.HexToAddress(testAddress)
+	outdatedAddress := common.HexToAddress(editorAddress)
+	noCharterAddress := common.HexToAddress(testAddress2)
+	for _, address := range []common.Address{upToDateAddress, outdatedAddress} {
+		err := persister.CreateListing(model.NewListing(&model.NewListingParams{
+			ContractAddress: address,
+			Charter: model.NewCharter(&model.CharterParams{
+				ContentID:  big.NewInt(0),
+				RevisionID: big.NewInt(1),
+			}),
+		}))
+		if err != nil {
+			t.Fatalf("Should not have gotten an error creating the listing: %v", err)
+		}
+	}
+	err := persister.CreateListing(model.NewListing(&model.NewListingParams{
+		ContractAddress: noCharterAddress,
+	}))
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the listing: %v", err)
+	}
+
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		ListingPersister:  persister,
+		RevisionPersister: persister,
+		GovEventPersister: persister,
+	})
+	caller := &stubNewsroomCaller{
+		revisionCounts: map[common.Address]*big.Int{
+			upToDateAddress: big.NewInt(2),
+			// A revision was added that the listing charter missed
+			outdatedAddress:  big.NewInt(3),
+			noCharterAddress: big.NewInt(1),
+		},
+	}
+	addresses, err := proc.ListingsWithOutdatedCharter(caller)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error checking the charters: %v", err)
+	}
+	if len(addresses) != 2 {
+		t.Fatalf("Should have returned 2 outdated listings: %v", addresses)
+	}
+	outdated := map[common.Address]bool{}
+	for _, address := range addresses {
+		outdated[address] = true
+	}
+	if !outdated[outdatedAddress] {
+		t.Errorf("Should have returned the listing behind the revision count")
+	}
+	if !outdated[noCharterAddress] {
+		t.Errorf("Should have returned the listing with no charter")
+	}
+	if outdated[upToDateAddress] {
+		t.Errorf("Should not have returned the listing with the latest revision")
+	}
+
+	// Listings that fail the revision count call are skipped
+	caller.failingAddresses = map[common.Address]bool{outdatedAddress: true}
+	addresses, err = proc.ListingsWithOutdatedCharter(caller)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error when a revision count call fails: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0] != noCharterAddress {
+		t.Errorf("Should have only returned the listing with no charter: %v", addresses)
+	}
+
+	// Listings are not outdated if the contract has no charter revisions
+	caller.failingAddresses = nil
+	caller.revisionCounts = map[common.Address]*big.Int{}
+	addresses, err = proc.ListingsWithOutdatedCharter(caller)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error checking the charters: %v", err)
+	}
+	if len(addresses) != 0 {
+		t.Errorf("Should not have returned any outdated listings: %v", addresses)
+	}
+}
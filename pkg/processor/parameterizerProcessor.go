@@ -148,17 +148,12 @@ func (p *ParameterizerEventProcessor) processProposalAccepted(event *crawlermode
 	if err != nil {
 		return err
 	}
-	parameter, err := p.getExistingParameter(event)
+	err = p.updateParameterFromProposal(event, paramProposal)
 	if err != nil {
 		return err
 	}
-	parameter.SetValue(paramProposal.Value())
 	paramProposal.SetAccepted(true)
 	paramProposal.SetExpired(true)
-	err = p.parameterPersister.UpdateParameter(parameter, []string{valueFieldName})
-	if err != nil {
-		return err
-	}
 
 	return p.paramProposalPersister.UpdateParamProposal(paramProposal, []string{proposalAcceptedFieldName, proposalExpiredFieldName})
 }
@@ -201,12 +196,7 @@ func (p *ParameterizerEventProcessor) processChallengeFailed(event *crawlermodel
 	}
 	processBy := paramProposal.AppExpiry().Int64() + processByDuration
 	if event.Timestamp() < processBy {
-		parameter, err := p.getExistingParameter(event)
-		if err != nil {
-			return err
-		}
-		parameter.SetValue(paramProposal.Value())
-		err = p.parameterPersister.UpdateParameter(parameter, []string{valueFieldName})
+		err = p.updateParameterFromProposal(event, paramProposal)
 		if err != nil {
 			return err
 		}
@@ -368,6 +358,34 @@ func (p *ParameterizerEventProcessor) getExistingParameter(event *crawlermodel.E
 	return parameter, nil
 }
 
+// updateParameterFromProposal sets the parameter to the value of the accepted
+// proposal and records the change in the parameter history
+func (p *ParameterizerEventProcessor) updateParameterFromProposal(event *crawlermodel.Event,
+	paramProposal *model.ParameterProposal) error {
+	parameter, err := p.getExistingParameter(event)
+	if err != nil {
+		return err
+	}
+	oldValue := parameter.Value()
+	newValue := paramProposal.Value()
+	parameter.SetValue(newValue)
+	err = p.parameterPersister.UpdateParameter(parameter, []string{valueFieldName})
+	if err != nil {
+		return err
+	}
+	if oldValue != nil && oldValue.Cmp(newValue) == 0 {
+		return nil
+	}
+	change := model.NewParameterChange(
+		paramProposal.Name(),
+		oldValue,
+		newValue,
+		paramProposal.PropID(),
+		event.Timestamp(),
+	)
+	return p.parameterPersister.CreateParameterChange(change)
+}
+
 func (p *ParameterizerEventProcessor) newParameterizationFromProposal(event *crawlermodel.Event) error {
 	payload := event.EventPayload()
 	name, ok := payload["Name"]
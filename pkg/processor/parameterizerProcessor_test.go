@@ -394,3 +394,80 @@ func TestProcessChallengeSucceeded(t *testing.T) {
 
 	memoryCheck(contracts)
 }
+
+func procReparameterizationAndAccept(t *testing.T, contracts *contractutils.AllTestContracts,
+	paramProc *processor.ParameterizerEventProcessor, propID [32]byte, value *big.Int, ts int64) {
+	raw := types.Log{
+		Address:     common.HexToAddress(testAddress),
+		Topics:      []common.Hash{},
+		Data:        []byte{},
+		BlockNumber: 8888991,
+		TxHash:      common.Hash{},
+		TxIndex:     4,
+		BlockHash:   common.Hash{},
+		Index:       7,
+		Removed:     false,
+	}
+	proposal := &contract.ParameterizerContractReparameterizationProposal{
+		Name:       "commitStageLen",
+		Value:      value,
+		PropID:     propID,
+		Deposit:    big.NewInt(10000000000000000),
+		AppEndDate: big.NewInt(1547765493),
+		Proposer:   common.HexToAddress("0xcEC56F1D4Dc439E298D5f8B6ff3Aa6be58Cd6Fdf"),
+		Raw:        raw,
+	}
+	accepted := &contract.ParameterizerContractProposalAccepted{
+		Name:   "commitStageLen",
+		Value:  value,
+		PropID: propID,
+		Raw:    raw,
+	}
+	proposalEvent, _ := crawlermodel.NewEventFromContractEvent("ReparameterizationProposal",
+		"ParameterizerContract", contracts.ParamAddr, proposal, ts, crawlermodel.Filterer)
+	acceptedEvent, _ := crawlermodel.NewEventFromContractEvent("ProposalAccepted",
+		"ParameterizerContract", contracts.ParamAddr, accepted, ts, crawlermodel.Filterer)
+	for _, event := range []*crawlermodel.Event{proposalEvent, acceptedEvent} {
+		_, err := paramProc.Process(event)
+		if err != nil {
+			t.Errorf("Should not have failed processing events, err: %v", err)
+		}
+	}
+}
+
+func TestProcessProposalAcceptedParameterHistory(t *testing.T) {
+	contracts, persister, paramProc := setupParameterizerProcessor(t)
+	parameter := model.NewParameter("commitStageLen", big.NewInt(500))
+	err := persister.UpdateParameter(parameter, []string{"param_name", "value"})
+	if err != nil {
+		t.Fatalf("Failed updating parameter, err: %v", err)
+	}
+
+	procReparameterizationAndAccept(t, contracts, paramProc, [32]byte{0x00, 0x01},
+		big.NewInt(1800), 1000)
+	procReparameterizationAndAccept(t, contracts, paramProc, [32]byte{0x00, 0x02},
+		big.NewInt(2400), 2000)
+
+	history, err := persister.ParameterHistory("commitStageLen")
+	if err != nil {
+		t.Fatalf("Error getting parameter history, err: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Should have 2 parameter changes, have %v", len(history))
+	}
+	if history[0].OldValue().Int64() != 500 || history[0].NewValue().Int64() != 1800 {
+		t.Errorf("Wrong values for first change: %v -> %v", history[0].OldValue(),
+			history[0].NewValue())
+	}
+	if history[0].PropID() != [32]byte{0x00, 0x01} || history[0].Timestamp() != 1000 {
+		t.Errorf("Wrong prop ID or timestamp for first change")
+	}
+	if history[1].OldValue().Int64() != 1800 || history[1].NewValue().Int64() != 2400 {
+		t.Errorf("Wrong values for second change: %v -> %v", history[1].OldValue(),
+			history[1].NewValue())
+	}
+	if history[1].PropID() != [32]byte{0x00, 0x02} || history[1].Timestamp() != 2000 {
+		t.Errorf("Wrong prop ID or timestamp for second change")
+	}
+	memoryCheck(contracts)
+}
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	goerrors "errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -220,7 +221,7 @@ func (p *PlcrEventProcessor) processVoteCommitted(event *crawlermodel.Event,
 		updatedFields, updateWithUserAddress, latestVote)
 	// If no rows affected, that means there is no existing vote for a user for this poll,
 	// so continue to save. If there is an error, then return.
-	if err != nil && err != persistence.ErrNoRowsAffected {
+	if err != nil && !goerrors.Is(err, persistence.ErrNoRowsAffected) {
 		return err
 	}
 
@@ -1,19 +1,21 @@
 package processor
 
 import (
+	"strings"
+
 	"github.com/davecgh/go-spew/spew"
 	log "github.com/golang/glog"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/joincivil/civil-events-processor/pkg/model"
 
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
 
 	cerrors "github.com/joincivil/go-common/pkg/errors"
-	"github.com/joincivil/go-common/pkg/pubsub"
 )
 
 const (
@@ -35,16 +37,8 @@ func NewEventProcessor(params *NewEventProcessorParams) *EventProcessor {
 	if params.ErrRep == nil {
 		params.ErrRep = &cerrors.NullErrorReporter{}
 	}
-	tcrEventProcessor := NewTcrEventProcessor(
-		params.Client,
-		params.ListingPersister,
-		params.ChallengePersister,
-		params.AppealPersister,
-		params.GovEventPersister,
-		params.UserChallengeDataPersister,
-		params.PollPersister,
-		params.ErrRep,
-	)
+	tcrEventProcessor := newTcrEventProcessorFromParams(params, params.ListingPersister,
+		params.GovEventPersister)
 	plcrEventProcessor := NewPlcrEventProcessor(
 		params.Client,
 		params.PollPersister,
@@ -53,12 +47,7 @@ func NewEventProcessor(params *NewEventProcessorParams) *EventProcessor {
 		params.AppealPersister,
 		params.ErrRep,
 	)
-	newsroomEventProcessor := NewNewsroomEventProcessor(
-		params.Client,
-		params.ListingPersister,
-		params.RevisionPersister,
-		params.ErrRep,
-	)
+	newsroomEventProcessor := newNewsroomEventProcessorFromParams(params, params.ListingPersister)
 	cvlTokenProcessor := NewCvlTokenEventProcessor(
 		params.Client,
 		params.TokenTransferPersister,
@@ -87,6 +76,8 @@ func NewEventProcessor(params *NewEventProcessorParams) *EventProcessor {
 		params.ErrRep,
 	)
 	return &EventProcessor{
+		params:                  params,
+		eventSource:             params.EventSource,
 		tcrEventProcessor:       tcrEventProcessor,
 		plcrEventProcessor:      plcrEventProcessor,
 		newsroomEventProcessor:  newsroomEventProcessor,
@@ -98,10 +89,41 @@ func NewEventProcessor(params *NewEventProcessorParams) *EventProcessor {
 		pubSubEventsTopicName:   params.PubSubEventsTopicName,
 		pubSubTokenTopicName:    params.PubSubTokenTopicName,
 		pubSubMultiSigTopicName: params.PubSubMultiSigTopicName,
+		pubSubEventTopics:       params.PubSubEventTopics,
 		errRep:                  params.ErrRep,
 	}
 }
 
+func newTcrEventProcessorFromParams(params *NewEventProcessorParams,
+	listingPersister model.ListingPersister,
+	govEventPersister model.GovernanceEventPersister) *TcrEventProcessor {
+	tcrEventProcessor := NewTcrEventProcessor(
+		params.Client,
+		listingPersister,
+		params.ChallengePersister,
+		params.AppealPersister,
+		govEventPersister,
+		params.UserChallengeDataPersister,
+		params.PollPersister,
+		params.ErrRep,
+	)
+	tcrEventProcessor.contractAddresses = params.TCRContractAddresses
+	return tcrEventProcessor
+}
+
+func newNewsroomEventProcessorFromParams(params *NewEventProcessorParams,
+	listingPersister model.ListingPersister) *NewsroomEventProcessor {
+	return NewNewsroomEventProcessor(
+		params.Client,
+		listingPersister,
+		params.RevisionPersister,
+		params.CharterScraper,
+		params.ContentScraper,
+		params.CivilMetadataScraper,
+		params.ErrRep,
+	)
+}
+
 // NewEventProcessorParams defines the params needed to be passed to the processor
 type NewEventProcessorParams struct {
 	Client                               bind.ContractBackend
@@ -119,16 +141,25 @@ type NewEventProcessorParams struct {
 	MultiSigOwnerPersister               model.MultiSigOwnerPersister
 	GovernmentParameterProposalPersister model.GovernmentParamProposalPersister
 	GovernmentParameterPersister         model.GovernmentParameterPersister
-	GooglePubSub                         *pubsub.GooglePubSub
+	CharterScraper                       model.ContentScraper
+	ContentScraper                       model.ContentScraper
+	CivilMetadataScraper                 model.CivilMetadataScraper
+	GooglePubSub                         Publisher
 	PubSubEventsTopicName                string
 	PubSubTokenTopicName                 string
 	PubSubMultiSigTopicName              string
+	PubSubEventTopics                    map[string]string
+	TCRContractAddresses                 []common.Address
 	ErrRep                               cerrors.ErrorReporter
+	// EventSource retrieves crawler events for ProcessEventByHash, optional
+	EventSource EventSource
 }
 
 // EventProcessor handles the processing of raw events into aggregated data
 // for use via the API.
 type EventProcessor struct {
+	params                  *NewEventProcessorParams
+	eventSource             EventSource
 	tcrEventProcessor       *TcrEventProcessor
 	plcrEventProcessor      *PlcrEventProcessor
 	newsroomEventProcessor  *NewsroomEventProcessor
@@ -136,18 +167,28 @@ type EventProcessor struct {
 	parameterizerProcessor  *ParameterizerEventProcessor
 	multiSigProcessor       *MultiSigEventProcessor
 	governmentProcessor     *GovernmentEventProcessor
-	googlePubSub            *pubsub.GooglePubSub
+	googlePubSub            Publisher
 	pubSubEventsTopicName   string
 	pubSubTokenTopicName    string
 	pubSubMultiSigTopicName string
+	pubSubEventTopics       map[string]string
 	errRep                  cerrors.ErrorReporter
 }
 
-// Process runs the processor with the given set of raw CivilEvents
-func (e *EventProcessor) Process(events []*crawlermodel.Event) error {
+// Process runs the processor with the given set of raw CivilEvents and returns
+// a summary of the run
+func (e *EventProcessor) Process(events []*crawlermodel.Event) (*ProcessResult, error) {
 	var err error
 	var ran bool
 
+	result := &ProcessResult{}
+	// Count the listings and gov events persisted by this run with processors
+	// wrapping the persisters for the run, so concurrent runs keep their own counts
+	listingCounter := &countingListingPersister{ListingPersister: e.params.ListingPersister}
+	govEventCounter := &countingGovEventPersister{GovernanceEventPersister: e.params.GovEventPersister}
+	tcrEventProcessor := newTcrEventProcessorFromParams(e.params, listingCounter, govEventCounter)
+	newsroomEventProcessor := newNewsroomEventProcessorFromParams(e.params, listingCounter)
+
 	if !e.pubsubEnabled(e.pubSubEventsTopicName) {
 		log.Info("Gov events pubsub is disabled, set the project ID and topic in the config.")
 	}
@@ -166,23 +207,28 @@ func (e *EventProcessor) Process(events []*crawlermodel.Event) error {
 		if event == nil {
 			log.Errorf("Nil event found, should not be nil")
 			e.errRep.Error(errors.New("nil event found"), nil)
+			result.ErrorsSkipped++
 			continue
 		}
+		result.EventsProcessed++
 
-		ran, err = e.newsroomEventProcessor.Process(event)
+		ran, err = newsroomEventProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing newsroom event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
 			if !e.isAllowedErrProcess(err) {
 				e.errRep.Error(err, nil)
 			}
 		}
 		if ran {
+			e.publishRoutedEvent(event)
 			continue
 		}
 
-		ran, err = e.tcrEventProcessor.Process(event)
+		ran, err = tcrEventProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing civil tcr event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
 			if !e.isAllowedErrProcess(err) {
 				e.errRep.Error(err, nil)
 			}
@@ -199,17 +245,20 @@ func (e *EventProcessor) Process(events []*crawlermodel.Event) error {
 		ran, err = e.plcrEventProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing plcr event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
 			if !e.isAllowedErrProcess(err) {
 				e.errRep.Error(err, nil)
 			}
 		}
 		if ran {
+			e.publishRoutedEvent(event)
 			continue
 		}
 
 		ran, err = e.cvlTokenProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing token transfer event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
 			if !e.isAllowedErrProcess(err) {
 				e.errRep.Error(err, nil)
 			}
@@ -226,44 +275,131 @@ func (e *EventProcessor) Process(events []*crawlermodel.Event) error {
 		ran, err = e.parameterizerProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing parameterizer event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
 		}
 		if ran {
+			e.publishRoutedEvent(event)
 			continue
 		}
 
 		ran, err = e.multiSigProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing multi sig event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
 		}
 		if ran {
+			e.publishRoutedEvent(event)
 			continue
 		}
 
-		_, err = e.governmentProcessor.Process(event)
+		ran, err = e.governmentProcessor.Process(event)
 		if err != nil {
 			log.Errorf("Error processing government event: err: %v\n", err)
+			result.addSkippedError(strings.Trim(event.EventType(), " _"))
+		}
+		if ran {
+			e.publishRoutedEvent(event)
 		}
 	}
 	log.Info("Finished Processing")
-	return err
+	result.ListingsCreated = listingCounter.created
+	result.ListingsUpdated = listingCounter.updated
+	result.GovernanceEventsCreated = govEventCounter.created
+	return result, err
+}
+
+// ReprocessListing rebuilds the derived state of a listing from its persisted
+// governance events
+func (e *EventProcessor) ReprocessListing(listingAddress common.Address) error {
+	return e.tcrEventProcessor.ReprocessListing(listingAddress)
+}
+
+// ReconcileChallengeFromChain updates the stored challenge with the challenge
+// data read from the TCR contract at tcrAddress with the given caller
+func (e *EventProcessor) ReconcileChallengeFromChain(tcrAddress common.Address, challengeID int,
+	client bind.ContractCaller) error {
+	return e.tcrEventProcessor.ReconcileChallengeFromChain(tcrAddress, challengeID, client)
+}
+
+// ListingsWithOutdatedCharter returns the addresses of the listings whose
+// charter is behind the latest charter revision in the newsroom contract
+func (e *EventProcessor) ListingsWithOutdatedCharter(client bind.ContractCaller) ([]common.Address, error) {
+	return e.newsroomEventProcessor.ListingsWithOutdatedCharter(client)
+}
+
+// ProcessEventByHash retrieves the crawler event with the given hash and runs
+// it through processing. Used to reproduce the processing of a single event.
+// Requires the EventSource param.
+func (e *EventProcessor) ProcessEventByHash(hash string) error {
+	if e.eventSource == nil {
+		return errors.New("no event source to retrieve the event")
+	}
+	events, err := e.eventSource.RetrieveEvents(&crawlermodel.RetrieveEventsCriteria{
+		Hash: hash,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error retrieving event to process")
+	}
+	if len(events) == 0 {
+		return errors.Errorf("no event found with hash %v", hash)
+	}
+
+	result, err := e.Process(events[:1])
+	if err != nil {
+		return err
+	}
+	if result.ErrorsSkipped > 0 {
+		return errors.Errorf("event %v skipped due to a processing error", hash)
+	}
+	return nil
+}
+
+func (e *EventProcessor) publishRoutedEvent(event *crawlermodel.Event) {
+	err := e.sendEventToRoutedPubsub(event)
+	if err != nil {
+		log.Errorf("Error publishing to routed pubsub: err %v\n", err)
+		e.errRep.Error(err, nil)
+	}
+}
+
+// eventTopic returns the topic the event type is routed to, or defaultTopic
+// if there is no route for the event type
+func (e *EventProcessor) eventTopic(event *crawlermodel.Event, defaultTopic string) string {
+	eventName := strings.Trim(event.EventType(), " _")
+	if topicName, ok := e.pubSubEventTopics[eventName]; ok && topicName != "" {
+		return topicName
+	}
+	return defaultTopic
 }
 
 // Send to gov events pubsub
 func (e *EventProcessor) sendEventToEventsPubsub(event *crawlermodel.Event) error {
-	if !e.pubsubEnabled(e.pubSubEventsTopicName) {
+	topicName := e.eventTopic(event, e.pubSubEventsTopicName)
+	if !e.pubsubEnabled(topicName) {
 		return nil
 	}
 
-	return e.pubSub(event, e.pubSubEventsTopicName)
+	return e.pubSub(event, topicName)
 }
 
 // Send to cvltoken events pubsub
 func (e *EventProcessor) sendEventToTokenPubsub(event *crawlermodel.Event) error {
-	if !e.pubsubEnabled(e.pubSubTokenTopicName) {
+	topicName := e.eventTopic(event, e.pubSubTokenTopicName)
+	if !e.pubsubEnabled(topicName) {
+		return nil
+	}
+
+	return e.pubSub(event, topicName)
+}
+
+// Send to the topic the event type is routed to, if any
+func (e *EventProcessor) sendEventToRoutedPubsub(event *crawlermodel.Event) error {
+	topicName := e.eventTopic(event, "")
+	if !e.pubsubEnabled(topicName) {
 		return nil
 	}
 
-	return e.pubSub(event, e.pubSubTokenTopicName)
+	return e.pubSub(event, topicName)
 }
 
 // isAllowedErrProcess returns if an error should be ignored or not in the
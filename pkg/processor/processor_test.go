@@ -11,9 +11,13 @@ import (
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/testutils"
 
+	"github.com/joincivil/civil-events-processor/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/processor"
+	"github.com/joincivil/civil-events-processor/pkg/utils"
 
 	"github.com/joincivil/go-common/pkg/generated/contract"
+	cpersist "github.com/joincivil/go-common/pkg/persistence"
+	"github.com/joincivil/go-common/pkg/pubsub"
 	ctime "github.com/joincivil/go-common/pkg/time"
 )
 
@@ -210,10 +214,38 @@ func TestProcessor(t *testing.T) {
 	}
 	proc := processor.NewEventProcessor(processorParams)
 	events := setupEventList(t, contracts)
-	err = proc.Process(events)
+	result, err := proc.Process(events)
 	if err != nil {
 		t.Fatalf("Error processing events: %v", err)
 	}
+	if result.EventsProcessed != len(events) {
+		t.Errorf("Should have processed %v events but saw %v", len(events), result.EventsProcessed)
+	}
+	if result.ListingsCreated != 1 {
+		t.Errorf("Should have created 1 listing but saw %v", result.ListingsCreated)
+	}
+	if result.GovernanceEventsCreated != 2 {
+		t.Errorf("Should have created 2 govEvents but saw %v", result.GovernanceEventsCreated)
+	}
+	if result.ListingsUpdated != 2 {
+		t.Errorf("Should have updated 2 listings but saw %v", result.ListingsUpdated)
+	}
+	// AppealRequested fails to retrieve the challenge from the test contract
+	if result.ErrorsSkipped != 1 {
+		t.Errorf("Should have skipped 1 error but saw %v", result.ErrorsSkipped)
+	}
+	if len(result.ErrorsSkippedByType) != 1 || result.ErrorsSkippedByType["Challenge"] != 1 {
+		t.Errorf("Should have skipped 1 Challenge error but saw %v", result.ErrorsSkippedByType)
+	}
+	// Each run only counts what it persisted
+	result, err = proc.Process([]*crawlermodel.Event{})
+	if err != nil {
+		t.Fatalf("Error processing no events: %v", err)
+	}
+	if result.ListingsCreated != 0 || result.ListingsUpdated != 0 ||
+		result.GovernanceEventsCreated != 0 {
+		t.Errorf("Should not have counted the previous run: %v", result)
+	}
 	if len(persister.Listings) != 1 {
 		t.Errorf("Should have only seen 1 listing but saw %v", len(persister.Listings))
 	}
@@ -237,3 +269,397 @@ func TestProcessor(t *testing.T) {
 	}
 	memoryCheck(contracts)
 }
+
+type recordingPublisher struct {
+	msgs []*pubsub.GooglePubSubMsg
+}
+
+func (r *recordingPublisher) Publish(msg *pubsub.GooglePubSubMsg) error {
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func processWithPublisher(t *testing.T, contracts *contractutils.AllTestContracts,
+	publisher processor.Publisher) {
+	persister := &testutils.TestPersister{}
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		Client:                 contracts.Client,
+		ListingPersister:       persister,
+		RevisionPersister:      persister,
+		GovEventPersister:      persister,
+		ChallengePersister:     persister,
+		PollPersister:          persister,
+		AppealPersister:        persister,
+		TokenTransferPersister: persister,
+		MultiSigPersister:      persister,
+		MultiSigOwnerPersister: persister,
+		GooglePubSub:           publisher,
+		PubSubEventsTopicName:  "events",
+		PubSubTokenTopicName:   "token",
+	})
+	_, err := proc.Process(setupEventList(t, contracts))
+	if err != nil {
+		t.Fatalf("Error processing events: %v", err)
+	}
+}
+
+func TestProcessorPublishes(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	publisher := &recordingPublisher{}
+	processWithPublisher(t, contracts, publisher)
+
+	topics := map[string]int{}
+	for _, msg := range publisher.msgs {
+		topics[msg.Topic]++
+	}
+	if topics["events"] == 0 {
+		t.Errorf("Should have published to the events topic")
+	}
+	if topics["token"] != 1 {
+		t.Errorf("Should have published 1 message to the token topic but saw %v", topics["token"])
+	}
+	memoryCheck(contracts)
+}
+
+func TestProcessorNoopPublisher(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	processWithPublisher(t, contracts, &processor.NoopPublisher{})
+	// Processing with a nil publisher also skips publishing
+	processWithPublisher(t, contracts, nil)
+	memoryCheck(contracts)
+}
+
+func TestProcessorEventTopicRouting(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	publisher := &recordingPublisher{}
+	persister := &testutils.TestPersister{}
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		Client:                 contracts.Client,
+		ListingPersister:       persister,
+		RevisionPersister:      persister,
+		GovEventPersister:      persister,
+		ChallengePersister:     persister,
+		PollPersister:          persister,
+		AppealPersister:        persister,
+		TokenTransferPersister: persister,
+		MultiSigPersister:      persister,
+		MultiSigOwnerPersister: persister,
+		GooglePubSub:           publisher,
+		PubSubEventsTopicName:  "events",
+		PubSubEventTopics: map[string]string{
+			"Challenge":       "challenges",
+			"RevisionUpdated": "revisions",
+			"Transfer":        "transfers",
+		},
+	})
+	_, err = proc.Process(setupEventList(t, contracts))
+	if err != nil {
+		t.Fatalf("Error processing events: %v", err)
+	}
+
+	topics := map[string]int{}
+	for _, msg := range publisher.msgs {
+		topics[msg.Topic]++
+	}
+	if topics["challenges"] != 1 {
+		t.Errorf("Should have published 1 challenge event but saw %v", topics["challenges"])
+	}
+	if topics["revisions"] != 1 {
+		t.Errorf("Should have published 1 revision event but saw %v", topics["revisions"])
+	}
+	// Token topic is not set, so the routed topic is used
+	if topics["transfers"] != 1 {
+		t.Errorf("Should have published 1 transfer event but saw %v", topics["transfers"])
+	}
+	// Application and AppealRequested are not routed
+	if topics["events"] != 2 {
+		t.Errorf("Should have published 2 events to the default topic but saw %v", topics["events"])
+	}
+	// Unrouted non-TCR events are not published
+	if len(publisher.msgs) != 5 {
+		t.Errorf("Should have published 5 messages but saw %v", len(publisher.msgs))
+	}
+	memoryCheck(contracts)
+}
+
+func tcrApplicationEvent(t *testing.T, newsroomAddress common.Address,
+	tcrAddress common.Address, index uint) *crawlermodel.Event {
+	application := &contract.CivilTCRContractApplication{
+		ListingAddress: newsroomAddress,
+		Deposit:        big.NewInt(1000),
+		AppEndDate:     big.NewInt(1653860896),
+		Data:           "DATA",
+		Applicant:      common.HexToAddress(testAddress),
+		Raw: types.Log{
+			Address:     tcrAddress,
+			Topics:      []common.Hash{},
+			Data:        []byte{},
+			BlockNumber: 8888890,
+			TxHash:      common.Hash{},
+			TxIndex:     4,
+			BlockHash:   common.Hash{},
+			Index:       index,
+			Removed:     false},
+	}
+	event, err := crawlermodel.NewEventFromContractEvent(
+		"_Application",
+		"CivilTCRContract",
+		tcrAddress,
+		application,
+		ctime.CurrentEpochSecsInInt64(),
+		crawlermodel.Filterer,
+	)
+	if err != nil {
+		t.Fatalf("Error creating application event: %v", err)
+	}
+	return event
+}
+
+func TestProcessorTCRContractAddresses(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	otherTcrAddr := common.HexToAddress("0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A")
+	events := []*crawlermodel.Event{
+		tcrApplicationEvent(t, contracts.NewsroomAddr, contracts.CivilTcrAddr, 1),
+		tcrApplicationEvent(t, contracts.NewsroomAddr, otherTcrAddr, 2),
+	}
+
+	tests := []struct {
+		tcrAddresses      []common.Address
+		expectedContracts []common.Address
+	}{
+		// No configured addresses processes events from any TCR
+		{nil, []common.Address{contracts.CivilTcrAddr, otherTcrAddr}},
+		{
+			[]common.Address{contracts.CivilTcrAddr, otherTcrAddr},
+			[]common.Address{contracts.CivilTcrAddr, otherTcrAddr},
+		},
+		{[]common.Address{otherTcrAddr}, []common.Address{otherTcrAddr}},
+	}
+	for _, test := range tests {
+		persister := &testutils.TestPersister{}
+		proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+			Client:               contracts.Client,
+			ListingPersister:     persister,
+			RevisionPersister:    persister,
+			GovEventPersister:    persister,
+			ChallengePersister:   persister,
+			PollPersister:        persister,
+			AppealPersister:      persister,
+			TCRContractAddresses: test.tcrAddresses,
+		})
+		_, err = proc.Process(events)
+		if err != nil {
+			t.Fatalf("Error processing events: %v", err)
+		}
+
+		govEvents := persister.GovEvents[contracts.NewsroomAddr.Hex()]
+		if len(govEvents) != len(test.expectedContracts) {
+			t.Fatalf("Should have %v gov events, have %v", len(test.expectedContracts),
+				len(govEvents))
+		}
+		for i, govEvent := range govEvents {
+			if govEvent.ContractAddress() != test.expectedContracts[i] {
+				t.Errorf("Should have stored contract %v on gov event, have %v",
+					test.expectedContracts[i].Hex(), govEvent.ContractAddress().Hex())
+			}
+		}
+	}
+	memoryCheck(contracts)
+}
+
+func TestProcessorInMemoryPersister(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	persister := &testutils.TestPersister{}
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		Client:                               contracts.Client,
+		ListingPersister:                     persister,
+		RevisionPersister:                    persister,
+		GovEventPersister:                    persister,
+		ChallengePersister:                   persister,
+		PollPersister:                        persister,
+		AppealPersister:                      persister,
+		TokenTransferPersister:               persister,
+		ParameterProposalPersister:           persister,
+		ParameterPersister:                   persister,
+		UserChallengeDataPersister:           persister,
+		MultiSigPersister:                    persister,
+		MultiSigOwnerPersister:               persister,
+		GovernmentParameterProposalPersister: persister,
+		GovernmentParameterPersister:         persister,
+	})
+	_, err = proc.Process(setupEventList(t, contracts))
+	if err != nil {
+		t.Fatalf("Error processing events: %v", err)
+	}
+
+	// Read the results back through the persister interfaces
+	listing, err := persister.ListingByAddress(contracts.NewsroomAddr)
+	if err != nil {
+		t.Fatalf("Should have retrieved the listing: err: %v", err)
+	}
+	if listing.ContractAddress() != contracts.NewsroomAddr {
+		t.Errorf("Should have retrieved the newsroom listing, have %v",
+			listing.ContractAddress().Hex())
+	}
+	govEvents, err := persister.GovernanceEventsByListingAddress(contracts.NewsroomAddr)
+	if err != nil {
+		t.Fatalf("Should have retrieved the gov events: err: %v", err)
+	}
+	if len(govEvents) != 2 {
+		t.Errorf("Should have retrieved 2 gov events, have %v", len(govEvents))
+	}
+	transfers, err := persister.TokenTransfersByToAddress(common.HexToAddress(testAddress))
+	if err != nil {
+		t.Fatalf("Should have retrieved the token transfers: err: %v", err)
+	}
+	if len(transfers) != 1 {
+		t.Errorf("Should have retrieved 1 token transfer, have %v", len(transfers))
+	}
+	memoryCheck(contracts)
+}
+
+func TestInMemoryPersisterGovernmentParameters(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	_, err := persister.AllGovernmentParameters()
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results with no parameters: err: %v", err)
+	}
+
+	config := &utils.ProcessorConfig{
+		GovernmentParameterDefaultValues: map[string]string{
+			"govtPRevealStageLen": "1209600",
+			"govtPCommitStageLen": "1209600",
+			"appealFee":           "1000000000000000000000",
+		},
+	}
+	err = persister.CreateDefaultValues(config)
+	if err != nil {
+		t.Fatalf("Should have created the default values: err: %v", err)
+	}
+	params, err := persister.AllGovernmentParameters()
+	if err != nil {
+		t.Fatalf("Should have retrieved the parameters: err: %v", err)
+	}
+	if len(params) != 3 {
+		t.Fatalf("Should have retrieved 3 parameters, have %v", len(params))
+	}
+	if params[0].ParamName() != "appealFee" {
+		t.Errorf("Should have ordered the parameters by name, first is %v", params[0].ParamName())
+	}
+
+	param, err := persister.GovernmentParameterByName("govtPCommitStageLen")
+	if err != nil {
+		t.Fatalf("Should have retrieved the parameter: err: %v", err)
+	}
+	param.SetValue(big.NewInt(100))
+	err = persister.UpdateGovernmentParameter(param, []string{"Value"})
+	if err != nil {
+		t.Fatalf("Should have updated the parameter: err: %v", err)
+	}
+	param, _ = persister.GovernmentParameterByName("govtPCommitStageLen")
+	if param.Value().Int64() != 100 {
+		t.Errorf("Should have updated the parameter value, have %v", param.Value())
+	}
+	_, err = persister.GovernmentParameterByName("missing")
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should have gotten no results for a missing parameter: err: %v", err)
+	}
+}
+
+// fakeEventSource is an in-memory EventSource that filters on the event hash
+type fakeEventSource struct {
+	events []*crawlermodel.Event
+}
+
+var _ processor.EventSource = &fakeEventSource{}
+
+func (es *fakeEventSource) RetrieveEvents(criteria *crawlermodel.RetrieveEventsCriteria) ([]*crawlermodel.Event, error) {
+	events := []*crawlermodel.Event{}
+	for _, event := range es.events {
+		if criteria.Hash != "" && event.Hash() != criteria.Hash {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func TestProcessEventByHash(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	persister := &testutils.TestPersister{}
+	events := setupEventList(t, contracts)
+	eventSource := &fakeEventSource{events: events}
+
+	params := &processor.NewEventProcessorParams{
+		Client:                               contracts.Client,
+		ListingPersister:                     persister,
+		RevisionPersister:                    persister,
+		GovEventPersister:                    persister,
+		ChallengePersister:                   persister,
+		PollPersister:                        persister,
+		AppealPersister:                      persister,
+		TokenTransferPersister:               persister,
+		ParameterProposalPersister:           persister,
+		ParameterPersister:                   persister,
+		UserChallengeDataPersister:           persister,
+		MultiSigPersister:                    persister,
+		MultiSigOwnerPersister:               persister,
+		GovernmentParameterProposalPersister: persister,
+		GovernmentParameterPersister:         persister,
+	}
+	proc := processor.NewEventProcessor(params)
+	err = proc.ProcessEventByHash(events[0].Hash())
+	if err == nil {
+		t.Errorf("Should have gotten an error with no event source")
+	}
+
+	params.EventSource = eventSource
+	proc = processor.NewEventProcessor(params)
+	err = proc.ProcessEventByHash("0xnotanevent")
+	if err == nil {
+		t.Errorf("Should have gotten an error for an unknown event hash")
+	}
+
+	// Only the application event is processed
+	err = proc.ProcessEventByHash(events[0].Hash())
+	if err != nil {
+		t.Fatalf("Should not have gotten an error processing the event: %v", err)
+	}
+	listing, err := persister.ListingByAddress(contracts.NewsroomAddr)
+	if err != nil {
+		t.Fatalf("Should have created the listing from the application: err: %v", err)
+	}
+	if listing.LastGovernanceState() != model.GovernanceStateApplied {
+		t.Errorf("Listing should be applied, is %v", listing.LastGovernanceState())
+	}
+	govEvents, err := persister.GovernanceEventsByListingAddress(contracts.NewsroomAddr)
+	if err != nil {
+		t.Fatalf("Should have retrieved the gov events: err: %v", err)
+	}
+	if len(govEvents) != 1 {
+		t.Errorf("Should have processed only 1 gov event, have %v", len(govEvents))
+	}
+	_, err = persister.TokenTransfersByToAddress(common.HexToAddress(testAddress))
+	if err != cpersist.ErrPersisterNoResults {
+		t.Errorf("Should not have processed the token transfer event: err: %v", err)
+	}
+	memoryCheck(contracts)
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+)
+
+// ProcessResult is a summary of the work done in a single run of
+// EventProcessor.Process
+type ProcessResult struct {
+	EventsProcessed         int
+	ListingsCreated         int
+	ListingsUpdated         int
+	GovernanceEventsCreated int
+	ErrorsSkipped           int
+	// ErrorsSkippedByType is the number of events skipped due to processing
+	// errors keyed by event type
+	ErrorsSkippedByType map[string]int
+}
+
+// addSkippedError counts an event of the given type skipped due to a
+// processing error
+func (p *ProcessResult) addSkippedError(eventType string) {
+	p.ErrorsSkipped++
+	if p.ErrorsSkippedByType == nil {
+		p.ErrorsSkippedByType = map[string]int{}
+	}
+	p.ErrorsSkippedByType[eventType]++
+}
+
+// String returns a log friendly representation of the result
+func (p *ProcessResult) String() string {
+	return fmt.Sprintf(
+		"events: %v, listings created: %v, listings updated: %v, gov events created: %v, errors skipped: %v",
+		p.EventsProcessed,
+		p.ListingsCreated,
+		p.ListingsUpdated,
+		p.GovernanceEventsCreated,
+		p.ErrorsSkipped,
+	)
+}
+
+// countingListingPersister wraps a ListingPersister and counts the listings
+// successfully created and updated. A new one is used for each run.
+type countingListingPersister struct {
+	model.ListingPersister
+	created int
+	updated int
+}
+
+// CreateListing creates a new listing and increments the created count
+func (c *countingListingPersister) CreateListing(listing *model.Listing) error {
+	err := c.ListingPersister.CreateListing(listing)
+	if err == nil {
+		c.created++
+	}
+	return err
+}
+
+// UpdateListing updates fields on a listing and increments the updated count
+func (c *countingListingPersister) UpdateListing(listing *model.Listing, updatedFields []string) error {
+	err := c.ListingPersister.UpdateListing(listing, updatedFields)
+	if err == nil {
+		c.updated++
+	}
+	return err
+}
+
+// countingGovEventPersister wraps a GovernanceEventPersister and counts the
+// governance events successfully persisted. A new one is used for each run.
+type countingGovEventPersister struct {
+	model.GovernanceEventPersister
+	created int
+}
+
+// CreateGovernanceEvent creates a new governance event and increments the
+// created count
+func (c *countingGovEventPersister) CreateGovernanceEvent(govEvent *model.GovernanceEvent) error {
+	err := c.GovernanceEventPersister.CreateGovernanceEvent(govEvent)
+	if err == nil {
+		c.created++
+	}
+	return err
+}
+
+// UpsertGovernanceEvent upserts a governance event and increments the
+// created count
+func (c *countingGovEventPersister) UpsertGovernanceEvent(govEvent *model.GovernanceEvent) error {
+	err := c.GovernanceEventPersister.UpsertGovernanceEvent(govEvent)
+	if err == nil {
+		c.created++
+	}
+	return err
+}
@@ -9,6 +9,33 @@ import (
 	"github.com/joincivil/go-common/pkg/pubsub"
 )
 
+// Publisher publishes messages to pubsub. Implemented by pubsub.GooglePubSub.
+type Publisher interface {
+	Publish(msg *pubsub.GooglePubSubMsg) error
+}
+
+// NoopPublisher is a Publisher that drops all messages. Used to disable
+// publishing while still processing events.
+type NoopPublisher struct{}
+
+// Publish drops the message
+func (n *NoopPublisher) Publish(msg *pubsub.GooglePubSubMsg) error {
+	return nil
+}
+
+func publisherEnabled(publisher Publisher, topicName string) bool {
+	if publisher == nil {
+		return false
+	}
+	if _, ok := publisher.(*NoopPublisher); ok {
+		return false
+	}
+	if topicName == "" {
+		return false
+	}
+	return true
+}
+
 func (e *EventProcessor) pubSub(event *crawlermodel.Event, topicName string) error {
 	if !e.pubsubEnabled(topicName) {
 		return nil
@@ -84,21 +111,9 @@ func (e *MultiSigEventProcessor) pubSubMultiSigBuildPayload(action string, owner
 }
 
 func (e *EventProcessor) pubsubEnabled(topicName string) bool {
-	if e.googlePubSub == nil {
-		return false
-	}
-	if topicName == "" {
-		return false
-	}
-	return true
+	return publisherEnabled(e.googlePubSub, topicName)
 }
 
 func (e *MultiSigEventProcessor) pubsubEnabled(topicName string) bool {
-	if e.googlePubSub == nil {
-		return false
-	}
-	if topicName == "" {
-		return false
-	}
-	return true
+	return publisherEnabled(e.googlePubSub, topicName)
 }
@@ -3,6 +3,7 @@ package processor
 import (
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 
 	log "github.com/golang/glog"
@@ -58,6 +59,60 @@ const (
 	civilTCRContractName = "CivilTCRContract"
 )
 
+// tcrEventNameToGovState maps TCR event names to the last governance state
+// they set on a listing
+var tcrEventNameToGovState = map[string]model.GovernanceState{
+	"Application":                   model.GovernanceStateApplied,
+	"ApplicationWhitelisted":        model.GovernanceStateAppWhitelisted,
+	"ApplicationRemoved":            model.GovernanceStateAppRemoved,
+	"Deposit":                       model.GovernanceStateDeposit,
+	"Withdrawal":                    model.GovernanceStateWithdrawal,
+	"ListingRemoved":                model.GovernanceStateRemoved,
+	"Challenge":                     model.GovernanceStateChallenged,
+	"ChallengeFailed":               model.GovernanceStateChallengeFailed,
+	"ChallengeSucceeded":            model.GovernanceStateChallengeSucceeded,
+	"FailedChallengeOverturned":     model.GovernanceStateFailedChallengeOverturned,
+	"SuccessfulChallengeOverturned": model.GovernanceStateSuccessfulChallengeOverturned,
+	"AppealGranted":                 model.GovernanceStateAppealGranted,
+	"AppealRequested":               model.GovernanceStateAppealRequested,
+	"GrantedAppealChallenged":       model.GovernanceStateGrantedAppealChallenged,
+	"GrantedAppealConfirmed":        model.GovernanceStateGrantedAppealConfirmed,
+	"GrantedAppealOverturned":       model.GovernanceStateGrantedAppealOverturned,
+	"TouchAndRemoved":               model.GovernanceStateTouchRemoved,
+	"ListingWithdrawn":              model.GovernanceStateListingWithdrawn,
+}
+
+// tcrEventNameToWhitelisted maps TCR event names to the whitelisted state they
+// set on a listing, if they change it
+var tcrEventNameToWhitelisted = map[string]bool{
+	"Application":            false,
+	"ApplicationWhitelisted": true,
+	"ApplicationRemoved":     false,
+	"ListingRemoved":         false,
+}
+
+// setTCRListingState sets the whitelisted and last governance state the TCR
+// event leaves the listing in and returns the updated fields. Both the event
+// handlers and ReprocessListing apply the listing state changes with it.
+func setTCRListingState(listing *model.Listing, eventName string) []string {
+	updatedFields := []string{}
+	govState, ok := tcrEventNameToGovState[eventName]
+	if ok {
+		listing.SetLastGovernanceState(govState)
+		updatedFields = append(updatedFields, lastGovStateFieldName)
+	}
+	whitelisted, ok := tcrEventNameToWhitelisted[eventName]
+	if ok {
+		listing.SetWhitelisted(whitelisted)
+		updatedFields = append(updatedFields, whitelistedFieldName)
+	}
+	return updatedFields
+}
+
+func tcrEventName(event *crawlermodel.Event) string {
+	return strings.Trim(event.EventType(), " _")
+}
+
 // NewTcrEventProcessor is a convenience function to init an EventProcessor
 func NewTcrEventProcessor(client bind.ContractBackend, listingPersister model.ListingPersister,
 	challengePersister model.ChallengePersister, appealPersister model.AppealPersister,
@@ -87,6 +142,12 @@ type TcrEventProcessor struct {
 	userChallengeDataPersister model.UserChallengeDataPersister
 	pollPersister              model.PollPersister
 	errRep                     cerrors.ErrorReporter
+	// contractAddresses are the TCR contracts to process events from.
+	// If empty, events from any TCR contract are processed.
+	contractAddresses []common.Address
+	// stateChange is the listing state change made by the event being
+	// processed, recorded once its governance event is persisted
+	stateChange *model.ListingStateChange
 }
 
 func (t *TcrEventProcessor) isValidCivilTCRContractEventName(name string) bool {
@@ -95,6 +156,18 @@ func (t *TcrEventProcessor) isValidCivilTCRContractEventName(name string) bool {
 	return isStringInSlice(eventNames, name)
 }
 
+func (t *TcrEventProcessor) isHandledTCRContractAddress(address common.Address) bool {
+	if len(t.contractAddresses) == 0 {
+		return true
+	}
+	for _, contractAddress := range t.contractAddresses {
+		if contractAddress == address {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *TcrEventProcessor) listingAddressFromEvent(event *crawlermodel.Event) (common.Address, error) {
 	payload := event.EventPayload()
 	listingAddrInterface, ok := payload["ListingAddress"]
@@ -121,10 +194,15 @@ func (t *TcrEventProcessor) Process(event *crawlermodel.Event) (bool, error) {
 	if !t.isValidCivilTCRContractEventName(event.EventType()) {
 		return false, nil
 	}
+	if !t.isHandledTCRContractAddress(event.ContractAddress()) {
+		log.Infof("Skipping event from unhandled TCR contract %v", event.ContractAddress().Hex())
+		return false, nil
+	}
 
 	var err error
 	ran := true
-	eventName := strings.Trim(event.EventType(), " _")
+	eventName := tcrEventName(event)
+	t.stateChange = nil
 
 	// NOTE(IS): RewardClaimed is the only TCR event that doesn't emit a listingAddress
 	if eventName == "RewardClaimed" {
@@ -164,13 +242,11 @@ func (t *TcrEventProcessor) Process(event *crawlermodel.Event) (bool, error) {
 
 	case "Deposit":
 		log.Infof("Handling Deposit for %v\n", listingAddress.Hex())
-		err = t.processTCRDepositWithdrawal(event, model.GovernanceStateDeposit, listingAddress,
-			tcrAddress)
+		err = t.processTCRDepositWithdrawal(event, listingAddress, tcrAddress)
 
 	case "Withdrawal":
 		log.Infof("Handling Withdrawal for %v\n", listingAddress.Hex())
-		err = t.processTCRDepositWithdrawal(event, model.GovernanceStateWithdrawal, listingAddress,
-			tcrAddress)
+		err = t.processTCRDepositWithdrawal(event, listingAddress, tcrAddress)
 
 	case "ListingRemoved":
 		log.Infof("Handling ListingRemoved for %v\n", listingAddress.Hex())
@@ -218,13 +294,11 @@ func (t *TcrEventProcessor) Process(event *crawlermodel.Event) (bool, error) {
 
 	case "TouchAndRemoved":
 		log.Infof("Handling TouchAndRemoved for %v\n", listingAddress.Hex())
-		err = t.updateListingWithLastGovState(listingAddress, tcrAddress,
-			model.GovernanceStateTouchRemoved)
+		err = t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 
 	case "ListingWithdrawn":
 		log.Infof("Handling ListingWithdrawn for %v\n", listingAddress.Hex())
-		err = t.updateListingWithLastGovState(listingAddress, tcrAddress,
-			model.GovernanceStateListingWithdrawn)
+		err = t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 
 	default:
 		ran = false
@@ -238,8 +312,39 @@ func (t *TcrEventProcessor) Process(event *crawlermodel.Event) (bool, error) {
 	if govErr != nil {
 		return ran, errors.WithMessage(govErr, "error persisting govEvent")
 	}
-	return ran, err
 
+	if t.stateChange != nil {
+		err = t.listingPersister.CreateListingStateChange(t.stateChange)
+		if err != nil {
+			return ran, errors.WithMessage(err, "error recording listing state change")
+		}
+	}
+	return ran, nil
+
+}
+
+// setListingState sets the state the event leaves the listing in with
+// setTCRListingState and returns the updated fields. If the whitelisted or
+// governance state changes, the change is kept to record after the event is
+// processed.
+func (t *TcrEventProcessor) setListingState(event *crawlermodel.Event,
+	listing *model.Listing) []string {
+	oldWhitelisted := listing.Whitelisted()
+	oldState := listing.LastGovernanceState()
+	updatedFields := setTCRListingState(listing, tcrEventName(event))
+	if listing.Whitelisted() == oldWhitelisted && listing.LastGovernanceState() == oldState {
+		return updatedFields
+	}
+	t.stateChange = model.NewListingStateChange(&model.NewListingStateChangeParams{
+		ListingAddress: listing.ContractAddress(),
+		OldWhitelisted: oldWhitelisted,
+		NewWhitelisted: listing.Whitelisted(),
+		OldState:       oldState,
+		NewState:       listing.LastGovernanceState(),
+		EventHash:      event.Hash(),
+		Timestamp:      event.Timestamp(),
+	})
+	return updatedFields
 }
 
 func (t *TcrEventProcessor) persistGovernanceEvent(event *crawlermodel.Event, eventName string) error {
@@ -279,10 +384,127 @@ func (t *TcrEventProcessor) persistGovernanceEvent(event *crawlermodel.Event, ev
 		logPayload.BlockHash,
 		logPayload.Index,
 	)
-	err = t.govEventPersister.CreateGovernanceEvent(govEvent)
+	govEvent.SetContractAddress(event.ContractAddress())
+	err = t.govEventPersister.UpsertGovernanceEvent(govEvent)
 	return err
 }
 
+// ReprocessListing rebuilds the derived whitelisted and last governance state of
+// a listing by replaying its persisted governance events. Does not make any
+// calls to the chain.
+func (t *TcrEventProcessor) ReprocessListing(listingAddress common.Address) error {
+	listing, err := t.listingPersister.ListingByAddress(listingAddress)
+	if err != nil {
+		return errors.WithMessage(err, "error retrieving listing to reprocess")
+	}
+
+	govEvents, err := t.govEventPersister.GovernanceEventsByListingAddress(listingAddress)
+	if err != nil && err != cpersist.ErrPersisterNoResults {
+		return errors.WithMessage(err, "error retrieving governance events to reprocess")
+	}
+
+	// Replay the events in the order they were emitted
+	sort.SliceStable(govEvents, func(i, j int) bool {
+		blockDataI := govEvents[i].BlockData()
+		blockDataJ := govEvents[j].BlockData()
+		if blockDataI.BlockNumber() != blockDataJ.BlockNumber() {
+			return blockDataI.BlockNumber() < blockDataJ.BlockNumber()
+		}
+		return blockDataI.Index() < blockDataJ.Index()
+	})
+
+	// Replay from the state of a new listing with the same state changes as
+	// the event handlers
+	replayed := model.NewListing(&model.NewListingParams{
+		ContractAddress: listingAddress,
+		LastState:       model.GovernanceStateNone,
+	})
+	for _, govEvent := range govEvents {
+		setTCRListingState(replayed, strings.Trim(govEvent.GovernanceEventType(), " _"))
+	}
+	// Nothing to replay, leave the listing as is
+	if replayed.LastGovernanceState() == model.GovernanceStateNone {
+		return nil
+	}
+
+	listing.SetWhitelisted(replayed.Whitelisted())
+	listing.SetLastGovernanceState(replayed.LastGovernanceState())
+	updatedFields := []string{whitelistedFieldName, lastGovStateFieldName}
+	err = t.listingPersister.UpdateListing(listing, updatedFields)
+	if err != nil {
+		return errors.WithMessage(err, "error updating reprocessed listing")
+	}
+	return nil
+}
+
+// ReconcileChallengeFromChain reads the challenge from the TCR contract at
+// tcrAddress with the given caller and updates the reward pool, stake, resolved
+// and total tokens of the stored challenge if they differ, such as after a
+// missed event. Challenge IDs are per TCR, so the TCR address is required.
+// It is run when a challenge resolution disagrees with the resolved challenge.
+func (t *TcrEventProcessor) ReconcileChallengeFromChain(tcrAddress common.Address,
+	challengeID int, client bind.ContractCaller) error {
+	if tcrAddress == (common.Address{}) {
+		return errors.New("no TCR contract address to reconcile the challenge from")
+	}
+	existingChallenge, err := t.challengePersister.ChallengeByChallengeID(challengeID)
+	if err != nil {
+		return errors.WithMessagef(err, "error retrieving challenge %v to reconcile", challengeID)
+	}
+
+	tcrCaller, err := contract.NewCivilTCRContractCaller(tcrAddress, client)
+	if err != nil {
+		return errors.WithMessage(err, "error creating TCR contract caller")
+	}
+	challengeRes, err := tcrCaller.Challenges(&bind.CallOpts{}, big.NewInt(int64(challengeID)))
+	if err != nil {
+		return errors.WithMessage(err, "error getting challenge from contract")
+	}
+	if challengeRes.Challenger == (common.Address{}) {
+		return errors.Errorf("challenge %v not found in contract", challengeID)
+	}
+
+	updatedFields := []string{}
+	if !bigIntsEqual(existingChallenge.RewardPool(), challengeRes.RewardPool) {
+		existingChallenge.SetRewardPool(challengeRes.RewardPool)
+		updatedFields = append(updatedFields, rewardPoolFieldName)
+	}
+	if !bigIntsEqual(existingChallenge.Stake(), challengeRes.Stake) {
+		existingChallenge.SetStake(challengeRes.Stake)
+		updatedFields = append(updatedFields, stakeFieldName)
+	}
+	if existingChallenge.Resolved() != challengeRes.Resolved {
+		existingChallenge.SetResolved(challengeRes.Resolved)
+		updatedFields = append(updatedFields, resolvedFieldName)
+	}
+	if !bigIntsEqual(existingChallenge.TotalTokens(), challengeRes.TotalTokens) {
+		existingChallenge.SetTotalTokens(challengeRes.TotalTokens)
+		updatedFields = append(updatedFields, totalTokensFieldName)
+	}
+	if len(updatedFields) == 0 {
+		return nil
+	}
+
+	log.Infof("Reconciling challenge %v from chain, updating: %v", challengeID, updatedFields)
+	existingChallenge.SetLastUpdateDateTs(ctime.CurrentEpochSecsInInt64())
+	err = t.challengePersister.UpdateChallenge(existingChallenge, updatedFields)
+	if err != nil {
+		return errors.WithMessagef(err, "error updating reconciled challenge %v", challengeID)
+	}
+	return nil
+}
+
+// bigIntsEqual returns if the ints are equal, treating nil as 0
+func bigIntsEqual(a *big.Int, b *big.Int) bool {
+	if a == nil {
+		a = big.NewInt(0)
+	}
+	if b == nil {
+		b = big.NewInt(0)
+	}
+	return a.Cmp(b) == 0
+}
+
 func (t *TcrEventProcessor) processTCRApplication(event *crawlermodel.Event,
 	listingAddress common.Address) error {
 	return t.newListingFromApplication(event, listingAddress)
@@ -311,14 +533,14 @@ func (t *TcrEventProcessor) processTCRChallenge(event *crawlermodel.Event,
 	existingListing.SetChallengeID(challengeID)
 	unstakedDeposit := existingListing.UnstakedDeposit()
 	existingListing.SetUnstakedDeposit(unstakedDeposit.Sub(unstakedDeposit, minDeposit))
-	existingListing.SetLastGovernanceState(model.GovernanceStateChallenged)
-	updatedFields := []string{challengeIDFieldName, unstakedDepositFieldName, lastGovStateFieldName}
+	updatedFields := []string{challengeIDFieldName, unstakedDepositFieldName}
+	updatedFields = append(updatedFields, t.setListingState(event, existingListing)...)
 
 	return t.listingPersister.UpdateListing(existingListing, updatedFields)
 }
 
 func (t *TcrEventProcessor) processTCRDepositWithdrawal(event *crawlermodel.Event,
-	govState model.GovernanceState, listingAddress common.Address, tcrAddress common.Address) error {
+	listingAddress common.Address, tcrAddress common.Address) error {
 
 	existingListing, err := t.getExistingListing(tcrAddress, listingAddress)
 	if err != nil {
@@ -327,34 +549,26 @@ func (t *TcrEventProcessor) processTCRDepositWithdrawal(event *crawlermodel.Even
 
 	payload := event.EventPayload()
 
-	if govState == model.GovernanceStateWithdrawal {
-		existingListing.SetLastGovernanceState(model.GovernanceStateWithdrawal)
-	} else if govState == model.GovernanceStateDeposit {
-		existingListing.SetLastGovernanceState(model.GovernanceStateDeposit)
-	}
 	unstakedDeposit, ok := payload["NewTotal"]
 	if !ok {
 		return errors.New("No NewTotal field found")
 	}
 
 	existingListing.SetUnstakedDeposit(unstakedDeposit.(*big.Int))
-	updatedFields := []string{unstakedDepositFieldName, lastGovStateFieldName}
+	updatedFields := []string{unstakedDepositFieldName}
+	updatedFields = append(updatedFields, t.setListingState(event, existingListing)...)
 	return t.listingPersister.UpdateListing(existingListing, updatedFields)
 }
 
 func (t *TcrEventProcessor) processTCRApplicationWhitelisted(event *crawlermodel.Event,
 	listingAddress common.Address, tcrAddress common.Address) error {
 	// NOTE(IS): The Dapp changes challengeID to 0 here but we keep this as -1 because it hasn't been challenged yet
-	whitelisted := true
-
 	existingListing, err := t.getExistingListing(tcrAddress, listingAddress)
 	if err != nil {
 		return err
 	}
 
-	existingListing.SetWhitelisted(whitelisted)
-	existingListing.SetLastGovernanceState(model.GovernanceStateAppWhitelisted)
-	updatedFields := []string{whitelistedFieldName, lastGovStateFieldName}
+	updatedFields := t.setListingState(event, existingListing)
 
 	if existingListing.ApprovalDateTs() == approvalDateEmptyValue {
 		existingListing.SetApprovalDateTs(event.Timestamp())
@@ -366,12 +580,12 @@ func (t *TcrEventProcessor) processTCRApplicationWhitelisted(event *crawlermodel
 
 func (t *TcrEventProcessor) processTCRApplicationRemoved(event *crawlermodel.Event,
 	listingAddress common.Address, tcrAddress common.Address) error {
-	return t.resetListing(event, listingAddress, model.GovernanceStateAppRemoved, tcrAddress)
+	return t.resetListing(event, listingAddress, tcrAddress)
 }
 
 func (t *TcrEventProcessor) processTCRListingRemoved(event *crawlermodel.Event,
 	listingAddress common.Address, tcrAddress common.Address) error {
-	return t.resetListing(event, listingAddress, model.GovernanceStateRemoved, tcrAddress)
+	return t.resetListing(event, listingAddress, tcrAddress)
 }
 
 func (t *TcrEventProcessor) processTCRChallengeFailed(event *crawlermodel.Event,
@@ -403,11 +617,9 @@ func (t *TcrEventProcessor) processTCRChallengeFailed(event *crawlermodel.Event,
 		return err
 	}
 	existingListing.SetUnstakedDeposit(unstakedDeposit)
-	existingListing.SetLastGovernanceState(model.GovernanceStateChallengeFailed)
 	existingListing.SetChallengeID(big.NewInt(challengeIDResetValue))
-	updatedFields := []string{unstakedDepositFieldName,
-		lastGovStateFieldName,
-		challengeIDFieldName}
+	updatedFields := []string{unstakedDepositFieldName, challengeIDFieldName}
+	updatedFields = append(updatedFields, t.setListingState(event, existingListing)...)
 
 	err = t.listingPersister.UpdateListing(existingListing, updatedFields)
 	if err != nil {
@@ -435,8 +647,7 @@ func (t *TcrEventProcessor) processTCRChallengeSucceeded(event *crawlermodel.Eve
 		return err
 	}
 
-	err = t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateChallengeSucceeded)
+	err = t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	if err != nil {
 		return errors.WithMessage(err, "error updating listing")
 	}
@@ -544,6 +755,16 @@ func (t *TcrEventProcessor) processChallengeResolution(event *crawlermodel.Event
 		return errors.WithMessagef(err, "error getting existing challenge with id: %v",
 			existingChallenge.ChallengeID())
 	}
+	// NOTE: A challenge already resolved with other total tokens than the event
+	// has drifted, such as after a missed event, so take it from the contract.
+	if existingChallenge.Resolved() &&
+		!bigIntsEqual(existingChallenge.TotalTokens(), totalTokens.(*big.Int)) {
+		err = t.ReconcileChallengeFromChain(tcrAddress, int(challengeID.Int64()), t.client)
+		if err != nil {
+			return errors.WithMessage(err, "error reconciling resolved challenge")
+		}
+		return t.updateUserChallengeDataForChallengeRes(challengeID, tcrAddress, pollIsPassed, false)
+	}
 	existingChallenge.SetResolved(resolved)
 	existingChallenge.SetTotalTokens(totalTokens.(*big.Int))
 	updatedFields := []string{resolvedFieldName, totalTokensFieldName}
@@ -647,8 +868,7 @@ func (t *TcrEventProcessor) processTCRAppealRequested(event *crawlermodel.Event,
 	if err != nil {
 		return errors.WithMessage(err, "error processing AppealRequested")
 	}
-	err = t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateAppealRequested)
+	err = t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	return err
 }
 
@@ -690,16 +910,14 @@ func (t *TcrEventProcessor) processTCRAppealGranted(event *crawlermodel.Event,
 	if err != nil {
 		return err
 	}
-	err = t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateAppealGranted)
+	err = t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	return err
 }
 
 func (t *TcrEventProcessor) processTCRFailedChallengeOverturned(event *crawlermodel.Event,
 	listingAddress common.Address, tcrAddress common.Address) error {
 
-	err := t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateFailedChallengeOverturned)
+	err := t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	if err != nil {
 		return err
 	}
@@ -773,16 +991,15 @@ func (t *TcrEventProcessor) processTCRSuccessfulChallengeOverturned(event *crawl
 	existingListing.SetUnstakedDeposit(unstakedDeposit)
 
 	existingListing.SetChallengeID(big.NewInt(challengeIDResetValue))
-	existingListing.SetLastGovernanceState(model.GovernanceStateSuccessfulChallengeOverturned)
-	updatedFields := []string{unstakedDepositFieldName, lastGovStateFieldName, challengeIDFieldName}
+	updatedFields := []string{unstakedDepositFieldName, challengeIDFieldName}
+	updatedFields = append(updatedFields, t.setListingState(event, existingListing)...)
 	return t.listingPersister.UpdateListing(existingListing, updatedFields)
 
 }
 
 func (t *TcrEventProcessor) processTCRGrantedAppealChallenged(event *crawlermodel.Event,
 	listingAddress common.Address, tcrAddress common.Address) error {
-	err := t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateGrantedAppealChallenged)
+	err := t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	if err != nil {
 		return err
 	}
@@ -798,8 +1015,7 @@ func (t *TcrEventProcessor) processTCRGrantedAppealOverturned(event *crawlermode
 	}
 
 	// NOTE(IS): in sol files, Appeal: overturned = TRUE, we don't have an overturned field.
-	err := t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateGrantedAppealOverturned)
+	err := t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	if err != nil {
 		return err
 	}
@@ -832,8 +1048,7 @@ func (t *TcrEventProcessor) processTCRGrantedAppealConfirmed(event *crawlermodel
 		return errors.New("Error getting appealChallengeID from event payload")
 	}
 
-	err := t.updateListingWithLastGovState(listingAddress, tcrAddress,
-		model.GovernanceStateGrantedAppealConfirmed)
+	err := t.updateListingWithLastGovState(event, listingAddress, tcrAddress)
 	if err != nil {
 		return err
 	}
@@ -1036,7 +1251,7 @@ func (t *TcrEventProcessor) challengeFromContract(tcrAddress common.Address, cha
 }
 
 func (t *TcrEventProcessor) resetListing(event *crawlermodel.Event, listingAddress common.Address,
-	govState model.GovernanceState, tcrAddress common.Address) error {
+	tcrAddress common.Address) error {
 	// NOTE(IS): This corresponds to delete listings[listingAddress] in the dApp.
 	existingListing, err := t.getExistingListing(tcrAddress, listingAddress)
 	if err != nil {
@@ -1045,18 +1260,15 @@ func (t *TcrEventProcessor) resetListing(event *crawlermodel.Event, listingAddre
 	existingListing.SetUnstakedDeposit(big.NewInt(0))
 	existingListing.SetApprovalDateTs(approvalDateEmptyValue)
 	existingListing.SetAppExpiry(big.NewInt(0))
-	existingListing.SetWhitelisted(false)
 	existingListing.SetChallengeID(big.NewInt(0))
-	existingListing.SetLastGovernanceState(govState)
 	existingListing.ResetContributorAddresses()
 	updatedFields := []string{
 		unstakedDepositFieldName,
 		approvalDateFieldName,
 		appExpiryFieldName,
-		whitelistedFieldName,
 		challengeIDFieldName,
-		lastGovStateFieldName,
 		contributorAddressesFieldName}
+	updatedFields = append(updatedFields, t.setListingState(event, existingListing)...)
 	return t.listingPersister.UpdateListing(existingListing, updatedFields)
 }
 
@@ -1108,15 +1320,14 @@ func (t *TcrEventProcessor) getExistingAppeal(challengeID *big.Int,
 	return existingAppeal, nil
 }
 
-func (t *TcrEventProcessor) updateListingWithLastGovState(listingAddress common.Address,
-	tcrAddress common.Address, govState model.GovernanceState) error {
+func (t *TcrEventProcessor) updateListingWithLastGovState(event *crawlermodel.Event,
+	listingAddress common.Address, tcrAddress common.Address) error {
 	listing, err := t.getExistingListing(tcrAddress, listingAddress)
 	if err != nil {
 		return errors.WithMessage(err, "error getting existing listing %v")
 	}
 
-	listing.SetLastGovernanceState(govState)
-	updatedFields := []string{lastGovStateFieldName}
+	updatedFields := t.setListingState(event, listing)
 	err = t.listingPersister.UpdateListing(listing, updatedFields)
 	if err != nil {
 		return errors.WithMessage(err, "error updating listing")
@@ -1151,8 +1362,6 @@ func (t *TcrEventProcessor) newListingFromApplication(event *crawlermodel.Event,
 	listing := model.NewListing(&model.NewListingParams{
 		Name:              name,
 		ContractAddress:   listingAddress,
-		Whitelisted:       false,
-		LastState:         model.GovernanceStateApplied,
 		URL:               url,
 		Owner:             ownerAddr,
 		OwnerAddresses:    ownerAddresses,
@@ -1170,6 +1379,12 @@ func (t *TcrEventProcessor) newListingFromApplication(event *crawlermodel.Event,
 	if err != nil && err != cpersist.ErrPersisterNoResults {
 		return errors.WithMessage(err, "Error retrieving persisted listing")
 	}
+	if existingListing != nil {
+		// Start from the persisted state so the state change is made from it
+		listing.SetWhitelisted(existingListing.Whitelisted())
+		listing.SetLastGovernanceState(existingListing.LastGovernanceState())
+	}
+	t.setListingState(event, listing)
 	if existingListing != nil {
 		// NOTE(IS): Adding the following log for debugging for now, can delete later
 		log.Infof("Existing listing in persistence for this application event %v", listingAddress.Hex())
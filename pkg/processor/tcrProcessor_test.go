@@ -1,22 +1,30 @@
 package processor_test
 
 import (
-	// "fmt"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"math/big"
 	// "reflect"
 	"runtime"
+	"strings"
 	"testing"
 
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/joincivil/civil-events-crawler/pkg/contractutils"
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
 
-	// "github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/processor"
-	// "github.com/joincivil/civil-events-processor/pkg/testutils"
+	"github.com/joincivil/civil-events-processor/pkg/testutils"
 
+	cerrors "github.com/joincivil/go-common/pkg/errors"
 	"github.com/joincivil/go-common/pkg/generated/contract"
 	ctime "github.com/joincivil/go-common/pkg/time"
 )
@@ -40,6 +48,15 @@ func memoryCheck(contracts *contractutils.AllTestContracts) {
 
 func createAndProcAppEvent(t *testing.T, tcrProc *processor.TcrEventProcessor,
 	newsroomAddress common.Address, tcrAddress common.Address) *crawlermodel.Event {
+	event := createAppEvent(newsroomAddress, tcrAddress)
+	_, err := tcrProc.Process(event)
+	if err != nil {
+		t.Errorf("Should not have failed processing events: err: %v", err)
+	}
+	return event
+}
+
+func createAppEvent(newsroomAddress common.Address, tcrAddress common.Address) *crawlermodel.Event {
 	application := &contract.CivilTCRContractApplication{
 		ListingAddress: newsroomAddress,
 		Deposit:        big.NewInt(1000),
@@ -65,10 +82,6 @@ func createAndProcAppEvent(t *testing.T, tcrProc *processor.TcrEventProcessor,
 		ctime.CurrentEpochSecsInInt64(),
 		crawlermodel.Filterer,
 	)
-	_, err := tcrProc.Process(event)
-	if err != nil {
-		t.Errorf("Should not have failed processing events: err: %v", err)
-	}
 	return event
 }
 
@@ -1179,3 +1192,507 @@ func createAndProcAppEvent(t *testing.T, tcrProc *processor.TcrEventProcessor,
 // 	}
 // 	memoryCheck(contracts)
 // }
+
+func createTestGovEvent(t *testing.T, persister *testutils.TestPersister,
+	listingAddress common.Address, eventType string, blockNumber uint64) {
+	govEvent := model.NewGovernanceEvent(
+		listingAddress,
+		model.Metadata{},
+		eventType,
+		ctime.CurrentEpochSecsInInt64(),
+		ctime.CurrentEpochSecsInInt64(),
+		fmt.Sprintf("%v%v", eventType, blockNumber),
+		blockNumber,
+		common.Hash{},
+		0,
+		common.Hash{},
+		0,
+	)
+	err := persister.CreateGovernanceEvent(govEvent)
+	if err != nil {
+		t.Fatalf("Should not have failed creating gov event: err: %v", err)
+	}
+}
+
+func TestReprocessListing(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	tcrProc := processor.NewTcrEventProcessor(
+		nil,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		&cerrors.NullErrorReporter{})
+
+	listingAddress := common.HexToAddress(testAddress)
+	listing := model.NewListing(&model.NewListingParams{
+		Name:            "test listing",
+		ContractAddress: listingAddress,
+		Whitelisted:     false,
+		LastState:       model.GovernanceStateApplied,
+	})
+	err := persister.CreateListing(listing)
+	if err != nil {
+		t.Fatalf("Should not have failed creating listing: err: %v", err)
+	}
+
+	// Persist out of order to ensure they are replayed by block
+	createTestGovEvent(t, persister, listingAddress, "_ChallengeFailed", 4)
+	createTestGovEvent(t, persister, listingAddress, "_Application", 1)
+	createTestGovEvent(t, persister, listingAddress, "_Challenge", 3)
+	createTestGovEvent(t, persister, listingAddress, "_ApplicationWhitelisted", 2)
+
+	err = tcrProc.ReprocessListing(listingAddress)
+	if err != nil {
+		t.Fatalf("Should not have failed reprocessing listing: err: %v", err)
+	}
+	listing = persister.Listings[listingAddress.Hex()]
+	if !listing.Whitelisted() {
+		t.Errorf("Listing should have been whitelisted")
+	}
+	if listing.LastGovernanceState() != model.GovernanceStateChallengeFailed {
+		t.Errorf("Listing last governance state is not what it should be %v",
+			listing.LastGovernanceState())
+	}
+
+	createTestGovEvent(t, persister, listingAddress, "_ListingRemoved", 5)
+
+	err = tcrProc.ReprocessListing(listingAddress)
+	if err != nil {
+		t.Fatalf("Should not have failed reprocessing listing: err: %v", err)
+	}
+	listing = persister.Listings[listingAddress.Hex()]
+	if listing.Whitelisted() {
+		t.Errorf("Listing should not have been whitelisted")
+	}
+	if listing.LastGovernanceState() != model.GovernanceStateRemoved {
+		t.Errorf("Listing last governance state is not what it should be %v",
+			listing.LastGovernanceState())
+	}
+
+	err = tcrProc.ReprocessListing(common.HexToAddress(editorAddress))
+	if err == nil {
+		t.Errorf("Should have failed reprocessing a nonexistent listing")
+	}
+}
+
+func TestListingStateHistory(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	persister := &testutils.TestPersister{}
+	tcrProc := processor.NewTcrEventProcessor(
+		contracts.Client,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		&cerrors.NullErrorReporter{})
+
+	createAndProcAppEvent(t, tcrProc, contracts.NewsroomAddr, contracts.CivilTcrAddr)
+
+	whitelisted := &contract.CivilTCRContractApplicationWhitelisted{
+		ListingAddress: contracts.NewsroomAddr,
+		Raw: types.Log{
+			Address:     common.HexToAddress(testAddress),
+			Topics:      []common.Hash{},
+			Data:        []byte{},
+			BlockNumber: 8888895,
+			TxHash:      common.Hash{},
+			TxIndex:     8,
+			BlockHash:   common.Hash{},
+			Index:       7,
+			Removed:     false},
+	}
+	whitelistedEvent, _ := crawlermodel.NewEventFromContractEvent(
+		"_ApplicationWhitelisted",
+		"CivilTCRContract",
+		contracts.CivilTcrAddr,
+		whitelisted,
+		ctime.CurrentEpochSecsInInt64(),
+		crawlermodel.Watcher,
+	)
+	_, err = tcrProc.Process(whitelistedEvent)
+	if err != nil {
+		t.Fatalf("Should not have failed processing whitelisted: err: %v", err)
+	}
+
+	challenge := &contract.CivilTCRContractChallenge{
+		ListingAddress: contracts.NewsroomAddr,
+		ChallengeID:    challengeID1,
+		Data:           "DATA",
+		CommitEndDate:  big.NewInt(1653860896),
+		RevealEndDate:  big.NewInt(1653860896),
+		Challenger:     common.HexToAddress(testAddress),
+		Raw: types.Log{
+			Address:     common.HexToAddress(testAddress),
+			Topics:      []common.Hash{},
+			Data:        []byte{},
+			BlockNumber: 8888896,
+			TxHash:      common.Hash{},
+			TxIndex:     4,
+			BlockHash:   common.Hash{},
+			Index:       7,
+			Removed:     false},
+	}
+	challengeEvent, _ := crawlermodel.NewEventFromContractEvent(
+		"_Challenge",
+		"CivilTCRContract",
+		contracts.CivilTcrAddr,
+		challenge,
+		ctime.CurrentEpochSecsInInt64(),
+		crawlermodel.Watcher,
+	)
+	// The test contracts have no challenge data, so processing the challenge
+	// fails and no state change should be recorded
+	_, err = tcrProc.Process(challengeEvent)
+	if err == nil {
+		t.Errorf("Should have failed processing challenge without contract data")
+	}
+	// Processing an event again does not change the state
+	_, err = tcrProc.Process(whitelistedEvent)
+	if err != nil {
+		t.Fatalf("Should not have failed reprocessing whitelisted: err: %v", err)
+	}
+
+	history, err := persister.ListingStateHistory(contracts.NewsroomAddr)
+	if err != nil {
+		t.Fatalf("Should not have failed getting listing state history: err: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Should have 2 state changes, have %v", len(history))
+	}
+	expected := []struct {
+		oldWhitelisted bool
+		newWhitelisted bool
+		oldState       model.GovernanceState
+		newState       model.GovernanceState
+		eventHash      string
+	}{
+		{false, false, model.GovernanceStateNone, model.GovernanceStateApplied, ""},
+		{false, true, model.GovernanceStateApplied, model.GovernanceStateAppWhitelisted,
+			whitelistedEvent.Hash()},
+	}
+	for i, exp := range expected {
+		change := history[i]
+		if change.OldWhitelisted() != exp.oldWhitelisted ||
+			change.NewWhitelisted() != exp.newWhitelisted {
+			t.Errorf("Wrong whitelisted change at %v: %v -> %v", i, change.OldWhitelisted(),
+				change.NewWhitelisted())
+		}
+		if change.OldState() != exp.oldState || change.NewState() != exp.newState {
+			t.Errorf("Wrong state change at %v: %v -> %v", i, change.OldState(),
+				change.NewState())
+		}
+		if exp.eventHash != "" && change.EventHash() != exp.eventHash {
+			t.Errorf("Wrong event hash at %v: %v", i, change.EventHash())
+		}
+	}
+	memoryCheck(contracts)
+}
+
+// failingStateHistoryPersister is a TestPersister that fails to record listing
+// state changes
+type failingStateHistoryPersister struct {
+	*testutils.TestPersister
+}
+
+func (f *failingStateHistoryPersister) CreateListingStateChange(
+	change *model.ListingStateChange) error {
+	return errors.New("state history write failed")
+}
+
+func TestListingStateHistoryFailureKeepsGovEvent(t *testing.T) {
+	contracts, err := contractutils.SetupAllTestContracts()
+	if err != nil {
+		t.Fatalf("Unable to setup the contracts: %v", err)
+	}
+	testPersister := &testutils.TestPersister{}
+	persister := &failingStateHistoryPersister{TestPersister: testPersister}
+	tcrProc := processor.NewTcrEventProcessor(
+		contracts.Client,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		&cerrors.NullErrorReporter{})
+
+	_, err = tcrProc.Process(createAppEvent(contracts.NewsroomAddr, contracts.CivilTcrAddr))
+	if err == nil {
+		t.Errorf("Should have returned the state history error")
+	}
+	if len(testPersister.GovEvents[contracts.NewsroomAddr.Hex()]) != 1 {
+		t.Errorf("Should have persisted the governance event before the state change: %v",
+			testPersister.GovEvents[contracts.NewsroomAddr.Hex()])
+	}
+	listing, err := testPersister.ListingByAddress(contracts.NewsroomAddr)
+	if err != nil {
+		t.Fatalf("Should have persisted the listing: err: %v", err)
+	}
+	if listing.LastGovernanceState() != model.GovernanceStateApplied {
+		t.Errorf("Should have updated the listing state: %v", listing.LastGovernanceState())
+	}
+	memoryCheck(contracts)
+}
+
+// stubTCRCaller is a bind.ContractCaller that returns the given challenge for
+// calls to the TCR contract challenges function
+type stubTCRCaller struct {
+	rewardPool  *big.Int
+	challenger  common.Address
+	resolved    bool
+	stake       *big.Int
+	totalTokens *big.Int
+	calls       int
+}
+
+func (s *stubTCRCaller) CodeAt(ctx context.Context, contract common.Address,
+	blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (s *stubTCRCaller) CallContract(ctx context.Context, call ethereum.CallMsg,
+	blockNumber *big.Int) ([]byte, error) {
+	s.calls++
+	tcrABI, err := abi.JSON(strings.NewReader(contract.CivilTCRContractABI))
+	if err != nil {
+		return nil, err
+	}
+	return tcrABI.Methods["challenges"].Outputs.Pack(s.rewardPool, s.challenger, s.resolved,
+		s.stake, s.totalTokens)
+}
+
+func TestReconcileChallengeFromChain(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	tcrAddress := common.HexToAddress(testAddress)
+	challenger := common.HexToAddress(editorAddress)
+	challenge := model.NewChallenge(challengeID1, common.HexToAddress(testAddress), "statement",
+		big.NewInt(100), challenger, false, big.NewInt(50), big.NewInt(1000), big.NewInt(0),
+		model.ChallengePollType, 0)
+	err := persister.CreateChallenge(challenge)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the challenge: %v", err)
+	}
+
+	proc := processor.NewEventProcessor(&processor.NewEventProcessorParams{
+		ListingPersister:           persister,
+		GovEventPersister:          persister,
+		ChallengePersister:         persister,
+		PollPersister:              persister,
+		AppealPersister:            persister,
+		UserChallengeDataPersister: persister,
+		TCRContractAddresses:       []common.Address{tcrAddress},
+	})
+
+	// The total tokens drifted from a missed vote
+	caller := &stubTCRCaller{
+		rewardPool:  big.NewInt(100),
+		challenger:  challenger,
+		resolved:    false,
+		stake:       big.NewInt(50),
+		totalTokens: big.NewInt(1500),
+	}
+	err = proc.ReconcileChallengeFromChain(tcrAddress, int(challengeID1.Int64()), caller)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error reconciling the challenge: %v", err)
+	}
+	if caller.calls != 1 {
+		t.Errorf("Should have called the contract once: %v", caller.calls)
+	}
+	reconciled, err := persister.ChallengeByChallengeID(int(challengeID1.Int64()))
+	if err != nil {
+		t.Fatalf("Should have retrieved the challenge: %v", err)
+	}
+	if reconciled.TotalTokens().Int64() != 1500 {
+		t.Errorf("Should have updated the total tokens: %v", reconciled.TotalTokens())
+	}
+	if reconciled.RewardPool().Int64() != 100 || reconciled.Stake().Int64() != 50 {
+		t.Errorf("Should not have changed the reward pool or stake: %v, %v",
+			reconciled.RewardPool(), reconciled.Stake())
+	}
+	if reconciled.LastUpdatedDateTs() == 0 {
+		t.Errorf("Should have bumped the last updated timestamp")
+	}
+
+	// The TCR address is required
+	err = proc.ReconcileChallengeFromChain(common.Address{}, int(challengeID1.Int64()), caller)
+	if err == nil {
+		t.Errorf("Should have gotten an error without a TCR address")
+	}
+
+	// Unknown challenges are not found in the persister
+	err = proc.ReconcileChallengeFromChain(tcrAddress, 999, caller)
+	if err == nil {
+		t.Errorf("Should have gotten an error for a challenge not in the persister")
+	}
+
+	// Challenges with no challenger on chain do not exist in the contract
+	caller.challenger = common.Address{}
+	err = proc.ReconcileChallengeFromChain(tcrAddress, int(challengeID1.Int64()), caller)
+	if err == nil {
+		t.Errorf("Should have gotten an error for a challenge not in the contract")
+	}
+}
+
+// stubTCRBackend is a bind.ContractBackend that answers the TCR voting and
+// challenges calls and the PLCR isPassed calls made when resolving a challenge
+type stubTCRBackend struct {
+	bind.ContractBackend
+	challenge stubTCRCaller
+	isPassed  bool
+}
+
+func (s *stubTCRBackend) CodeAt(ctx context.Context, contract common.Address,
+	blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (s *stubTCRBackend) CallContract(ctx context.Context, call ethereum.CallMsg,
+	blockNumber *big.Int) ([]byte, error) {
+	tcrABI, err := abi.JSON(strings.NewReader(contract.CivilTCRContractABI))
+	if err != nil {
+		return nil, err
+	}
+	plcrABI, err := abi.JSON(strings.NewReader(contract.CivilPLCRVotingContractABI))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.Equal(call.Data[:4], tcrABI.Methods["voting"].ID()):
+		return tcrABI.Methods["voting"].Outputs.Pack(*call.To)
+	case bytes.Equal(call.Data[:4], plcrABI.Methods["isPassed"].ID()):
+		return plcrABI.Methods["isPassed"].Outputs.Pack(s.isPassed)
+	}
+	return s.challenge.CallContract(ctx, call, blockNumber)
+}
+
+func createChallenge1SucceededEvent(newsroomAddress common.Address, tcrAddress common.Address,
+	totalTokens *big.Int) *crawlermodel.Event {
+	challenge1Succeeded := &contract.CivilTCRContractChallengeSucceeded{
+		ListingAddress: newsroomAddress,
+		ChallengeID:    challengeID1,
+		RewardPool:     big.NewInt(100),
+		TotalTokens:    totalTokens,
+		Raw: types.Log{
+			Address:     common.HexToAddress(testAddress),
+			Topics:      []common.Hash{},
+			Data:        []byte{},
+			BlockNumber: 8888900,
+			TxHash:      common.Hash{},
+			TxIndex:     4,
+			BlockHash:   common.Hash{},
+			Index:       7,
+			Removed:     false,
+		},
+	}
+	event, _ := crawlermodel.NewEventFromContractEvent(
+		"_ChallengeSucceeded",
+		"CivilTCRContract",
+		tcrAddress,
+		challenge1Succeeded,
+		ctime.CurrentEpochSecsInInt64(),
+		crawlermodel.Filterer,
+	)
+	return event
+}
+
+func TestChallengeResolutionReconcilesFromChain(t *testing.T) {
+	persister := &testutils.TestPersister{}
+	tcrAddress := common.HexToAddress(testAddress)
+	newsroomAddress := common.HexToAddress(editorAddress)
+	challenger := common.HexToAddress(editorAddress)
+	err := persister.CreateListing(model.NewListing(&model.NewListingParams{
+		ContractAddress: newsroomAddress,
+		LastState:       model.GovernanceStateChallenged,
+		ChallengeID:     challengeID1,
+	}))
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the listing: %v", err)
+	}
+	err = persister.CreatePoll(model.NewPoll(challengeID1, big.NewInt(0), big.NewInt(0),
+		big.NewInt(50), big.NewInt(0), big.NewInt(0), 0))
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the poll: %v", err)
+	}
+	challenge := model.NewChallenge(challengeID1, newsroomAddress, "statement",
+		big.NewInt(100), challenger, false, big.NewInt(50), big.NewInt(0), big.NewInt(0),
+		model.ChallengePollType, 0)
+	err = persister.CreateChallenge(challenge)
+	if err != nil {
+		t.Fatalf("Should not have gotten an error creating the challenge: %v", err)
+	}
+
+	backend := &stubTCRBackend{
+		challenge: stubTCRCaller{
+			rewardPool:  big.NewInt(120),
+			challenger:  challenger,
+			resolved:    true,
+			stake:       big.NewInt(50),
+			totalTokens: big.NewInt(1500),
+		},
+		isPassed: true,
+	}
+	tcrProc := processor.NewTcrEventProcessor(
+		backend,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		persister,
+		&cerrors.NullErrorReporter{})
+
+	// The first resolution takes the challenge from the event
+	_, err = tcrProc.Process(createChallenge1SucceededEvent(newsroomAddress, tcrAddress,
+		big.NewInt(1000)))
+	if err != nil {
+		t.Fatalf("Should not have failed processing the resolution: err: %v", err)
+	}
+	if backend.challenge.calls != 0 {
+		t.Errorf("Should not have read the challenge from the contract: %v", backend.challenge.calls)
+	}
+	resolved, err := persister.ChallengeByChallengeID(int(challengeID1.Int64()))
+	if err != nil {
+		t.Fatalf("Should have retrieved the challenge: %v", err)
+	}
+	if !resolved.Resolved() || resolved.TotalTokens().Int64() != 1000 {
+		t.Errorf("Should have resolved the challenge from the event: %v, %v",
+			resolved.Resolved(), resolved.TotalTokens())
+	}
+
+	// A replayed resolution matching the stored challenge is not reconciled
+	_, err = tcrProc.Process(createChallenge1SucceededEvent(newsroomAddress, tcrAddress,
+		big.NewInt(1000)))
+	if err != nil {
+		t.Fatalf("Should not have failed processing the replayed resolution: err: %v", err)
+	}
+	if backend.challenge.calls != 0 {
+		t.Errorf("Should not have read the challenge from the contract: %v", backend.challenge.calls)
+	}
+
+	// A resolution disagreeing with the stored resolved challenge is reconciled
+	_, err = tcrProc.Process(createChallenge1SucceededEvent(newsroomAddress, tcrAddress,
+		big.NewInt(1200)))
+	if err != nil {
+		t.Fatalf("Should not have failed processing the disagreeing resolution: err: %v", err)
+	}
+	if backend.challenge.calls != 1 {
+		t.Errorf("Should have read the challenge from the contract once: %v",
+			backend.challenge.calls)
+	}
+	reconciled, err := persister.ChallengeByChallengeID(int(challengeID1.Int64()))
+	if err != nil {
+		t.Fatalf("Should have retrieved the challenge: %v", err)
+	}
+	if reconciled.TotalTokens().Int64() != 1500 || reconciled.RewardPool().Int64() != 120 {
+		t.Errorf("Should have taken the challenge from the contract: %v, %v",
+			reconciled.TotalTokens(), reconciled.RewardPool())
+	}
+}
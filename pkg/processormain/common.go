@@ -1,6 +1,7 @@
 package processormain
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -11,9 +12,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 
-	crawlerhelpers "github.com/joincivil/civil-events-crawler/pkg/helpers"
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
-	crawlerutils "github.com/joincivil/civil-events-crawler/pkg/utils"
 
 	"github.com/joincivil/civil-events-processor/pkg/helpers"
 	"github.com/joincivil/civil-events-processor/pkg/model"
@@ -29,6 +28,7 @@ const (
 	maxOpenConns    = 5
 	maxIdleConns    = 5
 	connMaxLifetime = time.Second * 180 // 3 mins
+	warmUpTimeout   = time.Second * 30
 )
 
 // InitErrorReporter inits an error reporter struct
@@ -56,30 +56,27 @@ func InitErrorReporter(config *utils.ProcessorConfig) (cerrors.ErrorReporter, er
 	return reporter, nil
 }
 
-// SaveLastEventInformation saves the last timestamp and event hash info to the cron table
+// SaveLastEventInformation saves the last timestamp and event hash info to the cron table.
+// Only the hashes of the events at the last timestamp are saved. If the last
+// timestamp is unchanged, the hashes of the events at that timestamp are added
+// to the saved hashes.
 func SaveLastEventInformation(persister model.CronPersister, events []*crawlermodel.Event,
 	lastTs int64) error {
 	updated := false
-	numEvents := 0
 	for _, event := range events {
-		timestamp := event.Timestamp()
-		if timestamp > lastTs {
-			lastTs = timestamp
+		if event.Timestamp() > lastTs {
+			lastTs = event.Timestamp()
 			updated = true
-			numEvents = 1
-		} else if timestamp == lastTs {
-			numEvents++
 		}
 	}
-	eventHashes := make([]string, numEvents)
-	i := 0
-	if updated {
-		for _, event := range events {
-			if event.Timestamp() == lastTs {
-				eventHashes[i] = event.Hash()
-				i++
-			}
+	eventHashes := []string{}
+	for _, event := range events {
+		if event.Timestamp() == lastTs {
+			eventHashes = append(eventHashes, event.Hash())
 		}
+	}
+
+	if updated {
 		log.Infof("Updating timestamp %v, eventHashes %v", lastTs, eventHashes)
 		err := persister.UpdateTimestampForCron(lastTs)
 		if err != nil {
@@ -89,6 +86,16 @@ func SaveLastEventInformation(persister model.CronPersister, events []*crawlermo
 		if err != nil {
 			return fmt.Errorf("Error updating event hashes in cron table: %v", err)
 		}
+	} else if len(eventHashes) > 0 {
+		savedHashes, err := persister.EventHashesOfLastTimestampForCron()
+		if err != nil {
+			return fmt.Errorf("Error retrieving event hashes from cron table: %v", err)
+		}
+		log.Infof("Adding eventHashes for timestamp %v, eventHashes %v", lastTs, eventHashes)
+		err = persister.UpdateEventHashesForCron(append(savedHashes, eventHashes...))
+		if err != nil {
+			return fmt.Errorf("Error updating event hashes in cron table: %v", err)
+		}
 	}
 	return nil
 }
@@ -105,6 +112,10 @@ func SetupKillNotify(persisters *InitializedPersisters) {
 }
 
 func initPubSubEvents(config *utils.ProcessorConfig, ps *cpubsub.GooglePubSub) (*cpubsub.GooglePubSub, error) {
+	if !config.PubSubEnabled {
+		log.Infof("pub sub publishing is disabled")
+		return nil, nil
+	}
 	// If no events topic name, disable
 	if config.PubSubEventsTopicName == "" {
 		log.Infof("no pub sub events topic name")
@@ -123,11 +134,20 @@ func initPubSubEvents(config *utils.ProcessorConfig, ps *cpubsub.GooglePubSub) (
 	return ps, err
 }
 
+// eventsPublisher returns the publisher to pass to the processor. Returns a
+// no-op publisher if publishing is disabled or not configured.
+func eventsPublisher(config *utils.ProcessorConfig, ps *cpubsub.GooglePubSub) processor.Publisher {
+	if !config.PubSubEnabled || ps == nil {
+		return &processor.NoopPublisher{}
+	}
+	return ps
+}
+
 // InitializedPersisters contains initialized persisters needed to run processor
 type InitializedPersisters struct {
 	Persister                   *persistence.PostgresPersister
 	Cron                        model.CronPersister
-	Event                       crawlermodel.EventDataPersister
+	Event                       processor.EventSource
 	Listing                     model.ListingPersister
 	ContentRevision             model.ContentRevisionPersister
 	GovernanceEvent             model.GovernanceEventPersister
@@ -180,6 +200,27 @@ func initSqlxDB(config *utils.ProcessorConfig) (*sqlx.DB, error) {
 	return db, nil
 }
 
+// persisterWarmer is implemented by persisters that can prime their conn pool
+type persisterWarmer interface {
+	WarmUp(ctx context.Context, n int) error
+}
+
+// warmUpPersister primes the persister conn pool up to the max open conns if
+// the persister supports it
+func warmUpPersister(persister interface{}, config *utils.ProcessorConfig) error {
+	warmer, ok := persister.(persisterWarmer)
+	if !ok {
+		return nil
+	}
+	numConns := maxOpenConns
+	if config.PersisterPostgresMaxConns != nil {
+		numConns = *config.PersisterPostgresMaxConns
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), warmUpTimeout)
+	defer cancel()
+	return warmer.WarmUp(ctx, numConns)
+}
+
 // InitPersisters inits the persisters from the config file
 func InitPersisters(config *utils.ProcessorConfig) (*InitializedPersisters, error) {
 	db, err := initSqlxDB(config)
@@ -188,11 +229,9 @@ func InitPersisters(config *utils.ProcessorConfig) (*InitializedPersisters, erro
 		return nil, err
 	}
 
-	// Empty config here will set this to latest version of event tables
-	crawlerConfig := &crawlerutils.CrawlerConfig{}
-	eventPersister, err := crawlerhelpers.EventPersisterFromSqlx(db, crawlerConfig)
+	eventSource, err := processor.NewPostgresEventSource(db)
 	if err != nil {
-		log.Errorf("Error getting the event persister: %v", err)
+		log.Errorf("Error getting the event source: %v", err)
 		return nil, err
 	}
 
@@ -202,10 +241,17 @@ func InitPersisters(config *utils.ProcessorConfig) (*InitializedPersisters, erro
 		return nil, err
 	}
 
+	// Warming up only saves the connection setup on the first queries, so
+	// continue without it
+	err = warmUpPersister(persister, config)
+	if err != nil {
+		log.Errorf("Error warming up the persister, continuing: %v", err)
+	}
+
 	return &InitializedPersisters{
 		Persister:                   persister.(*persistence.PostgresPersister),
 		Cron:                        persister.(model.CronPersister),
-		Event:                       eventPersister,
+		Event:                       eventSource,
 		Listing:                     persister.(model.ListingPersister),
 		ContentRevision:             persister.(model.ContentRevisionPersister),
 		GovernanceEvent:             persister.(model.GovernanceEventPersister),
@@ -247,16 +293,58 @@ func GetLastEventInformation(persisters *InitializedPersisters) (int64, []string
 	return lastTs, lastHashes, nil
 }
 
+// RetrieveFilteredEvents retrieves the events matching the filter, regardless of
+// the last processed event. The crawler criteria only supports a contract
+// address and a single event type, so the other fields are applied to the
+// retrieved events.
+func RetrieveFilteredEvents(source processor.EventSource,
+	filter *utils.EventFilter) ([]*crawlermodel.Event, error) {
+	criteria := &crawlermodel.RetrieveEventsCriteria{
+		ContractAddress: filter.ContractAddress,
+	}
+	if len(filter.EventTypes) == 1 {
+		criteria.EventType = filter.EventTypes[0]
+	}
+	events, err := source.RetrieveEvents(criteria)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]*crawlermodel.Event, 0, len(events))
+	for _, event := range events {
+		if filter.Matches(event.ContractAddress(), event.EventType(), event.BlockNumber()) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered, nil
+}
+
+// RunFilteredProcessor processes events retrieved with an event filter. Does
+// not save the last event information, as the events are being reprocessed.
+func RunFilteredProcessor(proc *processor.EventProcessor, events []*crawlermodel.Event,
+	errRep cerrors.ErrorReporter) {
+	result, err := proc.Process(events)
+	if err != nil {
+		log.Errorf("Error processing filtered events: err: %v", err)
+		errRep.Error(err, nil)
+	}
+	log.Infof("Filtered processor run result: %v", result)
+}
+
 // RunProcessor runs the processor
 func RunProcessor(proc *processor.EventProcessor, persisters *InitializedPersisters,
 	events []*crawlermodel.Event, lastTs int64, errRep cerrors.ErrorReporter) {
-
-	err := proc.Process(events)
+	// Logged so operators can track the catch up progress
+	log.Infof("%v events to process", len(events))
+	result, err := proc.Process(events)
 	if err != nil {
 		log.Errorf("Error processing events: err: %v", err)
 		errRep.Error(err, nil)
 	}
+	log.Infof("Processor run result: %v", result)
 
+	// NOTE: Process attempts every event and reports the ones that fail, so the
+	// last event info is saved even on an error. Not saving it would process the
+	// whole batch again on every run and never get past a failing event.
 	err = SaveLastEventInformation(persisters.Cron, events, lastTs)
 	if err != nil {
 		log.Errorf("Error saving last seen event info %v: err: %v", lastTs, err)
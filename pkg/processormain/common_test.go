@@ -6,6 +6,7 @@ import (
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/processormain"
 	"github.com/joincivil/civil-events-processor/pkg/testutils"
+	"github.com/joincivil/civil-events-processor/pkg/utils"
 	"github.com/joincivil/go-common/pkg/generated/contract"
 	cstring "github.com/joincivil/go-common/pkg/strings"
 	ctime "github.com/joincivil/go-common/pkg/time"
@@ -49,6 +50,8 @@ func ReturnTestEventsSameTimestamp(t *testing.T, numEvents int) []*crawlermodel.
 	ts := ctime.CurrentEpochSecsInInt64()
 	for i := 0; i < numEvents; i++ {
 		appEvent := ReturnRandomTestApplicationEvent(t)
+		// Distinct log index so each event has a unique hash
+		appEvent.Raw.Index = uint(i)
 		event, err := crawlermodel.NewEventFromContractEvent(
 			"Application",
 			"CivilTCRContract",
@@ -81,3 +84,209 @@ func TestSaveLastEventInformation(t *testing.T) {
 	}
 
 }
+
+func TestSaveLastEventInformationOverlappingHashes(t *testing.T) {
+	testCronPersister := &testutils.TestPersister{}
+	ts := ctime.CurrentEpochSecsInInt64()
+	first := returnTestEventsWithUniqueHashes(t, 3, ts, 0)
+	// Overlaps with the first events and includes a duplicate
+	second := append(returnTestEventsWithUniqueHashes(t, 4, ts, 1), first[2])
+	later := returnTestEventsWithUniqueHashes(t, 2, ts+1, 10)
+
+	err := processormain.SaveLastEventInformation(testCronPersister, first, 0)
+	if err != nil {
+		t.Errorf("Error saving last event info, err: %v", err)
+	}
+	hashes, _ := testCronPersister.EventHashesOfLastTimestampForCron()
+	if len(hashes) != 3 {
+		t.Errorf("Number of hashes should be %v but is %v", 3, len(hashes))
+	}
+
+	// Events at the same timestamp add to the saved hashes without duplicates
+	err = processormain.SaveLastEventInformation(testCronPersister, second, ts)
+	if err != nil {
+		t.Errorf("Error saving last event info, err: %v", err)
+	}
+	hashes, _ = testCronPersister.EventHashesOfLastTimestampForCron()
+	if len(hashes) != 5 {
+		t.Errorf("Number of hashes should be %v but is %v", 5, len(hashes))
+	}
+	seen := map[string]bool{}
+	for _, hash := range hashes {
+		if seen[hash] {
+			t.Errorf("Should not have saved duplicate hash %v", hash)
+		}
+		seen[hash] = true
+	}
+	for _, event := range append(first, second...) {
+		if !seen[event.Hash()] {
+			t.Errorf("Should have saved hash for event %v", event.Hash())
+		}
+	}
+
+	// Events at a later timestamp replace the saved hashes, including
+	// earlier events in the same run
+	err = processormain.SaveLastEventInformation(testCronPersister, append(second, later...), ts)
+	if err != nil {
+		t.Errorf("Error saving last event info, err: %v", err)
+	}
+	hashes, _ = testCronPersister.EventHashesOfLastTimestampForCron()
+	if len(hashes) != 2 {
+		t.Errorf("Number of hashes should be %v but is %v", 2, len(hashes))
+	}
+	for _, event := range later {
+		if hashes[0] != event.Hash() && hashes[1] != event.Hash() {
+			t.Errorf("Should have saved hash for later event %v", event.Hash())
+		}
+	}
+	lastTs, _ := testCronPersister.TimestampOfLastEventForCron()
+	if lastTs != ts+1 {
+		t.Errorf("Should have saved the later timestamp %v but is %v", ts+1, lastTs)
+	}
+}
+
+// fakeEventPersister is an in-memory crawler event persister that filters on
+// the timestamp, excluded hashes, contract address and event type like the
+// crawler postgres persister
+type fakeEventPersister struct {
+	events []*crawlermodel.Event
+}
+
+func (ep *fakeEventPersister) RetrieveEvents(criteria *crawlermodel.RetrieveEventsCriteria) ([]*crawlermodel.Event, error) {
+	excluded := map[string]bool{}
+	for _, hash := range criteria.ExcludeHashes {
+		excluded[hash] = true
+	}
+	events := []*crawlermodel.Event{}
+	for _, event := range ep.events {
+		if event.Timestamp() < criteria.FromTs || excluded[event.Hash()] {
+			continue
+		}
+		if criteria.ContractAddress != "" && event.ContractAddress().Hex() != criteria.ContractAddress {
+			continue
+		}
+		if criteria.EventType != "" && event.EventType() != criteria.EventType {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (ep *fakeEventPersister) SaveEvents(events []*crawlermodel.Event) []error {
+	ep.events = append(ep.events, events...)
+	return nil
+}
+
+// returnTestEventsWithUniqueHashes returns events at the given timestamp with
+// distinct log indices, so each has a unique hash
+func returnTestEventsWithUniqueHashes(t *testing.T, numEvents int, ts int64,
+	startIndex uint) []*crawlermodel.Event {
+	events := make([]*crawlermodel.Event, numEvents)
+	for i := 0; i < numEvents; i++ {
+		appEvent := ReturnRandomTestApplicationEvent(t)
+		appEvent.Raw.Index = startIndex + uint(i)
+		event, err := crawlermodel.NewEventFromContractEvent(
+			"Application",
+			"CivilTCRContract",
+			common.HexToAddress(ContractAddress),
+			appEvent,
+			ts,
+			crawlermodel.Watcher,
+		)
+		if err != nil {
+			t.Errorf("Error creating new event %v", err)
+		}
+		events[i] = event
+	}
+	return events
+}
+
+func returnTestChallengeEvent(t *testing.T, contractAddress string, blockNumber uint64) *crawlermodel.Event {
+	listingAddress, _ := cstring.RandomHexStr(20)
+	challengeEvent := &contract.CivilTCRContractChallenge{
+		ListingAddress: common.HexToAddress(listingAddress),
+		ChallengeID:    big.NewInt(int64(blockNumber)),
+		Data:           "DATA",
+		CommitEndDate:  big.NewInt(1653860896),
+		RevealEndDate:  big.NewInt(1653860896),
+		Challenger:     common.HexToAddress(listingAddress),
+		Raw: types.Log{
+			Address:     common.HexToAddress(contractAddress),
+			BlockNumber: blockNumber,
+			Index:       uint(blockNumber),
+		},
+	}
+	event, err := crawlermodel.NewEventFromContractEvent(
+		"Challenge",
+		"CivilTCRContract",
+		common.HexToAddress(contractAddress),
+		challengeEvent,
+		ctime.CurrentEpochSecsInInt64(),
+		crawlermodel.Watcher,
+	)
+	if err != nil {
+		t.Errorf("Error creating new event %v", err)
+	}
+	return event
+}
+
+func TestRetrieveFilteredEvents(t *testing.T) {
+	otherContractAddress := "0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A"
+	eventPersister := &fakeEventPersister{}
+	// Application events are at block 8888888
+	eventPersister.SaveEvents(returnTestEventsWithUniqueHashes(t, 3, 0, 0))
+	eventPersister.SaveEvents([]*crawlermodel.Event{
+		returnTestChallengeEvent(t, ContractAddress, 100),
+		returnTestChallengeEvent(t, ContractAddress, 200),
+		returnTestChallengeEvent(t, ContractAddress, 300),
+		returnTestChallengeEvent(t, otherContractAddress, 200),
+	})
+
+	filter, err := utils.ParseEventFilter(`{
+		"contractAddress": "0x77e5aabddb760fba989a1c4b2cdd4aa8fa3d311d",
+		"eventTypes": ["Challenge"],
+		"fromBlock": 150,
+		"toBlock": 300
+	}`)
+	if err != nil {
+		t.Fatalf("Error parsing event filter, err: %v", err)
+	}
+	events, err := processormain.RetrieveFilteredEvents(eventPersister, filter)
+	if err != nil {
+		t.Errorf("Error retrieving filtered events, err: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Should have retrieved 2 events but retrieved %v", len(events))
+	}
+	for _, event := range events {
+		if event.EventType() != "Challenge" {
+			t.Errorf("Should have only retrieved challenge events: %v", event.EventType())
+		}
+		if event.ContractAddress() != common.HexToAddress(ContractAddress) {
+			t.Errorf("Should have only retrieved events from the filter contract")
+		}
+		if event.BlockNumber() < 150 || event.BlockNumber() > 300 {
+			t.Errorf("Should have only retrieved events in the block range: %v", event.BlockNumber())
+		}
+	}
+
+	// Multiple event types are filtered after retrieval
+	filter = &utils.EventFilter{EventTypes: []string{"Application", "Challenge"}}
+	events, err = processormain.RetrieveFilteredEvents(eventPersister, filter)
+	if err != nil {
+		t.Errorf("Error retrieving filtered events, err: %v", err)
+	}
+	if len(events) != 7 {
+		t.Errorf("Should have retrieved 7 events but retrieved %v", len(events))
+	}
+
+	filter = &utils.EventFilter{EventTypes: []string{"Application"}, ToBlock: 1000}
+	events, err = processormain.RetrieveFilteredEvents(eventPersister, filter)
+	if err != nil {
+		t.Errorf("Error retrieving filtered events, err: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Should have retrieved no events but retrieved %v", len(events))
+	}
+}
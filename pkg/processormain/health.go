@@ -0,0 +1,106 @@
+package processormain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/joincivil/civil-events-processor/pkg/model"
+	"github.com/joincivil/civil-events-processor/pkg/utils"
+
+	ctime "github.com/joincivil/go-common/pkg/time"
+)
+
+const (
+	healthPath = "/health"
+)
+
+// Status is the operational status of the processor
+type Status struct {
+	// LastProcessedTs is the timestamp of the last event processed by the cron
+	LastProcessedTs int64 `json:"lastProcessedTs"`
+	// LagSecs is the number of secs between now and the last processed event
+	LagSecs int64 `json:"lagSecs"`
+	// StaleThresholdSecs is the lag after which the processor is unhealthy
+	StaleThresholdSecs int64 `json:"staleThresholdSecs"`
+	// Healthy is false if the lag exceeds the stale threshold
+	Healthy bool `json:"healthy"`
+}
+
+// NewStatusChecker returns a new StatusChecker. A staleThreshold of 0 never
+// reports the processor as stale. The lag is from the last processed event, so
+// it also grows while no new events are emitted.
+func NewStatusChecker(cron model.CronPersister, staleThreshold time.Duration) *StatusChecker {
+	return &StatusChecker{
+		cron:           cron,
+		staleThreshold: staleThreshold,
+	}
+}
+
+// StatusChecker reports the status of the processor based on the last
+// processed event timestamp
+type StatusChecker struct {
+	cron           model.CronPersister
+	staleThreshold time.Duration
+}
+
+// ProcessorStatus returns the last processed event timestamp, the lag from now
+// and whether the lag is within the stale threshold
+func (s *StatusChecker) ProcessorStatus() (*Status, error) {
+	lastTs, err := s.cron.TimestampOfLastEventForCron()
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving last event timestamp")
+	}
+	thresholdSecs := int64(s.staleThreshold / time.Second)
+	lag := ctime.CurrentEpochSecsInInt64() - lastTs
+	return &Status{
+		LastProcessedTs:    lastTs,
+		LagSecs:            lag,
+		StaleThresholdSecs: thresholdSecs,
+		Healthy:            thresholdSecs == 0 || lag <= thresholdSecs,
+	}, nil
+}
+
+// ServeHTTP writes the processor status as JSON. Responds with 503 if the
+// processor is unhealthy or the status could not be retrieved.
+func (s *StatusChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, err := s.ProcessorStatus()
+	if err != nil {
+		log.Errorf("Error retrieving processor status: err: %v", err)
+		http.Error(w, "error retrieving processor status", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	err = json.NewEncoder(w).Encode(status)
+	if err != nil {
+		log.Errorf("Error writing processor status: err: %v", err)
+	}
+}
+
+// StartHealthServer serves the processor status at /health on the configured
+// health port in the background. Does nothing if the health port is not set.
+func StartHealthServer(config *utils.ProcessorConfig, persisters *InitializedPersisters) {
+	if config.HealthPort == 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle(healthPath, NewStatusChecker(persisters.Cron, config.HealthStaleThreshold()))
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%v", config.HealthPort),
+		Handler: mux,
+	}
+	go func() {
+		log.Infof("Serving processor status at %v%v", server.Addr, healthPath)
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Errorf("Error serving processor status: err: %v", err)
+		}
+	}()
+}
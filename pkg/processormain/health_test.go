@@ -0,0 +1,83 @@
+package processormain_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-events-processor/pkg/processormain"
+	"github.com/joincivil/civil-events-processor/pkg/testutils"
+
+	ctime "github.com/joincivil/go-common/pkg/time"
+)
+
+func TestProcessorStatus(t *testing.T) {
+	now := ctime.CurrentEpochSecsInInt64()
+	cronPersister := &testutils.TestPersister{Timestamp: now - 60}
+	checker := processormain.NewStatusChecker(cronPersister, time.Hour)
+
+	status, err := checker.ProcessorStatus()
+	if err != nil {
+		t.Fatalf("Error getting processor status: err: %v", err)
+	}
+	if status.LastProcessedTs != now-60 {
+		t.Errorf("Should have returned the last processed timestamp: %v", status.LastProcessedTs)
+	}
+	if status.LagSecs < 60 || status.LagSecs > 120 {
+		t.Errorf("Should have returned the lag from now: %v", status.LagSecs)
+	}
+	if status.StaleThresholdSecs != 3600 {
+		t.Errorf("Should have returned the stale threshold: %v", status.StaleThresholdSecs)
+	}
+	if !status.Healthy {
+		t.Errorf("Should have been healthy within the stale threshold")
+	}
+
+	cronPersister.Timestamp = now - 7200
+	status, err = checker.ProcessorStatus()
+	if err != nil {
+		t.Fatalf("Error getting processor status: err: %v", err)
+	}
+	if status.Healthy {
+		t.Errorf("Should have been unhealthy past the stale threshold")
+	}
+
+	// No stale threshold is always healthy
+	checker = processormain.NewStatusChecker(cronPersister, 0)
+	status, err = checker.ProcessorStatus()
+	if err != nil {
+		t.Fatalf("Error getting processor status: err: %v", err)
+	}
+	if !status.Healthy {
+		t.Errorf("Should have been healthy with no stale threshold")
+	}
+}
+
+func TestProcessorStatusHandler(t *testing.T) {
+	now := ctime.CurrentEpochSecsInInt64()
+	cronPersister := &testutils.TestPersister{Timestamp: now - 60}
+	checker := processormain.NewStatusChecker(cronPersister, time.Hour)
+
+	rec := httptest.NewRecorder()
+	checker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Should have returned 200 when healthy: %v", rec.Code)
+	}
+	status := &processormain.Status{}
+	err := json.Unmarshal(rec.Body.Bytes(), status)
+	if err != nil {
+		t.Fatalf("Error decoding status: err: %v", err)
+	}
+	if !status.Healthy || status.LastProcessedTs != now-60 {
+		t.Errorf("Should have returned the processor status: %+v", status)
+	}
+
+	cronPersister.Timestamp = now - 7200
+	rec = httptest.NewRecorder()
+	checker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Should have returned 503 when stale: %v", rec.Code)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	crawlermodel "github.com/joincivil/civil-events-crawler/pkg/model"
 
+	"github.com/joincivil/civil-events-processor/pkg/helpers"
 	"github.com/joincivil/civil-events-processor/pkg/processor"
 	"github.com/joincivil/civil-events-processor/pkg/utils"
 
@@ -56,8 +57,8 @@ func resetProcessLock() {
 }
 
 func initPubSubForCron(config *utils.ProcessorConfig) (*cpubsub.GooglePubSub, error) {
-	// If no project ID, disable
-	if config.PubSubProjectID == "" {
+	// If disabled or no project ID, disable
+	if !config.PubSubEnabled || config.PubSubProjectID == "" {
 		return nil, nil
 	}
 
@@ -87,12 +88,18 @@ func runProcessorCron(config *utils.ProcessorConfig, persisters *InitializedPers
 		return
 	}
 
-	events, err := persisters.Event.RetrieveEvents(
-		&crawlermodel.RetrieveEventsCriteria{
-			FromTs:        lastTs,
-			ExcludeHashes: lastHashes,
-		},
-	)
+	var events []*crawlermodel.Event
+	if config.EventFilter != nil {
+		log.Infof("Retrieving events with filter: %+v", *config.EventFilter)
+		events, err = RetrieveFilteredEvents(persisters.Event, config.EventFilter)
+	} else {
+		events, err = persisters.Event.RetrieveEvents(
+			&crawlermodel.RetrieveEventsCriteria{
+				FromTs:        lastTs,
+				ExcludeHashes: lastHashes,
+			},
+		)
+	}
 	if err != nil {
 		log.Errorf("Error retrieving events: err: %v", err)
 		errRep.Error(err, nil)
@@ -131,14 +138,23 @@ func runProcessorCron(config *utils.ProcessorConfig, persisters *InitializedPers
 			MultiSigOwnerPersister:               persisters.MultiSigOwner,
 			GovernmentParameterProposalPersister: persisters.GovernmentParameterProposal,
 			GovernmentParameterPersister:         persisters.GovernmentParameter,
-			GooglePubSub:                         pubsub,
+			CharterScraper:                       helpers.CharterScraper(config),
+			ContentScraper:                       helpers.ContentScraper(config),
+			CivilMetadataScraper:                 helpers.CivilMetadataScraper(config),
+			GooglePubSub:                         eventsPublisher(config, pubsub),
 			PubSubEventsTopicName:                config.PubSubEventsTopicName,
 			PubSubTokenTopicName:                 config.PubSubTokenTopicName,
 			PubSubMultiSigTopicName:              config.PubSubMultiSigTopicName,
+			PubSubEventTopics:                    config.PubSubEventTopics,
+			TCRContractAddresses:                 config.TCRAddresses(),
 			ErrRep:                               errRep,
 		})
 
-		RunProcessor(proc, persisters, events, lastTs, errRep)
+		if config.EventFilter != nil {
+			RunFilteredProcessor(proc, events, errRep)
+		} else {
+			RunProcessor(proc, persisters, events, lastTs, errRep)
+		}
 	}
 
 	log.Infof("Done running processor: %v", runtime.NumGoroutine())
@@ -161,6 +177,12 @@ func ProcessorCronMain(config *utils.ProcessorConfig, persisters *InitializedPer
 	// runProcessorCron one startup before waiting for cron to trigger
 	runProcessorCron(config, persisters, errRep)
 
+	// Filtered events are reprocessed once, rerunning would only repeat the work
+	if config.EventFilter != nil {
+		log.Infof("Done reprocessing filtered events, exiting")
+		return
+	}
+
 	cr := cron.New()
 	err = cr.AddFunc(config.CronConfig, func() { runProcessorCron(config, persisters, errRep) })
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	log "github.com/golang/glog"
 	"github.com/pkg/errors"
@@ -18,6 +19,7 @@ import (
 	cerrors "github.com/joincivil/go-common/pkg/errors"
 	cpubsub "github.com/joincivil/go-common/pkg/pubsub"
 
+	"github.com/joincivil/civil-events-processor/pkg/helpers"
 	"github.com/joincivil/civil-events-processor/pkg/processor"
 	"github.com/joincivil/civil-events-processor/pkg/utils"
 )
@@ -67,72 +69,73 @@ func isNewsroomException(crawlerMsg *crawlerps.CrawlerPubSubMessage) bool {
 // RunProcessorPubSub runs processor upon receiving messages from pubsub
 func RunProcessorPubSub(persisters *InitializedPersisters, ps *cpubsub.GooglePubSub,
 	proc *processor.EventProcessor, quit <-chan bool, errRep cerrors.ErrorReporter) {
+	RunProcessorPubSubBatched(persisters, ps, proc, quit, errRep, 1, 0)
+}
+
+// RunProcessorPubSubBatched runs processor upon receiving messages from pubsub.
+// Up to batchSize messages are handled by a single processor run before they are
+// acked. A batch is processed when full or batchInterval after its first message.
+// If batchInterval is 0, each message is processed on its own. Messages still
+// buffered when quitting are not acked and are redelivered by pubsub.
+func RunProcessorPubSubBatched(persisters *InitializedPersisters, ps *cpubsub.GooglePubSub,
+	proc *processor.EventProcessor, quit <-chan bool, errRep cerrors.ErrorReporter,
+	batchSize int, batchInterval time.Duration) {
 	log.Info("Start listening for messages")
-Loop:
+	batcher := newPubSubBatcher(batchSize, batchInterval, func(b *pubSubBatch) error {
+		return processPubSubBatch(persisters, proc, b)
+	})
+	toAck := func(msg *pubsub.Message) ackMessage { return msg }
+	runPubSubBatchLoop(batcher, ps.SubscribeChan, toAck, ps.SubscribeErrChan, quit, errRep)
+}
+
+// runPubSubBatchLoop adds the received messages to the batcher until quit.
+// toAck returns the message to ack for each received message.
+func runPubSubBatchLoop(batcher *pubSubBatcher, msgs <-chan *pubsub.Message,
+	toAck func(msg *pubsub.Message) ackMessage, errs <-chan error, quit <-chan bool,
+	errRep cerrors.ErrorReporter) {
+	processed := func(numMsgs int, err error) bool {
+		if err != nil {
+			log.Errorf("Error processing batch of %v messages: err: %v", numMsgs, err)
+			errRep.Error(err, nil)
+			return false
+		}
+		if numMsgs > 0 {
+			log.Infof("Finished processing events from %v messages\n", numMsgs)
+		}
+		return true
+	}
+
 	for {
 		select {
-		case msg, ok := <-ps.SubscribeChan:
+		case msg, ok := <-msgs:
 			if !ok {
 				log.Errorf("Sending on closed channel")
-				break Loop
+				return
 			}
 			messData, err := processMessageGetEvents(msg)
 			if err != nil {
 				log.Errorf("Error processing message: err: %v", err)
 				errRep.Error(err, nil)
 			}
-			lastTs, err := persisters.Cron.TimestampOfLastEventForCron()
-			if err != nil {
-				log.Errorf("Error getting last event timestamp: %v", err)
-				errRep.Error(err, nil)
-				return
-			}
-			var retrieveCriteria *crawlermodel.RetrieveEventsCriteria
 			if isNewsroomException(messData) {
 				log.Infof("Received newsroom exception message with ID: %v from crawler", msg.ID)
-				retrieveCriteria = &crawlermodel.RetrieveEventsCriteria{
-					FromTs:          lastTs,
-					ContractAddress: messData.ContractAddress,
-				}
 			} else {
 				log.Infof("Received regular message with ID: %v from crawler", msg.ID)
-				lastHashes, cronTableErr := persisters.Cron.EventHashesOfLastTimestampForCron()
-				if cronTableErr != nil {
-					log.Errorf("Error getting event hashes for last timestamp seen in cron: %v", cronTableErr)
-					errRep.Error(cronTableErr, nil)
-					return
-				}
-				retrieveCriteria = &crawlermodel.RetrieveEventsCriteria{
-					FromTs:        lastTs,
-					ExcludeHashes: lastHashes,
-				}
 			}
-			events, err := persisters.Event.RetrieveEvents(retrieveCriteria)
-			if err != nil {
-				log.Errorf("Error retrieving events: err: %v", err)
-				errRep.Error(err, nil)
+			numMsgs := batcher.size() + 1
+			err = batcher.add(toAck(msg), messData)
+			// The batcher is empty again if the batch was full and processed
+			if batcher.size() == 0 && !processed(numMsgs, err) {
 				return
 			}
-			err = proc.Process(events)
-			if err != nil {
-				log.Errorf("Error processing events: err: %v", err)
-				errRep.Error(err, nil)
+
+		case <-batcher.waitDone():
+			numMsgs := batcher.size()
+			if !processed(numMsgs, batcher.process()) {
 				return
 			}
-			// Manually acknowledge message receipt after processing is successful
-			msg.Ack()
-			// NOTE(IS): Only save lastTs if this message isn't a NewsroomException
-			if !isNewsroomException(messData) {
-				err := SaveLastEventInformation(persisters.Cron, events, lastTs)
-				if err != nil {
-					log.Errorf("Error saving last seen event info %v: err: %v", lastTs, err)
-					errRep.Error(err, nil)
-					return
-				}
-			}
-			log.Infof("Finished processing events from message\n")
 
-		case err := <-ps.SubscribeErrChan:
+		case err := <-errs:
 			// Error from a subscriber even after retries, send to error reporting
 			// and will require manual intervention. Keep loop going in case
 			// there are multiple subscribers running
@@ -141,9 +144,62 @@ Loop:
 
 		case <-quit:
 			log.Infof("Quitting")
-			break Loop
+			return
+		}
+	}
+}
+
+// processPubSubBatch processes the events for the messages in the batch. The
+// newsroom exceptions are processed first from the last processed timestamp,
+// then the events after the last processed event if there were any regular
+// messages. Only the regular run saves the last event information.
+func processPubSubBatch(persisters *InitializedPersisters, proc *processor.EventProcessor,
+	batch *pubSubBatch) error {
+	lastTs, err := persisters.Cron.TimestampOfLastEventForCron()
+	if err != nil {
+		return errors.WithMessage(err, "error getting last event timestamp")
+	}
+
+	for _, address := range batch.newsroomAddresses {
+		events, err := persisters.Event.RetrieveEvents(&crawlermodel.RetrieveEventsCriteria{
+			FromTs:          lastTs,
+			ContractAddress: address,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "error retrieving newsroom exception events")
 		}
+		result, err := proc.Process(events)
+		if err != nil {
+			return errors.WithMessage(err, "error processing newsroom exception events")
+		}
+		log.Infof("Processor run result for newsroom %v: %v", address, result)
+	}
+
+	if !batch.regular {
+		return nil
+	}
+	lastHashes, err := persisters.Cron.EventHashesOfLastTimestampForCron()
+	if err != nil {
+		return errors.WithMessage(err, "error getting event hashes for last timestamp seen in cron")
+	}
+	events, err := persisters.Event.RetrieveEvents(&crawlermodel.RetrieveEventsCriteria{
+		FromTs:        lastTs,
+		ExcludeHashes: lastHashes,
+	})
+	if err != nil {
+		return errors.WithMessage(err, "error retrieving events")
+	}
+	log.Infof("%v events to process", len(events))
+	result, err := proc.Process(events)
+	if err != nil {
+		return errors.WithMessage(err, "error processing events")
+	}
+	log.Infof("Processor run result: %v", result)
+	err = SaveLastEventInformation(persisters.Cron, events, lastTs)
+	if err != nil {
+		return errors.WithMessagef(err, "error saving last seen event info %v", lastTs)
 	}
+	return nil
 }
 
 func cleanup(ps *cpubsub.GooglePubSub, quitChan chan<- bool) {
@@ -231,10 +287,15 @@ func ProcessorPubSubMain(config *utils.ProcessorConfig, persisters *InitializedP
 		MultiSigOwnerPersister:               persisters.MultiSigOwner,
 		GovernmentParameterPersister:         persisters.GovernmentParameter,
 		GovernmentParameterProposalPersister: persisters.GovernmentParameterProposal,
-		GooglePubSub:                         eventsPs,
+		CharterScraper:                       helpers.CharterScraper(config),
+		ContentScraper:                       helpers.ContentScraper(config),
+		CivilMetadataScraper:                 helpers.CivilMetadataScraper(config),
+		GooglePubSub:                         eventsPublisher(config, eventsPs),
 		PubSubEventsTopicName:                config.PubSubEventsTopicName,
 		PubSubTokenTopicName:                 config.PubSubTokenTopicName,
 		PubSubMultiSigTopicName:              config.PubSubMultiSigTopicName,
+		PubSubEventTopics:                    config.PubSubEventTopics,
+		TCRContractAddresses:                 config.TCRAddresses(),
 		ErrRep:                               errRep,
 	})
 
@@ -258,5 +319,6 @@ func ProcessorPubSubMain(config *utils.ProcessorConfig, persisters *InitializedP
 	if len(events) > 0 {
 		RunProcessor(proc, persisters, events, lastTs, errRep)
 	}
-	RunProcessorPubSub(persisters, ps, proc, quitChan, errRep)
+	RunProcessorPubSubBatched(persisters, ps, proc, quitChan, errRep, config.PubSubBatchSize,
+		config.PubSubBatchInterval())
 }
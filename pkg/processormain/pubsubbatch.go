@@ -0,0 +1,141 @@
+package processormain
+
+import (
+	"time"
+
+	crawlerps "github.com/joincivil/civil-events-crawler/pkg/pubsub"
+)
+
+// ackMessage is a received pubsub message that can be acknowledged
+type ackMessage interface {
+	Ack()
+}
+
+// pubSubBatch buffers crawler trigger messages so a single processor run handles
+// the events for all of them. Batching only coalesces the runs and acks, it is
+// not a transaction. Each run saves what it processes as it goes, and the
+// messages are only acked after all runs succeed, so pubsub redelivers the
+// messages of a failed batch and the events are processed again from the last
+// saved event information.
+type pubSubBatch struct {
+	maxSize int
+	msgs    []ackMessage
+	// regular is true if any of the messages is a regular trigger message
+	regular bool
+	// newsroomAddresses are the distinct contract addresses of the newsroom
+	// exception messages in the order received
+	newsroomAddresses []string
+}
+
+func newPubSubBatch(maxSize int) *pubSubBatch {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	return &pubSubBatch{maxSize: maxSize}
+}
+
+// add buffers the message with its decoded crawler message
+func (b *pubSubBatch) add(msg ackMessage, crawlerMsg *crawlerps.CrawlerPubSubMessage) {
+	b.msgs = append(b.msgs, msg)
+	if !isNewsroomException(crawlerMsg) {
+		b.regular = true
+		return
+	}
+	for _, address := range b.newsroomAddresses {
+		if address == crawlerMsg.ContractAddress {
+			return
+		}
+	}
+	b.newsroomAddresses = append(b.newsroomAddresses, crawlerMsg.ContractAddress)
+}
+
+func (b *pubSubBatch) size() int {
+	return len(b.msgs)
+}
+
+func (b *pubSubBatch) full() bool {
+	return len(b.msgs) >= b.maxSize
+}
+
+// processAndAck runs processFn for the batch and acks the messages if it
+// succeeds. The batch is reset either way, the messages of a failed run are left
+// for pubsub to redeliver.
+func (b *pubSubBatch) processAndAck(processFn func(batch *pubSubBatch) error) error {
+	defer b.reset()
+	if len(b.msgs) == 0 {
+		return nil
+	}
+	err := processFn(b)
+	if err != nil {
+		return err
+	}
+	for _, msg := range b.msgs {
+		msg.Ack()
+	}
+	return nil
+}
+
+func (b *pubSubBatch) reset() {
+	b.msgs = nil
+	b.regular = false
+	b.newsroomAddresses = nil
+}
+
+// pubSubBatcher decides when a batch is processed. A batch is processed once
+// full or once maxWait has passed since its first message. Without a maxWait
+// each message is processed on its own, since otherwise a batch that never
+// fills would never be processed.
+type pubSubBatcher struct {
+	batch     *pubSubBatch
+	maxWait   time.Duration
+	processFn func(batch *pubSubBatch) error
+	waitTimer *time.Timer
+}
+
+func newPubSubBatcher(maxSize int, maxWait time.Duration,
+	processFn func(batch *pubSubBatch) error) *pubSubBatcher {
+	if maxWait <= 0 {
+		maxSize = 1
+	}
+	return &pubSubBatcher{
+		batch:     newPubSubBatch(maxSize),
+		maxWait:   maxWait,
+		processFn: processFn,
+	}
+}
+
+// add buffers the message and processes the batch if full, otherwise starts
+// the wait for the batch if it is the first message
+func (b *pubSubBatcher) add(msg ackMessage, crawlerMsg *crawlerps.CrawlerPubSubMessage) error {
+	b.batch.add(msg, crawlerMsg)
+	if b.batch.full() {
+		return b.process()
+	}
+	if b.waitTimer == nil {
+		b.waitTimer = time.NewTimer(b.maxWait)
+	}
+	return nil
+}
+
+// waitDone returns the channel that receives once the wait for the current
+// batch is over, nil if there is no batch waiting
+func (b *pubSubBatcher) waitDone() <-chan time.Time {
+	if b.waitTimer == nil {
+		return nil
+	}
+	return b.waitTimer.C
+}
+
+// process processes and acks the buffered messages
+func (b *pubSubBatcher) process() error {
+	if b.waitTimer != nil {
+		b.waitTimer.Stop()
+		b.waitTimer = nil
+	}
+	return b.batch.processAndAck(b.processFn)
+}
+
+// size returns the number of buffered messages
+func (b *pubSubBatcher) size() int {
+	return b.batch.size()
+}
@@ -0,0 +1,229 @@
+package processormain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+
+	crawlerps "github.com/joincivil/civil-events-crawler/pkg/pubsub"
+	cerrors "github.com/joincivil/go-common/pkg/errors"
+)
+
+type fakeAckMessage struct {
+	acked bool
+}
+
+func (m *fakeAckMessage) Ack() {
+	m.acked = true
+}
+
+func TestPubSubBatchAcksAfterProcessing(t *testing.T) {
+	batch := newPubSubBatch(3)
+	msgs := []*fakeAckMessage{{}, {}, {}}
+	regularMsg := &crawlerps.CrawlerPubSubMessage{}
+	exceptionMsg := &crawlerps.CrawlerPubSubMessage{
+		NewsroomException: true,
+		ContractAddress:   "0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d",
+	}
+
+	batch.add(msgs[0], regularMsg)
+	batch.add(msgs[1], exceptionMsg)
+	if batch.full() {
+		t.Errorf("Should not be full before the max size")
+	}
+	batch.add(msgs[2], exceptionMsg)
+	if !batch.full() {
+		t.Errorf("Should be full at the max size")
+	}
+
+	// Messages are not acked if processing fails
+	err := batch.processAndAck(func(b *pubSubBatch) error {
+		return errors.New("processing failed")
+	})
+	if err == nil {
+		t.Errorf("Should have returned the processing error")
+	}
+	for i, msg := range msgs {
+		if msg.acked {
+			t.Errorf("Should not have acked message %v after failed processing", i)
+		}
+	}
+	if batch.size() != 0 {
+		t.Errorf("Should have reset the batch after processing: %v", batch.size())
+	}
+
+	// Messages are acked only once processing succeeds
+	for _, msg := range msgs {
+		batch.add(msg, exceptionMsg)
+	}
+	batch.add(msgs[0], regularMsg)
+	processed := false
+	err = batch.processAndAck(func(b *pubSubBatch) error {
+		for i, msg := range msgs {
+			if msg.acked {
+				t.Errorf("Should not have acked message %v before processing", i)
+			}
+		}
+		if !b.regular {
+			t.Errorf("Should have a regular message in the batch")
+		}
+		if len(b.newsroomAddresses) != 1 {
+			t.Errorf("Should have deduped the newsroom addresses: %v", b.newsroomAddresses)
+		}
+		processed = true
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Should not have gotten an error processing: %v", err)
+	}
+	if !processed {
+		t.Errorf("Should have processed the batch")
+	}
+	for i, msg := range msgs {
+		if !msg.acked {
+			t.Errorf("Should have acked message %v after processing", i)
+		}
+	}
+}
+
+func TestPubSubBatchEmptyProcess(t *testing.T) {
+	batch := newPubSubBatch(0)
+	err := batch.processAndAck(func(b *pubSubBatch) error {
+		t.Errorf("Should not have processed an empty batch")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Should not have gotten an error processing an empty batch: %v", err)
+	}
+	batch.add(&fakeAckMessage{}, &crawlerps.CrawlerPubSubMessage{})
+	if !batch.full() {
+		t.Errorf("Should default to a batch size of 1")
+	}
+}
+
+type chanAckMessage struct {
+	id    string
+	acked chan<- string
+}
+
+func (m *chanAckMessage) Ack() {
+	m.acked <- m.id
+}
+
+type testBatchLoop struct {
+	msgs      chan *pubsub.Message
+	acked     chan string
+	processed chan int
+	quit      chan bool
+	done      chan bool
+	numMsgs   int
+}
+
+func startTestBatchLoop(batchSize int, batchInterval time.Duration, processErr error) *testBatchLoop {
+	loop := &testBatchLoop{
+		msgs:      make(chan *pubsub.Message),
+		acked:     make(chan string, 10),
+		processed: make(chan int, 10),
+		quit:      make(chan bool),
+		done:      make(chan bool),
+	}
+	batcher := newPubSubBatcher(batchSize, batchInterval, func(b *pubSubBatch) error {
+		loop.processed <- b.size()
+		return processErr
+	})
+	toAck := func(msg *pubsub.Message) ackMessage {
+		return &chanAckMessage{id: msg.ID, acked: loop.acked}
+	}
+	go func() {
+		runPubSubBatchLoop(batcher, loop.msgs, toAck, make(chan error), loop.quit,
+			&cerrors.NullErrorReporter{})
+		close(loop.done)
+	}()
+	return loop
+}
+
+func (l *testBatchLoop) send() {
+	l.numMsgs++
+	l.msgs <- &pubsub.Message{ID: fmt.Sprintf("%v", l.numMsgs), Data: []byte("{}")}
+}
+
+func (l *testBatchLoop) expectProcessed(t *testing.T, size int) {
+	select {
+	case numMsgs := <-l.processed:
+		if numMsgs != size {
+			t.Errorf("Should have processed a batch of %v messages: %v", size, numMsgs)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Should have processed a batch of %v messages", size)
+	}
+	for i := 0; i < size; i++ {
+		select {
+		case <-l.acked:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Should have acked %v messages: %v", size, i)
+		}
+	}
+}
+
+func (l *testBatchLoop) expectNotProcessed(t *testing.T, wait time.Duration) {
+	select {
+	case numMsgs := <-l.processed:
+		t.Errorf("Should not have processed a batch yet: %v", numMsgs)
+	case id := <-l.acked:
+		t.Errorf("Should not have acked message %v yet", id)
+	case <-time.After(wait):
+	}
+}
+
+func TestPubSubBatchLoopFullOrWait(t *testing.T) {
+	loop := startTestBatchLoop(3, 200*time.Millisecond, nil)
+
+	// Processed once full without waiting
+	loop.send()
+	loop.send()
+	loop.expectNotProcessed(t, 50*time.Millisecond)
+	loop.send()
+	loop.expectProcessed(t, 3)
+
+	// Processed once the wait is over if not full
+	loop.send()
+	loop.expectNotProcessed(t, 50*time.Millisecond)
+	loop.expectProcessed(t, 1)
+
+	// Buffered messages are not acked when quitting
+	loop.send()
+	close(loop.quit)
+	<-loop.done
+	loop.expectNotProcessed(t, 0)
+}
+
+func TestPubSubBatchLoopNoWait(t *testing.T) {
+	// Without a wait each message is processed on its own
+	loop := startTestBatchLoop(3, 0, nil)
+	loop.send()
+	loop.expectProcessed(t, 1)
+	loop.send()
+	loop.expectProcessed(t, 1)
+	close(loop.quit)
+	<-loop.done
+}
+
+func TestPubSubBatchLoopProcessError(t *testing.T) {
+	loop := startTestBatchLoop(2, time.Minute, errors.New("processing failed"))
+	loop.send()
+	loop.send()
+	select {
+	case <-loop.done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Should have stopped the loop after failed processing")
+	}
+	if len(loop.processed) != 1 {
+		t.Errorf("Should have processed the batch once: %v", len(loop.processed))
+	}
+	if len(loop.acked) != 0 {
+		t.Errorf("Should not have acked messages after failed processing: %v", len(loop.acked))
+	}
+}
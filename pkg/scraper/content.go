@@ -4,13 +4,42 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
 
 	"github.com/joincivil/civil-events-processor/pkg/model"
 	"github.com/joincivil/civil-events-processor/pkg/utils"
 )
 
+const (
+	defaultCharterTimeoutSecs = 3
+	defaultCharterMaxAttempts = 3
+)
+
+// NewCharterIPFSScraper returns a CharterIPFSScraper that retrieves IPFS links
+// from the IPFS gateway at gatewayURL, times out each request after timeout and
+// retries failed requests up to the given number of times.
+// If gatewayURL is empty, the default IPFS gateway is used.
+// If hostFilter is not nil, only URIs allowed by the filter are scraped.
+func NewCharterIPFSScraper(timeout time.Duration, retries int, gatewayURL string,
+	hostFilter *HostFilter) *CharterIPFSScraper {
+	return &CharterIPFSScraper{
+		timeout:     timeout,
+		maxAttempts: retries + 1,
+		gatewayURL:  gatewayURL,
+		hostFilter:  hostFilter,
+	}
+}
+
 // CharterIPFSScraper scrapes content from an IPFS link for a Civil charter.
+// The zero value uses the default timeout, retries and IPFS gateway.
 type CharterIPFSScraper struct {
+	timeout     time.Duration
+	maxAttempts int
+	gatewayURL  string
+	hostFilter  *HostFilter
 }
 
 // ScrapeContent scrapes the IPFS charter content at the given URI and returns it as a
@@ -19,13 +48,24 @@ type CharterIPFSScraper struct {
 // api-server to processor.  For now, just return a generic ScraperContent with payload
 // in data.
 func (c *CharterIPFSScraper) ScrapeContent(uri string) (*model.ScraperContent, error) {
-	bys, err := utils.RetrieveIPFSLink(uri)
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = defaultCharterTimeoutSecs * time.Second
+	}
+	maxAttempts := c.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultCharterMaxAttempts
+	}
+	if c.hostFilter != nil {
+		err := c.hostFilter.CheckURI(uri)
+		if err != nil {
+			return nil, err
+		}
+	}
+	bys, err := retrieveIPFSContent(uri, c.gatewayURL, timeout, maxAttempts)
 	if err != nil {
 		return nil, err
 	}
-	if len(bys) == 0 {
-		return nil, fmt.Errorf("No []byte returned")
-	}
 
 	var data map[string]interface{}
 	err = json.Unmarshal(bys, &data)
@@ -36,13 +76,66 @@ func (c *CharterIPFSScraper) ScrapeContent(uri string) (*model.ScraperContent, e
 	return model.NewScraperContent("", "", uri, "", data), nil
 }
 
+// NewContentScraper returns a ContentScraper that retrieves IPFS links from the
+// IPFS gateway at gatewayURL, times out each request after timeout and retries
+// failed requests up to the given number of times.
+// If gatewayURL is empty, the default IPFS gateway is used.
+func NewContentScraper(timeout time.Duration, retries int, gatewayURL string) *ContentScraper {
+	return &ContentScraper{
+		timeout:     timeout,
+		maxAttempts: retries + 1,
+		gatewayURL:  gatewayURL,
+	}
+}
+
 // ContentScraper is a struct that encapsulates scraping content off the web
 // Used to retrieve and store newsroom content
+// The zero value uses the default timeout, retries and IPFS gateway.
 type ContentScraper struct {
+	timeout     time.Duration
+	maxAttempts int
+	gatewayURL  string
 }
 
 // ScrapeContent scrapes the content at the given URI and returns it as a
-// ScraperContent struct.
+// ScraperContent struct. Only IPFS links are supported, the resolved content
+// is returned as the text of the ScraperContent.
 func (c *ContentScraper) ScrapeContent(uri string) (*model.ScraperContent, error) {
-	return &model.ScraperContent{}, errors.New("Not implemented yet")
+	if !strings.HasPrefix(uri, "ipfs://") {
+		return &model.ScraperContent{}, errors.New("Not implemented yet")
+	}
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = defaultCharterTimeoutSecs * time.Second
+	}
+	maxAttempts := c.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultCharterMaxAttempts
+	}
+	bys, err := retrieveIPFSContent(uri, c.gatewayURL, timeout, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewScraperContent(string(bys), "", uri, "", nil), nil
+}
+
+// retrieveIPFSContent retrieves the content at the IPFS link from the gateway
+// and verifies it matches the CID in the link, if the CID can be checked
+func retrieveIPFSContent(uri string, gatewayURL string, timeout time.Duration,
+	maxAttempts int) ([]byte, error) {
+	bys, err := utils.RetrieveIPFSLinkFromGateway(uri, gatewayURL, timeout, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if len(bys) == 0 {
+		return nil, fmt.Errorf("No []byte returned")
+	}
+	verified, err := utils.VerifyIPFSContent(uri, bys)
+	if err != nil {
+		return nil, fmt.Errorf("Error verifying content from %v: %v", uri, err)
+	}
+	if !verified {
+		log.Infof("Unable to verify content against CID, using unverified content: %v", uri)
+	}
+	return bys, nil
 }
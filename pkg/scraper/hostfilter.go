@@ -0,0 +1,153 @@
+package scraper
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var nonPublicIPNets = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	ipNets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		ipNets[i] = ipNet
+	}
+	return ipNets
+}
+
+// NewHostFilter returns a HostFilter that only allows the given hosts and
+// rejects the blocked hosts. Hosts also match their subdomains. An empty
+// allowed list allows any host that is not blocked.
+func NewHostFilter(allowedHosts []string, blockedHosts []string) *HostFilter {
+	return &HostFilter{
+		allowedHosts: normalizeHosts(allowedHosts),
+		blockedHosts: normalizeHosts(blockedHosts),
+	}
+}
+
+// HostFilter limits the URIs fetched by the scrapers to approved hosts.
+// Private, loopback and link local addresses are always rejected.
+// IPFS URIs are always allowed since they are fetched via the IPFS gateway.
+type HostFilter struct {
+	allowedHosts []string
+	blockedHosts []string
+}
+
+// CheckURI returns an error if the given URI is not allowed to be fetched
+func (f *HostFilter) CheckURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("Invalid URI: %v: %v", uri, err)
+	}
+	switch u.Scheme {
+	case "ipfs":
+		return nil
+	case "http", "https":
+	default:
+		return fmt.Errorf("Scheme not allowed: %v", uri)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("No host in URI: %v", uri)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("Host not allowed: %v", host)
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("Address not allowed: %v", host)
+	}
+	if matchesHost(host, f.blockedHosts) {
+		return fmt.Errorf("Host is blocked: %v", host)
+	}
+	if len(f.allowedHosts) > 0 && !matchesHost(host, f.allowedHosts) {
+		return fmt.Errorf("Host is not allowed: %v", host)
+	}
+	return nil
+}
+
+// Client returns an http.Client with the given timeout that checks each
+// redirect against the filter and refuses to connect to non-public addresses,
+// including hosts that resolve to them.
+func (f *HostFilter) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("Address not allowed: %v", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:       nil,
+			DialContext: dialer.DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("Stopped after 10 redirects")
+			}
+			return f.CheckURI(req.URL.String())
+		},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return false
+	}
+	for _, ipNet := range nonPublicIPNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHosts(hosts []string) []string {
+	normalized := []string{}
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+		if host != "" {
+			normalized = append(normalized, host)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,96 @@
+package scraper_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-events-processor/pkg/scraper"
+)
+
+func TestHostFilterCheckURI(t *testing.T) {
+	filter := scraper.NewHostFilter([]string{"civil.co", "IPFS.infura.io"}, []string{"bad.civil.co"})
+
+	allowed := []string{
+		"https://civil.co/article",
+		"https://www.civil.co/article",
+		"http://CIVIL.CO/article",
+		"https://ipfs.infura.io/ipfs/Qm",
+		"ipfs://zb34W52j4ctZtqo99ko7D64TWbsaF5DzFuw1A7gntSJfFfEwV",
+	}
+	for _, uri := range allowed {
+		if err := filter.CheckURI(uri); err != nil {
+			t.Errorf("Should have allowed %v: err: %v", uri, err)
+		}
+	}
+
+	disallowed := []string{
+		"https://example.com/article",
+		"https://notcivil.co/article",
+		"https://bad.civil.co/article",
+		"https://www.bad.civil.co/article",
+		"file:///etc/passwd",
+		"gopher://civil.co",
+		"https:///article",
+		"http://localhost/article",
+		"http://127.0.0.1/article",
+		"http://10.0.0.1/article",
+		"http://172.16.5.4/article",
+		"http://192.168.1.1/article",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/article",
+		"http://0.0.0.0/article",
+	}
+	for _, uri := range disallowed {
+		if err := filter.CheckURI(uri); err == nil {
+			t.Errorf("Should not have allowed %v", uri)
+		}
+	}
+}
+
+func TestHostFilterNoAllowedHosts(t *testing.T) {
+	filter := scraper.NewHostFilter(nil, []string{"bad.com"})
+	if err := filter.CheckURI("https://example.com/article"); err != nil {
+		t.Errorf("Should have allowed any public host: err: %v", err)
+	}
+	if err := filter.CheckURI("https://8.8.8.8/article"); err != nil {
+		t.Errorf("Should have allowed a public address: err: %v", err)
+	}
+	if err := filter.CheckURI("https://bad.com/article"); err == nil {
+		t.Errorf("Should not have allowed a blocked host")
+	}
+	if err := filter.CheckURI("http://127.0.0.1:8080/article"); err == nil {
+		t.Errorf("Should not have allowed a loopback address")
+	}
+}
+
+func TestCivilMetadataScraperHostFilter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"title": "This is a test post"}`) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	filter := scraper.NewHostFilter([]string{"civil.co"}, nil)
+	_scraper := scraper.NewCivilMetadataScraper(time.Second, 0, filter)
+	_, err := _scraper.ScrapeCivilMetadata(server.URL)
+	if err == nil {
+		t.Errorf("Should not have scraped a loopback address")
+	}
+
+	// The client also rejects non-public addresses on connect, such as hosts
+	// resolving to them
+	filter = scraper.NewHostFilter(nil, nil)
+	client := filter.Client(time.Second)
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close() // nolint: errcheck
+		t.Errorf("Should not have connected to a loopback address")
+	}
+	if requests != 0 {
+		t.Errorf("Should not have made any requests, made %v", requests)
+	}
+}
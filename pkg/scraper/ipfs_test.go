@@ -0,0 +1,85 @@
+package scraper_test
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-events-processor/pkg/scraper"
+)
+
+const (
+	testCharterJSON = `{"name": "The Colorado Sun", "newsroomUrl": "https://coloradosun.com"}`
+)
+
+func testRawCID(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	bys := append([]byte{0x01, 0x55, 0x12, 0x20}, sum[:]...)
+	return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bys))
+}
+
+// stubGateway serves the given content for every CID under /ipfs/
+func stubGateway(t *testing.T, content string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/ipfs/") {
+			t.Errorf("Should have requested an IPFS path, requested %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(content)) // nolint: errcheck
+	}))
+}
+
+func TestCharterIPFSScraperGateway(t *testing.T) {
+	gateway := stubGateway(t, testCharterJSON)
+	defer gateway.Close()
+
+	uri := "ipfs://" + testRawCID(testCharterJSON)
+	_scraper := scraper.NewCharterIPFSScraper(time.Second, 0, gateway.URL, nil)
+	content, err := _scraper.ScrapeContent(uri)
+	if err != nil {
+		t.Fatalf("Should not have gotten error scraping IPFS data: err: %v", err)
+	}
+	if content.URI() != uri {
+		t.Errorf("Should have gotten the same link in the content")
+	}
+	if content.Data()["newsroomUrl"] != "https://coloradosun.com" {
+		t.Errorf("Should have matched the newsroom URLs: %v", content.Data()["newsroomUrl"])
+	}
+}
+
+func TestCharterIPFSScraperGatewayMismatch(t *testing.T) {
+	gateway := stubGateway(t, `{"name": "Not The Colorado Sun"}`)
+	defer gateway.Close()
+
+	uri := "ipfs://" + testRawCID(testCharterJSON)
+	_scraper := scraper.NewCharterIPFSScraper(time.Second, 0, gateway.URL, nil)
+	_, err := _scraper.ScrapeContent(uri)
+	if err == nil {
+		t.Errorf("Should have failed to scrape content not matching the CID")
+	}
+}
+
+func TestContentScraperGateway(t *testing.T) {
+	gateway := stubGateway(t, "hello world\n")
+	defer gateway.Close()
+
+	uri := "ipfs://QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"
+	_scraper := scraper.NewContentScraper(time.Second, 0, gateway.URL+"/")
+	content, err := _scraper.ScrapeContent(uri)
+	if err != nil {
+		t.Fatalf("Should not have gotten error scraping IPFS content: err: %v", err)
+	}
+	if content.Text() != "hello world\n" {
+		t.Errorf("Should have stored the resolved content, have %v", content.Text())
+	}
+
+	_, err = _scraper.ScrapeContent("https://civil.co")
+	if err == nil {
+		t.Errorf("Should have failed to scrape a non IPFS link")
+	}
+}
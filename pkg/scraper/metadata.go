@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	log "github.com/golang/glog"
+
 	"github.com/joincivil/civil-events-processor/pkg/model"
 )
 
@@ -12,21 +14,66 @@ const (
 	timeoutSecs = 2
 )
 
+// NewCivilMetadataScraper returns a CivilMetadataScraper that times out each
+// request after timeout and retries failed requests up to the given number of times.
+// If hostFilter is not nil, only URIs allowed by the filter are scraped.
+func NewCivilMetadataScraper(timeout time.Duration, retries int,
+	hostFilter *HostFilter) *CivilMetadataScraper {
+	return &CivilMetadataScraper{
+		timeout:    timeout,
+		retries:    retries,
+		hostFilter: hostFilter,
+	}
+}
+
 // CivilMetadataScraper is a struct that encapsulates scraping the Civil article content API
 // metadata. Implements the CivilMetadataScraper interface.
-type CivilMetadataScraper struct{}
+// The zero value uses the default timeout and does not retry.
+type CivilMetadataScraper struct {
+	timeout    time.Duration
+	retries    int
+	hostFilter *HostFilter
+}
 
 // ScrapeCivilMetadata scrapes the metadata from the Civil article content API at
 // the given URI.
 func (m *CivilMetadataScraper) ScrapeCivilMetadata(uri string) (*model.ScraperCivilMetadata, error) {
-	timeout := timeoutSecs * time.Second
-	client := http.Client{
+	timeout := m.timeout
+	if timeout <= 0 {
+		timeout = timeoutSecs * time.Second
+	}
+	client := &http.Client{
 		Timeout: timeout,
 	}
+	if m.hostFilter != nil {
+		err := m.hostFilter.CheckURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		client = m.hostFilter.Client(timeout)
+	}
+
+	var metadata *model.ScraperCivilMetadata
+	var err error
+	for attempt := 0; attempt <= m.retries; attempt++ {
+		metadata, err = m.scrapeCivilMetadata(client, uri)
+		if err == nil {
+			return metadata, nil
+		}
+		if attempt < m.retries {
+			log.Infof("Retrying civil metadata scrape of %v: err: %v", uri, err)
+		}
+	}
+	return nil, err
+}
+
+func (m *CivilMetadataScraper) scrapeCivilMetadata(client *http.Client, uri string) (
+	*model.ScraperCivilMetadata, error) {
 	resp, err := client.Get(uri)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close() // nolint: errcheck
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -0,0 +1,55 @@
+package scraper_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-events-processor/pkg/scraper"
+)
+
+func TestCivilMetadataScraperTimeoutRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, `{"title": "slow"}`) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	_scraper := scraper.NewCivilMetadataScraper(20*time.Millisecond, 2, nil)
+	_, err := _scraper.ScrapeCivilMetadata(server.URL)
+	if err == nil {
+		t.Errorf("Should have timed out scraping metadata")
+	}
+	if atomic.LoadInt32(&requests) != 3 {
+		t.Errorf("Should have made 3 requests, made %v", atomic.LoadInt32(&requests))
+	}
+}
+
+func TestCivilMetadataScraperRetrySucceeds(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first request by timing out
+		if atomic.AddInt32(&requests, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"title": "This is a test post"}`) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	_scraper := scraper.NewCivilMetadataScraper(50*time.Millisecond, 1, nil)
+	metadata, err := _scraper.ScrapeCivilMetadata(server.URL)
+	if err != nil {
+		t.Fatalf("Should have scraped metadata on retry: err: %v", err)
+	}
+	if metadata.Title() != "This is a test post" {
+		t.Errorf("Should have scraped the title, have %v", metadata.Title())
+	}
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Should have made 2 requests, made %v", atomic.LoadInt32(&requests))
+	}
+}
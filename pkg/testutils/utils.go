@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	cpersist "github.com/joincivil/go-common/pkg/persistence"
@@ -17,11 +20,14 @@ const (
 	testCivilMetadata = `{"title":"This is a test post","revisionContentHash":"0x9e4acfe532c8458abfc1f1d30c4eaf986fee52cf1f65c9548f1dc437fb6dfd38","revisionContentUrl":"https:\/\/civil-develop.go-vip.co\/crawler-pod\/wp-json\/civil-newsroom-protocol\/v1\/revisions-content\/0x9e4acfe532c8458abfc1f1d30c4eaf986fee52cf1f65c9548f1dc437fb6dfd38\/","canonicalUrl":"https:\/\/civil-develop.go-vip.co\/crawler-pod\/2018\/07\/25\/this-is-a-test-post\/","slug":"this-is-a-test-post","description":"I'm being described","authors":[{"byline":"Walker Flynn"}],"images":[{"url":"https:\/\/civil-develop.go-vip.co\/crawler-pod\/wp-content\/uploads\/sites\/20\/2018\/07\/Messages-Image3453599984.png","hash":"0x72ca80ed96a2b1ca20bf758a2142a678c0bc316e597161d0572af378e52b2e80","h":960,"w":697}],"tags":["news"],"primaryTag":"news","revisionDate":"2018-07-25 17:17:20","originalPublishDate":"2018-07-25 17:17:07","credibilityIndicators":{"original_reporting":"1","on_the_ground":false,"sources_cited":"1","subject_specialist":false},"opinion":false,"civilSchemaVersion":"1.0.0"}`
 )
 
-//TestPersister is a test persister
+// TestPersister is an in-memory persister backed by maps. It implements the
+// model persister interfaces so unit tests and local dev can run the processor
+// without Postgres.
 type TestPersister struct {
 	Listings             map[string]*model.Listing
 	ListingsByURL        map[string]*model.Listing
 	ListingsByOwnerAddr  map[string][]*model.Listing
+	ListingStateChanges  map[string][]*model.ListingStateChange
 	Revisions            map[string][]*model.ContentRevision
 	GovEvents            map[string][]*model.GovernanceEvent
 	Challenges           map[int]*model.Challenge
@@ -31,6 +37,9 @@ type TestPersister struct {
 	TokenTransfersTxHash map[string][]*model.TokenTransfer
 	ParameterProposal    map[[32]byte]*model.ParameterProposal
 	Parameter            map[string]*model.Parameter
+	ParameterChanges     map[string][]*model.ParameterChange
+	GovParameterProposal map[[32]byte]*model.GovernmentParameterProposal
+	GovParameter         map[string]*model.GovernmentParameter
 	UserChallengeData    map[int]map[string]*model.UserChallengeData
 	Timestamp            int64
 	EventHashes          []string
@@ -53,15 +62,70 @@ func (t *TestPersister) Close() error {
 
 // ListingsByCriteria returns a slice of Listings based on ListingCriteria
 func (t *TestPersister) ListingsByCriteria(criteria *model.ListingCriteria) ([]*model.Listing, error) {
-	listings := make([]*model.Listing, len(t.Listings))
-	index := 0
+	listings := make([]*model.Listing, 0, len(t.Listings))
 	for _, listing := range t.Listings {
-		listings[index] = listing
-		index++
+		if listing.Removed() && !criteria.IncludeRemoved {
+			continue
+		}
+		listings = append(listings, listing)
 	}
 	return listings, nil
 }
 
+// ListingStats returns the number of listings that applied, were whitelisted,
+// rejected or withdrawn between the given timestamps
+func (t *TestPersister) ListingStats(fromTs int64, beforeTs int64) (*model.ListingStatsResult, error) {
+	inWindow := func(ts int64) bool {
+		return ts >= fromTs && ts < beforeTs
+	}
+	stats := &model.ListingStatsResult{}
+	for _, listing := range t.Listings {
+		withdrawn := listing.LastGovernanceState() == model.GovernanceStateListingWithdrawn
+		if inWindow(listing.ApplicationDateTs()) {
+			stats.Applications++
+		}
+		if listing.Whitelisted() && inWindow(listing.ApprovalDateTs()) {
+			stats.Whitelisted++
+		}
+		if !inWindow(listing.LastUpdatedDateTs()) {
+			continue
+		}
+		if withdrawn {
+			stats.Withdrawn++
+		} else if !listing.Whitelisted() && listing.ChallengeID() != nil &&
+			listing.ChallengeID().Int64() == 0 {
+			stats.Rejected++
+		}
+	}
+	return stats, nil
+}
+
+// ListingGovernanceState returns the listing with its active challenge, poll and appeal
+func (t *TestPersister) ListingGovernanceState(address common.Address) (*model.ListingGovernance, error) {
+	listing, err := t.ListingByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	governance := &model.ListingGovernance{Listing: listing}
+	if listing.ChallengeID() == nil || listing.ChallengeID().Int64() <= 0 {
+		return governance, nil
+	}
+	challengeID := int(listing.ChallengeID().Int64())
+	governance.Challenge = t.Challenges[challengeID]
+	governance.Poll = t.Polls[challengeID]
+	governance.Appeal = t.Appeals[challengeID]
+	return governance, nil
+}
+
+// ListingsByCriteriaIter returns an iterator over listings based on ListingCriteria
+func (t *TestPersister) ListingsByCriteriaIter(criteria *model.ListingCriteria) (model.ListingIterator, error) {
+	listings, err := t.ListingsByCriteria(criteria)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewListingSliceIterator(listings), nil
+}
+
 // ListingsByAddresses returns a slice of Listings based on addresses
 func (t *TestPersister) ListingsByAddresses(addresses []common.Address) ([]*model.Listing, error) {
 	results := []*model.Listing{}
@@ -79,6 +143,28 @@ func (t *TestPersister) ListingsByOwnerAddress(address common.Address) ([]*model
 	return t.ListingsByOwnerAddr[address.String()], nil
 }
 
+// ListingsWithRecentGovernanceEvent returns the listings with a governance
+// event of the given type created at or after the given timestamp
+func (t *TestPersister) ListingsWithRecentGovernanceEvent(eventType string, sinceTs int64) ([]*model.Listing, error) {
+	listings := []*model.Listing{}
+	for addressHex, events := range t.GovEvents {
+		listing, ok := t.Listings[addressHex]
+		if !ok {
+			continue
+		}
+		for _, event := range events {
+			if event.GovernanceEventType() == eventType && event.CreationDateTs() >= sinceTs {
+				listings = append(listings, listing)
+				break
+			}
+		}
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].CreatedDateTs() < listings[j].CreatedDateTs()
+	})
+	return listings, nil
+}
+
 // ListingByAddress retrieves a listing based on address
 func (t *TestPersister) ListingByAddress(address common.Address) (*model.Listing, error) {
 	listing := t.Listings[address.Hex()]
@@ -141,6 +227,31 @@ func (t *TestPersister) AllListingAddresses() ([]string, error) {
 	return keys, nil
 }
 
+// CreateListingStateChange records a change to the state of a listing, skipping
+// a change already recorded for the event
+func (t *TestPersister) CreateListingStateChange(change *model.ListingStateChange) error {
+	if t.ListingStateChanges == nil {
+		t.ListingStateChanges = map[string][]*model.ListingStateChange{}
+	}
+	addressHex := change.ListingAddress().Hex()
+	for _, existing := range t.ListingStateChanges[addressHex] {
+		if existing.EventHash() == change.EventHash() {
+			return nil
+		}
+	}
+	t.ListingStateChanges[addressHex] = append(t.ListingStateChanges[addressHex], change)
+	return nil
+}
+
+// ListingStateHistory returns the state changes for the listing at the given address
+func (t *TestPersister) ListingStateHistory(address common.Address) ([]*model.ListingStateChange, error) {
+	changes, ok := t.ListingStateChanges[address.Hex()]
+	if !ok {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return changes, nil
+}
+
 // DeleteListing removes a listing
 func (t *TestPersister) DeleteListing(listing *model.Listing) error {
 	addressHex := listing.ContractAddress().Hex()
@@ -155,6 +266,17 @@ func (t *TestPersister) DeleteListing(listing *model.Listing) error {
 	return nil
 }
 
+// MarkListingRemoved marks a listing as removed without deleting it
+func (t *TestPersister) MarkListingRemoved(addr common.Address) error {
+	listing, err := t.ListingByAddress(addr)
+	if err != nil {
+		return err
+	}
+	listing.SetRemoved(true)
+	listing.SetLastUpdatedDateTs(time.Now().Unix())
+	return nil
+}
+
 // ContentRevisionsByCriteria retrieves content revisions by ContentRevisionCriteria
 func (t *TestPersister) ContentRevisionsByCriteria(criteria *model.ContentRevisionCriteria) (
 	[]*model.ContentRevision, error) {
@@ -173,7 +295,7 @@ func (t *TestPersister) ContentRevisions(address common.Address,
 	addressHex := address.Hex()
 	addrRevs, ok := t.Revisions[addressHex]
 	if !ok {
-		return []*model.ContentRevision{}, nil
+		return nil, cpersist.ErrPersisterNoResults
 	}
 	contentRevisions := []*model.ContentRevision{}
 	for _, rev := range addrRevs {
@@ -181,10 +303,84 @@ func (t *TestPersister) ContentRevisions(address common.Address,
 			contentRevisions = append(contentRevisions, rev)
 		}
 	}
-
+	if len(contentRevisions) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
 	return contentRevisions, nil
 }
 
+// ContentRevisionCount returns the number of distinct content items on a listing
+func (t *TestPersister) ContentRevisionCount(address common.Address) (int, error) {
+	contentIDs := map[string]bool{}
+	for _, rev := range t.Revisions[address.Hex()] {
+		contentIDs[rev.ContractContentID().String()] = true
+	}
+	return len(contentIDs), nil
+}
+
+// LatestRevisionsByListing returns the newest revision of each content item on a listing
+func (t *TestPersister) LatestRevisionsByListing(address common.Address) ([]*model.ContentRevision, error) {
+	latest := map[string]*model.ContentRevision{}
+	for _, rev := range t.Revisions[address.Hex()] {
+		contentID := rev.ContractContentID().String()
+		existing, ok := latest[contentID]
+		if !ok || rev.RevisionDateTs() > existing.RevisionDateTs() {
+			latest[contentID] = rev
+		}
+	}
+	revisions := make([]*model.ContentRevision, 0, len(latest))
+	for _, rev := range latest {
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].ContractContentID().Cmp(revisions[j].ContractContentID()) < 0
+	})
+	return revisions, nil
+}
+
+// ContentRevisionsModifiedAfter returns up to limit revisions across all
+// listings with a revision timestamp after ts, including any revisions that
+// share the timestamp of the last one
+func (t *TestPersister) ContentRevisionsModifiedAfter(ts int64, limit int) (
+	[]*model.ContentRevision, error) {
+	revisions := []*model.ContentRevision{}
+	for _, listingRevs := range t.Revisions {
+		for _, rev := range listingRevs {
+			if rev.RevisionDateTs() > ts {
+				revisions = append(revisions, rev)
+			}
+		}
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].RevisionDateTs() < revisions[j].RevisionDateTs()
+	})
+	if limit <= 0 || len(revisions) <= limit {
+		return revisions, nil
+	}
+	end := limit
+	for end < len(revisions) && revisions[end].RevisionDateTs() == revisions[limit-1].RevisionDateTs() {
+		end++
+	}
+	return revisions[:end], nil
+}
+
+// MostRecentContentRevision returns the revision with the newest revision
+// timestamp across all listings
+func (t *TestPersister) MostRecentContentRevision() (*model.ContentRevision, error) {
+	var mostRecent *model.ContentRevision
+	for _, listingRevs := range t.Revisions {
+		for _, rev := range listingRevs {
+			if mostRecent == nil || rev.RevisionDateTs() > mostRecent.RevisionDateTs() {
+				mostRecent = rev
+			}
+		}
+	}
+	if mostRecent == nil {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return mostRecent, nil
+}
+
 // ContentRevision retrieves content revisions
 func (t *TestPersister) ContentRevision(address common.Address, contentID *big.Int,
 	revisionID *big.Int) (*model.ContentRevision, error) {
@@ -200,6 +396,40 @@ func (t *TestPersister) ContentRevision(address common.Address, contentID *big.I
 	return nil, nil
 }
 
+// ContentRevisionByHash retrieves the earliest content revision with the given hash
+func (t *TestPersister) ContentRevisionByHash(hash string) (*model.ContentRevision, error) {
+	revisions, err := t.ContentRevisionsByHashes([]string{hash})
+	if err != nil {
+		return nil, err
+	}
+	if revisions[0] == nil {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return revisions[0], nil
+}
+
+// ContentRevisionsByHashes retrieves the earliest content revision for each
+// hash, in the order of the hashes
+func (t *TestPersister) ContentRevisionsByHashes(hashes []string) ([]*model.ContentRevision, error) {
+	if len(hashes) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	revisionsMap := map[string]*model.ContentRevision{}
+	for _, addrRevs := range t.Revisions {
+		for _, rev := range addrRevs {
+			existing, ok := revisionsMap[rev.PayloadHash()]
+			if !ok || rev.RevisionDateTs() < existing.RevisionDateTs() {
+				revisionsMap[rev.PayloadHash()] = rev
+			}
+		}
+	}
+	revisions := make([]*model.ContentRevision, len(hashes))
+	for i, hash := range hashes {
+		revisions[i] = revisionsMap[hash]
+	}
+	return revisions, nil
+}
+
 // CreateContentRevision creates a new content item
 func (t *TestPersister) CreateContentRevision(revision *model.ContentRevision) error {
 	addressHex := revision.ListingAddress().Hex()
@@ -232,6 +462,36 @@ func (t *TestPersister) UpdateContentRevision(revision *model.ContentRevision, u
 	return nil
 }
 
+// ContentRevisionExists returns true if there is a revision for the listing
+// content ID and revision ID
+func (t *TestPersister) ContentRevisionExists(address common.Address, contentID *big.Int,
+	revisionID *big.Int) (bool, error) {
+	return t.contentRevisionIndex(address, contentID, revisionID) >= 0, nil
+}
+
+// UpsertContentRevision creates a new content revision or replaces the existing
+// revision for the same listing content ID and revision ID
+func (t *TestPersister) UpsertContentRevision(revision *model.ContentRevision) error {
+	index := t.contentRevisionIndex(revision.ListingAddress(), revision.ContractContentID(),
+		revision.ContractRevisionID())
+	if index < 0 {
+		return t.CreateContentRevision(revision)
+	}
+	t.Revisions[revision.ListingAddress().Hex()][index] = revision
+	return nil
+}
+
+func (t *TestPersister) contentRevisionIndex(address common.Address, contentID *big.Int,
+	revisionID *big.Int) int {
+	for index, rev := range t.Revisions[address.Hex()] {
+		if rev.ContractContentID().Cmp(contentID) == 0 &&
+			rev.ContractRevisionID().Cmp(revisionID) == 0 {
+			return index
+		}
+	}
+	return -1
+}
+
 // DeleteContentRevision removes a content item
 func (t *TestPersister) DeleteContentRevision(revision *model.ContentRevision) error {
 	contentRevisions, err := t.ContentRevisions(
@@ -255,6 +515,9 @@ func (t *TestPersister) DeleteContentRevision(revision *model.ContentRevision) e
 // GovernanceEventsByCriteria retrieves content revisions by GovernanceEventCriteria
 func (t *TestPersister) GovernanceEventsByCriteria(criteria *model.GovernanceEventCriteria) (
 	[]*model.GovernanceEvent, error) {
+	if criteria.ChallengeID != nil {
+		return t.governanceEventsByChallengeIDMetadata(*criteria.ChallengeID), nil
+	}
 	// This is more of a placeholder
 	events := make([]*model.GovernanceEvent, len(t.GovEvents))
 	index := 0
@@ -265,6 +528,39 @@ func (t *TestPersister) GovernanceEventsByCriteria(criteria *model.GovernanceEve
 	return events, nil
 }
 
+func (t *TestPersister) governanceEventsByChallengeIDMetadata(challengeID int) []*model.GovernanceEvent {
+	events := []*model.GovernanceEvent{}
+	for _, listingEvents := range t.GovEvents {
+		for _, event := range listingEvents {
+			val, ok := event.Metadata()["ChallengeID"]
+			if ok && fmt.Sprint(val) == strconv.Itoa(challengeID) {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreationDateTs() < events[j].CreationDateTs()
+	})
+	return events
+}
+
+// GovernanceEventsByCursor retrieves governance events by GovernanceEventCriteria.
+// All events are returned in a single page.
+func (t *TestPersister) GovernanceEventsByCursor(criteria *model.GovernanceEventCriteria) (
+	[]*model.GovernanceEvent, string, error) {
+	events, err := t.GovernanceEventsByCriteria(criteria)
+	return events, "", err
+}
+
+// CountGovernanceEventsByCriteria returns the number of governance events matching the criteria
+func (t *TestPersister) CountGovernanceEventsByCriteria(criteria *model.GovernanceEventCriteria) (int, error) {
+	events, err := t.GovernanceEventsByCriteria(criteria)
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
 // GovernanceEventByChallengeID retrieves challenge by challengeID
 func (t *TestPersister) GovernanceEventByChallengeID(challengeID int) (*model.GovernanceEvent, error) {
 	// NOTE(IS): Placeholder for now
@@ -286,10 +582,27 @@ func (t *TestPersister) GovernanceEventsByTxHash(txHash common.Hash) ([]*model.G
 	return govEvents, nil
 }
 
+// GovernanceEventBySourceEvent gets the governance event processed from the
+// crawler event with the given tx hash and log index
+func (t *TestPersister) GovernanceEventBySourceEvent(txHash common.Hash, logIndex uint) (*model.GovernanceEvent, error) {
+	for _, listingEvents := range t.GovEvents {
+		for _, event := range listingEvents {
+			blockData := event.BlockData()
+			if blockData.TxHash() == txHash.Hex() && blockData.Index() == logIndex {
+				return event, nil
+			}
+		}
+	}
+	return nil, cpersist.ErrPersisterNoResults
+}
+
 // GovernanceEventsByListingAddress retrieves governance events based on criteria
 func (t *TestPersister) GovernanceEventsByListingAddress(address common.Address) ([]*model.GovernanceEvent, error) {
 	addressHex := address.Hex()
 	govEvents := t.GovEvents[addressHex]
+	if len(govEvents) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
 	return govEvents, nil
 }
 
@@ -307,6 +620,35 @@ func (t *TestPersister) CreateGovernanceEvent(govEvent *model.GovernanceEvent) e
 	return nil
 }
 
+// CreateGovernanceEvents creates new governance events
+func (t *TestPersister) CreateGovernanceEvents(govEvents []*model.GovernanceEvent) error {
+	for _, govEvent := range govEvents {
+		err := model.ValidateMetadata(govEvent.GovernanceEventType(), govEvent.Metadata())
+		if err != nil {
+			return err
+		}
+	}
+	for _, govEvent := range govEvents {
+		err := t.CreateGovernanceEvent(govEvent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertGovernanceEvent creates a new governance event or updates an existing one
+func (t *TestPersister) UpsertGovernanceEvent(govEvent *model.GovernanceEvent) error {
+	addressHex := govEvent.ListingAddress().Hex()
+	for index, event := range t.GovEvents[addressHex] {
+		if event.EventHash() == govEvent.EventHash() {
+			t.GovEvents[addressHex][index] = govEvent
+			return nil
+		}
+	}
+	return t.CreateGovernanceEvent(govEvent)
+}
+
 // UpdateGovernanceEvent updates fields on an existing governance event
 func (t *TestPersister) UpdateGovernanceEvent(govEvent *model.GovernanceEvent, updatedFields []string) error {
 	addressHex := govEvent.ListingAddress().Hex()
@@ -352,6 +694,14 @@ func (t *TestPersister) ChallengeByChallengeID(challengeID int) (*model.Challeng
 	return challenge, nil
 }
 
+// ChallengeByPollID gets the challenge associated with the given pollID
+func (t *TestPersister) ChallengeByPollID(pollID int) (*model.Challenge, error) {
+	if t.Polls[pollID] == nil {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return t.ChallengeByChallengeID(pollID)
+}
+
 // ChallengesByChallengeIDs returns a slice of challenges based on challenge IDs
 func (t *TestPersister) ChallengesByChallengeIDs(challengeIDs []int) ([]*model.Challenge, error) {
 	results := []*model.Challenge{}
@@ -375,6 +725,41 @@ func (t *TestPersister) ChallengesByChallengerAddress(addr common.Address) ([]*m
 	return results, nil
 }
 
+// ResolvedChallengesByTimeRange returns the resolved challenges last updated
+// between fromTs and beforeTs inclusive, sorted by timestamp
+func (t *TestPersister) ResolvedChallengesByTimeRange(fromTs int64,
+	beforeTs int64) ([]*model.Challenge, error) {
+	results := []*model.Challenge{}
+	for _, challenge := range t.Challenges {
+		ts := challenge.LastUpdatedDateTs()
+		if challenge.Resolved() && ts >= fromTs && ts <= beforeTs {
+			results = append(results, challenge)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].LastUpdatedDateTs() == results[j].LastUpdatedDateTs() {
+			return results[i].ChallengeID().Cmp(results[j].ChallengeID()) < 0
+		}
+		return results[i].LastUpdatedDateTs() < results[j].LastUpdatedDateTs()
+	})
+	return results, nil
+}
+
+// OrphanedChallenges returns the challenges for listing addresses that have
+// no listing, sorted by challenge id
+func (t *TestPersister) OrphanedChallenges() ([]*model.Challenge, error) {
+	results := []*model.Challenge{}
+	for _, challenge := range t.Challenges {
+		if t.Listings[challenge.ListingAddress().Hex()] == nil {
+			results = append(results, challenge)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ChallengeID().Cmp(results[j].ChallengeID()) < 0
+	})
+	return results, nil
+}
+
 // ChallengesByListingAddress gets a list of challenges by listing
 func (t *TestPersister) ChallengesByListingAddress(addr common.Address) ([]*model.Challenge, error) {
 	challenges := []*model.Challenge{}
@@ -429,6 +814,14 @@ func (t *TestPersister) PollByPollID(pollID int) (*model.Poll, error) {
 	return poll, nil
 }
 
+// PollByChallengeID gets the poll associated with the given challengeID
+func (t *TestPersister) PollByChallengeID(challengeID int) (*model.Poll, error) {
+	if t.Challenges[challengeID] == nil {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return t.PollByPollID(challengeID)
+}
+
 // PollsByPollIDs returns a slice of polls based on poll IDs
 func (t *TestPersister) PollsByPollIDs(pollIDs []int) ([]*model.Poll, error) {
 	results := []*model.Poll{}
@@ -441,6 +834,18 @@ func (t *TestPersister) PollsByPollIDs(pollIDs []int) ([]*model.Poll, error) {
 	return results, nil
 }
 
+// PollsByPollIDsMap returns a map of found polls keyed by poll ID
+func (t *TestPersister) PollsByPollIDsMap(pollIDs []int) (map[int]*model.Poll, error) {
+	results := map[int]*model.Poll{}
+	for _, pollID := range pollIDs {
+		poll, err := t.PollByPollID(pollID)
+		if err == nil {
+			results[pollID] = poll
+		}
+	}
+	return results, nil
+}
+
 // CreatePoll creates a new poll
 func (t *TestPersister) CreatePoll(poll *model.Poll) error {
 	pollID := int(poll.PollID().Int64())
@@ -461,6 +866,11 @@ func (t *TestPersister) UpdatePoll(poll *model.Poll, updatedFields []string) err
 	return nil
 }
 
+// UpsertPoll creates a new poll or updates an existing poll
+func (t *TestPersister) UpsertPoll(poll *model.Poll) error {
+	return t.CreatePoll(poll)
+}
+
 // AppealByChallengeID gets an appeal by challengeID
 func (t *TestPersister) AppealByChallengeID(challengeID int) (*model.Appeal, error) {
 	appeal := t.Appeals[challengeID]
@@ -480,6 +890,22 @@ func (t *TestPersister) AppealByAppealChallengeID(challengeID int) (*model.Appea
 	return nil, cpersist.ErrPersisterNoResults
 }
 
+// AppealsByAppealChallengeIDs returns a slice of appeals in order based on
+// appeal challenge IDs, with nils for missing appeals
+func (t *TestPersister) AppealsByAppealChallengeIDs(appealChallengeIDs []int) ([]*model.Appeal, error) {
+	results := make([]*model.Appeal, len(appealChallengeIDs))
+	for i, appealChallengeID := range appealChallengeIDs {
+		for _, appeal := range t.Appeals {
+			if appeal.AppealChallengeID() != nil &&
+				int(appeal.AppealChallengeID().Int64()) == appealChallengeID {
+				results[i] = appeal
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
 // AppealsByChallengeIDs returns a slice of appeals based on challenge IDs
 func (t *TestPersister) AppealsByChallengeIDs(challengeIDs []int) ([]*model.Appeal, error) {
 	results := []*model.Appeal{}
@@ -492,6 +918,19 @@ func (t *TestPersister) AppealsByChallengeIDs(challengeIDs []int) ([]*model.Appe
 	return results, nil
 }
 
+// AppealWithChallenge gets an appeal by challengeID with its challenge
+func (t *TestPersister) AppealWithChallenge(challengeID int) (*model.AppealWithChallenge, error) {
+	appeal, err := t.AppealByChallengeID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	challenge := t.Challenges[challengeID]
+	if challenge == nil {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return &model.AppealWithChallenge{Appeal: appeal, Challenge: challenge}, nil
+}
+
 // CreateAppeal creates a new appeal
 func (t *TestPersister) CreateAppeal(appeal *model.Appeal) error {
 	challengeID := int(appeal.OriginalChallengeID().Int64())
@@ -530,7 +969,21 @@ func (t *TestPersister) EventHashesOfLastTimestampForCron() ([]string, error) {
 
 // UpdateEventHashesForCron updates the eventHashes saved in cron table
 func (t *TestPersister) UpdateEventHashesForCron(eventHashes []string) error {
-	t.EventHashes = eventHashes
+	seen := map[string]bool{}
+	t.EventHashes = []string{}
+	for _, hash := range eventHashes {
+		if hash != "" && !seen[hash] {
+			seen[hash] = true
+			t.EventHashes = append(t.EventHashes, hash)
+		}
+	}
+	return nil
+}
+
+// SetCronTimestamp sets the timestamp and clears the event hashes for the cron
+func (t *TestPersister) SetCronTimestamp(timestamp int64) error {
+	t.Timestamp = timestamp
+	t.EventHashes = []string{}
 	return nil
 }
 
@@ -554,6 +1007,110 @@ func (t *TestPersister) TokenTransfersByToAddress(addr common.Address) (
 	return purchases, nil
 }
 
+// TokenTransfersByBlockRange gets a list of token transfers between the given blocks
+func (t *TestPersister) TokenTransfersByBlockRange(fromBlock uint64, toBlock uint64) (
+	[]*model.TokenTransfer, error) {
+	purchases := []*model.TokenTransfer{}
+	for _, txPurchases := range t.TokenTransfersTxHash {
+		for _, purchase := range txPurchases {
+			blockData := purchase.BlockData()
+			if blockData.BlockNumber() >= fromBlock && blockData.BlockNumber() <= toBlock {
+				purchases = append(purchases, purchase)
+			}
+		}
+	}
+	if len(purchases) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	sort.Slice(purchases, func(i, j int) bool {
+		blockDataI := purchases[i].BlockData()
+		blockDataJ := purchases[j].BlockData()
+		if blockDataI.BlockNumber() != blockDataJ.BlockNumber() {
+			return blockDataI.BlockNumber() < blockDataJ.BlockNumber()
+		}
+		return blockDataI.Index() < blockDataJ.Index()
+	})
+	return purchases, nil
+}
+
+// TokenTransfersByCriteriaIter returns an iterator over token transfers by
+// TokenTransferCriteria sorted by transfer date
+func (t *TestPersister) TokenTransfersByCriteriaIter(criteria *model.TokenTransferCriteria) (
+	model.TokenTransferIterator, error) {
+	purchases := []*model.TokenTransfer{}
+	for _, txPurchases := range t.TokenTransfersTxHash {
+		for _, purchase := range txPurchases {
+			if criteria.FromAddress != "" && purchase.FromAddress().Hex() != criteria.FromAddress {
+				continue
+			}
+			if criteria.ToAddress != "" && purchase.ToAddress().Hex() != criteria.ToAddress {
+				continue
+			}
+			if criteria.FromTs > 0 && purchase.TransferDate() < criteria.FromTs {
+				continue
+			}
+			if criteria.BeforeTs > 0 && purchase.TransferDate() >= criteria.BeforeTs {
+				continue
+			}
+			purchases = append(purchases, purchase)
+		}
+	}
+	sort.Slice(purchases, func(i, j int) bool {
+		if purchases[i].TransferDate() != purchases[j].TransferDate() {
+			return purchases[i].TransferDate() < purchases[j].TransferDate()
+		}
+		blockDataI := purchases[i].BlockData()
+		blockDataJ := purchases[j].BlockData()
+		if blockDataI.BlockNumber() != blockDataJ.BlockNumber() {
+			return blockDataI.BlockNumber() < blockDataJ.BlockNumber()
+		}
+		return blockDataI.Index() < blockDataJ.Index()
+	})
+	if criteria.Offset > 0 {
+		if criteria.Offset >= len(purchases) {
+			purchases = []*model.TokenTransfer{}
+		} else {
+			purchases = purchases[criteria.Offset:]
+		}
+	}
+	if criteria.Count > 0 && criteria.Count < len(purchases) {
+		purchases = purchases[:criteria.Count]
+	}
+	return model.NewTokenTransferSliceIterator(purchases), nil
+}
+
+// DailyTokenTransferTotals gets the token transfer totals per UTC day between the given timestamps
+func (t *TestPersister) DailyTokenTransferTotals(fromTs int64, beforeTs int64) (
+	[]*model.DayTotal, error) {
+	totalsByDay := map[int64]*model.DayTotal{}
+	for _, txPurchases := range t.TokenTransfersTxHash {
+		for _, purchase := range txPurchases {
+			if purchase.TransferDate() < fromTs || purchase.TransferDate() >= beforeTs {
+				continue
+			}
+			day := time.Unix(purchase.TransferDate(), 0).UTC().Truncate(24 * time.Hour)
+			total, ok := totalsByDay[day.Unix()]
+			if !ok {
+				total = &model.DayTotal{Day: day, Total: big.NewInt(0)}
+				totalsByDay[day.Unix()] = total
+			}
+			total.Total.Add(total.Total, purchase.Amount())
+			total.Count++
+		}
+	}
+	if len(totalsByDay) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	totals := make([]*model.DayTotal, 0, len(totalsByDay))
+	for _, total := range totalsByDay {
+		totals = append(totals, total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Day.Before(totals[j].Day)
+	})
+	return totals, nil
+}
+
 // CreateTokenTransfer creates a new token transfer
 func (t *TestPersister) CreateTokenTransfer(purchase *model.TokenTransfer) error {
 	addr := purchase.ToAddress().Hex()
@@ -643,6 +1200,33 @@ func (t *TestPersister) ParametersByName(names []string) ([]*model.Parameter, er
 	return results, nil
 }
 
+// ParametersByNameMap returns a map of parameters with given names keyed by name
+func (t *TestPersister) ParametersByNameMap(names []string) (map[string]*model.Parameter, error) {
+	results := map[string]*model.Parameter{}
+	for _, paramName := range names {
+		parameter, ok := t.Parameter[paramName]
+		if ok {
+			results[paramName] = parameter
+		}
+	}
+	return results, nil
+}
+
+// AllParameters returns all parameters ordered by name
+func (t *TestPersister) AllParameters() ([]*model.Parameter, error) {
+	if len(t.Parameter) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	results := []*model.Parameter{}
+	for _, parameter := range t.Parameter {
+		results = append(results, parameter)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ParamName() < results[j].ParamName()
+	})
+	return results, nil
+}
+
 // UpdateParameter updates the parameter
 func (t *TestPersister) UpdateParameter(parameter *model.Parameter, updatedFields []string) error {
 	if t.Parameter == nil {
@@ -655,12 +1239,43 @@ func (t *TestPersister) UpdateParameter(parameter *model.Parameter, updatedField
 	return nil
 }
 
+// CreateParameterChange records a change to a parameter value
+func (t *TestPersister) CreateParameterChange(change *model.ParameterChange) error {
+	if t.ParameterChanges == nil {
+		t.ParameterChanges = map[string][]*model.ParameterChange{}
+	}
+	paramName := change.ParamName()
+	t.ParameterChanges[paramName] = append(t.ParameterChanges[paramName], change)
+	return nil
+}
+
+// ParameterHistory returns the changes to the parameter with given name
+func (t *TestPersister) ParameterHistory(paramName string) ([]*model.ParameterChange, error) {
+	changes, ok := t.ParameterChanges[paramName]
+	if !ok {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return changes, nil
+}
+
 // CreateDefaultValues creates Parameter default values
 func (t *TestPersister) CreateDefaultValues(config *utils.ProcessorConfig) error {
+	err := t.UpsertParameterDefaults(config.ParameterizerDefaults())
+	if err != nil {
+		return err
+	}
+	return t.UpsertGovernmentParameterDefaults(config.GovernmentParameterDefaults())
+}
+
+// UpsertParameterDefaults adds default values for parameters that do not exist yet
+func (t *TestPersister) UpsertParameterDefaults(defaults map[string]string) error {
 	if t.Parameter == nil {
 		t.Parameter = map[string]*model.Parameter{}
 	}
-	for paramName, value := range config.ParameterizerDefaults() {
+	for paramName, value := range defaults {
+		if _, ok := t.Parameter[paramName]; ok {
+			continue
+		}
 		val := new(big.Int)
 		_, err := fmt.Sscan(value, val)
 		if err != nil {
@@ -671,6 +1286,124 @@ func (t *TestPersister) CreateDefaultValues(config *utils.ProcessorConfig) error
 	return nil
 }
 
+// UpsertGovernmentParameterDefaults adds default values for government
+// parameters that do not exist yet
+func (t *TestPersister) UpsertGovernmentParameterDefaults(defaults map[string]string) error {
+	if t.GovParameter == nil {
+		t.GovParameter = map[string]*model.GovernmentParameter{}
+	}
+	for paramName, value := range defaults {
+		if _, ok := t.GovParameter[paramName]; ok {
+			continue
+		}
+		val := new(big.Int)
+		_, err := fmt.Sscan(value, val)
+		if err != nil {
+			return err
+		}
+		t.GovParameter[paramName] = model.NewGovernmentParameter(paramName, val)
+	}
+	return nil
+}
+
+// CreateGovernmentParameterProposal creates a new government parameter proposal
+func (t *TestPersister) CreateGovernmentParameterProposal(paramProposal *model.GovernmentParameterProposal) error {
+	propID := paramProposal.PropID()
+	if t.GovParameterProposal == nil {
+		t.GovParameterProposal = map[[32]byte]*model.GovernmentParameterProposal{}
+	}
+	t.GovParameterProposal[propID] = paramProposal
+	return nil
+}
+
+// GovernmentParamProposalByPropID gets a government parameter proposal using propID
+func (t *TestPersister) GovernmentParamProposalByPropID(propID [32]byte, active bool) (*model.GovernmentParameterProposal, error) {
+	paramProposal, ok := t.GovParameterProposal[propID]
+	if !ok {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return paramProposal, nil
+}
+
+// GovernmentParamProposalByName gets government parameter proposals by name
+func (t *TestPersister) GovernmentParamProposalByName(name string, active bool) ([]*model.GovernmentParameterProposal, error) {
+	proposals := []*model.GovernmentParameterProposal{}
+	for _, prop := range t.GovParameterProposal {
+		if name != prop.Name() {
+			continue
+		}
+		if active && prop.Expired() {
+			continue
+		}
+		proposals = append(proposals, prop)
+	}
+	return proposals, nil
+}
+
+// UpdateGovernmentParamProposal updates a government parameter proposal
+func (t *TestPersister) UpdateGovernmentParamProposal(paramProposal *model.GovernmentParameterProposal, updatedFields []string) error {
+	if t.GovParameterProposal == nil {
+		t.GovParameterProposal = map[[32]byte]*model.GovernmentParameterProposal{}
+	}
+	t.GovParameterProposal[paramProposal.PropID()] = paramProposal
+	return nil
+}
+
+// GovernmentParameterByName returns the government parameter with given name
+func (t *TestPersister) GovernmentParameterByName(name string) (*model.GovernmentParameter, error) {
+	parameter, ok := t.GovParameter[name]
+	if !ok {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	return parameter, nil
+}
+
+// GovernmentParametersByName returns a slice of government parameters with given names
+func (t *TestPersister) GovernmentParametersByName(names []string) ([]*model.GovernmentParameter, error) {
+	results := []*model.GovernmentParameter{}
+	for _, paramName := range names {
+		results = append(results, t.GovParameter[paramName])
+	}
+	return results, nil
+}
+
+// GovernmentParametersByNameMap returns a map of government parameters with
+// given names keyed by name
+func (t *TestPersister) GovernmentParametersByNameMap(names []string) (map[string]*model.GovernmentParameter, error) {
+	results := map[string]*model.GovernmentParameter{}
+	for _, paramName := range names {
+		parameter, ok := t.GovParameter[paramName]
+		if ok {
+			results[paramName] = parameter
+		}
+	}
+	return results, nil
+}
+
+// AllGovernmentParameters returns all government parameters ordered by name
+func (t *TestPersister) AllGovernmentParameters() ([]*model.GovernmentParameter, error) {
+	if len(t.GovParameter) == 0 {
+		return nil, cpersist.ErrPersisterNoResults
+	}
+	results := []*model.GovernmentParameter{}
+	for _, parameter := range t.GovParameter {
+		results = append(results, parameter)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ParamName() < results[j].ParamName()
+	})
+	return results, nil
+}
+
+// UpdateGovernmentParameter updates the government parameter
+func (t *TestPersister) UpdateGovernmentParameter(parameter *model.GovernmentParameter, updatedFields []string) error {
+	if t.GovParameter == nil {
+		t.GovParameter = map[string]*model.GovernmentParameter{}
+	}
+	t.GovParameter[parameter.ParamName()] = parameter
+	return nil
+}
+
 // CreateUserChallengeData creates a new UserChallengeData
 func (t *TestPersister) CreateUserChallengeData(userChallengeData *model.UserChallengeData) error {
 	pollID := int(userChallengeData.PollID().Int64())
@@ -699,6 +1432,15 @@ func (t *TestPersister) UserChallengeDataByCriteria(criteria *model.UserChalleng
 	return []*model.UserChallengeData{t.UserChallengeData[pollID][address]}, nil
 }
 
+// CountUserChallengeDataByCriteria returns the number of UserChallengeData matching the criteria
+func (t *TestPersister) CountUserChallengeDataByCriteria(criteria *model.UserChallengeDataCriteria) (int, error) {
+	userChalls, err := t.UserChallengeDataByCriteria(criteria)
+	if err != nil {
+		return 0, err
+	}
+	return len(userChalls), nil
+}
+
 // UpdateUserChallengeData updates UserChallengeData in table
 func (t *TestPersister) UpdateUserChallengeData(userChallengeData *model.UserChallengeData,
 	updatedFields []string, updateWithUserAddress bool, latestVote bool) error {
@@ -4,8 +4,11 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/robfig/cron"
 
@@ -26,15 +29,24 @@ const (
 // If CronConfig is set, will use the cron process.  If it is not set, will setup
 // the subscription to the crawler pubsub to listen for trigger events.
 type ProcessorConfig struct {
-	CronConfig string `envconfig:"cron_config" desc:"Cron config string * * * * *"`
+	CronConfig string `envconfig:"cron_config" desc:"Cron config string with seconds * * * * * *"`
 	EthAPIURL  string `envconfig:"eth_api_url" required:"true" desc:"Ethereum API address"`
 
-	PubSubProjectID         string `split_words:"true" desc:"Sets GPubSub project ID. If not set, will not push or pull events."`
-	PubSubEventsTopicName   string `split_words:"true" desc:"Sets GPubSub topic name for governance events. If not set, will not push events."`
-	PubSubTokenTopicName    string `split_words:"true" desc:"Sets GPubSub topic name for cvltoken events. If not set, will not push events."`
-	PubSubMultiSigTopicName string `split_words:"true" desc:"Sets GPubSub topic name for multi sig events. If not set, will not push events."`
-	PubSubCrawlTopicName    string `split_words:"true" desc:"Sets GPubSub topic name for crawler. Set if using pubsub to run the processor."`
-	PubSubCrawlSubName      string `split_words:"true" desc:"Sets GPubSub subscription name. Needs to be set to run processor using pubsub updates."`
+	TCRContractAddresses []string `envconfig:"tcr_contract_addresses" desc:"Comma separated TCR contract addresses to process events from. If not set, processes events from any TCR."`
+
+	EventFilter     *EventFilter `ignored:"true"`
+	EventFilterJSON string       `split_words:"true" desc:"JSON event filter with contractAddress, eventTypes, fromBlock and toBlock. If set, the cron processor reprocesses the matching events once and exits without updating the last processed event. Requires the cron config."`
+
+	PubSubEnabled           bool              `split_words:"true" default:"true" desc:"Enables pushing events to GPubSub. Set to false to only populate the DB."`
+	PubSubProjectID         string            `split_words:"true" desc:"Sets GPubSub project ID. If not set, will not push or pull events."`
+	PubSubEventsTopicName   string            `split_words:"true" desc:"Sets GPubSub topic name for governance events. If not set, will not push events."`
+	PubSubTokenTopicName    string            `split_words:"true" desc:"Sets GPubSub topic name for cvltoken events. If not set, will not push events."`
+	PubSubMultiSigTopicName string            `split_words:"true" desc:"Sets GPubSub topic name for multi sig events. If not set, will not push events."`
+	PubSubEventTopics       map[string]string `split_words:"true" desc:"<event type>:<topic name>. Routes events of the type to the topic instead of the default topic."`
+	PubSubCrawlTopicName    string            `split_words:"true" desc:"Sets GPubSub topic name for crawler. Set if using pubsub to run the processor."`
+	PubSubCrawlSubName      string            `split_words:"true" desc:"Sets GPubSub subscription name. Needs to be set to run processor using pubsub updates."`
+	PubSubBatchSize         int               `split_words:"true" default:"1" desc:"Sets the max number of crawler messages handled by one processor run before acking them. 1 processes each message on its own."`
+	PubSubBatchSecs         int               `split_words:"true" default:"0" desc:"Sets the max secs to wait for more crawler messages before processing a batch. Must be greater than 0 for a batch size greater than 1."`
 
 	PersisterType             cconfig.PersisterType `ignored:"true"`
 	PersisterTypeName         string                `split_words:"true" required:"true" desc:"Sets the persister type to use"`
@@ -46,9 +58,27 @@ type ProcessorConfig struct {
 	PersisterPostgresMaxConns *int                  `split_words:"true" desc:"If persister type is Postgresql, sets the max conns in pool"`
 	PersisterPostgresMaxIdle  *int                  `split_words:"true" desc:"If persister type is Postgresql, sets the max idle conns in pool"`
 	PersisterPostgresConnLife *int                  `split_words:"true" desc:"If persister type is Postgresql, sets the max conn lifetime in secs"`
+	PersisterPostgresSslMode  string                `split_words:"true" default:"disable" desc:"If persister type is Postgresql, sets the SSL mode (disable, require, verify-ca, verify-full)"`
+	PersisterPostgresSslRoot  string                `split_words:"true" desc:"If persister type is Postgresql, sets the path to the SSL root cert"`
+	PersisterPostgresSslCert  string                `split_words:"true" desc:"If persister type is Postgresql, sets the path to the SSL client cert"`
+	PersisterPostgresSslKey   string                `split_words:"true" desc:"If persister type is Postgresql, sets the path to the SSL client key"`
+	PersisterPostgresSlowMs   int                   `split_words:"true" default:"0" desc:"If persister type is Postgresql, logs queries that take longer than this in millisecs. 0 disables."`
 
 	VersionNumber string `split_words:"true" desc:"Sets the version to use for Postgres tables"`
 
+	ScraperCharterTimeoutSecs  int `split_words:"true" default:"3" desc:"Sets the timeout in secs for each charter scrape request"`
+	ScraperCharterRetries      int `split_words:"true" default:"2" desc:"Sets the number of times to retry a failed charter scrape"`
+	ScraperMetadataTimeoutSecs int `split_words:"true" default:"2" desc:"Sets the timeout in secs for each article metadata scrape request"`
+	ScraperMetadataRetries     int `split_words:"true" default:"0" desc:"Sets the number of times to retry a failed article metadata scrape"`
+
+	ScraperIpfsGatewayURL string `split_words:"true" default:"https://ipfs.infura.io" desc:"Sets the IPFS gateway URL used to retrieve ipfs:// charter and content links"`
+
+	ScraperAllowedHosts []string `split_words:"true" desc:"Comma separated hosts, including subdomains, the scrapers may fetch from. If not set, allows any public host."`
+	ScraperBlockedHosts []string `split_words:"true" desc:"Comma separated hosts, including subdomains, the scrapers may not fetch from"`
+
+	HealthPort      int `split_words:"true" default:"0" desc:"Sets the port to serve the processor status at /health. 0 disables."`
+	HealthStaleSecs int `split_words:"true" default:"0" desc:"Sets the secs since the last processed event after which the processor is reported unhealthy. The lag grows when there are no new events, so only set if events are expected at least this often. 0 never reports stale."`
+
 	StackDriverProjectID string `split_words:"true" desc:"Sets the Stackdriver project ID"`
 	SentryDsn            string `split_words:"true" desc:"Sets the Sentry DSN"`
 	SentryEnv            string `split_words:"true" desc:"Sets the Sentry environment"`
@@ -102,6 +132,32 @@ func (c *ProcessorConfig) PoolConnLifetimeSecs() *int {
 	return c.PersisterPostgresConnLife
 }
 
+// SSLMode returns the postgres persister SSL mode
+func (c *ProcessorConfig) SSLMode() string {
+	return c.PersisterPostgresSslMode
+}
+
+// SSLRootCert returns the path to the postgres persister SSL root cert, if configured
+func (c *ProcessorConfig) SSLRootCert() string {
+	return c.PersisterPostgresSslRoot
+}
+
+// SSLCert returns the path to the postgres persister SSL client cert, if configured
+func (c *ProcessorConfig) SSLCert() string {
+	return c.PersisterPostgresSslCert
+}
+
+// SSLKey returns the path to the postgres persister SSL client key, if configured
+func (c *ProcessorConfig) SSLKey() string {
+	return c.PersisterPostgresSslKey
+}
+
+// SlowQueryThreshold returns the duration after which a persister query is
+// logged as slow, 0 if disabled
+func (c *ProcessorConfig) SlowQueryThreshold() time.Duration {
+	return time.Duration(c.PersisterPostgresSlowMs) * time.Millisecond
+}
+
 // ParameterizerDefaults returns the parameterizer default values
 func (c *ProcessorConfig) ParameterizerDefaults() map[string]string {
 	return c.ParameterizerDefaultValues
@@ -142,6 +198,16 @@ func (c *ProcessorConfig) PopulateFromEnv() error {
 		return err
 	}
 
+	err = c.validateTCRContractAddresses()
+	if err != nil {
+		return err
+	}
+
+	err = c.populateEventFilter()
+	if err != nil {
+		return err
+	}
+
 	err = c.populatePersisterType()
 	if err != nil {
 		return err
@@ -157,14 +223,84 @@ func (c *ProcessorConfig) PopulateFromEnv() error {
 		return err
 	}
 
+	err = c.validateScraperConfig()
+	if err != nil {
+		return err
+	}
+
+	err = c.validateHealthConfig()
+	if err != nil {
+		return err
+	}
+
+	err = c.validatePubSubBatchConfig()
+	if err != nil {
+		return err
+	}
+
 	return c.validatePersister()
 }
 
+// Validate checks the fields required by the processor mode. If CronConfig is
+// set, it must be a valid cron spec. Otherwise the processor runs from the
+// crawler pubsub and requires the project ID, crawl topic and subscription.
+// The event filter is only supported with cron, as a one off run.
+// Should be called after PopulateFromEnv.
+func (c *ProcessorConfig) Validate() error {
+	if c.CronConfig != "" {
+		return c.validateCronConfig()
+	}
+	if c.EventFilter != nil {
+		return errors.New("Event filter requires cron config, not supported with pubsub")
+	}
+	if c.PubSubProjectID == "" {
+		return errors.New("PubSub project ID required when not running with cron config")
+	}
+	if c.PubSubCrawlTopicName == "" {
+		return errors.New("PubSub crawl topic name required when not running with cron config")
+	}
+	if c.PubSubCrawlSubName == "" {
+		return errors.New("PubSub crawl subscription name required when not running with cron config")
+	}
+	return nil
+}
+
 func (c *ProcessorConfig) validateCronConfig() error {
-	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	_, err := parser.Parse(c.CronConfig)
-	if err != nil {
-		return fmt.Errorf("Invalid cron config: '%v'", c.CronConfig)
+	return validateCronSpec(c.CronConfig)
+}
+
+// cronSpecFields are the fields of a cron spec in order, with the option to
+// parse each on its own so errors can point to the offending field
+var cronSpecFields = []struct {
+	name   string
+	option cron.ParseOption
+}{
+	{"second", cron.Second},
+	{"minute", cron.Minute},
+	{"hour", cron.Hour},
+	{"day of month", cron.Dom},
+	{"month", cron.Month},
+	{"day of week", cron.Dow},
+}
+
+// validateCronSpec checks the spec has a valid value for each of the cron
+// fields, seconds first
+func validateCronSpec(spec string) error {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronSpecFields) {
+		return fmt.Errorf(
+			"Invalid cron config: '%v': expected %v fields (second minute hour dom month dow), found %v",
+			spec,
+			len(cronSpecFields),
+			len(fields),
+		)
+	}
+	for i, field := range cronSpecFields {
+		_, err := cron.NewParser(field.option).Parse(fields[i])
+		if err != nil {
+			return fmt.Errorf("Invalid cron config: '%v': bad %v field '%v': %v", spec,
+				field.name, fields[i], err)
+		}
 	}
 	return nil
 }
@@ -176,6 +312,107 @@ func (c *ProcessorConfig) validateAPIURL() error {
 	return nil
 }
 
+func (c *ProcessorConfig) validateTCRContractAddresses() error {
+	for _, address := range c.TCRContractAddresses {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("Invalid TCR contract address: '%v'", address)
+		}
+	}
+	return nil
+}
+
+func (c *ProcessorConfig) populateEventFilter() error {
+	if c.EventFilterJSON == "" {
+		c.EventFilter = nil
+		return nil
+	}
+	filter, err := ParseEventFilter(c.EventFilterJSON)
+	if err != nil {
+		return err
+	}
+	c.EventFilter = filter
+	return nil
+}
+
+func (c *ProcessorConfig) validateHealthConfig() error {
+	if c.HealthPort < 0 || c.HealthPort > 65535 {
+		return fmt.Errorf("Invalid health port: %v", c.HealthPort)
+	}
+	if c.HealthStaleSecs < 0 {
+		return fmt.Errorf("Invalid health stale secs, must be 0 or greater: %v", c.HealthStaleSecs)
+	}
+	return nil
+}
+
+func (c *ProcessorConfig) validatePubSubBatchConfig() error {
+	if c.PubSubBatchSize < 1 {
+		return fmt.Errorf("Invalid pubsub batch size, must be greater than 0: %v", c.PubSubBatchSize)
+	}
+	if c.PubSubBatchSecs < 0 {
+		return fmt.Errorf("Invalid pubsub batch secs, must be 0 or greater: %v", c.PubSubBatchSecs)
+	}
+	// Without a wait, a batch that never fills would never be processed
+	if c.PubSubBatchSize > 1 && c.PubSubBatchSecs == 0 {
+		return fmt.Errorf("Invalid pubsub batch secs, must be greater than 0 for a batch size of %v",
+			c.PubSubBatchSize)
+	}
+	return nil
+}
+
+func (c *ProcessorConfig) validateScraperConfig() error {
+	if c.ScraperCharterTimeoutSecs <= 0 || c.ScraperMetadataTimeoutSecs <= 0 {
+		return fmt.Errorf("Invalid scraper timeout, must be greater than 0: charter: %v, metadata: %v",
+			c.ScraperCharterTimeoutSecs, c.ScraperMetadataTimeoutSecs)
+	}
+	if c.ScraperCharterRetries < 0 || c.ScraperMetadataRetries < 0 {
+		return fmt.Errorf("Invalid scraper retries, must be 0 or greater: charter: %v, metadata: %v",
+			c.ScraperCharterRetries, c.ScraperMetadataRetries)
+	}
+	gatewayURL, err := url.Parse(c.ScraperIpfsGatewayURL)
+	if err != nil || (gatewayURL.Scheme != "http" && gatewayURL.Scheme != "https") ||
+		gatewayURL.Host == "" {
+		return fmt.Errorf("Invalid scraper IPFS gateway URL: '%v'", c.ScraperIpfsGatewayURL)
+	}
+	hosts := append([]string{}, c.ScraperAllowedHosts...)
+	for _, host := range append(hosts, c.ScraperBlockedHosts...) {
+		if strings.TrimSpace(host) == "" || strings.Contains(host, "/") {
+			return fmt.Errorf("Invalid scraper host: '%v'", host)
+		}
+	}
+	return nil
+}
+
+// ScraperCharterTimeout returns the timeout for each charter scrape request
+func (c *ProcessorConfig) ScraperCharterTimeout() time.Duration {
+	return time.Duration(c.ScraperCharterTimeoutSecs) * time.Second
+}
+
+// ScraperMetadataTimeout returns the timeout for each article metadata scrape request
+func (c *ProcessorConfig) ScraperMetadataTimeout() time.Duration {
+	return time.Duration(c.ScraperMetadataTimeoutSecs) * time.Second
+}
+
+// HealthStaleThreshold returns the duration since the last processed event
+// after which the processor is unhealthy, 0 if never stale
+func (c *ProcessorConfig) HealthStaleThreshold() time.Duration {
+	return time.Duration(c.HealthStaleSecs) * time.Second
+}
+
+// PubSubBatchInterval returns the max duration to wait for more crawler
+// messages before processing a batch, 0 if not waiting
+func (c *ProcessorConfig) PubSubBatchInterval() time.Duration {
+	return time.Duration(c.PubSubBatchSecs) * time.Second
+}
+
+// TCRAddresses returns the configured TCR contract addresses
+func (c *ProcessorConfig) TCRAddresses() []common.Address {
+	addresses := make([]common.Address, len(c.TCRContractAddresses))
+	for i, address := range c.TCRContractAddresses {
+		addresses[i] = common.HexToAddress(address)
+	}
+	return addresses
+}
+
 func (c *ProcessorConfig) validatePersister() error {
 	var err error
 	if c.PersisterType == cconfig.PersisterTypePostgresql {
@@ -187,6 +424,22 @@ func (c *ProcessorConfig) validatePersister() error {
 		if err != nil {
 			return err
 		}
+		err = validatePostgresqlPoolParams(
+			c.PersisterPostgresMaxConns,
+			c.PersisterPostgresMaxIdle,
+			c.PersisterPostgresConnLife,
+		)
+		if err != nil {
+			return err
+		}
+		err = validatePostgresqlSSLMode(c.PersisterPostgresSslMode)
+		if err != nil {
+			return err
+		}
+		if c.PersisterPostgresSlowMs < 0 {
+			return fmt.Errorf("Invalid slow query threshold, must be 0 or greater: %v",
+				c.PersisterPostgresSlowMs)
+		}
 	}
 	return nil
 }
@@ -233,3 +486,26 @@ func validatePostgresqlPersisterParams(address string, port int, dbname string)
 	}
 	return nil
 }
+
+func validatePostgresqlPoolParams(maxConns *int, maxIdle *int, connLifetimeSecs *int) error {
+	if maxConns != nil && *maxConns < 0 {
+		return fmt.Errorf("Invalid Postgresql max conns: '%v'", *maxConns)
+	}
+	if maxIdle != nil && *maxIdle < 0 {
+		return fmt.Errorf("Invalid Postgresql max idle conns: '%v'", *maxIdle)
+	}
+	if connLifetimeSecs != nil && *connLifetimeSecs < 0 {
+		return fmt.Errorf("Invalid Postgresql conn lifetime secs: '%v'", *connLifetimeSecs)
+	}
+	return nil
+}
+
+// validatePostgresqlSSLMode checks the SSL mode is one supported by lib/pq, which
+// does not support allow or prefer
+func validatePostgresqlSSLMode(sslMode string) error {
+	switch sslMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+		return nil
+	}
+	return fmt.Errorf("Invalid Postgresql SSL mode: '%v'", sslMode)
+}
@@ -3,7 +3,9 @@ package utils_test
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/joincivil/civil-events-processor/pkg/utils"
 )
@@ -46,6 +48,9 @@ func TestCrawlerConfig(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to populate from environment: err: %v", err)
 	}
+	if !config.PubSubEnabled {
+		t.Errorf("Should have enabled pubsub by default")
+	}
 }
 
 func TestBadPersisterNameCrawlerConfig(t *testing.T) {
@@ -247,3 +252,488 @@ func TestBadCronConfigCrawlerConfig2(t *testing.T) {
 		t.Errorf("Should have failed config: err: %v", err)
 	}
 }
+
+func TestBadPersisterPostgresqlPoolCrawlerConfig(t *testing.T) {
+	os.Setenv(
+		"PROCESSOR_CRON_CONFIG",
+		"* * * * * *",
+	)
+	os.Setenv(
+		"PROCESSOR_ETH_API_URL",
+		"http://ethaddress.com",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_TYPE_NAME",
+		"postgresql",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_ADDRESS",
+		"localhost",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_PORT",
+		"5432",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_DBNAME",
+		"civil_crawler",
+	)
+	os.Setenv(
+		"PROCESSOR_PARAMETERIZER_DEFAULT_VALUES",
+		"minDeposit:50",
+	)
+	os.Setenv(
+		"PROCESSOR_GOVERNMENT_PARAMETER_DEFAULT_VALUES",
+		"appealFee:500",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_MAX_CONNS",
+		"10",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_MAX_IDLE",
+		"2",
+	)
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_CONN_LIFE",
+		"60",
+	)
+	defer os.Unsetenv("PROCESSOR_PERSISTER_POSTGRES_MAX_CONNS")
+	defer os.Unsetenv("PROCESSOR_PERSISTER_POSTGRES_MAX_IDLE")
+	defer os.Unsetenv("PROCESSOR_PERSISTER_POSTGRES_CONN_LIFE")
+
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.PoolMaxConns() == nil || *config.PoolMaxConns() != 10 {
+		t.Errorf("Should have set the max conns")
+	}
+	if config.PoolMaxIdleConns() == nil || *config.PoolMaxIdleConns() != 2 {
+		t.Errorf("Should have set the max idle conns")
+	}
+	if config.PoolConnLifetimeSecs() == nil || *config.PoolConnLifetimeSecs() != 60 {
+		t.Errorf("Should have set the conn lifetime")
+	}
+
+	// Bad max conns
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_MAX_CONNS",
+		"-1",
+	)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow negative max conns from environment")
+	}
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_MAX_CONNS",
+		"10",
+	)
+
+	// Bad conn lifetime
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_CONN_LIFE",
+		"-60",
+	)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow negative conn lifetime from environment")
+	}
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_CONN_LIFE",
+		"60",
+	)
+
+	// Slow query threshold
+	defer os.Unsetenv("PROCESSOR_PERSISTER_POSTGRES_SLOW_MS")
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_SLOW_MS",
+		"250",
+	)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.SlowQueryThreshold() != 250*time.Millisecond {
+		t.Errorf("Should have set the slow query threshold, have %v", config.SlowQueryThreshold())
+	}
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_SLOW_MS",
+		"-1",
+	)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow a negative slow query threshold from environment")
+	}
+	os.Setenv(
+		"PROCESSOR_PERSISTER_POSTGRES_SLOW_MS",
+		"250",
+	)
+
+	// SSL mode, only the modes supported by lib/pq
+	defer os.Unsetenv("PROCESSOR_PERSISTER_POSTGRES_SSL_MODE")
+	for _, mode := range []string{"disable", "require", "verify-ca", "verify-full"} {
+		os.Setenv("PROCESSOR_PERSISTER_POSTGRES_SSL_MODE", mode)
+		config = &utils.ProcessorConfig{}
+		err = config.PopulateFromEnv()
+		if err != nil {
+			t.Errorf("Should have allowed SSL mode %v: err: %v", mode, err)
+		}
+	}
+	for _, mode := range []string{"allow", "prefer", "required"} {
+		os.Setenv("PROCESSOR_PERSISTER_POSTGRES_SSL_MODE", mode)
+		config = &utils.ProcessorConfig{}
+		err = config.PopulateFromEnv()
+		if err == nil {
+			t.Errorf("Should have failed to allow SSL mode %v", mode)
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	configs := []*utils.ProcessorConfig{
+		{CronConfig: "* * * * * *"},
+		{
+			CronConfig:  "* * * * * *",
+			EventFilter: &utils.EventFilter{EventTypes: []string{"Application"}},
+		},
+		{
+			PubSubProjectID:      "project",
+			PubSubCrawlTopicName: "crawl",
+			PubSubCrawlSubName:   "crawl-sub",
+		},
+	}
+	for _, config := range configs {
+		err := config.Validate()
+		if err != nil {
+			t.Errorf("Should have validated config: err: %v", err)
+		}
+	}
+}
+
+func TestValidateBadConfig(t *testing.T) {
+	configs := []*utils.ProcessorConfig{
+		// Bad cron spec
+		{CronConfig: "* *"},
+		// Bad cron spec, pubsub config is not used in cron mode
+		{
+			CronConfig:           "* * * * * 145",
+			PubSubProjectID:      "project",
+			PubSubCrawlTopicName: "crawl",
+			PubSubCrawlSubName:   "crawl-sub",
+		},
+		// No cron or pubsub config
+		{},
+		// Missing pubsub project ID
+		{
+			PubSubCrawlTopicName: "crawl",
+			PubSubCrawlSubName:   "crawl-sub",
+		},
+		// Missing pubsub crawl topic
+		{
+			PubSubProjectID:    "project",
+			PubSubCrawlSubName: "crawl-sub",
+		},
+		// Missing pubsub crawl subscription
+		{
+			PubSubProjectID:      "project",
+			PubSubCrawlTopicName: "crawl",
+		},
+		// Event filter is not supported with pubsub
+		{
+			PubSubProjectID:      "project",
+			PubSubCrawlTopicName: "crawl",
+			PubSubCrawlSubName:   "crawl-sub",
+			EventFilter:          &utils.EventFilter{EventTypes: []string{"Application"}},
+		},
+	}
+	for index, config := range configs {
+		err := config.Validate()
+		if err == nil {
+			t.Errorf("Should have failed to validate config %v", index)
+		}
+	}
+}
+
+func TestValidateCronSpec(t *testing.T) {
+	validSpecs := []string{
+		"* * * * * *",
+		"0 */5 * * * *",
+		"30 0 12 1-15 * MON-FRI",
+	}
+	for _, spec := range validSpecs {
+		config := &utils.ProcessorConfig{CronConfig: spec}
+		err := config.Validate()
+		if err != nil {
+			t.Errorf("Should have validated cron spec '%v': err: %v", spec, err)
+		}
+	}
+
+	invalidSpecs := map[string]string{
+		"* *":               "expected 6 fields",
+		"* * * * *":         "expected 6 fields",
+		"* * * * * * *":     "expected 6 fields",
+		"61 * * * * *":      "second field '61'",
+		"* 0-60 * * * *":    "minute field '0-60'",
+		"* * 25 * * *":      "hour field '25'",
+		"* * * 0 * *":       "day of month field '0'",
+		"* * * * 13 *":      "month field '13'",
+		"* * * * * 145":     "day of week field '145'",
+		"* * * * * */0":     "day of week field '*/0'",
+		"* * * * JANUARY *": "month field 'JANUARY'",
+	}
+	for spec, expected := range invalidSpecs {
+		config := &utils.ProcessorConfig{CronConfig: spec}
+		err := config.Validate()
+		if err == nil {
+			t.Errorf("Should have failed to validate cron spec '%v'", spec)
+			continue
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Error for cron spec '%v' should contain '%v': err: %v", spec, expected, err)
+		}
+	}
+}
+
+func TestTCRContractAddressesConfig(t *testing.T) {
+	defer os.Unsetenv("PROCESSOR_TCR_CONTRACT_ADDRESSES")
+	os.Setenv(
+		"PROCESSOR_TCR_CONTRACT_ADDRESSES",
+		"0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d,0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A",
+	)
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	addresses := config.TCRAddresses()
+	if len(addresses) != 2 {
+		t.Fatalf("Should have 2 TCR addresses, have %v", len(addresses))
+	}
+	if !strings.EqualFold(addresses[1].Hex(), "0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A") {
+		t.Errorf("Should have parsed the TCR address: %v", addresses[1].Hex())
+	}
+
+	os.Setenv(
+		"PROCESSOR_TCR_CONTRACT_ADDRESSES",
+		"0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d,notanaddress",
+	)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow bad TCR contract address from environment")
+	}
+}
+
+func TestHealthConfig(t *testing.T) {
+	defer os.Unsetenv("PROCESSOR_HEALTH_PORT")
+	defer os.Unsetenv("PROCESSOR_HEALTH_STALE_SECS")
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.HealthPort != 0 {
+		t.Errorf("Should have disabled the health server by default: %v", config.HealthPort)
+	}
+	if config.HealthStaleThreshold() != 0 {
+		t.Errorf("Should have defaulted to never reporting stale: %v", config.HealthStaleThreshold())
+	}
+
+	os.Setenv("PROCESSOR_HEALTH_PORT", "8080")
+	os.Setenv("PROCESSOR_HEALTH_STALE_SECS", "600")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.HealthPort != 8080 || config.HealthStaleThreshold() != 10*time.Minute {
+		t.Errorf("Should have set the health config: %v, %v", config.HealthPort,
+			config.HealthStaleThreshold())
+	}
+
+	os.Setenv("PROCESSOR_HEALTH_STALE_SECS", "-1")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow negative health stale secs")
+	}
+}
+
+func TestPubSubBatchConfig(t *testing.T) {
+	defer os.Unsetenv("PROCESSOR_PUB_SUB_BATCH_SIZE")
+	defer os.Unsetenv("PROCESSOR_PUB_SUB_BATCH_SECS")
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.PubSubBatchSize != 1 || config.PubSubBatchInterval() != 0 {
+		t.Errorf("Should have defaulted to processing each message: %v, %v",
+			config.PubSubBatchSize, config.PubSubBatchInterval())
+	}
+
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SIZE", "20")
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SECS", "5")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.PubSubBatchSize != 20 || config.PubSubBatchInterval() != 5*time.Second {
+		t.Errorf("Should have set the batch config: %v, %v", config.PubSubBatchSize,
+			config.PubSubBatchInterval())
+	}
+
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SIZE", "0")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow a batch size of 0")
+	}
+
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SIZE", "20")
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SECS", "0")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow a batch size without batch secs")
+	}
+
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SIZE", "1")
+	os.Setenv("PROCESSOR_PUB_SUB_BATCH_SECS", "-1")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow negative batch secs")
+	}
+}
+
+func TestEventFilterConfig(t *testing.T) {
+	defer os.Unsetenv("PROCESSOR_EVENT_FILTER_JSON")
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.EventFilter != nil {
+		t.Errorf("Should not have an event filter if not set")
+	}
+
+	os.Setenv(
+		"PROCESSOR_EVENT_FILTER_JSON",
+		`{"contractAddress": "0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d", "eventTypes": ["Challenge"]}`,
+	)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.EventFilter == nil {
+		t.Fatalf("Should have parsed the event filter")
+	}
+	if len(config.EventFilter.EventTypes) != 1 || config.EventFilter.EventTypes[0] != "Challenge" {
+		t.Errorf("Should have parsed the event filter types: %v", config.EventFilter.EventTypes)
+	}
+
+	os.Setenv("PROCESSOR_EVENT_FILTER_JSON", `{"toBlock": "notanumber"}`)
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow bad event filter from environment")
+	}
+}
+
+func TestScraperConfig(t *testing.T) {
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.ScraperCharterTimeout() != 3*time.Second {
+		t.Errorf("Should have defaulted the charter timeout, have %v", config.ScraperCharterTimeout())
+	}
+	if config.ScraperMetadataTimeout() != 2*time.Second {
+		t.Errorf("Should have defaulted the metadata timeout, have %v", config.ScraperMetadataTimeout())
+	}
+	if config.ScraperCharterRetries != 2 || config.ScraperMetadataRetries != 0 {
+		t.Errorf("Should have defaulted the retries, have %v, %v", config.ScraperCharterRetries,
+			config.ScraperMetadataRetries)
+	}
+
+	defer os.Unsetenv("PROCESSOR_SCRAPER_METADATA_TIMEOUT_SECS")
+	os.Setenv("PROCESSOR_SCRAPER_METADATA_TIMEOUT_SECS", "0")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow a 0 scraper timeout from environment")
+	}
+
+	os.Setenv("PROCESSOR_SCRAPER_METADATA_TIMEOUT_SECS", "5")
+	defer os.Unsetenv("PROCESSOR_SCRAPER_CHARTER_RETRIES")
+	os.Setenv("PROCESSOR_SCRAPER_CHARTER_RETRIES", "-1")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow negative scraper retries from environment")
+	}
+}
+
+func TestScraperIPFSGatewayConfig(t *testing.T) {
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.ScraperIpfsGatewayURL != utils.DefaultIPFSGatewayURL {
+		t.Errorf("Should have defaulted the IPFS gateway, have %v", config.ScraperIpfsGatewayURL)
+	}
+
+	defer os.Unsetenv("PROCESSOR_SCRAPER_IPFS_GATEWAY_URL")
+	os.Setenv("PROCESSOR_SCRAPER_IPFS_GATEWAY_URL", "http://localhost:8080")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if config.ScraperIpfsGatewayURL != "http://localhost:8080" {
+		t.Errorf("Should have set the IPFS gateway, have %v", config.ScraperIpfsGatewayURL)
+	}
+
+	os.Setenv("PROCESSOR_SCRAPER_IPFS_GATEWAY_URL", "ipfs.infura.io")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow an IPFS gateway without a scheme")
+	}
+}
+
+func TestScraperHostsConfig(t *testing.T) {
+	defer os.Unsetenv("PROCESSOR_SCRAPER_ALLOWED_HOSTS")
+	defer os.Unsetenv("PROCESSOR_SCRAPER_BLOCKED_HOSTS")
+	os.Setenv("PROCESSOR_SCRAPER_ALLOWED_HOSTS", "civil.co,ipfs.infura.io")
+	os.Setenv("PROCESSOR_SCRAPER_BLOCKED_HOSTS", "bad.civil.co")
+	config := &utils.ProcessorConfig{}
+	err := config.PopulateFromEnv()
+	if err != nil {
+		t.Errorf("Failed to populate from environment: err: %v", err)
+	}
+	if len(config.ScraperAllowedHosts) != 2 || config.ScraperAllowedHosts[1] != "ipfs.infura.io" {
+		t.Errorf("Should have set the allowed hosts: %v", config.ScraperAllowedHosts)
+	}
+	if len(config.ScraperBlockedHosts) != 1 || config.ScraperBlockedHosts[0] != "bad.civil.co" {
+		t.Errorf("Should have set the blocked hosts: %v", config.ScraperBlockedHosts)
+	}
+
+	os.Setenv("PROCESSOR_SCRAPER_BLOCKED_HOSTS", "https://bad.civil.co/path")
+	config = &utils.ProcessorConfig{}
+	err = config.PopulateFromEnv()
+	if err == nil {
+		t.Errorf("Should have failed to allow a URL as a scraper host")
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EventFilter limits the crawler events retrieved for processing, used to
+// reprocess a subset of events. Empty fields match all events. Block numbers
+// are inclusive and ToBlock of 0 has no upper bound.
+type EventFilter struct {
+	ContractAddress string   `json:"contractAddress"`
+	EventTypes      []string `json:"eventTypes"`
+	FromBlock       uint64   `json:"fromBlock"`
+	ToBlock         uint64   `json:"toBlock"`
+}
+
+// ParseEventFilter parses and validates a JSON encoded EventFilter in the form
+// {"contractAddress": "0x...", "eventTypes": ["Challenge"], "fromBlock": 1, "toBlock": 2}
+func ParseEventFilter(filterJSON string) (*EventFilter, error) {
+	filter := &EventFilter{}
+	err := json.Unmarshal([]byte(filterJSON), filter)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid event filter: '%v': %v", filterJSON, err)
+	}
+	if filter.ContractAddress != "" {
+		if !common.IsHexAddress(filter.ContractAddress) {
+			return nil, fmt.Errorf("Invalid event filter contract address: '%v'", filter.ContractAddress)
+		}
+		filter.ContractAddress = common.HexToAddress(filter.ContractAddress).Hex()
+	}
+	if filter.ToBlock != 0 && filter.FromBlock > filter.ToBlock {
+		return nil, fmt.Errorf("Invalid event filter block range: from %v is after to %v",
+			filter.FromBlock, filter.ToBlock)
+	}
+	return filter, nil
+}
+
+// Matches returns true if an event with the given contract address, type and
+// block number passes the filter
+func (f *EventFilter) Matches(contractAddress common.Address, eventType string,
+	blockNumber uint64) bool {
+	if f.ContractAddress != "" && common.HexToAddress(f.ContractAddress) != contractAddress {
+		return false
+	}
+	if len(f.EventTypes) > 0 {
+		found := false
+		for _, filterType := range f.EventTypes {
+			if filterType == eventType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if blockNumber < f.FromBlock {
+		return false
+	}
+	if f.ToBlock != 0 && blockNumber > f.ToBlock {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/joincivil/civil-events-processor/pkg/utils"
+)
+
+func TestParseEventFilter(t *testing.T) {
+	filter, err := utils.ParseEventFilter(`{
+		"contractAddress": "0x77e5aabddb760fba989a1c4b2cdd4aa8fa3d311d",
+		"eventTypes": ["Challenge", "Appeal"],
+		"fromBlock": 10,
+		"toBlock": 20
+	}`)
+	if err != nil {
+		t.Fatalf("Should have parsed event filter: err: %v", err)
+	}
+	if filter.ContractAddress != "0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d" {
+		t.Errorf("Should have normalized the contract address: %v", filter.ContractAddress)
+	}
+	if len(filter.EventTypes) != 2 || filter.EventTypes[0] != "Challenge" {
+		t.Errorf("Should have parsed the event types: %v", filter.EventTypes)
+	}
+	if filter.FromBlock != 10 || filter.ToBlock != 20 {
+		t.Errorf("Should have parsed the block range: %v, %v", filter.FromBlock, filter.ToBlock)
+	}
+
+	filter, err = utils.ParseEventFilter(`{}`)
+	if err != nil {
+		t.Errorf("Should have parsed empty event filter: err: %v", err)
+	}
+	if filter.ContractAddress != "" || len(filter.EventTypes) != 0 {
+		t.Errorf("Should have had an empty event filter")
+	}
+
+	badFilters := []string{
+		`notjson`,
+		`{"contractAddress": "notanaddress"}`,
+		`{"fromBlock": 20, "toBlock": 10}`,
+		`{"fromBlock": -1}`,
+	}
+	for _, badFilter := range badFilters {
+		_, err = utils.ParseEventFilter(badFilter)
+		if err == nil {
+			t.Errorf("Should have failed to parse bad event filter: %v", badFilter)
+		}
+	}
+}
+
+func TestEventFilterMatches(t *testing.T) {
+	addr := common.HexToAddress("0x77e5aaBddb760FBa989A1C4B2CDd4aA8Fa3d311d")
+	otherAddr := common.HexToAddress("0x39eB24C2a2a1b6d1D4fDF2F3d6E1F8Dbb1C1Ac3A")
+	filter := &utils.EventFilter{
+		ContractAddress: addr.Hex(),
+		EventTypes:      []string{"Challenge"},
+		FromBlock:       10,
+		ToBlock:         20,
+	}
+	if !filter.Matches(addr, "Challenge", 10) || !filter.Matches(addr, "Challenge", 20) {
+		t.Errorf("Should have matched events in the inclusive block range")
+	}
+	if filter.Matches(otherAddr, "Challenge", 15) {
+		t.Errorf("Should not have matched event from another contract")
+	}
+	if filter.Matches(addr, "Application", 15) {
+		t.Errorf("Should not have matched event of another type")
+	}
+	if filter.Matches(addr, "Challenge", 9) || filter.Matches(addr, "Challenge", 21) {
+		t.Errorf("Should not have matched events outside the block range")
+	}
+
+	filter = &utils.EventFilter{}
+	if !filter.Matches(otherAddr, "Application", 1000000) {
+		t.Errorf("Should have matched any event with an empty filter")
+	}
+}
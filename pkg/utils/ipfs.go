@@ -11,13 +11,34 @@ import (
 )
 
 const (
-	ipfsNodeURI = "https://ipfs.infura.io"
-	timeout     = 3 * time.Second
+	// DefaultIPFSGatewayURL is the IPFS gateway used to retrieve IPFS links
+	// if one is not configured
+	DefaultIPFSGatewayURL = "https://ipfs.infura.io"
+
+	timeout = 3 * time.Second
+
+	defaultMaxAttempts = 3
 )
 
 // RetrieveIPFSLink retrieves data from a given IPFS link via the given IPFS
 // node
 func RetrieveIPFSLink(uri string) ([]byte, error) {
+	return RetrieveIPFSLinkWithTimeout(uri, timeout, defaultMaxAttempts)
+}
+
+// RetrieveIPFSLinkWithTimeout retrieves data from a given IPFS link via the
+// given IPFS node, timing out each request after the given duration and making
+// up to maxAttempts requests
+func RetrieveIPFSLinkWithTimeout(uri string, reqTimeout time.Duration, maxAttempts int) ([]byte, error) {
+	return RetrieveIPFSLinkFromGateway(uri, DefaultIPFSGatewayURL, reqTimeout, maxAttempts)
+}
+
+// RetrieveIPFSLinkFromGateway retrieves data from a given IPFS link via the
+// IPFS gateway at gatewayURL, timing out each request after the given duration
+// and making up to maxAttempts requests. ipfs://CID/path is retrieved from
+// <gatewayURL>/ipfs/CID/path.
+func RetrieveIPFSLinkFromGateway(uri string, gatewayURL string, reqTimeout time.Duration,
+	maxAttempts int) ([]byte, error) {
 	if !strings.HasPrefix(uri, "ipfs://") {
 		return nil, fmt.Errorf("Invalid IPFS link: %v", uri)
 	}
@@ -27,18 +48,23 @@ func RetrieveIPFSLink(uri string) ([]byte, error) {
 		return nil, err
 	}
 
-	addr := u.Host
-	client := chttp.NewRestHelperWithTimeout(ipfsNodeURI, "", timeout)
-	targetURI := fmt.Sprintf("ipfs/%v", addr)
+	if u.Host == "" {
+		return nil, fmt.Errorf("No CID in IPFS link: %v", uri)
+	}
+	if gatewayURL == "" {
+		gatewayURL = DefaultIPFSGatewayURL
+	}
+
+	client := chttp.NewRestHelperWithTimeout(strings.TrimSuffix(gatewayURL, "/"), "", reqTimeout)
+	targetURI := fmt.Sprintf("ipfs/%v%v", u.Host, strings.TrimSuffix(u.Path, "/"))
 
-	maxAtts := 3
 	baseWaitMs := 500
 	return client.SendRequestWithRetry(
 		targetURI,
 		http.MethodGet,
 		nil,
 		nil,
-		maxAtts,
+		maxAttempts,
 		baseWaitMs,
 	)
 }
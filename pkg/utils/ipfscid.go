@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// multibasePrefixes are the prefixes of the multibase encodings a CIDv1 can
+	// be encoded with, of which only base58btc and base32 are decoded
+	multibasePrefixes = "0179fFvVtTcCbBhkKzZmMuUp"
+
+	cidCodecRaw     = 0x55
+	cidCodecDagPB   = 0x70
+	multihashSha2   = 0x12
+	multihashKeccak = 0x1b
+
+	// unixFSMaxChunkSize is the default IPFS chunk size. Files larger than this
+	// are split into multiple blocks and cannot be verified from the content alone.
+	unixFSMaxChunkSize = 262144
+)
+
+// ErrIPFSContentMismatch is returned when the retrieved IPFS content does not
+// hash to the CID in the IPFS link
+var ErrIPFSContentMismatch = errors.New("IPFS content does not match CID")
+
+// errUnsupportedCIDEncoding is returned when decoding a CID with a valid
+// multibase encoding that is not supported
+var errUnsupportedCIDEncoding = errors.New("Unsupported CID encoding")
+
+// VerifyIPFSContent checks that the given content hashes to the CID in the
+// given IPFS link. Returns true if the content was verified, false if the CID
+// could not be checked, such as for unsupported hashes or CID encodings,
+// multi-block files or links to paths within a directory. Returns ErrIPFSContentMismatch if the
+// content does not match the CID.
+func VerifyIPFSContent(uri string, content []byte) (bool, error) {
+	if !strings.HasPrefix(uri, "ipfs://") {
+		return false, fmt.Errorf("Invalid IPFS link: %v", uri)
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false, err
+	}
+	if strings.Trim(u.Path, "/") != "" {
+		return false, nil
+	}
+
+	codec, hashCode, digest, err := decodeCID(u.Host)
+	if err == errUnsupportedCIDEncoding {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	block := content
+	switch codec {
+	case cidCodecRaw:
+	case cidCodecDagPB:
+		if len(content) > unixFSMaxChunkSize {
+			return false, nil
+		}
+		block = unixFSFileBlock(content)
+	default:
+		return false, nil
+	}
+
+	var hash []byte
+	switch hashCode {
+	case multihashSha2:
+		sum := sha256.Sum256(block)
+		hash = sum[:]
+	case multihashKeccak:
+		hash = crypto.Keccak256(block)
+	default:
+		return false, nil
+	}
+
+	if !bytes.Equal(hash, digest) {
+		return false, ErrIPFSContentMismatch
+	}
+	return true, nil
+}
+
+// decodeCID returns the codec, multihash code and digest of the given CID.
+// Supports CIDv0 and base58btc and base32 CIDv1. Returns
+// errUnsupportedCIDEncoding for CIDv1 with any other multibase encoding.
+func decodeCID(cid string) (uint64, uint64, []byte, error) {
+	if len(cid) == 46 && strings.HasPrefix(cid, "Qm") {
+		bys, err := decodeBase58(cid)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		hashCode, digest, err := decodeMultihash(bys)
+		return cidCodecDagPB, hashCode, digest, err
+	}
+	if len(cid) < 2 {
+		return 0, 0, nil, fmt.Errorf("Invalid CID: %v", cid)
+	}
+
+	var bys []byte
+	var err error
+	switch cid[0] {
+	case 'z':
+		bys, err = decodeBase58(cid[1:])
+	case 'b':
+		bys, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(
+			strings.ToUpper(cid[1:]))
+	default:
+		if strings.IndexByte(multibasePrefixes, cid[0]) >= 0 {
+			return 0, 0, nil, errUnsupportedCIDEncoding
+		}
+		return 0, 0, nil, fmt.Errorf("Invalid CID encoding: %v", cid)
+	}
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("Invalid CID: %v: %v", cid, err)
+	}
+
+	version, n := binary.Uvarint(bys)
+	if n <= 0 || version != 1 {
+		return 0, 0, nil, fmt.Errorf("Unsupported CID version: %v", cid)
+	}
+	bys = bys[n:]
+	codec, n := binary.Uvarint(bys)
+	if n <= 0 {
+		return 0, 0, nil, fmt.Errorf("Invalid CID codec: %v", cid)
+	}
+	hashCode, digest, err := decodeMultihash(bys[n:])
+	return codec, hashCode, digest, err
+}
+
+func decodeMultihash(bys []byte) (uint64, []byte, error) {
+	hashCode, n := binary.Uvarint(bys)
+	if n <= 0 {
+		return 0, nil, errors.New("Invalid multihash code")
+	}
+	bys = bys[n:]
+	length, n := binary.Uvarint(bys)
+	if n <= 0 || uint64(len(bys[n:])) != length {
+		return 0, nil, errors.New("Invalid multihash length")
+	}
+	return hashCode, bys[n:], nil
+}
+
+func decodeBase58(str string) ([]byte, error) {
+	num := new(big.Int)
+	radix := big.NewInt(58)
+	leadingZeros := 0
+	for i, c := range str {
+		index := strings.IndexRune(base58Alphabet, c)
+		if index < 0 {
+			return nil, fmt.Errorf("Invalid base58 character: %q", c)
+		}
+		if index == 0 && leadingZeros == i {
+			leadingZeros++
+		}
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(index)))
+	}
+	return append(make([]byte, leadingZeros), num.Bytes()...), nil
+}
+
+// unixFSFileBlock returns the dag-pb block for a single chunk UnixFS file with
+// the given content, as created by adding the file to IPFS
+func unixFSFileBlock(content []byte) []byte {
+	unixFS := []byte{0x08, 0x02}
+	if len(content) > 0 {
+		unixFS = append(unixFS, 0x12)
+		unixFS = appendUvarint(unixFS, uint64(len(content)))
+		unixFS = append(unixFS, content...)
+	}
+	unixFS = append(unixFS, 0x18)
+	unixFS = appendUvarint(unixFS, uint64(len(content)))
+
+	block := []byte{0x0a}
+	block = appendUvarint(block, uint64(len(unixFS)))
+	return append(block, unixFS...)
+}
+
+func appendUvarint(bys []byte, value uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, value)
+	return append(bys, buf[:n]...)
+}
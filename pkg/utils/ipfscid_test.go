@@ -0,0 +1,99 @@
+package utils_test
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-events-processor/pkg/utils"
+)
+
+const (
+	// CIDv0 of "hello world\n" added to IPFS
+	testHelloWorldCIDv0 = "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"
+	// CIDv0 of an empty file added to IPFS
+	testEmptyCIDv0 = "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"
+)
+
+func rawCIDv1(content []byte) string {
+	sum := sha256.Sum256(content)
+	bys := append([]byte{0x01, 0x55, 0x12, 0x20}, sum[:]...)
+	return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bys))
+}
+
+func TestVerifyIPFSContentCIDv0(t *testing.T) {
+	verified, err := utils.VerifyIPFSContent("ipfs://"+testHelloWorldCIDv0, []byte("hello world\n"))
+	if err != nil {
+		t.Errorf("Should not have gotten error verifying content: err: %v", err)
+	}
+	if !verified {
+		t.Errorf("Should have verified the content")
+	}
+
+	verified, err = utils.VerifyIPFSContent("ipfs://"+testEmptyCIDv0, []byte{})
+	if err != nil || !verified {
+		t.Errorf("Should have verified the empty content: err: %v", err)
+	}
+
+	verified, err = utils.VerifyIPFSContent("ipfs://"+testHelloWorldCIDv0, []byte("goodbye world\n"))
+	if err != utils.ErrIPFSContentMismatch {
+		t.Errorf("Should have gotten a mismatch error: err: %v", err)
+	}
+	if verified {
+		t.Errorf("Should not have verified mismatched content")
+	}
+}
+
+func TestVerifyIPFSContentCIDv1(t *testing.T) {
+	content := []byte(`{"name": "The Colorado Sun"}`)
+	cid := rawCIDv1(content)
+
+	verified, err := utils.VerifyIPFSContent("ipfs://"+cid, content)
+	if err != nil || !verified {
+		t.Errorf("Should have verified the content: err: %v", err)
+	}
+
+	_, err = utils.VerifyIPFSContent("ipfs://"+cid, []byte(`{"name": "Fake"}`))
+	if err != utils.ErrIPFSContentMismatch {
+		t.Errorf("Should have gotten a mismatch error: err: %v", err)
+	}
+}
+
+func TestVerifyIPFSContentUnverifiable(t *testing.T) {
+	verified, err := utils.VerifyIPFSContent("ipfs://"+testHelloWorldCIDv0+"/charter.json",
+		[]byte("hello world\n"))
+	if err != nil {
+		t.Errorf("Should not have gotten error for a path in a directory: err: %v", err)
+	}
+	if verified {
+		t.Errorf("Should not have verified content for a path in a directory")
+	}
+
+	// base36 CIDv1
+	verified, err = utils.VerifyIPFSContent(
+		"ipfs://k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8",
+		[]byte("hello world\n"))
+	if err != nil {
+		t.Errorf("Should not have gotten error for an unsupported CID encoding: err: %v", err)
+	}
+	if verified {
+		t.Errorf("Should not have verified content for an unsupported CID encoding")
+	}
+}
+
+func TestVerifyIPFSContentInvalid(t *testing.T) {
+	invalid := []string{
+		"https://civil.co",
+		"ipfs://",
+		"ipfs://Qm0000000000000000000000000000000000000000000",
+		"ipfs://xnotacid",
+		"ipfs://bnotbase32!",
+	}
+	for _, uri := range invalid {
+		_, err := utils.VerifyIPFSContent(uri, []byte("hello world\n"))
+		if err == nil {
+			t.Errorf("Should have gotten error for invalid link %v", uri)
+		}
+	}
+}